@@ -2,13 +2,149 @@ package config
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 )
 
+//Policy is the confirmation policy of a service type
+type Policy struct {
+	//Threshold is the confident which a submission need to be confirmed
+	Threshold int `json:"threshold"`
+	//RejectThreshold is the net confident which a submission is rejected when it is fallen to, 0 is disabled
+	RejectThreshold int `json:"reject-threshold"`
+	//MinVoters is the number of distinct upvoters which a submission need to be confirmed
+	MinVoters int `json:"min-voters"`
+	//TrustedSources are the users whose upvote confirm a submission at once
+	TrustedSources []string `json:"trusted-sources"`
+	//MaxAgeDays is the number of days which a pending submission is archived after when it isn't voted, 0 is disabled
+	MaxAgeDays int `json:"max-age-days"`
+	//SpamRejections is the number of rejected submissions which a contributor is flagged as spam after, 0 is disabled
+	SpamRejections int `json:"spam-rejections"`
+	//DecayDays is the number of days which the confident of a service lose 1 after without positive signals, 0 is disabled
+	DecayDays int `json:"decay-days"`
+	//StaleDays is the number of days which a listed service need to be re-verified after without positive signals, 0 is disabled
+	StaleDays int `json:"stale-days"`
+	//ReportThreshold is the number of concurring problem reports which change the state of a service, 0 is disabled
+	ReportThreshold int `json:"report-threshold"`
+}
+
+//DefaultPolicy is used for the service types which are not configured in `policies`
+var DefaultPolicy Policy = Policy{Threshold: 5}
+
+//IsTrusted determine whether user is a trusted source of the policy
+func (p Policy) IsTrusted(user string) bool {
+	for _, source := range p.TrustedSources {
+		if source == user {
+			return true
+		}
+	}
+
+	return false
+}
+
+//JwtKey is a key which the tokens are signed or verified by, the HMAC keys have Secret and the RSA and ECDSA keys
+//are read from the PEM files. A key without the private key only verifies the tokens, it is useful for rotation
+type JwtKey struct {
+	//Kid identify the key in the `kid` header of the tokens
+	Kid string `json:"kid"`
+	//Algorithm is HS256, RS256 or ES256, HS256 is used when it is empty
+	Algorithm string `json:"algorithm"`
+	Secret    string `json:"secret"`
+	//SecretEnv is the environment variable and SecretFile is the path of the file which the secret is read from, so
+	//the secret doesn't need to be written in the config. Relative paths are from the config directory
+	SecretEnv  string `json:"secret-env"`
+	SecretFile string `json:"secret-file"`
+	//PrivateKeyFile and PublicKeyFile are the paths of the PEM files, relative paths are from the config directory
+	PrivateKeyFile string `json:"private-key-file"`
+	PublicKeyFile  string `json:"public-key-file"`
+}
+
+//JwtConfig is the configuration of the tokens which are authenticating the users
+type JwtConfig struct {
+	//Kid is the key which the new tokens are signed by, the first key is used when it is empty
+	Kid  string   `json:"kid"`
+	Keys []JwtKey `json:"keys"`
+	//Issuer and Audience are put into the new tokens and required in the received tokens when they are not empty
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+	//ExpireMinutes is the lifetime of the new tokens, 10 minutes is used when it is 0
+	ExpireMinutes int `json:"expire-minutes"`
+}
+
+//RateLimit is the token bucket of a route group, a client can make Burst requests at once and Requests requests in
+//every PerSeconds after that
+type RateLimit struct {
+	//Paths are the patterns of the paths in the group, see path.Match. The group without paths is used for the
+	//requests which are not in the other groups
+	Paths      []string `json:"paths"`
+	Requests   int      `json:"requests"`
+	PerSeconds int      `json:"per-seconds"`
+	//Burst is the size of the bucket, Requests is used when it is 0
+	Burst int `json:"burst"`
+}
+
+//SigningConfig is the configuration of the signed requests, the requests of Paths must be signed by a client
+type SigningConfig struct {
+	//Clients are the secrets by client id
+	Clients map[string]string `json:"clients"`
+	//Paths are the patterns of the paths which need the signature, see path.Match
+	Paths []string `json:"paths"`
+	//SkewSeconds is the difference which is allowed between the timestamp and the server clock, 300 is used when
+	//it is 0
+	SkewSeconds int `json:"skew-seconds"`
+	//MaxBodyBytes is the size of the largest body which is read to be verified, 32MB is used when it is 0
+	MaxBodyBytes int64 `json:"max-body-bytes"`
+}
+
+//Deprecation deprecate the api versions which are lower than Below
+type Deprecation struct {
+	Below string `json:"below"`
+	//Sunset is the date (2006-01-02) which the versions are removed after
+	Sunset string `json:"sunset"`
+	//Link is the document of the migration
+	Link string `json:"link"`
+}
+
+//VersionConfig is the api versions which are accepted by the services
+type VersionConfig struct {
+	Min        string        `json:"min"`
+	Max        string        `json:"max"`
+	Deprecated []Deprecation `json:"deprecated"`
+}
+
+//CachePolicy is the `Cache-Control` of the responses of a route group
+type CachePolicy struct {
+	//Paths are the patterns of the paths in the group, see path.Match. The group without paths is used for the
+	//requests which are not in the other groups
+	Paths []string `json:"paths"`
+	//MaxAge is the seconds which the responses are fresh for, they are revalidated by their ETag when it is 0
+	MaxAge int `json:"max-age"`
+	//Public allow the shared caches like CDNs to keep the responses, the responses of the requests with a token
+	//are always private
+	Public bool `json:"public"`
+	//NoStore forbid the caches to keep the responses
+	NoStore bool `json:"no-store"`
+}
+
+//PagingConfig is the page sizes of the listings
+type PagingConfig struct {
+	//DefaultLimit is the size of the pages which have no limit, 50 is used when it is 0
+	DefaultLimit int `json:"default-limit"`
+	//MaxLimit is the largest size of a page, the larger limits are lowered to it. 200 is used when it is 0
+	MaxLimit int `json:"max-limit"`
+}
+
+//BatchConfig is the limits of the batch requests
+type BatchConfig struct {
+	//MaxRequests is the most sub-requests of a batch, 20 is used when it is 0
+	MaxRequests int `json:"max-requests"`
+	//Parallelism is the sub-requests of a batch which are ran at once, 4 is used when it is 0
+	Parallelism int `json:"parallelism"`
+}
+
 type Configuration struct {
 	Server          string
 	Database        string `json:"dbname"`
@@ -16,33 +152,185 @@ type Configuration struct {
 	Password        string
 	UserHost        string `json:"user-host"`
 	MaintenanceHost string `json:"maintenance-host"`
+	//Driver is the gorm dialect which is used to connect to the database, mysql is used when it is empty
+	Driver string `json:"driver"`
+	//Dsn is the data source name for the driver, for mysql it is built from Server, Username, Password
+	//and Database when it is empty
+	Dsn string `json:"dsn"`
+	//Migrate create the missing tables when the database is connected, it is always on for sqlite3
+	Migrate bool `json:"migrate"`
+	//Policies are the confirmation policies by service type, `default` is used for the missing types
+	Policies map[string]Policy `json:"policies"`
+	Jwt      JwtConfig         `json:"jwt"`
+	//RateLimits are the rate limits by route group, the requests are not limited when it is empty
+	RateLimits map[string]RateLimit `json:"rate-limits"`
+	Signing    SigningConfig        `json:"signing"`
+	Versions   VersionConfig        `json:"versions"`
+	Paging     PagingConfig         `json:"paging"`
+	//Cache are the cache policies by route group, the read responses are revalidated by their ETag when it is empty
+	Cache map[string]CachePolicy `json:"cache"`
+	Batch BatchConfig            `json:"batch"`
 }
 
 var Config Configuration
+var configPath string
+var policyMutex sync.RWMutex
+
+//OverlayEnv is the environment variable which select the overlay of the config file: its value `name` is the
+//config.name.json file next to the config file, its fields replace the fields of the config file. The test binaries
+//set it to `test` in their TestMain so `go test ./...` runs on an in-memory SQLite database, see configtest.Main
+const OverlayEnv = "STREETLITY_CONFIG"
+
+//overlayOf return the overlay file of the config file which is selected by OverlayEnv, it is empty when no overlay
+//is selected
+func overlayOf(path string) string {
+	name := os.Getenv(OverlayEnv)
+	if name == "" {
+		return ""
+	}
+
+	return filepath.Join(filepath.Dir(path), "config."+name+".json")
+}
 
 func LoadConfig(path string) {
-	file, fileErr := os.Open(path)
-	if fileErr != nil {
+	config, err := readConfig(path)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	policyMutex.Lock()
+	Config = config
+	configPath = path
+	policyMutex.Unlock()
+
+	loadEnv()
+}
 
-		log.Panic(fileErr)
+func readConfig(path string) (config Configuration, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
 	}
 
 	defer file.Close()
 	decoder := json.NewDecoder(file)
-	Config = Configuration{}
+	if err = decoder.Decode(&config); err != nil {
+		return
+	}
 
-	err := decoder.Decode(&Config)
+	if overlay := overlayOf(path); overlay != "" {
+		err = decodeFile(overlay, &config)
+	}
+
+	return
+}
 
+func decodeFile(path string, v interface{}) error {
+	file, err := os.Open(path)
 	if err != nil {
-		log.Panic(err)
+		return err
+	}
+
+	defer file.Close()
+	return json.NewDecoder(file).Decode(v)
+}
+
+//ReloadPolicies read the confirmation policies from the loaded config file again,
+//the other configurations are kept because they are only used on starting
+func ReloadPolicies() error {
+	config, err := readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	policyMutex.Lock()
+	Config.Policies = config.Policies
+	policyMutex.Unlock()
+
+	log.Println("[Config]", "Reloaded policies", config.Policies)
+	return nil
+}
+
+//ReloadJwt read the token configuration from the loaded config file again, the keys are rotated by adding the
+//new key, making it the signing key and removing the old key after the lifetime of its tokens
+func ReloadJwt() (JwtConfig, error) {
+	config, err := readConfig(configPath)
+	if err != nil {
+		return JwtConfig{}, err
+	}
+
+	jwt := envJwt(config.Jwt)
+	policyMutex.Lock()
+	Config.Jwt = jwt
+	policyMutex.Unlock()
+
+	return jwt, nil
+}
+
+//PolicyOf return the confirmation policy of the service type
+func PolicyOf(service_type string) Policy {
+	policyMutex.RLock()
+	defer policyMutex.RUnlock()
+
+	if p, ok := Config.Policies[service_type]; ok {
+		return p
+	}
+
+	if p, ok := Config.Policies["default"]; ok {
+		return p
+	}
+
+	return DefaultPolicy
+}
+
+//Policies return the configured confirmation policies
+func Policies() map[string]Policy {
+	policyMutex.RLock()
+	defer policyMutex.RUnlock()
+
+	policies := make(map[string]Policy)
+	for t, p := range Config.Policies {
+		policies[t] = p
+	}
+
+	return policies
+}
+
+//loadEnv override the database configuration by the environment variables, it is useful
+//for running the test suite against another database without editing config.json
+func loadEnv() {
+	if driver, ok := os.LookupEnv("STREETLITY_DB_DRIVER"); ok {
+		Config.Driver = driver
 	}
+
+	if dsn, ok := os.LookupEnv("STREETLITY_DB_DSN"); ok {
+		Config.Dsn = dsn
+	}
+
+	Config.Jwt = envJwt(Config.Jwt)
+}
+
+//envJwt add the secret of the `STREETLITY_JWT_SECRET` environment variable to c as the signing key, so the secret
+//doesn't need to be written in config.json
+func envJwt(c JwtConfig) JwtConfig {
+	if secret, ok := os.LookupEnv("STREETLITY_JWT_SECRET"); ok {
+		c.Keys = append([]JwtKey{{Kid: "env", Algorithm: "HS256", Secret: secret}}, c.Keys...)
+		c.Kid = "env"
+	}
+
+	return c
+}
+
+//ConfigPath return the path of the file which the config is loaded from, see LoadConfig
+func ConfigPath() string {
+	policyMutex.RLock()
+	defer policyMutex.RUnlock()
+	return configPath
 }
 
 func init() {
 	_, b, _, _ := runtime.Caller(0)
 	basepath := filepath.Dir(b)
-	configPath := filepath.Join(filepath.Dir(basepath), "config", "config.json")
-
-	fmt.Println("[Config]", configPath)
-	LoadConfig(configPath)
+	path := filepath.Join(filepath.Dir(basepath), "config", "config.json")
+	LoadConfig(path)
 }
@@ -0,0 +1,15 @@
+package configtest
+
+import (
+	"os"
+	"streelity/v1/config"
+	"testing"
+)
+
+//Main run the tests of m on the config.test.json overlay, it is the TestMain of the test packages which use the
+//database or the tokens. The config is loaded again since it is loaded before TestMain
+func Main(m *testing.M) {
+	os.Setenv(config.OverlayEnv, "test")
+	config.LoadConfig(config.ConfigPath())
+	os.Exit(m.Run())
+}
@@ -6,6 +6,7 @@ require (
 	cloud.google.com/go/firestore v1.2.0 // indirect
 	cloud.google.com/go/storage v1.10.0 // indirect
 	firebase.google.com/go v3.12.1+incompatible // indirect
+	github.com/andybalholm/brotli v1.0.4
 	github.com/brianvoe/gofakeit/v5 v5.7.0
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/ethereum/go-ethereum v1.9.12
@@ -13,13 +14,15 @@ require (
 	github.com/gojektech/heimdall v5.0.2+incompatible
 	github.com/gojektech/valkyrie v0.0.0-20190210220504-8f62c1e7ba45 // indirect
 	github.com/golang/geo v0.0.0-20200319012246-673a6f80352d
+	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/gookit/event v1.0.3
 	github.com/gorilla/handlers v1.4.2
 	github.com/gorilla/mux v1.7.4
 	github.com/jinzhu/gorm v1.9.14
 	github.com/lukehoban/go-outline v0.0.0-20161011150102-e78556874252 // indirect
-	github.com/mattn/go-sqlite3 v2.0.1+incompatible // indirect
+	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/mdempsky/gocode v0.0.0-20191202075140-939b4a677f2f // indirect
 	github.com/nvnamsss/goinf v1.1.4
 	github.com/uudashr/gopkgs/v2 v2.1.2 // indirect
+	google.golang.org/protobuf v1.27.1
 )
@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"streelity/v1/config"
 	"streelity/v1/model"
 	"streelity/v1/router"
+	"streelity/v1/srpc"
 	"time"
 
 	"github.com/gorilla/handlers"
@@ -20,14 +22,24 @@ var Server http.Server
 
 func main() {
 	var wait time.Duration
+	var sweep time.Duration
 
 	flag.DurationVar(&wait, "graceful-timeout", time.Second*15, "the duration for which the server gracefully wait for existing connections to finish - e.g. 15s or 1m")
+	flag.DurationVar(&sweep, "sweep-interval", time.Hour, "the duration between the sweeps of the stale submissions and the decayed services")
 	flag.Parse()
 
 	loggedRouter := handlers.LoggingHandler(os.Stdout, Router)
 
+	if e := model.LoadKeys(config.Config.Jwt); e != nil {
+		log.Panic(e)
+	}
+
 	model.Connect()
-	router.Handle(Router)
+
+	model.Notifier = srpc.Notify
+	repos := router.DatabaseRepositories()
+	router.Handle(Router, repos)
+	go sweepServices(repos, sweep)
 	Server := &http.Server{
 		Addr:         "0.0.0.0:9000",
 		WriteTimeout: time.Second * 30,
@@ -57,3 +69,34 @@ func main() {
 
 	os.Exit(0)
 }
+
+//sweeper is the part of the repositories which is ran periodically
+type sweeper interface {
+	ArchiveStale() (int, error)
+	DecayServices() (int, error)
+}
+
+//sweepServices archive the submissions which are not voted in the max age of their policies and decay the
+//confident of the services which don't have positive signals every interval
+func sweepServices(repos router.Repositories, interval time.Duration) {
+	for range time.Tick(interval) {
+		for name, repo := range map[string]sweeper{
+			"atm":         repos.Atm,
+			"fuel":        repos.Fuel,
+			"toilet":      repos.Toilet,
+			"maintenance": repos.Maintenance,
+		} {
+			if archived, e := repo.ArchiveStale(); e != nil {
+				log.Println("[Archive]", name, e.Error())
+			} else if archived > 0 {
+				log.Println("[Archive]", name, archived, "stale submissions")
+			}
+
+			if disputed, e := repo.DecayServices(); e != nil {
+				log.Println("[Decay]", name, e.Error())
+			} else if disputed > 0 {
+				log.Println("[Decay]", name, disputed, "services are disputed")
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"streelity/v1/model"
+	"streelity/v1/sres"
+	"time"
+)
+
+//ApiKeyHeader is the header which the partners pass their api key by
+const ApiKeyHeader = "X-Api-Key"
+
+//ApiKey middleware
+//
+//The requests which have the `X-Api-Key` header must be allowed by the scopes of the key and be in its quotas,
+//the key is carried by the request context, see model.ApiKeyFromContext. The requests without the header are passed
+func ApiKey(keys model.ApiKeyRepository, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := r.Header.Get(ApiKeyHeader)
+		if secret == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		key, e := keys.Verify(secret)
+		if e != nil {
+			log.Println("[ApiKey]", r.URL, e.Error())
+			sres.WriteError(w, sres.NewError(sres.CodeUnauthorized, "Api key is invalid."))
+			return
+		}
+
+		if e := key.Allows(r.Method, r.URL.Path); e != nil {
+			log.Println("[ApiKey]", r.URL, key.Prefix, e.Error())
+			sres.WriteError(w, sres.NewError(sres.CodeForbidden, e.Error()))
+			return
+		}
+
+		daily, monthly, e := keys.Use(key.Id, time.Now())
+		if key.DailyQuota > 0 {
+			w.Header().Set("X-Quota-Daily-Remaining", strconv.Itoa(key.DailyQuota-daily.Count))
+		}
+
+		if key.MonthlyQuota > 0 {
+			w.Header().Set("X-Quota-Monthly-Remaining", strconv.Itoa(key.MonthlyQuota-monthly.Count))
+		}
+
+		if e == model.ErrQuotaExceeded {
+			sres.WriteError(w, e)
+			return
+		} else if e != nil {
+			log.Println("[ApiKey]", r.URL, key.Prefix, e.Error())
+			sres.WriteError(w, sres.NewError(sres.CodeInternal, e.Error()))
+			return
+		}
+
+		h.ServeHTTP(w, r.WithContext(model.WithApiKey(r.Context(), key)))
+	})
+}
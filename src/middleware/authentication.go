@@ -9,25 +9,66 @@ import (
 
 //Authenticate middleware
 //
-//Request must have `Auth` header to be passed
+//Request must have `Auth` header to be passed, the user who is owning the token with its subject and roles is carried
+//by the request context, see model.UserFromContext
 func Authenticate(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		auth := r.Header.Get("Auth")
 		if auth != "" {
-			log.Println("[Authorization]", "Received key", auth)
-			err := model.Authenticate(auth)
+			user, err := model.ParseToken(auth)
 			if err != nil {
-				var res sres.Response = sres.Response{Status: false, Message: err.Error()}
-				w.WriteHeader(http.StatusUnauthorized)
-				sres.WriteJson(w, res)
+				//the key is a credential so it is never logged
+				log.Println("[Authorization]", r.URL, "Invalid key", err.Error())
+				sres.WriteError(w, sres.NewError(sres.CodeUnauthorized, err.Error()))
 			} else {
-				h.ServeHTTP(w, r)
+				h.ServeHTTP(w, r.WithContext(model.WithUser(r.Context(), user)))
 			}
 		} else {
-			var res sres.Response = sres.Response{Status: false, Message: "Authorization failure."}
 			log.Println("[Authorization]", r.URL, "Authorization failure")
-			sres.WriteJson(w, res)
+			sres.WriteError(w, sres.NewError(sres.CodeUnauthorized, "Authorization failure."))
 		}
 
 	})
 }
+
+//Forbid respond that the authenticated user is not allowed to do the request
+func Forbid(w http.ResponseWriter, r *http.Request) {
+	log.Println("[Authorization]", r.URL, "Permission denied")
+	sres.WriteError(w, sres.NewError(sres.CodeForbidden, "Permission denied."))
+}
+
+//Admin middleware
+//
+//Request must be authenticated by an admin, it is used after Authenticate
+func Admin(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, ok := model.UserFromContext(r.Context()); ok && user.IsAdmin() {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		Forbid(w, r)
+	})
+}
+
+//Moderator middleware
+//
+//Request must be authenticated by an user who can moderate, it is used after Authenticate
+func Moderator(h http.Handler) http.Handler {
+	return Require(model.PermissionModerate, h)
+}
+
+//Require middleware
+//
+//Request must be authenticated by an user who is granted the permission by the role or by the token,
+//it is used after Authenticate
+func Require(permission string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, ok := model.UserFromContext(r.Context()); ok && user.Can(permission) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		Forbid(w, r)
+	})
+}
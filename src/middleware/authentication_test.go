@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
+	"strings"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	h := middleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	token, _ := model.CreateToken(1)
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{"valid key", token, http.StatusOK},
+		{"invalid key", token + "x", http.StatusUnauthorized},
+		{"missing key", "", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Auth", test.key)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		if rr.Code != test.status {
+			t.Errorf("%v: wrong status: got %v want %v", test.name, rr.Code, test.status)
+		}
+	}
+
+	if strings.Contains(logged.String(), token) {
+		t.Errorf("key is logged: %v", logged.String())
+	}
+}
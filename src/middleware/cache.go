@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"streelity/v1/config"
+	"strings"
+	"time"
+)
+
+//bufferedWriter keep the response of a handler so it can be answered by 304
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(data)
+}
+
+//cachePolicyOf return the cache policy of the route group of p like the rate limits, ok is false when p is not in a
+//group
+func cachePolicyOf(policies map[string]config.CachePolicy, p string) (policy config.CachePolicy, ok bool) {
+	names := make([]string, 0, len(policies))
+	for n := range policies {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+	for _, n := range names {
+		for _, pattern := range policies[n].Paths {
+			if matched, _ := path.Match(pattern, p); matched {
+				return policies[n], true
+			}
+		}
+	}
+
+	for _, n := range names {
+		if len(policies[n].Paths) == 0 {
+			return policies[n], true
+		}
+	}
+
+	return
+}
+
+//cacheControl return the `Cache-Control` of policy, the responses of the authenticated requests are private
+func cacheControl(policy config.CachePolicy, authenticated bool) string {
+	if policy.NoStore {
+		return "no-store"
+	}
+
+	scope := "private"
+	if policy.Public && !authenticated {
+		scope = "public"
+	}
+
+	if policy.MaxAge <= 0 {
+		return scope + ", no-cache"
+	}
+
+	return scope + ", max-age=" + strconv.Itoa(policy.MaxAge)
+}
+
+//ETag return the strong entity tag of a response body, it is a hash of the body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+//matchETag determine whether etag is one of the tags of an `If-None-Match` header, the weak tags are compared by their
+//value
+func matchETag(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+//notModified determine whether the response which is having the headers h is not modified for the conditional
+//request r. `If-Modified-Since` is only used when there is no `If-None-Match`
+func notModified(r *http.Request, h http.Header) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		return matchETag(match, h.Get("ETag"))
+	}
+
+	since, e := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if e != nil {
+		return false
+	}
+
+	modified, e := http.ParseTime(h.Get("Last-Modified"))
+	return e == nil && !modified.After(since)
+}
+
+//LastModified set the `Last-Modified` header of the response of a single resource, nothing is set when t is nil
+func LastModified(w http.ResponseWriter, t *time.Time) {
+	if t != nil {
+		w.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+//Caching middleware
+//
+//The successful responses of the GET requests get an `ETag` of their content and the `Cache-Control` of their route
+//group. The conditional requests whose `If-None-Match` or `If-Modified-Since` is matched get 304 without the body.
+//The handlers can set an `ETag` of their data version before, it is kept
+func Caching(policies map[string]config.CachePolicy, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		buffered := &bufferedWriter{ResponseWriter: w}
+		h.ServeHTTP(buffered, r)
+		if buffered.status == 0 {
+			buffered.status = http.StatusOK
+		}
+
+		header := w.Header()
+		if buffered.status == http.StatusOK {
+			if header.Get("ETag") == "" {
+				header.Set("ETag", ETag(buffered.body.Bytes()))
+			}
+
+			if policy, ok := cachePolicyOf(policies, r.URL.Path); ok && header.Get("Cache-Control") == "" {
+				header.Set("Cache-Control", cacheControl(policy, r.Header.Get("Auth") != ""))
+			}
+
+			header.Add("Vary", VersionHeader)
+			if notModified(r, header) {
+				header.Del("Content-Type")
+				header.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		w.WriteHeader(buffered.status)
+		w.Write(buffered.body.Bytes())
+	})
+}
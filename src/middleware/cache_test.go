@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/config"
+	"streelity/v1/middleware"
+	"testing"
+	"time"
+)
+
+func TestCaching(t *testing.T) {
+	modified := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	policies := map[string]config.CachePolicy{
+		"default": {},
+		"catalog": {Paths: []string{"/service/*/all"}, MaxAge: 300, Public: true},
+		"private": {Paths: []string{"/apikey/*"}, NoStore: true},
+	}
+
+	h := middleware.Caching(policies, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("missing") != "" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+
+		if r.URL.Query().Get("single") != "" {
+			middleware.LastModified(w, &modified)
+		}
+
+		w.Write([]byte(`{"Status":true}`))
+	}))
+
+	etag := middleware.ETag([]byte(`{"Status":true}`))
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		headers map[string]string
+		status  int
+		control string
+		hasETag bool
+		hasBody bool
+	}{
+		{"catalog", "GET", "/service/fuel/all", nil, 200, "public, max-age=300", true, true},
+		{"authenticated", "GET", "/service/fuel/all", map[string]string{"Auth": "token"}, 200, "private, max-age=300", true, true},
+		{"default", "GET", "/service/fuel/", nil, 200, "private, no-cache", true, true},
+		{"no store", "GET", "/apikey/", nil, 200, "no-store", true, true},
+		{"matched", "GET", "/service/fuel/all", map[string]string{"If-None-Match": etag}, 304, "public, max-age=300", true, false},
+		{"weak matched", "GET", "/service/fuel/all", map[string]string{"If-None-Match": `"x", W/` + etag}, 304, "public, max-age=300", true, false},
+		{"any matched", "GET", "/service/fuel/all", map[string]string{"If-None-Match": "*"}, 304, "public, max-age=300", true, false},
+		{"not matched", "GET", "/service/fuel/all", map[string]string{"If-None-Match": `"x"`}, 200, "public, max-age=300", true, true},
+		{"not modified since", "GET", "/service/fuel/?single=1", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, 304, "private, no-cache", true, false},
+		{"modified since", "GET", "/service/fuel/?single=1", map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, 200, "private, no-cache", true, true},
+		{"etag precedes", "GET", "/service/fuel/?single=1", map[string]string{"If-None-Match": `"x"`, "If-Modified-Since": modified.Format(http.TimeFormat)}, 200, "private, no-cache", true, true},
+		{"failed", "GET", "/service/fuel/?missing=1", map[string]string{"If-None-Match": "*"}, 404, "", false, true},
+		{"post", "POST", "/service/fuel/all", map[string]string{"If-None-Match": "*"}, 200, "", false, true},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		for k, v := range test.headers {
+			req.Header.Set(k, v)
+		}
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != test.status {
+			t.Errorf("%v: status got %v want %v", test.name, rr.Code, test.status)
+		}
+
+		if control := rr.Header().Get("Cache-Control"); control != test.control {
+			t.Errorf("%v: Cache-Control got %v want %v", test.name, control, test.control)
+		}
+
+		if hasETag := rr.Header().Get("ETag") != ""; hasETag != test.hasETag {
+			t.Errorf("%v: ETag got %v", test.name, rr.Header().Get("ETag"))
+		}
+
+		if hasBody := rr.Body.Len() > 0; hasBody != test.hasBody {
+			t.Errorf("%v: body got %v", test.name, rr.Body.String())
+		}
+	}
+}
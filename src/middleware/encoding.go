@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"streelity/v1/sres"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+//CompressMinSize is the size of the smallest body which is compressed, the smaller bodies are not reduced much
+const CompressMinSize = 1024
+
+//accepted is a value of an `Accept` or `Accept-Encoding` header and its quality
+type accepted struct {
+	value string
+	q     float64
+}
+
+//parseAccept return the values of header in their order, the values whose quality is 0 are kept because they
+//refuse the wildcards
+func parseAccept(header string) (list []accepted) {
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		a := accepted{value: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		if a.value == "" {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, e := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); e == nil {
+					a.q = q
+				}
+			}
+		}
+
+		list = append(list, a)
+	}
+
+	return
+}
+
+//contentTypes are the aliases of the content types of the responses
+var contentTypes map[string]string = map[string]string{
+	"*/*":                             sres.ContentJson,
+	"application/*":                   sres.ContentJson,
+	sres.ContentJson:                  sres.ContentJson,
+	sres.ContentMsgpack:               sres.ContentMsgpack,
+	"application/x-msgpack":           sres.ContentMsgpack,
+	sres.ContentProtobuf:              sres.ContentProtobuf,
+	"application/protobuf":            sres.ContentProtobuf,
+	"application/vnd.google.protobuf": sres.ContentProtobuf,
+}
+
+//AcceptedType return the content type of the response by the `Accept` header, it is the known type which has the
+//highest quality and it is listed first. The responses are json when no known type is accepted
+func AcceptedType(header string) string {
+	best, bestQ := sres.ContentJson, 0.0
+	for _, a := range parseAccept(header) {
+		if t, ok := contentTypes[a.value]; ok && a.q > bestQ {
+			best, bestQ = t, a.q
+		}
+	}
+
+	return best
+}
+
+//Compressor create the writer which compress the body to w
+type Compressor func(w io.Writer) io.WriteCloser
+
+//compression is a content coding of the responses and its compressor
+type compression struct {
+	name string
+	new  Compressor
+}
+
+//compressions are the supported codings in their preference when the client accepts several of them at the same
+//quality, brotli is the smallest
+var compressions struct {
+	sync.RWMutex
+	list []compression
+}
+
+func init() {
+	compressions.list = []compression{
+		{"br", func(w io.Writer) io.WriteCloser { return brotli.NewWriterLevel(w, brotli.DefaultCompression) }},
+		{"gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }},
+		{"deflate", func(w io.Writer) io.WriteCloser {
+			writer, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return writer
+		}},
+	}
+}
+
+//RegisterCompression add the content coding name, the registered codings are preferred to the others when the client
+//accepts them at the same quality. A registered name replaces the coding of the name
+func RegisterCompression(name string, c Compressor) {
+	compressions.Lock()
+	defer compressions.Unlock()
+
+	list := []compression{{name, c}}
+	for _, existed := range compressions.list {
+		if existed.name != name {
+			list = append(list, existed)
+		}
+	}
+
+	compressions.list = list
+}
+
+//acceptedCompression return the coding of the response by the `Accept-Encoding` header, nil is returned when the
+//body is not compressed
+func acceptedCompression(header string) (best *compression) {
+	list := parseAccept(header)
+	qOf := func(name string) float64 {
+		wildcard := 0.0
+		for _, a := range list {
+			if a.value == name {
+				return a.q
+			}
+
+			if a.value == "*" {
+				wildcard = a.q
+			}
+		}
+
+		return wildcard
+	}
+
+	compressions.RLock()
+	defer compressions.RUnlock()
+
+	bestQ := 0.0
+	for i, c := range compressions.list {
+		if q := qOf(c.name); q > bestQ {
+			best, bestQ = &compressions.list[i], q
+		}
+	}
+
+	return
+}
+
+//Encoding middleware
+//
+//The json responses are encoded as MessagePack or Protocol Buffers when the `Accept` header prefers them, see
+//sres.Encoders. The other responses and the responses which cannot be encoded are written as they are
+func Encoding(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		encode, ok := sres.Encoders[AcceptedType(r.Header.Get("Accept"))]
+		if !ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		buffered := &bufferedWriter{ResponseWriter: w}
+		h.ServeHTTP(buffered, r)
+		if buffered.status == 0 {
+			buffered.status = http.StatusOK
+		}
+
+		body := buffered.body.Bytes()
+		if media, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type")); media == sres.ContentJson {
+			if encoded, e := encode(body); e != nil {
+				//the responses without a protobuf schema are json as they are not encoded
+				if e != sres.ErrNoSchema {
+					log.Println("[Encoding]", r.URL, e.Error())
+				}
+			} else {
+				w.Header().Set("Content-Type", AcceptedType(r.Header.Get("Accept")))
+				body = encoded
+			}
+		}
+
+		w.WriteHeader(buffered.status)
+		w.Write(body)
+	})
+}
+
+//Compression middleware
+//
+//The bodies which are larger than CompressMinSize are compressed by the coding which the `Accept-Encoding` header
+//prefers, brotli, gzip and deflate are supported. The `ETag` of a compressed response is weak because it is the tag of the
+//uncompressed body
+func Compression(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		c := acceptedCompression(r.Header.Get("Accept-Encoding"))
+		if c == nil || r.Method == http.MethodHead {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		buffered := &bufferedWriter{ResponseWriter: w}
+		h.ServeHTTP(buffered, r)
+		if buffered.status == 0 {
+			buffered.status = http.StatusOK
+		}
+
+		body := buffered.body.Bytes()
+		header := w.Header()
+		if len(body) >= CompressMinSize && header.Get("Content-Encoding") == "" {
+			var compressed bytes.Buffer
+			writer := c.new(&compressed)
+			writer.Write(body)
+			if e := writer.Close(); e != nil {
+				log.Println("[Compression]", r.URL, e.Error())
+			} else {
+				body = compressed.Bytes()
+				header.Set("Content-Encoding", c.name)
+				header.Del("Content-Length")
+				if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+					header.Set("ETag", "W/"+etag)
+				}
+			}
+		}
+
+		w.WriteHeader(buffered.status)
+		w.Write(body)
+	})
+}
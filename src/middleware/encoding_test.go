@@ -0,0 +1,153 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/middleware"
+	"streelity/v1/sres"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestAcceptedType(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", sres.ContentJson},
+		{"text/html", sres.ContentJson},
+		{"application/msgpack", sres.ContentMsgpack},
+		{"application/x-msgpack, application/json", sres.ContentMsgpack},
+		{"application/json;q=0.9, application/msgpack", sres.ContentMsgpack},
+		{"application/msgpack;q=0.5, */*;q=0.8", sres.ContentJson},
+		{"application/json;q=0.9, application/x-protobuf", sres.ContentProtobuf},
+		{"application/protobuf;q=0.5, */*;q=0.8", sres.ContentJson},
+	}
+
+	for _, test := range tests {
+		if got := middleware.AcceptedType(test.header); got != test.expected {
+			t.Errorf("%v: got %v want %v", test.header, got, test.expected)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	h := middleware.Encoding(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/text" {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("text"))
+			return
+		}
+
+		sres.WriteError(w, sres.NewError(sres.CodeNotFound, "missing"))
+	}))
+
+	tests := []struct {
+		name    string
+		path    string
+		accept  string
+		content string
+	}{
+		{"json", "/", "", sres.ContentJson},
+		{"msgpack", "/", sres.ContentMsgpack, sres.ContentMsgpack},
+		{"protobuf", "/", sres.ContentProtobuf, sres.ContentProtobuf},
+		{"not json", "/text", sres.ContentMsgpack, "text/plain"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.path, nil)
+		req.Header.Set("Accept", test.accept)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if content := rr.Header().Get("Content-Type"); content != test.content || rr.Code != http.StatusNotFound && test.path == "/" {
+			t.Errorf("%v: got %v %v", test.name, rr.Code, content)
+		}
+
+		if test.content == sres.ContentMsgpack && rr.Body.Bytes()[0]&0xf0 != 0x80 {
+			t.Errorf("%v: body is not a msgpack map % x", test.name, rr.Body.Bytes())
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	large := strings.Repeat(`{"Status":true}`, 100)
+	h := middleware.Compression(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"tag"`)
+		if r.URL.Path == "/small" {
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		w.Write([]byte(large))
+	}))
+
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		encoding string
+	}{
+		{"gzip", "/", "gzip, deflate", "gzip"},
+		{"brotli", "/", "gzip, deflate, br", "br"},
+		{"quality", "/", "br;q=0.3, gzip;q=0.5, deflate", "deflate"},
+		{"wildcard", "/", "*", "br"},
+		{"refused", "/", "br;q=0, gzip;q=0, deflate;q=0", ""},
+		{"unknown", "/", "compress", ""},
+		{"identity", "/", "", ""},
+		{"small", "/small", "gzip", ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.path, nil)
+		req.Header.Set("Accept-Encoding", test.accept)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if encoding := rr.Header().Get("Content-Encoding"); encoding != test.encoding {
+			t.Errorf("%v: got %v want %v", test.name, encoding, test.encoding)
+		}
+
+		if etag := rr.Header().Get("ETag"); (test.encoding != "") != strings.HasPrefix(etag, "W/") {
+			t.Errorf("%v: ETag got %v", test.name, etag)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	reader, e := gzip.NewReader(rr.Body)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if body, _ := ioutil.ReadAll(reader); string(body) != large {
+		t.Errorf("gzip: body is not decompressed to the original")
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if body, _ := ioutil.ReadAll(brotli.NewReader(rr.Body)); string(body) != large {
+		t.Errorf("br: body is not decompressed to the original")
+	}
+
+	//a registered coding is preferred at the same quality
+	middleware.RegisterCompression("zstd", func(w io.Writer) io.WriteCloser {
+		return gzip.NewWriter(w)
+	})
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br, zstd")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if encoding := rr.Header().Get("Content-Encoding"); encoding != "zstd" || bytes.Equal(rr.Body.Bytes(), []byte(large)) {
+		t.Errorf("registered: got %v", encoding)
+	}
+}
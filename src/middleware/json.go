@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"streelity/v1/sres"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+//MaxJsonBody is the size of the largest json body which is accepted
+const MaxJsonBody = 1 << 20
+
+//scalarValue return the form value of a json string, number or bool
+func scalarValue(field string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+
+	return "", fmt.Errorf("%v must be a string, a number, a bool or an array of them", field)
+}
+
+//JsonValues convert a json object to the form values, an array is converted to the repeated values of its field
+//like `location=10&location=106` and null is skipped. The nested objects are not accepted
+func JsonValues(body []byte) (values url.Values, e error) {
+	var object map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if e = decoder.Decode(&object); e != nil {
+		return nil, errors.New("body is not a json object: " + e.Error())
+	}
+
+	values = url.Values{}
+	for field, value := range object {
+		switch v := value.(type) {
+		case nil:
+		case []interface{}:
+			values[field] = []string{}
+			for i, item := range v {
+				s, e := scalarValue(fmt.Sprintf("%v[%v]", field, i), item)
+				if e != nil {
+					return nil, e
+				}
+
+				values[field] = append(values[field], s)
+			}
+		default:
+			s, e := scalarValue(field, v)
+			if e != nil {
+				return nil, e
+			}
+
+			values.Set(field, s)
+		}
+	}
+
+	return
+}
+
+//rawBodies are the routes whose json bodies are read by their handlers
+var rawBodies sync.Map
+
+//RawBody keep the json bodies of route for its handler, they are not converted by JsonBody so they can have nested
+//objects
+func RawBody(route *mux.Route) *mux.Route {
+	rawBodies.Store(route, true)
+	return route
+}
+
+//JsonBody middleware
+//
+//The `application/json` bodies are converted to the form values, see JsonValues. They are the PostForm of the POST,
+//PUT and PATCH requests and they are added to the query of the other requests, so the stages read them as the forms
+func JsonBody(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if media, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); media != "application/json" || r.Body == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if route := mux.CurrentRoute(r); route != nil {
+			if _, ok := rawBodies.Load(route); ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		body, e := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxJsonBody))
+		var values url.Values
+		if e == nil {
+			values, e = JsonValues(body)
+		}
+
+		if e != nil {
+			log.Println("[Json]", r.URL, e.Error())
+			sres.WriteError(w, sres.NewError(sres.CodeBadRequest, e.Error()))
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		query := r.URL.Query()
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			//the form is the body values before the query values like http.Request.ParseForm
+			r.PostForm, r.Form = values, url.Values{}
+			for _, from := range []url.Values{values, query} {
+				for field, v := range from {
+					r.Form[field] = append(r.Form[field], v...)
+				}
+			}
+		default:
+			for field, v := range values {
+				query[field] = append(query[field], v...)
+			}
+
+			r.URL.RawQuery = query.Encode()
+			r.PostForm, r.Form = url.Values{}, query
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
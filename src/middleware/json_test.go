@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/middleware"
+	"strings"
+	"testing"
+)
+
+func TestJsonValues(t *testing.T) {
+	values, e := middleware.JsonValues([]byte(`{"location": [10.5, 106], "images": ["a.png", "b.png"], "service_id": 3, "read_only": false, "note": "x", "skipped": null}`))
+	if e != nil {
+		t.Fatalf("JsonValues returned error: %v", e)
+	}
+
+	expected := map[string][]string{
+		"location":   {"10.5", "106"},
+		"images":     {"a.png", "b.png"},
+		"service_id": {"3"},
+		"read_only":  {"false"},
+		"note":       {"x"},
+	}
+
+	if len(values) != len(expected) {
+		t.Errorf("wrong values: got %v", values)
+	}
+
+	for field, v := range expected {
+		if strings.Join(values[field], ",") != strings.Join(v, ",") {
+			t.Errorf("%v: got %v want %v", field, values[field], v)
+		}
+	}
+
+	invalid := []string{`[1, 2]`, `{"location": {"lat": 10}}`, `{"images": [["a"]]}`, `{"note": "x"`}
+	for _, body := range invalid {
+		if _, e := middleware.JsonValues([]byte(body)); e == nil {
+			t.Errorf("%v is accepted", body)
+		}
+	}
+}
+
+func TestJsonBody(t *testing.T) {
+	var form, query string
+	handler := middleware.JsonBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form, query = r.PostForm.Encode(), r.URL.Query().Encode()
+	}))
+
+	tests := []struct {
+		method string
+		path   string
+		ctype  string
+		body   string
+		code   int
+		form   string
+		query  string
+	}{
+		{"POST", "/create", "application/json", `{"location": [10, 106]}`, 200, "location=10&location=106", ""},
+		{"POST", "/create", "application/json; charset=utf-8", `{"id": 1}`, 200, "id=1", ""},
+		{"DELETE", "/review/?review_id=1", "application/json", `{"note": "x"}`, 200, "", "note=x&review_id=1"},
+		{"POST", "/create", "application/x-www-form-urlencoded", `id=2`, 200, "id=2", ""},
+		{"POST", "/create", "application/json", `{"location": {"lat": 10}}`, 400, "", ""},
+	}
+
+	for _, test := range tests {
+		form, query = "", ""
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", test.ctype)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != test.code || form != test.form || query != test.query {
+			t.Errorf("%v %v %v: got %v %v %v want %v %v %v", test.method, test.path, test.body, rr.Code, form, query, test.code, test.form, test.query)
+		}
+	}
+}
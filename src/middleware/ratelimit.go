@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"streelity/v1/config"
+	"streelity/v1/model"
+	"streelity/v1/sres"
+	"sync"
+	"time"
+)
+
+//RateStore is the storage of the token buckets, it is shared between the servers by implementing it on a shared
+//storage like redis
+type RateStore interface {
+	//Take remove a token from the bucket of key which is limited by limit, the duration until the next token is
+	//returned when the bucket is empty
+	Take(key string, limit config.RateLimit, now time.Time) (allowed bool, wait time.Duration)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rate   float64
+	burst  float64
+}
+
+//MemoryRateStore is the RateStore of a server, the full buckets are dropped periodically
+type MemoryRateStore struct {
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+func NewMemoryRateStore() *MemoryRateStore {
+	return &MemoryRateStore{buckets: make(map[string]*bucket)}
+}
+
+//rateOf return the tokens which are added to the bucket of limit in a second and the size of the bucket
+func rateOf(limit config.RateLimit) (rate float64, burst float64) {
+	per := limit.PerSeconds
+	if per <= 0 {
+		per = 1
+	}
+
+	burst = float64(limit.Burst)
+	if burst <= 0 {
+		burst = float64(limit.Requests)
+	}
+
+	return float64(limit.Requests) / float64(per), burst
+}
+
+func (s *MemoryRateStore) Take(key string, limit config.RateLimit, now time.Time) (bool, time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.Sub(s.swept) > time.Minute {
+		s.sweep(now)
+	}
+
+	rate, burst := rateOf(limit)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+
+	b.rate, b.burst = rate, burst
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	if rate <= 0 {
+		return false, time.Hour
+	}
+
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+//sweep drop the buckets which would be full at now, they are the same as the new buckets
+func (s *MemoryRateStore) sweep(now time.Time) {
+	s.swept = now
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+//groupOf return the name and the limit of the route group of p, the group without paths is returned when p is not
+//in the other groups. ok is false when p is not limited
+func groupOf(limits map[string]config.RateLimit, p string) (name string, limit config.RateLimit, ok bool) {
+	names := make([]string, 0, len(limits))
+	for n := range limits {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+	for _, n := range names {
+		for _, pattern := range limits[n].Paths {
+			if matched, _ := path.Match(pattern, p); matched {
+				return n, limits[n], true
+			}
+		}
+	}
+
+	for _, n := range names {
+		if len(limits[n].Paths) == 0 {
+			return n, limits[n], true
+		}
+	}
+
+	return
+}
+
+//clientOf return the key of the client which is making r, the api key which is verified by ApiKey or the user of the
+//`Auth` token is used before the IP address, so an unverified header cannot choose the bucket
+func clientOf(r *http.Request) string {
+	if key, ok := model.ApiKeyFromContext(r.Context()); ok {
+		return "key:" + strconv.FormatInt(key.Id, 10)
+	}
+
+	if auth := r.Header.Get("Auth"); auth != "" {
+		if user, e := model.ParseToken(auth); e == nil {
+			return "user:" + user.GetName()
+		}
+	}
+
+	host, _, e := net.SplitHostPort(r.RemoteAddr)
+	if e != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+//RateLimit middleware
+//
+//The requests of a client are limited by the token bucket of their route group, the client is identified by the
+//api key, the user or the IP address. The requests which are over the limit get 429 with `Retry-After`. It is used
+//inside ApiKey so the api key is verified before its bucket is chosen
+func RateLimit(store RateStore, limits map[string]config.RateLimit, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		group, limit, ok := groupOf(limits, r.URL.Path)
+		if !ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		client := clientOf(r)
+		if allowed, wait := store.Take(group+":"+client, limit, time.Now()); !allowed {
+			log.Println("[RateLimit]", r.URL, client, "is limited in", group)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			sres.WriteError(w, sres.NewError(sres.CodeTooManyRequests, "Too many requests."))
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/config"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateStore(t *testing.T) {
+	store := middleware.NewMemoryRateStore()
+	limit := config.RateLimit{Requests: 1, PerSeconds: 2, Burst: 2}
+	now := time.Now()
+
+	takes := []struct {
+		after   time.Duration
+		allowed bool
+		wait    time.Duration
+	}{
+		{0, true, 0},
+		{0, true, 0},
+		{0, false, 2 * time.Second},
+		{time.Second, false, time.Second},
+		{2 * time.Second, true, 0},
+		{10 * time.Second, true, 0},
+		{10 * time.Second, true, 0},
+		{10 * time.Second, false, 2 * time.Second},
+	}
+
+	for i, take := range takes {
+		allowed, wait := store.Take("client", limit, now.Add(take.after))
+		if allowed != take.allowed || wait != take.wait {
+			t.Errorf("take %v: got %v %v want %v %v", i, allowed, wait, take.allowed, take.wait)
+		}
+	}
+
+	if allowed, _ := store.Take("another", limit, now.Add(10*time.Second)); !allowed {
+		t.Errorf("bucket is shared between the keys")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limits := map[string]config.RateLimit{
+		"default": {Requests: 100, PerSeconds: 60},
+		"range":   {Paths: []string{"/service/*/range"}, Requests: 1, PerSeconds: 60},
+	}
+
+	keys := new(model.ApiKeyMemory)
+	key, _ := keys.Issue(model.ApiKey{Name: "partner"})
+	handler := middleware.ApiKey(keys, middleware.RateLimit(middleware.NewMemoryRateStore(), limits, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	token, _ := model.CreateToken(1)
+	serve := func(path, ip, token, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = ip + ":1234"
+		if token != "" {
+			req.Header.Set("Auth", token)
+		}
+
+		if key != "" {
+			req.Header.Set(middleware.ApiKeyHeader, key)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		ip    string
+		token string
+		key   string
+		code  int
+	}{
+		{"first range", "/service/atm/range", "10.0.0.1", "", "", 200},
+		{"second range", "/service/fuel/range", "10.0.0.1", "", "", 429},
+		{"other ip", "/service/atm/range", "10.0.0.2", "", "", 200},
+		{"other group", "/service/atm/all", "10.0.0.1", "", "", 200},
+		{"user", "/service/atm/range", "10.0.0.1", token, "", 200},
+		{"user from other ip", "/service/atm/range", "10.0.0.3", token, "", 429},
+		{"api key", "/service/atm/range", "10.0.0.1", "", key.Secret, 200},
+		{"api key from other ip", "/service/atm/range", "10.0.0.4", "", key.Secret, 429},
+		{"unverified api key", "/service/atm/range", "10.0.0.1", "", key.Secret[:11] + "random", 401},
+	}
+
+	for _, test := range tests {
+		rr := serve(test.path, test.ip, test.token, test.key)
+		if rr.Code != test.code {
+			t.Errorf("%v: got %v want %v", test.name, rr.Code, test.code)
+		}
+
+		if test.code == 429 && rr.Header().Get("Retry-After") != "60" {
+			t.Errorf("%v: wrong Retry-After: got %v", test.name, rr.Header().Get("Retry-After"))
+		}
+	}
+}
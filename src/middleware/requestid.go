@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"streelity/v1/sres"
+)
+
+//validRequestId is the pattern of the request ids which are accepted from the clients
+var validRequestId *regexp.Regexp = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type requestIdKey struct{}
+
+//RequestIdFromContext return the id of the request which is set by RequestId
+func RequestIdFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+//NewRequestId generate a random request id
+func NewRequestId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+//RequestId middleware
+//
+//The request id is read from the `X-Request-Id` header or it is generated, it is carried by the request context and
+//it is set to the response header so the failed responses have it, see sres.WriteJson
+func RequestId(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(sres.RequestIdHeader)
+		if !validRequestId.MatchString(id) {
+			id = NewRequestId()
+		}
+
+		w.Header().Set(sres.RequestIdHeader, id)
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIdKey{}, id)))
+	})
+}
@@ -0,0 +1,38 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/middleware"
+	"streelity/v1/sres"
+	"testing"
+)
+
+func TestRequestId(t *testing.T) {
+	var carried string
+	h := middleware.RequestId(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		carried = middleware.RequestIdFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{"passed", "client-1", "client-1"},
+		{"generated", "", ""},
+		{"invalid", "a b\n", ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(sres.RequestIdHeader, test.header)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		id := rr.Header().Get(sres.RequestIdHeader)
+		if id == "" || id != carried || test.expected != "" && id != test.expected || test.expected == "" && id == test.header {
+			t.Errorf("%v: got %v carried %v", test.name, id, carried)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"streelity/v1/config"
+	"streelity/v1/sres"
+	"sync"
+	"time"
+)
+
+//The headers of the signed requests
+const (
+	ClientIdHeader  = "client-id"
+	TimestampHeader = "timestamp"
+	NonceHeader     = "nonce"
+	SignatureHeader = "authen-hash"
+)
+
+//NonceStore is the replay cache of the signed requests, it is shared between the servers by implementing it on a
+//shared storage
+type NonceStore interface {
+	//Seen record key until expire and determine whether it is recorded before
+	Seen(key string, expire time.Time) bool
+}
+
+//MemoryNonceStore is the NonceStore of a server, the expired nonces are dropped periodically
+type MemoryNonceStore struct {
+	mutex  sync.Mutex
+	nonces map[string]time.Time
+	swept  time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+func (s *MemoryNonceStore) Seen(key string, expire time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.swept) > time.Minute {
+		s.swept = now
+		for k, e := range s.nonces {
+			if e.Before(now) {
+				delete(s.nonces, k)
+			}
+		}
+	}
+
+	if e, ok := s.nonces[key]; ok && !e.Before(now) {
+		return true
+	}
+
+	s.nonces[key] = expire
+	return false
+}
+
+//Sign return the signature of a request by secret, it is the base64 of the HMAC-SHA256 of the method, the uri,
+//the timestamp, the nonce and the hex of the SHA256 of the body which are joined by new lines. The uri is the path
+//with the query as it is sent like `/service/atm/create?bank=1`, see url.URL.RequestURI
+func Sign(secret string, method string, uri string, timestamp string, nonce string, body []byte) string {
+	sum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + uri + "\n" + timestamp + "\n" + nonce + "\n" + hex.EncodeToString(sum[:])))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+//needSignature determine whether the path is in the signed paths of c
+func needSignature(c config.SigningConfig, p string) bool {
+	for _, pattern := range c.Paths {
+		if matched, _ := path.Match(pattern, p); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+//unsigned return the error of a request whose signature is not verified
+func unsigned(message string) error {
+	return sres.NewError(sres.CodeUnauthorized, message)
+}
+
+//verify check the signature of r by the secret of its client, the body of r is read up to the limit of c and
+//replaced
+func verify(c config.SigningConfig, nonces NonceStore, w http.ResponseWriter, r *http.Request, now time.Time) error {
+	client := r.Header.Get(ClientIdHeader)
+	secret, ok := c.Clients[client]
+	if !ok {
+		return unsigned("client-id is unknown")
+	}
+
+	timestamp := r.Header.Get(TimestampHeader)
+	seconds, e := strconv.ParseInt(timestamp, 10, 64)
+	if e != nil {
+		return unsigned("timestamp is not a unix time")
+	}
+
+	skew := time.Duration(c.SkewSeconds) * time.Second
+	if skew == 0 {
+		skew = 5 * time.Minute
+	}
+
+	at := time.Unix(seconds, 0)
+	if at.Before(now.Add(-skew)) || at.After(now.Add(skew)) {
+		return unsigned("timestamp is out of the allowed skew")
+	}
+
+	nonce := r.Header.Get(NonceHeader)
+	if nonce == "" {
+		return unsigned("nonce is missing")
+	}
+
+	limit := c.MaxBodyBytes
+	if limit == 0 {
+		limit = 32 << 20
+	}
+
+	var body []byte
+	if r.Body != nil {
+		if body, e = ioutil.ReadAll(http.MaxBytesReader(w, r.Body, limit)); e != nil {
+			return sres.NewError(sres.CodeBadRequest, "body cannot be read: "+e.Error())
+		}
+
+		r.Body.Close()
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	expected := Sign(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(SignatureHeader))) {
+		return unsigned("signature is invalid")
+	}
+
+	//the nonce is kept until the timestamp is out of the skew, the older requests are rejected by their timestamp
+	if nonces.Seen(client+":"+nonce, at.Add(skew)) {
+		return unsigned("request is replayed")
+	}
+
+	return nil
+}
+
+//Signature middleware
+//
+//The requests of the signed paths must have the `client-id`, `timestamp`, `nonce` and `authen-hash` headers, see
+//Sign. The requests which are out of the clock skew or are replayed with the same nonce are rejected
+func Signature(c config.SigningConfig, nonces NonceStore, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !needSignature(c, r.URL.Path) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if e := verify(c, nonces, w, r, time.Now()); e != nil {
+			log.Println("[Signature]", r.URL, r.Header.Get(ClientIdHeader), e.Error())
+			sres.WriteError(w, e)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
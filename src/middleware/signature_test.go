@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"streelity/v1/config"
+	"streelity/v1/middleware"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignature(t *testing.T) {
+	c := config.SigningConfig{Clients: map[string]string{"android": "secret"}, Paths: []string{"/service/*/create"}, SkewSeconds: 60, MaxBodyBytes: 64}
+	var received string
+	handler := middleware.Signature(c, middleware.NewMemoryNonceStore(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received = string(b)
+	}))
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-2*time.Minute).Unix(), 10)
+	body := "location=10&location=106"
+	sign := func(secret, path, timestamp, nonce, body string) string {
+		return middleware.Sign(secret, "POST", path, timestamp, nonce, []byte(body))
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		client    string
+		timestamp string
+		nonce     string
+		signature string
+		body      string
+		code      int
+	}{
+		{"unsigned path", "/service/atm/all", "", "", "", "", body, 200},
+		{"missing signature", "/service/atm/create", "android", now, "1", "", body, 401},
+		{"unknown client", "/service/atm/create", "ios", now, "1", sign("secret", "/service/atm/create", now, "1", body), body, 401},
+		{"wrong secret", "/service/atm/create", "android", now, "1", sign("other", "/service/atm/create", now, "1", body), body, 401},
+		{"forged body", "/service/atm/create", "android", now, "1", sign("secret", "/service/atm/create", now, "1", body), "location=0&location=0", 401},
+		{"other path", "/service/fuel/create", "android", now, "1", sign("secret", "/service/atm/create", now, "1", body), body, 401},
+		{"out of skew", "/service/atm/create", "android", old, "1", sign("secret", "/service/atm/create", old, "1", body), body, 401},
+		{"signed", "/service/atm/create", "android", now, "1", sign("secret", "/service/atm/create", now, "1", body), body, 200},
+		{"replayed", "/service/atm/create", "android", now, "1", sign("secret", "/service/atm/create", now, "1", body), body, 401},
+		{"new nonce", "/service/atm/create", "android", now, "2", sign("secret", "/service/atm/create", now, "2", body), body, 200},
+		{"signed query", "/service/atm/create?bank=1", "android", now, "3", sign("secret", "/service/atm/create?bank=1", now, "3", body), body, 200},
+		{"forged query", "/service/atm/create?bank=2", "android", now, "4", sign("secret", "/service/atm/create?bank=1", now, "4", body), body, 401},
+		{"unsigned query", "/service/atm/create?bank=2", "android", now, "5", sign("secret", "/service/atm/create", now, "5", body), body, 401},
+		{"large body", "/service/atm/create", "android", now, "6", sign("secret", "/service/atm/create", now, "6", body+strings.Repeat("&note=x", 10)), body + strings.Repeat("&note=x", 10), 400},
+	}
+
+	for _, test := range tests {
+		received = ""
+		req := httptest.NewRequest("POST", test.path, strings.NewReader(test.body))
+		headers := map[string]string{
+			middleware.ClientIdHeader:  test.client,
+			middleware.TimestampHeader: test.timestamp,
+			middleware.NonceHeader:     test.nonce,
+			middleware.SignatureHeader: test.signature,
+		}
+
+		for k, v := range headers {
+			if v != "" {
+				req.Header.Set(k, v)
+			}
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != test.code {
+			t.Errorf("%v: got %v want %v, %v", test.name, rr.Code, test.code, rr.Body.String())
+		}
+
+		if test.code == 200 && received != test.body {
+			t.Errorf("%v: body is not passed: got %v", test.name, received)
+		}
+	}
+}
@@ -1,72 +1,219 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
-	"regexp"
+	"sort"
+	"strconv"
+	"streelity/v1/config"
 	"streelity/v1/sres"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
-//sumOfRunes return the sum of runes by int in a string
-func sumOfRunes(s string) (sum int) {
-	for _, r := range s {
-		sum += int(r)
+//VersionHeader is the header which the clients pass their api version by
+const VersionHeader = "Version"
+
+//Version is a semantic version, the build metadata is ignored
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+}
+
+//ParseVersion parse a semantic version like 1.10.0, 2.0.0-beta.1 or v2. The missing minor and patch are 0
+func ParseVersion(s string) (v Version, e error) {
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+
+	if i := strings.Index(s, "-"); i >= 0 {
+		if v.PreRelease = s[i+1:]; v.PreRelease == "" {
+			return v, errors.New("version " + s + " has an empty pre-release")
+		}
+
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, errors.New("version " + s + " has more than 3 parts")
+	}
+
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, e := strconv.Atoi(part)
+		if e != nil || n < 0 || part == "" || len(part) > 1 && part[0] == '0' {
+			return v, errors.New("version " + s + " is invalid")
+		}
+
+		*numbers[i] = n
 	}
 
 	return
 }
 
-//compareVersion compare the
-func compareVersion(currentVersion, minVersion, maxVersion string) (status bool, err error) {
-	regFormat, _ := regexp.Compile("\\d*?[.]\\d*")
-	reg, _ := regexp.Compile("[.]")
-	if !regFormat.Match([]byte(currentVersion)) {
-		return false, errors.New("currentVersion format is invalid")
+func (v Version) String() string {
+	s := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+
+	return s
+}
+
+//comparePreRelease compare the pre-releases by their identifiers, the numeric identifiers are lower than the others
+//and a version without pre-release is higher than the versions with it
+func comparePreRelease(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	if a == "" {
+		return 1
+	}
+
+	if b == "" {
+		return -1
 	}
 
-	if !regFormat.Match([]byte(minVersion)) {
-		return false, errors.New("currentVersion format is invalid")
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, ae := strconv.Atoi(as[i])
+		bn, be := strconv.Atoi(bs[i])
+		switch {
+		case ae == nil && be == nil && an != bn:
+			return compareInt(an, bn)
+		case ae == nil && be != nil:
+			return -1
+		case ae != nil && be == nil:
+			return 1
+		case as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
 	}
 
-	if !regFormat.Match([]byte(maxVersion)) {
-		return false, errors.New("currentVersion format is invalid")
+	return compareInt(len(as), len(bs))
+}
+
+func compareInt(a, b int) int {
+	if a < b {
+		return -1
 	}
 
-	current := reg.Split(currentVersion, -1)
-	min := reg.Split(minVersion, -1)
-	max := reg.Split(maxVersion, -1)
+	if a > b {
+		return 1
+	}
+
+	return 0
+}
+
+//Compare return -1, 0 or 1 when v is lower than, equal to or higher than o
+func (v Version) Compare(o Version) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	return comparePreRelease(v.PreRelease, o.PreRelease)
+}
+
+//VersionRange is the versions from Min to Max, an empty bound is not limited
+type VersionRange struct {
+	Min string
+	Max string
+}
+
+//Contains determine whether v is in the range, the bounds are included
+func (r VersionRange) Contains(v Version) (bool, error) {
+	if r.Min != "" {
+		min, e := ParseVersion(r.Min)
+		if e != nil {
+			return false, e
+		}
 
-	if len(current) < 3 {
-		return false, errors.New("currentVersion format is invalid")
+		if v.Compare(min) < 0 {
+			return false, nil
+		}
 	}
 
-	if len(min) < 3 {
-		return false, errors.New("minVersion format is invalid")
+	if r.Max != "" {
+		max, e := ParseVersion(r.Max)
+		if e != nil {
+			return false, e
+		}
+
+		if v.Compare(max) > 0 {
+			return false, nil
+		}
 	}
 
-	if len(max) < 3 {
-		return false, errors.New("maxVersion format is invalid")
+	return true, nil
+}
+
+//compareVersion determine whether currentVersion is in the range of minVersion and maxVersion
+func compareVersion(currentVersion, minVersion, maxVersion string) (status bool, err error) {
+	current, err := ParseVersion(currentVersion)
+	if err != nil {
+		return false, err
 	}
-	minValue := sumOfRunes(min[0])*100 + sumOfRunes(min[1])*10 + sumOfRunes(min[2])
-	currentValue := sumOfRunes(current[0])*100 + sumOfRunes(current[1])*10 + sumOfRunes(current[2])
-	maxValue := sumOfRunes(max[0])*100 + sumOfRunes(max[1])*10 + sumOfRunes(max[2])
 
-	if currentValue >= minValue && currentValue <= maxValue {
-		return true, nil
+	if status, err = (VersionRange{Min: minVersion, Max: maxVersion}).Contains(current); err != nil || status {
+		return
 	}
 
 	return false, errors.New("This version is not supported")
 }
 
+type versionKey struct{}
+
+//VersionFromContext return the api version of the request which is checked by Versioning
+func VersionFromContext(ctx context.Context) (v Version, ok bool) {
+	v, ok = ctx.Value(versionKey{}).(Version)
+	return
+}
+
+//deprecate set the `Deprecation`, `Sunset` and `Link` headers when v is deprecated by one of deprecations
+func deprecate(w http.ResponseWriter, v Version, deprecations []config.Deprecation) {
+	for _, d := range deprecations {
+		below, e := ParseVersion(d.Below)
+		if e != nil || v.Compare(below) >= 0 {
+			continue
+		}
+
+		w.Header().Set("Deprecation", "true")
+		if sunset, e := time.Parse("2006-01-02", d.Sunset); e == nil {
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+
+		if d.Link != "" {
+			w.Header().Set("Link", "<"+d.Link+">; rel=\"deprecation\"")
+		}
+
+		return
+	}
+}
+
 //Versioning middleware
 //
-//Only accept the request with in the version range
-func Versioning(router *mux.Router, minVersion string, maxVersion string) {
+//Only accept the request with in the version range, the version is carried by the request context and the
+//deprecated versions get the `Deprecation` and `Sunset` headers
+func Versioning(router *mux.Router, minVersion string, maxVersion string, deprecations ...config.Deprecation) {
 	middleware := func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			version := r.Header.Get("Version")
+			version := r.Header.Get(VersionHeader)
 
 			if version != "" {
 				var res sres.Response
@@ -74,17 +221,75 @@ func Versioning(router *mux.Router, minVersion string, maxVersion string) {
 				res.Status, err = compareVersion(version, minVersion, maxVersion)
 
 				if err != nil {
-					res.Error(err)
+					res.Error(sres.NewError(sres.CodeBadRequest, err.Error()))
 					sres.WriteJson(w, res)
 				} else {
-					h.ServeHTTP(w, r)
+					v, _ := ParseVersion(version)
+					deprecate(w, v, deprecations)
+					h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), versionKey{}, v)))
 				}
 			} else {
-				var res sres.Response = sres.Response{Status: false, Message: "Version is missing"}
-				sres.WriteJson(w, res)
+				sres.WriteError(w, sres.NewError(sres.CodeBadRequest, "Version is missing"))
 			}
 		})
 	}
 
 	router.Use(middleware)
 }
+
+type versionedHandler struct {
+	min     Version
+	handler http.Handler
+}
+
+//Versioned is the handler which dispatch a request to the handler of its version, a handler serve the versions from
+//its min to the min of the next handler. The requests without a version are dispatched to the lowest handler
+type Versioned struct {
+	handlers []versionedHandler
+}
+
+//Version register the handler of the versions from min, it panics when min is invalid
+func (v *Versioned) Version(min string, h http.HandlerFunc) *Versioned {
+	version, e := ParseVersion(min)
+	if e != nil {
+		panic(e)
+	}
+
+	v.handlers = append(v.handlers, versionedHandler{version, h})
+	sort.SliceStable(v.handlers, func(i, j int) bool {
+		return v.handlers[i].min.Compare(v.handlers[j].min) < 0
+	})
+
+	return v
+}
+
+func (v *Versioned) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(v.handlers) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	version, ok := VersionFromContext(r.Context())
+	if !ok {
+		header := r.Header.Get(VersionHeader)
+		if header == "" {
+			v.handlers[0].handler.ServeHTTP(w, r)
+			return
+		}
+
+		var e error
+		if version, e = ParseVersion(header); e != nil {
+			sres.WriteError(w, sres.NewError(sres.CodeBadRequest, e.Error()))
+			return
+		}
+	}
+
+	for i := len(v.handlers) - 1; i >= 0; i-- {
+		if version.Compare(v.handlers[i].min) >= 0 {
+			v.handlers[i].handler.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	sres.WriteError(w, sres.NewError(sres.CodeBadRequest, "This version is not supported"))
+}
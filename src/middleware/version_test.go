@@ -1,113 +1,107 @@
 package middleware_test
 
 import (
-	"errors"
-	"regexp"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/config"
+	"streelity/v1/middleware"
 	"testing"
-)
-
-//sumOfRunes return the sum of runes by int in a string
-func sumOfRunes(s string) (sum int) {
-	for _, r := range s {
-		sum += int(r)
-	}
-
-	return
-}
-
-func compareVersion(currentVersion, minVersion, maxVersion string) (status bool, err error) {
-	regFormat, _ := regexp.Compile("\\d*?[.]\\d*")
-	reg, _ := regexp.Compile("[.]")
-	if !regFormat.Match([]byte(currentVersion)) {
-		return false, errors.New("currentVersion format is invalid")
-	}
 
-	if !regFormat.Match([]byte(minVersion)) {
-		return false, errors.New("currentVersion format is invalid")
-	}
+	"github.com/gorilla/mux"
+)
 
-	if !regFormat.Match([]byte(maxVersion)) {
-		return false, errors.New("currentVersion format is invalid")
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		current string
+		min     string
+		max     string
+		in      bool
+		valid   bool
+	}{
+		{"1.0.0", "1.0.0", "1.0.0", true, true},
+		{"1.1.0", "1.0.0", "1.0.0", false, true},
+		{"1.1.0", "1.0.0", "2.0.0", true, true},
+		{"1.1.0fdf", "1.0.0", "2.0.0", false, false},
+		{"1.10.0", "1.0.0", "1.9.0", false, true},
+		{"1.10.0", "1.9.0", "2.0.0", true, true},
+		{"2.0.0-beta.1", "2.0.0", "", false, true},
+		{"2.0.0-beta.2", "2.0.0-beta.1", "2.0.0", true, true},
+		{"v2", "2.0.0", "2.0.0", true, true},
+		{"2.1.0+build.5", "1.0.0", "2.1.0", true, true},
+		{"01.0.0", "1.0.0", "2.0.0", false, false},
 	}
 
-	current := reg.Split(currentVersion, -1)
-	min := reg.Split(minVersion, -1)
-	max := reg.Split(maxVersion, -1)
+	for _, test := range tests {
+		v, e := middleware.ParseVersion(test.current)
+		if (e == nil) != test.valid {
+			t.Errorf("ParseVersion(%v): got error %v want valid %v", test.current, e, test.valid)
+			continue
+		}
 
-	if len(current) < 3 {
-		return false, errors.New("currentVersion format is invalid")
-	}
+		if !test.valid {
+			continue
+		}
 
-	if len(min) < 3 {
-		return false, errors.New("minVersion format is invalid")
+		if in, _ := (middleware.VersionRange{Min: test.min, Max: test.max}).Contains(v); in != test.in {
+			t.Errorf("%v in %v..%v: got %v want %v", test.current, test.min, test.max, in, test.in)
+		}
 	}
 
-	if len(max) < 3 {
-		return false, errors.New("maxVersion format is invalid")
+	pre, _ := middleware.ParseVersion("1.0.0-alpha")
+	numeric, _ := middleware.ParseVersion("1.0.0-1")
+	if numeric.Compare(pre) >= 0 {
+		t.Errorf("numeric pre-release is not lower than alphanumeric one")
 	}
-	minValue := sumOfRunes(min[0])*100 + sumOfRunes(min[1])*10 + sumOfRunes(min[2])
-	currentValue := sumOfRunes(current[0])*100 + sumOfRunes(current[1])*10 + sumOfRunes(current[2])
-	maxValue := sumOfRunes(max[0])*100 + sumOfRunes(max[1])*10 + sumOfRunes(max[2])
-
-	if currentValue >= minValue && currentValue <= maxValue {
-		return true, nil
-	}
-
-	return false, errors.New("This version is not supported")
 }
 
-func TestCompareVersion(t *testing.T) {
-	current := "1.0.0"
-	min := "1.0.0"
-	max := "1.0.0"
-
-	status, err := compareVersion(current, min, max)
-	expected := true
-
-	if err != nil {
-		t.Errorf("Compare version failed, expected %v but got error %v", expected, err.Error())
-	} else {
-		t.Logf("Compare version success, expected %v got %v", expected, status)
-	}
-
-	//test 2
-	current = "1.1.0"
-	min = "1.0.0"
-	max = "1.0.0"
-
-	status, err = compareVersion(current, min, max)
-	expected = false
-
-	if status != expected {
-		t.Errorf("Compare version failed, expected %v but got %v", expected, status)
-	} else {
-		t.Logf("Compare version success, expected %v got %v", expected, status)
+func TestVersioning(t *testing.T) {
+	r := mux.NewRouter()
+	s := r.PathPrefix("/service").Subrouter()
+	v1 := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{"Status":true,"Message":"v1"}`)) }
+	v2 := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{"Status":true,"Message":"v2"}`)) }
+	s.Handle("/range", new(middleware.Versioned).Version("2.0.0", v2).Version("1.0.0", v1))
+	middleware.Versioning(s, "1.0.0", "2.1.0", config.Deprecation{Below: "1.5.0", Sunset: "2027-01-31", Link: "https://example.com/v2"})
+
+	tests := []struct {
+		version    string
+		status     bool
+		message    string
+		deprecated bool
+	}{
+		{"", false, "Version is missing", false},
+		{"0.9.0", false, "This version is not supported", false},
+		{"1.2.0", true, "v1", true},
+		{"1.10.0", true, "v1", false},
+		{"2.0.0", true, "v2", false},
+		{"2.1.0", true, "v2", false},
+		{"2.10.0", false, "This version is not supported", false},
 	}
 
-	//test 3
-	current = "1.1.0"
-	min = "1.0.0"
-	max = "2.0.0"
-
-	status, err = compareVersion(current, min, max)
-	expected = true
-
-	if status != expected {
-		t.Errorf("Compare version failed, expected %v but got %v", expected, status)
-	} else {
-		t.Logf("Compare version success, expected %v got %v", expected, status)
-	}
-
-	current = "1.1.0fdf"
-	min = "1.0.0"
-	max = "2.0.0"
-
-	status, err = compareVersion(current, min, max)
-	expected = false
-
-	if status != expected {
-		t.Errorf("Compare version failed, expected %v but got %v", expected, status)
-	} else {
-		t.Logf("Compare version success, expected %v got %v", expected, status)
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/service/range", nil)
+		if test.version != "" {
+			req.Header.Set("Version", test.version)
+		}
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		var res struct {
+			Status  bool
+			Message string
+		}
+		json.Unmarshal(rr.Body.Bytes(), &res)
+		if res.Status != test.status || res.Message != test.message {
+			t.Errorf("version %v: got %v %v want %v %v", test.version, res.Status, res.Message, test.status, test.message)
+		}
+
+		if deprecated := rr.Header().Get("Deprecation") == "true"; deprecated != test.deprecated {
+			t.Errorf("version %v: got deprecated %v want %v", test.version, deprecated, test.deprecated)
+		}
+
+		if test.deprecated && rr.Header().Get("Sunset") != "Sun, 31 Jan 2027 00:00:00 GMT" {
+			t.Errorf("version %v: wrong Sunset: got %v", test.version, rr.Header().Get("Sunset"))
+		}
 	}
 }
@@ -0,0 +1,409 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+//ApiKey is a key of a partner, it is scoped by the methods, the service types and the endpoints which it can
+//request and its usage is limited by the quotas
+type ApiKey struct {
+	Id int64 `gorm:"column:id"`
+	//Name is the partner who is owning the key
+	Name string `gorm:"column:name"`
+	//Prefix is the beginning of the secret, it identify the key to the admins and the partner
+	Prefix string `gorm:"column:prefix"`
+	//Hash is the sha256 of the secret, the secret is only shown when the key is issued
+	Hash   string `gorm:"column:hash;unique_index" json:"-"`
+	Secret string `gorm:"-" json:",omitempty"`
+	//ReadOnly allow the GET requests only
+	ReadOnly bool `gorm:"column:read_only"`
+	//ServiceTypes and Endpoints are the comma separated service types and path prefixes which the key can request,
+	//every service type or endpoint is allowed when they are empty
+	ServiceTypes string `gorm:"column:service_types"`
+	Endpoints    string `gorm:"column:endpoints"`
+	//DailyQuota and MonthlyQuota are the requests which the key can make in a day and a month, 0 is unlimited
+	DailyQuota   int        `gorm:"column:daily_quota"`
+	MonthlyQuota int        `gorm:"column:monthly_quota"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	RevokedAt    *time.Time `gorm:"column:revoked_at"`
+}
+
+const ApiKeyTableName = "api_key"
+
+func (ApiKey) TableName() string {
+	return ApiKeyTableName
+}
+
+//ApiUsage is the requests which are made by an api key in a period, the period is a day (2006-01-02) or a month
+//(2006-01)
+type ApiUsage struct {
+	KeyId  int64  `gorm:"column:key_id;primary_key;auto_increment:false"`
+	Period string `gorm:"column:period;primary_key"`
+	Count  int    `gorm:"column:count"`
+}
+
+const ApiUsageTableName = "api_usage"
+
+func (ApiUsage) TableName() string {
+	return ApiUsageTableName
+}
+
+//usagePeriods return the day and the month of t
+func usagePeriods(t time.Time) (day string, month string) {
+	return t.Format("2006-01-02"), t.Format("2006-01")
+}
+
+//hashKey return the stored hash of the secret of an api key
+func hashKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+//newSecret generate the secret of an api key and put its hash and its prefix into key
+func newSecret(key *ApiKey) error {
+	b := make([]byte, 24)
+	if _, e := rand.Read(b); e != nil {
+		return e
+	}
+
+	key.Secret = "sk_" + hex.EncodeToString(b)
+	key.Prefix = key.Secret[:11]
+	key.Hash = hashKey(key.Secret)
+	return nil
+}
+
+//IsRevoked determine whether the key is revoked
+func (key ApiKey) IsRevoked() bool {
+	return key.RevokedAt != nil
+}
+
+//splitList return the values of a comma separated list, nil is returned for an empty list
+func splitList(list string) (values []string) {
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return
+}
+
+//ServiceTypeOfPath return the service type which a request path is belonging to, the unconfirmed services are
+//belonging to their service type. It is the segment after /service of the path
+func ServiceTypeOfPath(path string) string {
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(segments) < 2 || segments[0] != "service" {
+		return ""
+	}
+
+	return strings.TrimSuffix(segments[1], "_ucf")
+}
+
+//Allows return an error when the key cannot make the request of method to path
+func (key ApiKey) Allows(method string, path string) error {
+	if key.IsRevoked() {
+		return errors.New("api key is revoked")
+	}
+
+	if key.ReadOnly && method != http.MethodGet && method != http.MethodHead {
+		return errors.New("api key is read-only")
+	}
+
+	if types := splitList(key.ServiceTypes); len(types) > 0 && !contains(types, ServiceTypeOfPath(path)) {
+		return errors.New("api key is not allowed to request " + ServiceTypeOfPath(path))
+	}
+
+	if endpoints := splitList(key.Endpoints); len(endpoints) > 0 {
+		for _, endpoint := range endpoints {
+			if withinEndpoint(path, endpoint) {
+				return nil
+			}
+		}
+
+		return errors.New("api key is not allowed to request " + path)
+	}
+
+	return nil
+}
+
+//withinEndpoint determine whether path is the endpoint or one of its sub paths, the endpoint is matched by whole
+//segments so /service/atm doesn't match /service/atm_ucf
+func withinEndpoint(path string, endpoint string) bool {
+	return path == endpoint || strings.HasPrefix(path, strings.TrimSuffix(endpoint, "/")+"/")
+}
+
+//exceeded determine whether a request over the usages is exceeding the quotas of the key
+func (key ApiKey) exceeded(daily int, monthly int) bool {
+	return key.DailyQuota > 0 && daily >= key.DailyQuota || key.MonthlyQuota > 0 && monthly >= key.MonthlyQuota
+}
+
+type apiKeyKey struct{}
+
+//WithApiKey return a copy of ctx which is carrying the api key of the request
+func WithApiKey(ctx context.Context, key ApiKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey{}, key)
+}
+
+//ApiKeyFromContext return the api key which is carried by ctx
+func ApiKeyFromContext(ctx context.Context) (key ApiKey, ok bool) {
+	key, ok = ctx.Value(apiKeyKey{}).(ApiKey)
+	return
+}
+
+//ApiKeyRepository determine the data access of the api keys and their usages
+type ApiKeyRepository interface {
+	//Issue create the key with a new secret, the secret is only returned here
+	Issue(key ApiKey) (ApiKey, error)
+	Revoke(id int64) (ApiKey, error)
+	Keys() ([]ApiKey, error)
+	//Verify return the key of the secret
+	Verify(secret string) (ApiKey, error)
+	//Use count a request of the key at t, ErrQuotaExceeded is returned without counting when the request is over
+	//the quotas. The usages of the day and the month are returned
+	Use(id int64, t time.Time) (daily ApiUsage, monthly ApiUsage, e error)
+	//Usage return the usages of the key by period, the latest is the first
+	Usage(id int64) ([]ApiUsage, error)
+}
+
+//ApiKeyDatabase is the ApiKeyRepository which is working on model.Db
+type ApiKeyDatabase struct{}
+
+func (ApiKeyDatabase) Issue(key ApiKey) (ApiKey, error) {
+	if e := newSecret(&key); e != nil {
+		return key, e
+	}
+
+	key.Id, key.RevokedAt = 0, nil
+	if e := Db.Create(&key).Error; e != nil {
+		log.Println("[Database]", "issue api key", e.Error())
+		return key, e
+	}
+
+	return key, nil
+}
+
+func (ApiKeyDatabase) Revoke(id int64) (key ApiKey, e error) {
+	if e = Db.Where("id = ?", id).First(&key).Error; e != nil {
+		return
+	}
+
+	if key.IsRevoked() {
+		return key, NewError(ErrConflict, "api key is already revoked")
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	e = Db.Model(&key).Update("revoked_at", now).Error
+	return
+}
+
+func (ApiKeyDatabase) Keys() (keys []ApiKey, e error) {
+	if e = Db.Order("id").Find(&keys).Error; e != nil {
+		log.Println("[Database]", "api keys", e.Error())
+	}
+
+	return
+}
+
+func (ApiKeyDatabase) Verify(secret string) (key ApiKey, e error) {
+	if e = Db.Where("hash = ?", hashKey(secret)).First(&key).Error; gorm.IsRecordNotFoundError(e) {
+		e = errors.New("api key is invalid")
+	}
+
+	return
+}
+
+//Use count a request of the key in its usages. Every usage is increased by an update which requires the count to
+//be under the quota, so the concurrent requests cannot pass the quota together
+func (ApiKeyDatabase) Use(id int64, t time.Time) (daily ApiUsage, monthly ApiUsage, e error) {
+	day, month := usagePeriods(t)
+	e = Db.Transaction(func(tx *gorm.DB) error {
+		var key ApiKey
+		if e := tx.Where("id = ?", id).First(&key).Error; e != nil {
+			return e
+		}
+
+		quotas := map[string]int{day: key.DailyQuota, month: key.MonthlyQuota}
+		for _, period := range []string{day, month} {
+			if e := createUsage(tx, id, period); e != nil {
+				return e
+			}
+
+			update := tx.Model(&ApiUsage{}).Where("key_id = ? AND period = ?", id, period)
+			if quota := quotas[period]; quota > 0 {
+				update = update.Where("count < ?", quota)
+			}
+
+			if result := update.UpdateColumn("count", gorm.Expr("count + 1")); result.Error != nil {
+				return result.Error
+			} else if result.RowsAffected == 0 {
+				return ErrQuotaExceeded
+			}
+		}
+
+		return nil
+	})
+
+	if e != nil && e != ErrQuotaExceeded {
+		return
+	}
+
+	daily, monthly = ApiUsage{KeyId: id, Period: day}, ApiUsage{KeyId: id, Period: month}
+	Db.Where(daily).First(&daily)
+	Db.Where(monthly).First(&monthly)
+	return
+}
+
+//createUsage insert the usage of the period when it is not existed, the usage which is inserted by a concurrent
+//request is read instead
+func createUsage(tx *gorm.DB, id int64, period string) error {
+	usage := ApiUsage{KeyId: id, Period: period}
+	if e := tx.Where(usage).FirstOrCreate(&usage).Error; e != nil {
+		return tx.Where(usage).First(&usage).Error
+	}
+
+	return nil
+}
+
+func (ApiKeyDatabase) Usage(id int64) (usages []ApiUsage, e error) {
+	if e = Db.Where("key_id = ?", id).Order("period DESC").Find(&usages).Error; e != nil {
+		log.Println("[Database]", "api usage", e.Error())
+	}
+
+	return
+}
+
+//ApiKeyMemory is the in-memory ApiKeyRepository
+type ApiKeyMemory struct {
+	mutex  sync.Mutex
+	keys   []ApiKey
+	usages map[int64]map[string]int
+}
+
+func (m *ApiKeyMemory) find(id int64) (int, error) {
+	for i, key := range m.keys {
+		if key.Id == id {
+			return i, nil
+		}
+	}
+
+	return -1, gorm.ErrRecordNotFound
+}
+
+func (m *ApiKeyMemory) Issue(key ApiKey) (ApiKey, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if e := newSecret(&key); e != nil {
+		return key, e
+	}
+
+	key.Id, key.RevokedAt, key.CreatedAt = int64(len(m.keys)+1), nil, time.Now()
+	stored := key
+	stored.Secret = ""
+	m.keys = append(m.keys, stored)
+	return key, nil
+}
+
+func (m *ApiKeyMemory) Revoke(id int64) (key ApiKey, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i, e := m.find(id)
+	if e != nil {
+		return
+	}
+
+	if m.keys[i].IsRevoked() {
+		return m.keys[i], NewError(ErrConflict, "api key is already revoked")
+	}
+
+	now := time.Now()
+	m.keys[i].RevokedAt = &now
+	return m.keys[i], nil
+}
+
+func (m *ApiKeyMemory) Keys() (keys []ApiKey, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keys = append([]ApiKey{}, m.keys...)
+	return
+}
+
+func (m *ApiKeyMemory) Verify(secret string) (key ApiKey, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	hash := hashKey(secret)
+	for _, key := range m.keys {
+		if key.Hash == hash {
+			return key, nil
+		}
+	}
+
+	return key, errors.New("api key is invalid")
+}
+
+func (m *ApiKeyMemory) Use(id int64, t time.Time) (daily ApiUsage, monthly ApiUsage, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i, e := m.find(id)
+	if e != nil {
+		return
+	}
+
+	if m.usages == nil {
+		m.usages = make(map[int64]map[string]int)
+	}
+
+	if m.usages[id] == nil {
+		m.usages[id] = make(map[string]int)
+	}
+
+	day, month := usagePeriods(t)
+	daily = ApiUsage{KeyId: id, Period: day, Count: m.usages[id][day]}
+	monthly = ApiUsage{KeyId: id, Period: month, Count: m.usages[id][month]}
+	if m.keys[i].exceeded(daily.Count, monthly.Count) {
+		return daily, monthly, ErrQuotaExceeded
+	}
+
+	m.usages[id][day]++
+	m.usages[id][month]++
+	daily.Count++
+	monthly.Count++
+	return
+}
+
+func (m *ApiKeyMemory) Usage(id int64) (usages []ApiUsage, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	usages = []ApiUsage{}
+	for period, count := range m.usages[id] {
+		usages = append(usages, ApiUsage{KeyId: id, Period: period, Count: count})
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Period > usages[j].Period
+	})
+
+	return
+}
+
+func init() {
+	AutoMigrate(&ApiKey{}, &ApiUsage{})
+}
@@ -0,0 +1,133 @@
+package model_test
+
+import (
+	"streelity/v1/model"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestApiKeyAllows(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		key     model.ApiKey
+		method  string
+		path    string
+		allowed bool
+	}{
+		{"unscoped", model.ApiKey{}, "POST", "/service/atm/create", true},
+		{"read-only get", model.ApiKey{ReadOnly: true}, "GET", "/service/atm/range", true},
+		{"read-only post", model.ApiKey{ReadOnly: true}, "POST", "/service/atm/create", false},
+		{"allowed type", model.ApiKey{ServiceTypes: "fuel,atm"}, "GET", "/service/atm/range", true},
+		{"allowed unconfirmed type", model.ApiKey{ServiceTypes: "atm"}, "GET", "/service/atm_ucf/range", true},
+		{"disallowed type", model.ApiKey{ServiceTypes: "fuel"}, "GET", "/service/atm/range", false},
+		{"allowed endpoint", model.ApiKey{Endpoints: "/service/service/fuel/range,/service/fuel/all"}, "GET", "/service/fuel/all", true},
+		{"disallowed endpoint", model.ApiKey{Endpoints: "/service/fuel/range"}, "GET", "/service/fuel/all", false},
+		{"sub path of endpoint", model.ApiKey{Endpoints: "/service/atm"}, "GET", "/service/atm/range", true},
+		{"endpoint with slash", model.ApiKey{Endpoints: "/service/atm/"}, "GET", "/service/atm/range", true},
+		{"prefix of segment", model.ApiKey{Endpoints: "/service/atm"}, "GET", "/service/atm_ucf/range", false},
+		{"revoked", model.ApiKey{RevokedAt: &now}, "GET", "/service/atm/range", false},
+		{"typed key on another route", model.ApiKey{ServiceTypes: "atm"}, "GET", "/reputation", false},
+	}
+
+	for _, test := range tests {
+		if e := test.key.Allows(test.method, test.path); (e == nil) != test.allowed {
+			t.Errorf("%v: got %v want allowed %v", test.name, e, test.allowed)
+		}
+	}
+}
+
+func TestApiKeyRepositories(t *testing.T) {
+	model.ConnectSync()
+	repositories := map[string]model.ApiKeyRepository{
+		"database": model.ApiKeyDatabase{},
+		"memory":   new(model.ApiKeyMemory),
+	}
+
+	for name, r := range repositories {
+		key, e := r.Issue(model.ApiKey{Name: name, ReadOnly: true, DailyQuota: 2, MonthlyQuota: 3})
+		if e != nil || key.Secret == "" || key.Prefix == "" {
+			t.Fatalf("%v: wrong issue: got %v %v", name, key, e)
+		}
+
+		if verified, e := r.Verify(key.Secret); e != nil || verified.Id != key.Id || verified.Secret != "" {
+			t.Errorf("%v: wrong verify: got %v %v", name, verified, e)
+		}
+
+		if _, e := r.Verify(key.Prefix); e == nil {
+			t.Errorf("%v: a wrong secret is verified", name)
+		}
+
+		today := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+		tomorrow := today.Add(24 * time.Hour)
+		uses := []struct {
+			t     time.Time
+			daily int
+			e     error
+		}{
+			{today, 1, nil},
+			{today, 2, nil},
+			{today, 2, model.ErrQuotaExceeded},
+			{tomorrow, 1, nil},
+			{tomorrow, 1, model.ErrQuotaExceeded},
+		}
+
+		for i, use := range uses {
+			if daily, _, e := r.Use(key.Id, use.t); e != use.e || daily.Count != use.daily {
+				t.Errorf("%v: use %v: got %v %v want %v %v", name, i, daily.Count, e, use.daily, use.e)
+			}
+		}
+
+		//the second request of tomorrow is over the monthly quota, the latest day is the first usage
+		usages, _ := r.Usage(key.Id)
+		if len(usages) != 3 || usages[0].Period != "2026-03-16" || usages[0].Count != 1 || usages[2].Count != 3 {
+			t.Errorf("%v: wrong usage: got %v", name, usages)
+		}
+
+		if revoked, e := r.Revoke(key.Id); e != nil || !revoked.IsRevoked() {
+			t.Errorf("%v: wrong revoke: got %v %v", name, revoked, e)
+		}
+
+		if _, e := r.Revoke(key.Id); e == nil {
+			t.Errorf("%v: key is revoked twice", name)
+		}
+	}
+}
+
+func TestApiKeyConcurrentUse(t *testing.T) {
+	model.ConnectSync()
+	repositories := map[string]model.ApiKeyRepository{
+		"database": model.ApiKeyDatabase{},
+		"memory":   new(model.ApiKeyMemory),
+	}
+
+	for name, r := range repositories {
+		key, _ := r.Issue(model.ApiKey{Name: name + " concurrent", DailyQuota: 5})
+		now := time.Now()
+		errs := make(chan error, 20)
+		var wg sync.WaitGroup
+		for i := 0; i < cap(errs); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _, e := r.Use(key.Id, now)
+				errs <- e
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+		used := 0
+		for e := range errs {
+			if e == nil {
+				used++
+			}
+		}
+
+		daily, _, _ := r.Use(key.Id, now)
+		if used != key.DailyQuota || daily.Count != key.DailyQuota {
+			t.Errorf("%v: quota is passed by the concurrent requests: got %v used and %v counted want %v", name, used, daily.Count, key.DailyQuota)
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package atm
 
 import (
-	"errors"
 	"log"
 	"math"
 	"net/url"
+	"sort"
 	"strconv"
 	"streelity/v1/model"
 	"strings"
+	"time"
 
 	"github.com/golang/geo/r2"
 	"github.com/jinzhu/gorm"
@@ -19,7 +20,7 @@ type Atm struct {
 	BankId int64 `gorm:"column:bank_id"`
 }
 
-var map_services map[int64]Atm
+var map_services map[int64]Atm = make(map[int64]Atm)
 var services spatial.RTree
 var tag string = "[ATM]"
 
@@ -89,13 +90,21 @@ func ServicesByAddress(address string) (services []Atm, e error) {
 
 //ServicesByIds query the atm services by specific ids
 func ServicesByIds(ids ...int64) (services []Atm) {
+	var found []Atm
+	if e := model.Db.Where("id IN (?)", ids).Find(&found).Error; e != nil {
+		log.Println("[Database]", "atm by ids", e.Error())
+	}
+
+	//the services are returned in the order of ids like they are queried one by one
+	byId := make(map[int64]Atm)
+	for _, s := range found {
+		byId[s.Id] = s
+	}
+
 	for _, id := range ids {
-		s, e := ServiceById(id)
-		if e != nil {
-			continue
+		if s, ok := byId[id]; ok {
+			services = append(services, s)
 		}
-
-		services = append(services, s)
 	}
 
 	return
@@ -105,12 +114,16 @@ func ServicesByIds(ids ...int64) (services []Atm) {
 //
 //return error if there is something wrong when doing transaction
 func CreateService(s Atm) (service Atm, e error) {
+	return createService(model.Db, s)
+}
+
+func createService(db *gorm.DB, s Atm) (service Atm, e error) {
 	service = s
-	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&Atm{}).Error; e == nil {
-		return s, errors.New("The service location is existed or some problems is occured")
+	if e = db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&Atm{}).Error; e == nil {
+		return s, model.NewError(model.ErrConflict, "The service location is existed or some problems is occured")
 	}
 
-	if e = model.Db.Create(&service).Error; e != nil {
+	if e = db.Create(&service).Error; e != nil {
 		log.Println("[Database]", "Add atm", e.Error())
 		return
 	}
@@ -118,66 +131,308 @@ func CreateService(s Atm) (service Atm, e error) {
 	return
 }
 
-//UpvoteService upvote the unconfirmed atm by specific id
-func UpvoteService(id int64) error {
-	return upvoteService(id, 1)
+//UpvoteService record the upvote of voter on the atm service by specific id,
+//the previous vote of voter on the service is replaced. The upvote of a trusted source confirm the service at once
+func UpvoteService(id int64, voter string) error {
+	return voteService(id, model.Vote{Voter: voter, Direction: model.VoteUp, Trusted: policy().IsTrusted(voter)})
+}
+
+//DownvoteService record the downvote of voter on the atm service by specific id
+func DownvoteService(id int64, voter string) error {
+	return voteService(id, model.Vote{Voter: voter, Direction: model.VoteDown})
+}
+
+//WithdrawVote remove the vote of voter on the atm service by specific id
+func WithdrawVote(id int64, voter string) error {
+	return voteService(id, model.Vote{Voter: voter, Direction: model.VoteWithdraw})
+}
+
+//VotesByService query the active votes on the atm service by specific id
+func VotesByService(id int64) ([]model.Vote, error) {
+	return model.VotesByService(ServiceTableName, id)
+}
+
+//TransitService change the state of the atm service by specific id, the transition is logged with actor and reason
+func TransitService(id int64, to, actor, reason string) (service Atm, e error) {
+	if service, e = ServiceById(id); e != nil {
+		return
+	}
+
+	if e = service.Transit(to, actor, reason); e != nil {
+		return
+	}
+
+	if e = model.Db.Save(&service).Error; e != nil {
+		log.Println("[Database]", "transit atm", id, ":", e.Error())
+	}
+
+	return
+}
+
+//TransitionsByService query the lifecycle log of the atm service by specific id
+func TransitionsByService(id int64) ([]model.Transition, error) {
+	return model.TransitionsByService(ServiceTableName, id)
 }
 
-func DownvoteService(id int64) error {
-	return upvoteService(id, -1)
+//ServicesByState query the atm services which are in one of the states
+func ServicesByState(states ...string) (services []Atm, e error) {
+	if e = model.Db.Where("state IN (?)", model.QueriedStates(states)).Find(&services).Error; e != nil {
+		log.Println("[Database]", "atm by state", e.Error())
+	}
+
+	return
+}
+
+//PageServices return the page of the atm services which is queried by q, the services are filtered by the states
+//when there are some
+func PageServices(q model.PageQuery, states ...string) (services []Atm, page model.Page, e error) {
+	db := model.Db
+	if len(states) > 0 {
+		db = db.Where("state IN (?)", model.QueriedStates(states))
+	}
+
+	q.RatingTable = ReviewTableName
+	page, e = model.PaginateQuery(db, &services, q)
+	return
 }
 
-func UpvoteServiceImmediately(id int64) error {
-	return upvoteService(id, confident)
+//PageServicesByAddress return the page of the atm services whose address contain address which is queried by q
+func PageServicesByAddress(address string, q model.PageQuery) (services []Atm, page model.Page, e error) {
+	q.RatingTable = ReviewTableName
+	page, e = model.PaginateQuery(model.Db.Where("address LIKE ?", "%"+address+"%"), &services, q)
+	return
 }
 
-func upvoteService(id int64, value int) (e error) {
+//PageServicesInRange return the page of the atm services in the radius of a location which is queried by q, the
+//services are filtered by the states when there are some and they are the listed ones otherwise
+func PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) (services []Atm, page model.Page, e error) {
+	if len(states) == 0 {
+		states = model.ListedStates
+	}
+
+	db := model.InRange(model.Db.Where("state IN (?)", model.QueriedStates(states)), ServiceTableName, p, max_range)
+	q.RatingTable = ReviewTableName
+	page, e = model.PaginateQuery(db, &services, q)
+	return
+}
+
+//VerifyService record a positive signal like a check-in on the atm service by specific id
+func VerifyService(id int64) (service Atm, e error) {
+	if service, e = ServiceById(id); e != nil {
+		return
+	}
+
+	service.Verify(time.Now())
+	if e = model.Db.Save(&service).Error; e != nil {
+		log.Println("[Database]", "verify atm", id, ":", e.Error())
+	}
+
+	return
+}
+
+//DecayServices evaluate the confirmed atm services whose confident is decayed again, the services which
+//drop out of the confirmation policy are disputed
+func DecayServices() (disputed int, e error) {
+	ids, e := model.DecayingIds(ServiceTableName, policy(), time.Now())
+	if e != nil {
+		return
+	}
+
+	for _, id := range ids {
+		s, e := ServiceById(id)
+		if e != nil {
+			continue
+		}
+
+		if e = model.Db.Save(&s).Error; e != nil {
+			log.Println("[Database]", "decay atm", id, ":", e.Error())
+		} else if s.State == model.StateDisputed {
+			disputed++
+		}
+	}
+
+	return
+}
+
+//ReverifyQueue query the atm services in the radius of a location which should be verified again by the nearby
+//users, the service which is verified longest ago is the first
+func ReverifyQueue(p r2.Point, max_range float64) []Atm {
+	return reverifyQueue(ServicesInRange(p, max_range))
+}
+
+func reverifyQueue(services []Atm) []Atm {
+	var result []Atm = []Atm{}
+	now := time.Now()
+	for _, s := range services {
+		if model.NeedsReverification(policy(), s.Lifecycle, now) {
+			result = append(result, s)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return model.SignalBefore(result[i].Lifecycle, result[j].Lifecycle) })
+	return result
+}
+
+//ReportService file the problem report on the atm service by specific id, the service is changed when enough
+//reporters concur on the kind of the report, see model.ReportTransition
+func ReportService(id int64, r model.Report) (report model.Report, e error) {
 	s, e := ServiceById(id)
+	if e != nil {
+		return
+	}
+
+	if r.Kind == model.ReportDuplicate {
+		if _, e = ServiceById(r.DuplicateOf); e != nil {
+			return
+		}
+	}
 
+	r.ServiceType = ServiceTableName
+	r.ServiceId = id
+	report, concurring, e := model.FileReport(r)
 	if e != nil {
-		return e
+		return
+	}
+
+	//the reports are queued for the moderation until they are accepted, the concurring reporters raise the priority
+	model.Enqueue(model.Db, model.ModerationItem{Kind: model.ModerationReport, ServiceType: ServiceTableName, ServiceId: id, Subject: report.Kind,
+		Author: report.Reporter, Note: report.Note, Lat: s.Lat, Lon: s.Lon, Priority: concurring})
+	if !reported(s, report, concurring) {
+		return
 	}
 
-	s.Confident += value
-	if e := model.Db.Save(&s).Error; e != nil {
-		log.Println("[Database]", "upvote unconfirmed atm", id, ":", e.Error())
+	if report, e = acceptReport(s, report, model.ActorSystem); e == nil {
+		model.ResolveQueued(model.Db, model.ModerationReport, ServiceTableName, id, report.Kind, true, model.ActorSystem, "concurring reports reach the policy")
 	}
 
 	return
 }
 
-func UpdateService(id int64, values url.Values) (service Atm, e error) {
-	service, e = ServiceById(id)
+//reported determine whether the concurring reports of the kind of r change the state of s, the services which
+//cannot be changed to the state of the report are left for the moderation
+func reported(s Atm, r model.Report, concurring int) bool {
+	p := policy()
+	to, _ := model.ReportTransition(r)
+	return p.ReportThreshold > 0 && concurring >= p.ReportThreshold && s.GetState() != to && model.CanTransit(s.GetState(), to)
+}
+
+//ReportsByService query the problem reports on the atm service by specific id
+func ReportsByService(id int64) ([]model.Report, error) {
+	return model.ReportsByService(ServiceTableName, id)
+}
+
+//ReportsByServices query the reports on the services by specific ids, the newest is the first
+func ReportsByServices(ids ...int64) ([]model.Report, error) {
+	return model.ReportsByServices(ServiceTableName, ids...)
+}
+
+//AcceptReport change the atm service by specific id like the concurring reports of kind do, the open reports
+//of kind on the service are accepted by actor
+func AcceptReport(id int64, kind string, actor string) (report model.Report, e error) {
+	s, e := ServiceById(id)
+	if e != nil {
+		return
+	}
+
+	if report, e = model.OpenReport(ServiceTableName, id, kind); e != nil {
+		return
+	}
+
+	return acceptReport(s, report, actor)
+}
+
+func acceptReport(s Atm, report model.Report, actor string) (model.Report, error) {
+	to, reason := model.ReportTransition(report)
+	if _, e := TransitService(s.Id, to, actor, reason); e != nil {
+		return report, e
+	}
+
+	model.ResolveReports(model.Db, ServiceTableName, s.Id, report.Kind, model.ReportAccepted, actor)
+	if report.Kind == model.ReportDuplicate {
+		model.ChangeReputation(model.Db, s.Contributor, model.ReasonDuplicate, ServiceTableName, s.Id)
+	}
+
+	report.Status = model.ReportAccepted
+	return report, nil
+}
+
+//DismissReports dismiss the open reports of kind on the atm service by specific id by actor
+func DismissReports(id int64, kind string, actor string) (e error) {
+	if _, e = ServiceById(id); e != nil {
+		return
+	}
+
+	return model.ResolveReports(model.Db, ServiceTableName, id, kind, model.ReportDismissed, actor)
+}
+
+//ArchiveStale archive the pending atm services and unconfirmed atm which are not voted in the max age of the policy
+func ArchiveStale() (archived int, e error) {
+	reason := model.StaleReason(policy())
+	ids, e := model.StaleIds(ServiceTableName, policy(), time.Now())
 	if e != nil {
 		return
 	}
 
+	for _, id := range ids {
+		if _, e := TransitService(id, model.StateArchived, model.ActorSystem, reason); e == nil {
+			archived++
+		}
+	}
+
+	if ids, e = model.StaleIds(UcfServiceTableName, policy(), time.Now()); e != nil {
+		return
+	}
+
+	for _, id := range ids {
+		if e := transitUcf(id, model.StateArchived, model.ActorSystem, reason); e == nil {
+			archived++
+		}
+	}
+
+	return
+}
+
+func voteService(id int64, v model.Vote) error {
+	var s Atm
+	return model.CastVote(id, v, &s)
+}
+
+//setValues update the fields of service by the provided values, unknown or malformed values are ignored
+func (s *Atm) setValues(values url.Values) {
 	_, ok := values["lat"]
 	if ok {
-		if lat, e := strconv.ParseFloat(values["lat"][0], 64); e != nil {
-			service.Lat = float32(lat)
+		if lat, e := strconv.ParseFloat(values["lat"][0], 64); e == nil {
+			s.Lat = float32(lat)
 		}
 	}
 	_, ok = values["lon"]
 	if ok {
-		if lon, e := strconv.ParseFloat(values["lon"][0], 64); e != nil {
-			service.Lon = float32(lon)
+		if lon, e := strconv.ParseFloat(values["lon"][0], 64); e == nil {
+			s.Lon = float32(lon)
 		}
 	}
 
 	_, ok = values["note"]
 	if ok {
-		service.Note = values["note"][0]
+		s.Note = values["note"][0]
 	}
 
 	if _, ok = values["address"]; ok {
-		service.Address = values["address"][0]
+		s.Address = values["address"][0]
 	}
 
 	if _, ok = values["images"]; ok {
-		service.SetImages(values["images"]...)
+		s.SetImages(values["images"]...)
 	}
+}
 
+func UpdateService(id int64, values url.Values) (service Atm, e error) {
+	service, e = ServiceById(id)
+	if e != nil {
+		return
+	}
+
+	service.setValues(values)
 	if e := model.Db.Save(&service).Error; e != nil {
 		log.Println("[Database]", "update ", ServiceTableName, e.Error())
 	}
@@ -195,6 +450,15 @@ func Import(bytes []byte, t string) (e error) {
 }
 
 func ImportByRawText(data string) (e error) {
+	for _, s := range parseRawText(data, BankByName) {
+		CreateService(s)
+	}
+
+	return
+}
+
+//parseRawText parse the services from the raw text, each line is a service with fields separated by ";"
+func parseRawText(data string, bankByName func(name string) (Bank, error)) (services []Atm) {
 	lines := strings.Split(data, "\n")
 	for _, line := range lines {
 		fields := strings.Split(line, ";")
@@ -211,14 +475,14 @@ func ImportByRawText(data string) (e error) {
 		}
 
 		if lat, e := strconv.ParseFloat(m["lat"], 64); e != nil {
-			log.Println("[Fuel]", "import", "cannot parse lat to float")
+			log.Println("[ATM]", "import", "cannot parse lat to float")
 			continue
 		} else {
 			s.Lat = float32(lat)
 		}
 
 		if lon, e := strconv.ParseFloat(m["lon"], 64); e != nil {
-			log.Println("[Fuel]", "import", "cannot parse lon to float")
+			log.Println("[ATM]", "import", "cannot parse lon to float")
 			continue
 		} else {
 			s.Lon = float32(lon)
@@ -231,40 +495,72 @@ func ImportByRawText(data string) (e error) {
 		if note, ok := m["note"]; ok {
 			s.Note = note
 		}
-		if bank, e := BankByName(m["name"]); e != nil {
+		if bank, e := bankByName(m["name"]); e != nil {
 			continue
 		} else {
 			s.BankId = bank.Id
 		}
 
 		s.Contributor = "Streetlity"
-		s.Confident = confident + 1
-		CreateService(s)
+		s.Confident = policy().Threshold
+		services = append(services, s)
+	}
+
+	return
+}
+
+//BeforeSave change the state of the service which is driven by the votes, see model.Lifecycle. The service which
+//isn't confirmed is rejected when it is matching the rejection rules of the policy, see model.Rejection
+func (s *Atm) BeforeSave(scope *gorm.Scope) (e error) {
+	db := scope.NewDB()
+	voters := model.CountVoters(db, ServiceTableName, s.Id)
+	decayed := model.Decayed(policy(), s.Confident, s.Lifecycle, time.Now())
+	confirmed := model.Confirmed(policy(), s.Contributor, decayed, model.ReputationOf(db, s.Contributor), voters)
+	if !confirmed {
+		s.Reject(model.Rejection(policy(), s.Confident, model.IsSpammer(db, s.Contributor)))
 	}
 
+	s.Evaluate(confirmed)
 	return
 }
 
 func (s *Atm) AfterSave(scope *gorm.Scope) (e error) {
-	if s.Confident > confident {
+	db := scope.NewDB()
+	model.Settle(db, policy(), s.Contributor, s.Transited(), ServiceTableName, s.Id)
+	model.QueueSubmission(db, ServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
+	s.LogTransition(db, ServiceTableName, s.Id)
+	place(*s)
+	return
+}
+
+//place put the service into the index of its state, the services which are not listed or unconfirmed
+//are taken out of the indexes
+func place(s Atm) {
+	state := s.GetState()
+	if _, ok := map_services[s.Id]; ok && !model.IsListed(state) {
+		services.RemoveItem(s)
+		delete(map_services, s.Id)
+	}
+
+	if _, ok := map_ucfservices[s.Id]; ok && !model.IsUnconfirmed(state) {
+		ucf_services.RemoveItem(s)
+		delete(map_ucfservices, s.Id)
+	}
+
+	switch {
+	case model.IsListed(state):
 		if _, ok := map_services[s.Id]; !ok {
-			ucf_services.RemoveItem(s)
-			if e = services.AddItem(*s); e != nil {
+			if e := services.AddItem(s); e != nil {
 				log.Println("[Database]", "atm offical", e.Error())
 			}
-			delete(map_ucfservices, s.Id)
 		}
-		map_services[s.Id] = *s
-	} else {
+		map_services[s.Id] = s
+	case model.IsUnconfirmed(state):
 		if _, ok := map_ucfservices[s.Id]; !ok {
-			services.RemoveItem(s)
-			ucf_services.AddItem(*s)
-			delete(map_services, s.Id)
+			ucf_services.AddItem(s)
 		}
-		map_ucfservices[s.Id] = *s
+		map_ucfservices[s.Id] = s
 	}
-
-	return
 }
 
 // func (s Atm) AfterCreate(scope *gorm.Scope) (e error) {
@@ -307,19 +603,20 @@ func LoadService() {
 	map_ucfservices = make(map[int64]Atm)
 
 	ss, _ := AllServices()
+	reputations := model.AllReputations()
+	voters := model.AllVoters(ServiceTableName)
 	for _, s := range ss {
-		if s.Confident > confident {
-			services.AddItem(s)
-			map_services[s.Id] = s
-		} else {
-			ucf_services.AddItem(s)
-			map_ucfservices[s.Id] = s
+		//the services which are created before the lifecycle don't have a state
+		if s.State == "" && model.Confirmed(policy(), s.Contributor, s.Confident, reputations[s.Contributor], voters[s.Id]) {
+			s.State = model.StateConfirmed
 		}
 
+		place(s)
 	}
 }
 
 func init() {
+	model.AutoMigrate(&Atm{}, &AtmUcf{}, &Review{}, &Bank{})
 	model.OnConnected.Subscribe(LoadService)
 	model.OnDisconnect.Subscribe(func() {
 		model.OnConnected.Unsubscribe(LoadService)
@@ -1,8 +1,8 @@
 package atm
 
 import (
-	"errors"
 	"log"
+	"streelity/v1/config"
 	"streelity/v1/model"
 
 	"github.com/golang/geo/r2"
@@ -17,8 +17,12 @@ type AtmUcf struct {
 
 const UcfServiceTableName = "atm_ucf"
 
-var confident int = 5
-var map_ucfservices map[int64]Atm
+//policy return the confirmation policy of the atm services
+func policy() config.Policy {
+	return config.PolicyOf(ServiceTableName)
+}
+
+var map_ucfservices map[int64]Atm = make(map[int64]Atm)
 var ucf_services spatial.RTree
 
 //TableName determine the table name in database which is using for gorm
@@ -40,6 +44,19 @@ func AllUcfs() []AtmUcf {
 	return services
 }
 
+//PageUcfs return the page of the unconfirmed atm services which is queried by q
+func PageUcfs(q model.PageQuery) (ucfs []AtmUcf, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.Db, &ucfs, q)
+	return
+}
+
+//PageUcfsByAddress return the page of the unconfirmed atm services whose address contain address which is queried
+//by q
+func PageUcfsByAddress(address string, q model.PageQuery) (ucfs []AtmUcf, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.Db.Where("address LIKE ?", "%"+address+"%"), &ucfs, q)
+	return
+}
+
 func queryAtmUcf(s AtmUcf) (service AtmUcf, e error) {
 	service = s
 
@@ -76,28 +93,14 @@ func UcfsByAddress(address string) (services []AtmUcf, e error) {
 	return
 }
 
-//UpvoteUcf upvote the unconfirmed atm by specific id
-func UpvoteUcf(id int64) error {
-	return upvoteAtmUcf(id, 1)
+//UpvoteUcf record the upvote of voter on the unconfirmed atm by specific id
+func UpvoteUcf(id int64, voter string) error {
+	return voteUcf(id, model.Vote{Voter: voter, Direction: model.VoteUp, Trusted: policy().IsTrusted(voter)})
 }
 
-func UpvoteUcfImmediately(id int64) error {
-	return upvoteAtmUcf(id, confident)
-}
-
-func upvoteAtmUcf(id int64, value int) (e error) {
-	s, e := UcfById(id)
-
-	if e != nil {
-		return e
-	}
-
-	s.Confident += value
-	if e := model.Db.Save(&s).Error; e != nil {
-		log.Println("[Database]", "upvote unconfirmed atm", id, ":", e.Error())
-	}
-
-	return
+func voteUcf(id int64, v model.Vote) error {
+	var s AtmUcf
+	return model.CastVote(id, v, &s)
 }
 
 //CreateUcf add new AtmUcf service to the database
@@ -106,11 +109,11 @@ func upvoteAtmUcf(id int64, value int) (e error) {
 func CreateUcf(s AtmUcf) (ucf AtmUcf, e error) {
 	var existed AtmUcf
 	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&AtmUcf{}).Error; e == nil {
-		return ucf, errors.New("The service location is existed or some problems is occured")
+		return ucf, model.NewError(model.ErrConflict, "The service location is existed or some problems is occured")
 	}
 
 	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&existed).Error; e == nil {
-		return ucf, errors.New("The service location is existed or some problems is occured")
+		return ucf, model.NewError(model.ErrConflict, "The service location is existed or some problems is occured")
 	}
 
 	if e = model.Db.Create(&s).Error; e != nil {
@@ -122,42 +125,150 @@ func CreateUcf(s AtmUcf) (ucf AtmUcf, e error) {
 	return
 }
 
-//UcfInRange query the unconfirmed atm services that are in the radius of a location
+//UcfInRange query the unconfirmed atm services that are in the radius of a location, they are the pending services
+//and the submissions which are waiting for their confirmation. Both are matched by the bounding box of the radius
 func UcfInRange(p r2.Point, max_range float64) []Atm {
+	pending := model.QueriedStates([]string{model.StatePending})
 	var result []Atm = []Atm{}
-	trees := ucf_services.InRange(p, max_range)
-
-	for _, tree := range trees {
-		for _, item := range tree.Items {
-			location := item.Location()
-			d := distance(location, p)
-			s, isService := item.(Atm)
-			if isService && d < max_range {
-				result = append(result, map_ucfservices[s.Id])
-			}
-		}
+	if e := model.InRange(model.Db.Where("state IN (?)", pending), ServiceTableName, p, max_range).Order("id").Find(&result).Error; e != nil {
+		log.Println("[Database]", "pending atm in range", e.Error())
+	}
+
+	var ucfs []AtmUcf
+	if e := model.InRange(model.Db.Where("state IN (?)", pending), UcfServiceTableName, p, max_range).Order("id").Find(&ucfs).Error; e != nil {
+		log.Println("[Database]", "unconfirmed atm in range", e.Error())
+	}
+
+	for _, ucf := range ucfs {
+		result = append(result, Atm{Service: ucf.Listed(), BankId: ucf.BankId})
 	}
+
 	return result
 }
 
-func DeleteUcf(id int64) (e error) {
-	var ucf AtmUcf
-	ucf.Id = id
-	if e := model.Db.Delete(&ucf).Error; e != nil {
-		log.Println("[Database]", "delete ucf fuel", e.Error())
+//DeleteUcf reject the unconfirmed atm by specific id, the row is removed and the rejection is logged with actor
+func DeleteUcf(id int64, actor string) (e error) {
+	ucf, e := UcfById(id)
+	if e != nil {
+		return
+	}
+
+	if e = ucf.Transit(model.StateRejected, actor, "unconfirmed service is deleted"); e != nil {
+		return
+	}
+
+	if e = model.Db.Delete(&ucf).Error; e != nil {
+		log.Println("[Database]", "delete ucf atm", e.Error())
+		return
+	}
+
+	//the unconfirmed service is rejected, its contributor lose the reputation and is notified
+	model.Settle(model.Db, policy(), ucf.Contributor, ucf.Transited(), UcfServiceTableName, id)
+	model.QueueSubmission(model.Db, UcfServiceTableName, id, ucf.Lifecycle, ucf.Transited(), ucf.Contributor, ucf.Location())
+	ucf.LogTransition(model.Db, UcfServiceTableName, id)
+	return
+}
+
+//transitUcf change the state of the unconfirmed atm by specific id, the transition is logged with actor and reason
+func transitUcf(id int64, to, actor, reason string) (e error) {
+	ucf, e := UcfById(id)
+	if e != nil {
+		return
+	}
+
+	if e = ucf.Transit(to, actor, reason); e != nil {
+		return
+	}
+
+	if e = model.Db.Save(&ucf).Error; e != nil {
+		log.Println("[Database]", "transit ucf atm", id, ":", e.Error())
+	}
+
+	return
+}
+
+//TransitUcf change the state of the unconfirmed atm by specific id by actor, the confirmed one is moved to the
+//services like the confirmation policy does
+func TransitUcf(id int64, to, actor, reason string) error {
+	if to == model.StateConfirmed {
+		return confirmUcf(id, actor, reason)
+	}
+
+	return transitUcf(id, to, actor, reason)
+}
+
+func confirmUcf(id int64, actor, reason string) (e error) {
+	ucf, e := UcfById(id)
+	if e != nil {
+		return
+	}
+
+	if e = ucf.Transit(model.StateConfirmed, actor, reason); e != nil {
+		return
+	}
+
+	var s Atm = Atm{Service: ucf.GetService(), BankId: ucf.BankId}
+	s.Transit(model.StateConfirmed, actor, reason)
+	//the service is created and the unconfirmed one is removed together, a failure leave the unconfirmed one as is
+	e = model.Db.Transaction(func(tx *gorm.DB) error {
+		service, e := createService(tx, s)
+		if e != nil {
+			return e
+		}
+
+		if e := model.MoveVotes(tx, UcfServiceTableName, id, ServiceTableName, service.Id); e != nil {
+			return e
+		}
+
+		if e := tx.Delete(&ucf).Error; e != nil {
+			log.Println("[Database]", "confirm ucf atm", e.Error())
+			return e
+		}
+
+		model.QueueSubmission(tx, UcfServiceTableName, id, ucf.Lifecycle, ucf.Transited(), ucf.Contributor, ucf.Location())
+		return ucf.LogTransition(tx, UcfServiceTableName, id)
+	})
+
+	return
+}
+
+//BeforeSave reject the unconfirmed service which is matching the rejection rules of the policy, see model.Rejection
+func (s *AtmUcf) BeforeSave(scope *gorm.Scope) (e error) {
+	db := scope.NewDB()
+	voters := model.CountVoters(db, UcfServiceTableName, s.Id)
+	if !model.Confirmed(policy(), s.Contributor, s.Confident, model.ReputationOf(db, s.Contributor), voters) {
+		s.Reject(model.Rejection(policy(), s.Confident, model.IsSpammer(db, s.Contributor)))
 	}
 
 	return
 }
 
 func (s *AtmUcf) AfterSave(scope *gorm.Scope) (err error) {
-	if s.Confident >= confident {
+	db := scope.NewDB()
+	if !model.IsUnconfirmed(s.GetState()) {
+		//the rejected and archived services are taken out of the index
+		ucf_services.RemoveItem(s)
+		model.Settle(db, policy(), s.Contributor, s.Transited(), UcfServiceTableName, s.Id)
+		model.QueueSubmission(db, UcfServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
+		s.LogTransition(db, UcfServiceTableName, s.Id)
+		return
+	}
+
+	voters := model.CountVoters(db, UcfServiceTableName, s.Id)
+	if model.Confirmed(policy(), s.Contributor, s.Confident, model.ReputationOf(db, s.Contributor), voters) {
 		var a Atm = Atm{Service: s.GetService(), BankId: s.BankId}
-		CreateService(a)
+		if service, e := createService(db, a); e == nil {
+			//the voters of the unconfirmed service keep their votes on the service
+			model.MoveVotes(db, UcfServiceTableName, s.Id, ServiceTableName, service.Id)
+		}
+		s.Transit(model.StateConfirmed, model.ActorSystem, "unconfirmed service is moved to the services")
+		model.QueueSubmission(db, UcfServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
+		s.LogTransition(db, UcfServiceTableName, s.Id)
 		scope.DB().Delete(s)
 		log.Println("[Unconfirmed Atm]", "Confident is enough. Added", a)
 	} else {
 		ucf_services.AddItem(s)
+		model.QueueSubmission(db, UcfServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
 	}
 
 	return
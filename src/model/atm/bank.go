@@ -1,7 +1,6 @@
 package atm
 
 import (
-	"errors"
 	"log"
 	"streelity/v1/model"
 )
@@ -24,10 +23,16 @@ func AllBanks() []Bank {
 	return banks
 }
 
+//PageBanks return the page of the banks which is queried by q
+func PageBanks(q model.PageQuery) (banks []Bank, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.Db, &banks, q)
+	return
+}
+
 func CreateBank(s Bank) (e error) {
 	db := model.Db.Where("name=?", s.Name).Find(&s)
 	if e = db.Error; e == nil || db.RowsAffected > 0 {
-		e = errors.New("Bank was existed")
+		e = model.NewError(model.ErrConflict, "Bank was existed")
 		log.Println("[Database]", "Create new bank", e.Error())
 		return
 	}
@@ -52,7 +57,7 @@ func BankByName(name string) (bank Bank, e error) {
 	}
 
 	if db.RowsAffected == 0 {
-		e = errors.New("Bank was not found")
+		e = model.NewError(model.ErrNotFound, "Bank was not found")
 		log.Println("[Database]", e.Error())
 	}
 
@@ -0,0 +1,307 @@
+package atm
+
+import (
+	"net/url"
+	"streelity/v1/model"
+
+	"github.com/golang/geo/r2"
+)
+
+//ServiceRepository determine the data access of the atm services
+type ServiceRepository interface {
+	AllServices() ([]Atm, error)
+	PageServices(q model.PageQuery, states ...string) ([]Atm, model.Page, error)
+	ServiceById(id int64) (Atm, error)
+	ServiceByLocation(lat, lon float64) (Atm, error)
+	ServiceByAddress(address string) (Atm, error)
+	ServicesByAddress(address string) ([]Atm, error)
+	PageServicesByAddress(address string, q model.PageQuery) ([]Atm, model.Page, error)
+	ServicesByIds(ids ...int64) []Atm
+	ServicesInRange(p r2.Point, max_range float64) []Atm
+	PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) ([]Atm, model.Page, error)
+	CreateService(s Atm) (Atm, error)
+	UpdateService(id int64, values url.Values) (Atm, error)
+	UpvoteService(id int64, voter string) error
+	DownvoteService(id int64, voter string) error
+	WithdrawVote(id int64, voter string) error
+	VotesByService(id int64) ([]model.Vote, error)
+	TransitService(id int64, to, actor, reason string) (Atm, error)
+	TransitionsByService(id int64) ([]model.Transition, error)
+	ServicesByState(states ...string) ([]Atm, error)
+	ArchiveStale() (int, error)
+	VerifyService(id int64) (Atm, error)
+	DecayServices() (int, error)
+	ReverifyQueue(p r2.Point, max_range float64) []Atm
+	ReportService(id int64, r model.Report) (model.Report, error)
+	ReportsByService(id int64) ([]model.Report, error)
+	ReportsByServices(ids ...int64) ([]model.Report, error)
+	AcceptReport(id int64, kind string, actor string) (model.Report, error)
+	DismissReports(id int64, kind string, actor string) error
+	Import(bytes []byte, t string) error
+}
+
+//UcfRepository determine the data access of the unconfirmed atm services
+type UcfRepository interface {
+	AllUcfs() []AtmUcf
+	PageUcfs(q model.PageQuery) ([]AtmUcf, model.Page, error)
+	UcfById(id int64) (AtmUcf, error)
+	UcfByLocation(lat, lon float64) (AtmUcf, error)
+	UcfByAddress(address string) (AtmUcf, error)
+	UcfsByAddress(address string) ([]AtmUcf, error)
+	PageUcfsByAddress(address string, q model.PageQuery) ([]AtmUcf, model.Page, error)
+	UcfInRange(p r2.Point, max_range float64) []Atm
+	CreateUcf(s AtmUcf) (AtmUcf, error)
+	DeleteUcf(id int64, actor string) error
+	TransitUcf(id int64, to, actor, reason string) error
+	UpvoteUcf(id int64, voter string) error
+}
+
+//ReviewRepository determine the data access of the atm reviews
+type ReviewRepository interface {
+	CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error)
+	DeleteReview(review_id int64) error
+	ReviewByService(service_id, order int64, limit int64) ([]Review, error)
+	PageReviews(service_id, order int64, q model.PageQuery) ([]Review, model.Page, error)
+	ReviewsByServices(ids ...int64) ([]Review, error)
+	ReviewById(review_id int64) (Review, error)
+	ReviewAverageScore(service_id int64) float64
+	ReviewAverageScores(ids ...int64) map[int64]float64
+	SaveReview(r Review) error
+}
+
+//BankRepository determine the data access of the banks
+type BankRepository interface {
+	AllBanks() []Bank
+	PageBanks(q model.PageQuery) ([]Bank, model.Page, error)
+	CreateBank(s Bank) error
+	BankByName(name string) (Bank, error)
+}
+
+//Repository is the whole data access of the atm package which is injected into the router
+type Repository interface {
+	ServiceRepository
+	UcfRepository
+	ReviewRepository
+	model.Moderated
+	BankRepository
+}
+
+//Database is the Repository which is stored in model.Db
+type Database struct{}
+
+func (Database) AllServices() ([]Atm, error) {
+	return AllServices()
+}
+
+func (Database) PageServices(q model.PageQuery, states ...string) ([]Atm, model.Page, error) {
+	return PageServices(q, states...)
+}
+
+func (Database) ServiceById(id int64) (Atm, error) {
+	return ServiceById(id)
+}
+
+func (Database) ServiceByLocation(lat, lon float64) (Atm, error) {
+	return ServiceByLocation(lat, lon)
+}
+
+func (Database) ServiceByAddress(address string) (Atm, error) {
+	return ServiceByAddress(address)
+}
+
+func (Database) ServicesByAddress(address string) ([]Atm, error) {
+	return ServicesByAddress(address)
+}
+
+func (Database) PageServicesByAddress(address string, q model.PageQuery) ([]Atm, model.Page, error) {
+	return PageServicesByAddress(address, q)
+}
+
+func (Database) ServicesByIds(ids ...int64) []Atm {
+	return ServicesByIds(ids...)
+}
+
+func (Database) ServicesInRange(p r2.Point, max_range float64) []Atm {
+	return ServicesInRange(p, max_range)
+}
+
+func (Database) PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) ([]Atm, model.Page, error) {
+	return PageServicesInRange(p, max_range, q, states...)
+}
+
+func (Database) CreateService(s Atm) (Atm, error) {
+	return CreateService(s)
+}
+
+func (Database) UpdateService(id int64, values url.Values) (Atm, error) {
+	return UpdateService(id, values)
+}
+
+func (Database) UpvoteService(id int64, voter string) error {
+	return UpvoteService(id, voter)
+}
+
+func (Database) DownvoteService(id int64, voter string) error {
+	return DownvoteService(id, voter)
+}
+
+func (Database) WithdrawVote(id int64, voter string) error {
+	return WithdrawVote(id, voter)
+}
+
+func (Database) VotesByService(id int64) ([]model.Vote, error) {
+	return VotesByService(id)
+}
+
+func (Database) TransitService(id int64, to, actor, reason string) (Atm, error) {
+	return TransitService(id, to, actor, reason)
+}
+
+func (Database) TransitionsByService(id int64) ([]model.Transition, error) {
+	return TransitionsByService(id)
+}
+
+func (Database) ServicesByState(states ...string) ([]Atm, error) {
+	return ServicesByState(states...)
+}
+
+func (Database) ArchiveStale() (int, error) {
+	return ArchiveStale()
+}
+
+func (Database) VerifyService(id int64) (Atm, error) {
+	return VerifyService(id)
+}
+
+func (Database) DecayServices() (int, error) {
+	return DecayServices()
+}
+
+func (Database) ReverifyQueue(p r2.Point, max_range float64) []Atm {
+	return ReverifyQueue(p, max_range)
+}
+
+func (Database) ReportService(id int64, r model.Report) (model.Report, error) {
+	return ReportService(id, r)
+}
+
+func (Database) ReportsByService(id int64) ([]model.Report, error) {
+	return ReportsByService(id)
+}
+
+func (Database) ReportsByServices(ids ...int64) ([]model.Report, error) {
+	return ReportsByServices(ids...)
+}
+
+func (Database) AcceptReport(id int64, kind string, actor string) (model.Report, error) {
+	return AcceptReport(id, kind, actor)
+}
+
+func (Database) DismissReports(id int64, kind string, actor string) error {
+	return DismissReports(id, kind, actor)
+}
+
+func (Database) Import(bytes []byte, t string) error {
+	return Import(bytes, t)
+}
+
+func (Database) AllUcfs() []AtmUcf {
+	return AllUcfs()
+}
+
+func (Database) PageUcfs(q model.PageQuery) ([]AtmUcf, model.Page, error) {
+	return PageUcfs(q)
+}
+
+func (Database) UcfById(id int64) (AtmUcf, error) {
+	return UcfById(id)
+}
+
+func (Database) UcfByLocation(lat, lon float64) (AtmUcf, error) {
+	return UcfByLocation(lat, lon)
+}
+
+func (Database) UcfByAddress(address string) (AtmUcf, error) {
+	return UcfByAddress(address)
+}
+
+func (Database) UcfsByAddress(address string) ([]AtmUcf, error) {
+	return UcfsByAddress(address)
+}
+
+func (Database) PageUcfsByAddress(address string, q model.PageQuery) ([]AtmUcf, model.Page, error) {
+	return PageUcfsByAddress(address, q)
+}
+
+func (Database) UcfInRange(p r2.Point, max_range float64) []Atm {
+	return UcfInRange(p, max_range)
+}
+
+func (Database) CreateUcf(s AtmUcf) (AtmUcf, error) {
+	return CreateUcf(s)
+}
+
+func (Database) DeleteUcf(id int64, actor string) error {
+	return DeleteUcf(id, actor)
+}
+
+func (Database) TransitUcf(id int64, to, actor, reason string) error {
+	return TransitUcf(id, to, actor, reason)
+}
+
+func (Database) UpvoteUcf(id int64, voter string) error {
+	return UpvoteUcf(id, voter)
+}
+
+func (Database) CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error) {
+	return CreateReview(service_id, reviewer, score, body)
+}
+
+func (Database) DeleteReview(review_id int64) error {
+	return DeleteReview(review_id)
+}
+
+func (Database) ReviewByService(service_id, order int64, limit int64) ([]Review, error) {
+	return ReviewByService(service_id, order, limit)
+}
+
+func (Database) PageReviews(service_id, order int64, q model.PageQuery) ([]Review, model.Page, error) {
+	return PageReviews(service_id, order, q)
+}
+
+func (Database) ReviewsByServices(ids ...int64) ([]Review, error) {
+	return ReviewsByServices(ids...)
+}
+
+func (Database) ReviewById(review_id int64) (Review, error) {
+	return ReviewById(review_id)
+}
+
+func (Database) ReviewAverageScore(service_id int64) float64 {
+	return ReviewAverageScore(service_id)
+}
+
+func (Database) ReviewAverageScores(ids ...int64) map[int64]float64 {
+	return ReviewAverageScores(ids...)
+}
+
+func (Database) SaveReview(r Review) error {
+	r.db = model.Db
+	return r.Save()
+}
+
+func (Database) AllBanks() []Bank {
+	return AllBanks()
+}
+
+func (Database) PageBanks(q model.PageQuery) ([]Bank, model.Page, error) {
+	return PageBanks(q)
+}
+
+func (Database) CreateBank(s Bank) error {
+	return CreateBank(s)
+}
+
+func (Database) BankByName(name string) (Bank, error) {
+	return BankByName(name)
+}
@@ -1,11 +1,16 @@
 package atm_test
 
 import (
+	"strconv"
 	"streelity/v1/model"
 	"streelity/v1/model/atm"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v5"
+	"github.com/golang/geo/r2"
 )
 
 func TestCreateService(t *testing.T) {
@@ -30,3 +35,514 @@ func TestCreateService(t *testing.T) {
 
 	t.Log("Completed")
 }
+
+func TestVoteService(t *testing.T) {
+	model.ConnectSync()
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.5, Lon: 105.5, Address: "vote"}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	var wg sync.WaitGroup
+	for loop := 0; loop < 10; loop++ {
+		wg.Add(1)
+		go func(voter int) {
+			defer wg.Done()
+			for repeat := 0; repeat < 3; repeat++ {
+				if e := atm.UpvoteService(s.Id, strconv.Itoa(voter)); e != nil {
+					t.Error(e)
+				}
+			}
+		}(loop)
+	}
+	wg.Wait()
+
+	if s, _ = atm.ServiceById(s.Id); s.Confident != 10 {
+		t.Errorf("wrong confident after concurrent upvotes: got %v want 10", s.Confident)
+	}
+
+	atm.DownvoteService(s.Id, "0")
+	if e := atm.WithdrawVote(s.Id, "1"); e != nil {
+		t.Error(e)
+	}
+
+	if e := atm.WithdrawVote(s.Id, "1"); e == nil {
+		t.Error("withdraw a missing vote is accepted")
+	}
+
+	if s, _ = atm.ServiceById(s.Id); s.Confident != 7 {
+		t.Errorf("wrong confident after downvote and withdraw: got %v want 7", s.Confident)
+	}
+
+	if votes, _ := atm.VotesByService(s.Id); len(votes) != 9 {
+		t.Errorf("wrong voters: got %v want 9", len(votes))
+	}
+
+	if e := atm.UpvoteService(-1, "0"); e == nil {
+		t.Error("upvote a missing service is accepted")
+	}
+}
+
+func TestReputation(t *testing.T) {
+	model.ConnectSync()
+	contributor := "reputation-contributor"
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.75, Lon: 105.75, Address: "reputation", Contributor: contributor}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	for voter := 0; voter < 6; voter++ {
+		atm.UpvoteService(s.Id, "reputation-voter-"+strconv.Itoa(voter))
+	}
+
+	if r := model.ReputationOf(model.Db, contributor); r != 10 {
+		t.Errorf("wrong reputation after the service is confirmed: got %v want 10", r)
+	}
+
+	ucf, e := atm.CreateUcf(atm.AtmUcf{ServiceUcf: model.ServiceUcf{Lat: 9.8, Lon: 105.8, Address: "reputation", Contributor: contributor}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if e := atm.DeleteUcf(ucf.Id, "reputation-admin"); e != nil {
+		t.Fatal(e)
+	}
+
+	if r := model.ReputationOf(model.Db, contributor); r != 0 {
+		t.Errorf("wrong reputation after the submission is rejected: got %v want 0", r)
+	}
+
+	//a trusted voter votes with more weight
+	for id := int64(0); id < 5; id++ {
+		model.ChangeReputation(model.Db, "reputation-trusted", model.ReasonConfirmed, "reputation", id)
+	}
+
+	s, _ = atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.85, Lon: 105.85, Address: "reputation"}})
+	atm.UpvoteService(s.Id, "reputation-trusted")
+	if s, _ = atm.ServiceById(s.Id); s.Confident != 2 {
+		t.Errorf("wrong confident after the trusted vote: got %v want 2", s.Confident)
+	}
+}
+
+//inRange determine whether s is found in the index, the range is measured to the center of the trees
+//so a wide range is used
+func inRange(s atm.Atm) bool {
+	for _, found := range atm.ServicesInRange(s.Location(), 1) {
+		if found.Id == s.Id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestLifecycle(t *testing.T) {
+	model.ConnectSync()
+
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.6, Lon: 105.6, Address: "lifecycle", Contributor: "Streetlity"}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if s.State != model.StateConfirmed {
+		t.Fatalf("service of trusted source is not confirmed: got %v", s.State)
+	}
+
+	if !inRange(s) {
+		t.Errorf("confirmed service is not in the index")
+	}
+
+	if s, e = atm.TransitService(s.Id, model.StateClosed, "admin", "moved away"); e != nil {
+		t.Fatal(e)
+	}
+
+	if inRange(s) {
+		t.Errorf("closed service is still in the index")
+	}
+
+	if _, e = atm.TransitService(s.Id, model.StatePending, "admin", ""); e == nil {
+		t.Errorf("closed service is moved to pending")
+	}
+
+	if closed, _ := atm.ServicesByState(model.StateClosed); len(closed) != 1 {
+		t.Errorf("wrong closed services: got %v want 1", len(closed))
+	}
+
+	transitions, _ := atm.TransitionsByService(s.Id)
+	if len(transitions) != 2 {
+		t.Fatalf("wrong transitions: got %v want 2", len(transitions))
+	}
+
+	if last := transitions[1]; last.To != model.StateClosed || last.Actor != "admin" || last.Reason != "moved away" {
+		t.Errorf("wrong transition: got %v", last)
+	}
+}
+
+func TestRejection(t *testing.T) {
+	model.ConnectSync()
+
+	contributor := "rejection-contributor"
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.4, Lon: 105.4, Address: "rejection", Contributor: contributor}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	for voter := 0; voter < 5; voter++ {
+		atm.DownvoteService(s.Id, "rejection-voter-"+strconv.Itoa(voter))
+	}
+
+	if s, _ = atm.ServiceById(s.Id); s.State != model.StateRejected {
+		t.Fatalf("downvoted service is not rejected: got %v", s.State)
+	}
+
+	if inRange(s) {
+		t.Errorf("rejected service is still in the index")
+	}
+
+	if notifications, _ := model.NotificationsOf(contributor); len(notifications) != 1 {
+		t.Errorf("wrong notifications of the contributor: got %v want 1", len(notifications))
+	}
+
+	model.FlagSpammer(model.Db, contributor, true)
+	if s, _ = atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.45, Lon: 105.45, Address: "rejection", Contributor: contributor}}); s.State != model.StateRejected {
+		t.Errorf("service of spammer is not rejected: got %v", s.State)
+	}
+}
+
+func TestArchiveStale(t *testing.T) {
+	model.ConnectSync()
+
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.3, Lon: 105.3, Address: "stale"}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	voted, _ := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.35, Lon: 105.35, Address: "stale"}})
+	atm.UpvoteService(voted.Id, "stale-voter")
+
+	old := time.Now().AddDate(0, 0, -100)
+	model.Db.Table(atm.ServiceTableName).Where("id IN (?)", []int64{s.Id, voted.Id}).UpdateColumn("created_at", old)
+
+	if _, e := atm.ArchiveStale(); e != nil {
+		t.Fatal(e)
+	}
+
+	if s, _ = atm.ServiceById(s.Id); s.State != model.StateArchived {
+		t.Errorf("stale service is not archived: got %v", s.State)
+	}
+
+	if voted, _ = atm.ServiceById(voted.Id); voted.State == model.StateArchived {
+		t.Errorf("voted service is archived")
+	}
+}
+
+func TestDecay(t *testing.T) {
+	model.ConnectSync()
+
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.2, Lon: 105.2, Address: "decay"}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	for voter := 0; voter < 5; voter++ {
+		atm.UpvoteService(s.Id, "decay-voter-"+strconv.Itoa(voter))
+	}
+
+	if s, _ = atm.ServiceById(s.Id); s.State != model.StateConfirmed || s.VerifiedAt == nil {
+		t.Fatalf("upvoted service is not confirmed and verified: got %v", s.State)
+	}
+
+	old := time.Now().AddDate(0, 0, -100)
+	model.Db.Table(atm.ServiceTableName).Where("id = ?", s.Id).UpdateColumns(map[string]interface{}{"created_at": old, "verified_at": old})
+	if _, e := atm.DecayServices(); e != nil {
+		t.Fatal(e)
+	}
+
+	if s, _ = atm.ServiceById(s.Id); s.State != model.StateDisputed {
+		t.Fatalf("decayed service is not disputed: got %v", s.State)
+	}
+
+	inQueue := func() bool {
+		for _, queued := range atm.ReverifyQueue(s.Location(), 1) {
+			if queued.Id == s.Id {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if !inQueue() {
+		t.Errorf("disputed service is not in the re-verification queue")
+	}
+
+	if s, e = atm.VerifyService(s.Id); e != nil || s.State != model.StateConfirmed {
+		t.Fatalf("verified service is not confirmed: got %v %v", s.State, e)
+	}
+
+	if inQueue() {
+		t.Errorf("verified service is still in the re-verification queue")
+	}
+}
+
+func TestReportService(t *testing.T) {
+	model.ConnectSync()
+
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.1, Lon: 105.1, Address: "report", Contributor: "Streetlity"}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	invalid := []model.Report{
+		{Reporter: "report-0", Kind: "gone"},
+		{Reporter: "report-0", Kind: model.ReportWrongLocation},
+		{Reporter: "report-0", Kind: model.ReportDuplicate, DuplicateOf: s.Id},
+		{Reporter: "report-0", Kind: model.ReportDuplicate, DuplicateOf: 100000},
+	}
+
+	for _, r := range invalid {
+		if _, e := atm.ReportService(s.Id, r); e == nil {
+			t.Errorf("invalid report is accepted: %v", r)
+		}
+	}
+
+	//the same reporter doesn't concur with the self
+	for loop := 0; loop < 3; loop++ {
+		atm.ReportService(s.Id, model.Report{Reporter: "report-0", Kind: model.ReportClosed})
+	}
+
+	if s, _ = atm.ServiceById(s.Id); s.State != model.StateConfirmed {
+		t.Fatalf("service is changed by a reporter: got %v", s.State)
+	}
+
+	for reporter := 1; reporter < 3; reporter++ {
+		if _, e := atm.ReportService(s.Id, model.Report{Reporter: "report-" + strconv.Itoa(reporter), Kind: model.ReportClosed}); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	if s, _ = atm.ServiceById(s.Id); s.State != model.StateClosed {
+		t.Errorf("reported service is not closed: got %v", s.State)
+	}
+
+	reports, _ := atm.ReportsByService(s.Id)
+	if len(reports) != 3 {
+		t.Fatalf("wrong reports: got %v want 3", len(reports))
+	}
+
+	for _, r := range reports {
+		if r.Status != model.ReportAccepted {
+			t.Errorf("concurring report is not accepted: got %v", r.Status)
+		}
+	}
+}
+
+func TestModeration(t *testing.T) {
+	model.ConnectSync()
+
+	queued := func(kind string, service_type string, id int64) (item model.ModerationItem, ok bool) {
+		items, _ := model.ModerationDatabase{}.Queue(model.ModerationFilter{Kinds: []string{kind}, ServiceTypes: []string{service_type}})
+		for _, item := range items {
+			if item.ServiceId == id {
+				return item, true
+			}
+		}
+
+		return
+	}
+
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.05, Lon: 105.05, Address: "moderation", Contributor: "moderation-contributor"}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	item, ok := queued(model.ModerationSubmission, atm.ServiceTableName, s.Id)
+	if !ok {
+		t.Fatalf("pending service is not queued")
+	}
+
+	if e = (atm.Database{}).Moderate(item, true, "moderator", "looks right"); e != nil {
+		t.Fatal(e)
+	}
+
+	if s, _ = atm.ServiceById(s.Id); s.State != model.StateConfirmed {
+		t.Errorf("approved service is not confirmed: got %v", s.State)
+	}
+
+	//the submission is resolved by the transition of the moderator
+	if item, _ = (model.ModerationDatabase{}).Item(item.Id); item.Status != model.ModerationApproved {
+		t.Errorf("moderated submission is not resolved: got %v", item.Status)
+	}
+
+	ucf, e := atm.CreateUcf(atm.AtmUcf{ServiceUcf: model.ServiceUcf{Lat: 9.06, Lon: 105.06, Address: "moderation", Contributor: "moderation-contributor"}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if item, ok = queued(model.ModerationSubmission, atm.UcfServiceTableName, ucf.Id); !ok {
+		t.Fatalf("unconfirmed service is not queued")
+	}
+
+	if e = (atm.Database{}).Moderate(item, true, "moderator", ""); e != nil {
+		t.Fatal(e)
+	}
+
+	if _, e = atm.UcfById(ucf.Id); e == nil {
+		t.Errorf("approved unconfirmed service is not moved")
+	}
+
+	if moved, e := atm.ServiceByLocation(9.06, 105.06); e != nil || moved.State != model.StateConfirmed {
+		t.Errorf("approved unconfirmed service is not confirmed: got %v %v", moved.State, e)
+	}
+
+	atm.ReportService(s.Id, model.Report{Reporter: "moderation-reporter", Kind: model.ReportTemporarilyClosed})
+	if item, ok = queued(model.ModerationReport, atm.ServiceTableName, s.Id); !ok || item.Subject != model.ReportTemporarilyClosed {
+		t.Fatalf("report is not queued: got %v", item)
+	}
+
+	if e = (atm.Database{}).Moderate(item, true, "moderator", ""); e != nil {
+		t.Fatal(e)
+	}
+
+	if s, _ = atm.ServiceById(s.Id); s.State != model.StateDisputed {
+		t.Errorf("approved report doesn't change the service: got %v", s.State)
+	}
+
+	if reports, _ := atm.ReportsByService(s.Id); len(reports) != 1 || reports[0].Status != model.ReportAccepted || reports[0].ResolvedBy != "moderator" {
+		t.Errorf("approved report is not accepted: got %v", reports)
+	}
+}
+
+func TestReviewByService(t *testing.T) {
+	model.ConnectSync()
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.7, Lon: 105.7, Address: "reviewed"}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	for loop := 0; loop < 3; loop++ {
+		if _, e := atm.CreateReview(s.Id, strconv.Itoa(loop), float32(loop), "body"); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		order    int64
+		limit    int64
+		expected []string
+	}{
+		{"every review", 0, -1, []string{"0", "1", "2"}},
+		{"after order", 1, -1, []string{"1", "2"}},
+		{"limited after order", 1, 1, []string{"1"}},
+		{"order after the reviews", 5, -1, nil},
+	}
+
+	for _, test := range tests {
+		reviews, e := atm.ReviewByService(s.Id, test.order, test.limit)
+		if e != nil {
+			t.Errorf("%v: got error %v", test.name, e)
+			continue
+		}
+
+		var reviewers []string
+		for _, r := range reviews {
+			reviewers = append(reviewers, r.Reviewer)
+		}
+
+		if strings.Join(reviewers, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("%v: got reviewers %v want %v", test.name, reviewers, test.expected)
+		}
+	}
+}
+
+func TestPageServicesInRange(t *testing.T) {
+	model.ConnectSync()
+	locations := [][2]float32{{5, 100}, {5.1, 100.1}, {4.9, 99.9}, {5.4, 100.4}, {7, 102}}
+	for i, l := range locations {
+		if _, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: l[0], Lon: l[1], Address: "paged " + strconv.Itoa(i)}}); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	q := model.PageQuery{Limit: 2}
+	var addresses []string
+	for pages := 0; pages < 3; pages++ {
+		services, page, e := atm.PageServicesInRange(r2.Point{X: 5, Y: 100}, 0.5, q, model.StatePending)
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		if page.Total != 3 {
+			t.Errorf("wrong total: got %v want 3", page.Total)
+		}
+
+		for _, s := range services {
+			addresses = append(addresses, s.Address)
+		}
+
+		if q.Cursor = page.Next; q.Cursor == "" {
+			break
+		}
+	}
+
+	if strings.Join(addresses, ",") != "paged 0,paged 1,paged 2" {
+		t.Errorf("wrong services in range: got %v", addresses)
+	}
+
+	if services, _, _ := atm.PageServicesInRange(r2.Point{X: 5, Y: 100}, 0.5, model.PageQuery{}); len(services) != 0 {
+		t.Errorf("pending services are listed by default: got %v", len(services))
+	}
+}
+
+func TestPageReviews(t *testing.T) {
+	model.ConnectSync()
+	s, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 9.8, Lon: 105.8, Address: "paged reviews"}})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	for loop := 0; loop < 4; loop++ {
+		if _, e := atm.CreateReview(s.Id, strconv.Itoa(loop), float32(loop), "body"); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	reviews, page, e := atm.PageReviews(s.Id, 1, model.PageQuery{Limit: 2, Sort: "-rating"})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if len(reviews) != 2 || reviews[0].Reviewer != "3" || reviews[1].Reviewer != "2" || page.Total != 3 || page.Next == "" {
+		t.Fatalf("wrong first page: got %v, %+v", reviews, page)
+	}
+
+	reviews, page, _ = atm.PageReviews(s.Id, 1, model.PageQuery{Limit: 2, Sort: "-rating", Cursor: page.Next})
+	if len(reviews) != 1 || reviews[0].Reviewer != "1" || page.Next != "" {
+		t.Errorf("wrong last page: got %v, %+v", reviews, page)
+	}
+}
+
+func TestUcfInRange(t *testing.T) {
+	model.ConnectSync()
+	if _, e := atm.CreateService(atm.Atm{Service: model.Service{Lat: 3, Lon: 98, Address: "pending in range"}, BankId: 2}); e != nil {
+		t.Fatal(e)
+	}
+
+	if _, e := atm.CreateUcf(atm.AtmUcf{ServiceUcf: model.ServiceUcf{Lat: 3.1, Lon: 98.1, Address: "submission in range"}, BankId: 3}); e != nil {
+		t.Fatal(e)
+	}
+
+	if _, e := atm.CreateUcf(atm.AtmUcf{ServiceUcf: model.ServiceUcf{Lat: 3.4, Lon: 98.4, Address: "submission in the box"}}); e != nil {
+		t.Fatal(e)
+	}
+
+	banks := map[string]int64{}
+	for _, s := range atm.UcfInRange(r2.Point{X: 3, Y: 98}, 0.5) {
+		banks[s.Address] = s.BankId
+	}
+
+	if len(banks) != 2 || banks["pending in range"] != 2 || banks["submission in range"] != 3 {
+		t.Errorf("wrong unconfirmed services in range: got %v", banks)
+	}
+}
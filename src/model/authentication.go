@@ -1,55 +1,238 @@
 package model
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
-	"time"
+	"strconv"
 
 	"github.com/dgrijalva/jwt-go"
 )
 
 type User struct {
 	Id int64
+	//Subject is the `sub` claim of the token, it is the id of the user for the tokens which are created here
+	Subject string
+	//Role is the highest role of the user, Roles are the names of the roles which the token is granting
+	Role  int
+	Roles []string
+	//Permissions are granted to the user by the token in addition to the permissions of the role
+	Permissions []string
 }
 
-const RoleAdmin = 10
+//The roles of the users, a role has the permissions of the lower roles
+const (
+	RoleUser      = 0
+	RoleModerator = 5
+	RoleAdmin     = 10
+)
+
+//roleNames are the names of the roles in the `roles` claim
+var roleNames map[string]int = map[string]int{
+	"user":      RoleUser,
+	"moderator": RoleModerator,
+	"admin":     RoleAdmin,
+}
+
+//RoleName return the name of the role, the highest named role which is not higher than role is used
+func RoleName(role int) (name string) {
+	highest := -1
+	for n, r := range roleNames {
+		if r <= role && r > highest {
+			name, highest = n, r
+		}
+	}
+
+	return
+}
+
+//The permissions which are required by the routes
+const (
+	//PermissionContribute allow to submit services, reviews, votes and reports
+	PermissionContribute = "contribute"
+	//PermissionModerate allow to moderate and to edit the contributions of the other users
+	PermissionModerate = "moderate"
+	//PermissionImport allow to import the services from the files
+	PermissionImport = "import"
+	//PermissionAdminister allow to change the policies, the states and the reference data like the banks
+	PermissionAdminister = "administer"
+)
+
+//permissionRoles are the lowest roles which are granted the permissions
+var permissionRoles map[string]int = map[string]int{
+	PermissionContribute: RoleUser,
+	PermissionModerate:   RoleModerator,
+	PermissionImport:     RoleAdmin,
+	PermissionAdminister: RoleAdmin,
+}
 
 func CreateToken(id int64) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":  id,
-		"exp": time.Now().Add(time.Minute*10 + time.Second*30).Unix(),
-	})
+	return CreateRoleToken(id, RoleUser)
+}
+
+//CreateRoleToken create the token of an user who is having the role and the additional permissions
+func CreateRoleToken(id int64, role int, permissions ...string) (string, error) {
+	ring, e := keys()
+	if e != nil {
+		return "", e
+	}
+
+	claims := jwt.MapClaims{
+		"id":    id,
+		"sub":   strconv.FormatInt(id, 10),
+		"role":  role,
+		"roles": []string{RoleName(role)},
+	}
 
-	tokenString, err := token.SignedString([]byte("secret-key-0985399536aA"))
+	if len(permissions) > 0 {
+		claims["permissions"] = permissions
+	}
 
-	return tokenString, err
+	return ring.Sign(claims)
+}
+
+type userKey struct{}
+
+//GetName return the name which is identifying the user in the contributions like votes and reviews
+func (u User) GetName() string {
+	return strconv.FormatInt(u.Id, 10)
+}
+
+//IsAdmin determine whether the user is having the admin role
+func (u User) IsAdmin() bool {
+	return u.Role >= RoleAdmin
+}
+
+//Can determine whether the user is granted the permission by the role or by the token
+func (u User) Can(permission string) bool {
+	if role, ok := permissionRoles[permission]; ok && u.Role >= role {
+		return true
+	}
+
+	for _, p := range u.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+//CanEdit determine whether the user who is carried by ctx can edit a contribution, the owners edit their
+//contributions and the moderators edit every contribution
+func CanEdit(ctx context.Context, owners ...string) bool {
+	u, ok := UserFromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	if u.Can(PermissionModerate) {
+		return true
+	}
+
+	for _, owner := range owners {
+		if owner != "" && owner == u.GetName() {
+			return true
+		}
+	}
+
+	return false
+}
+
+//ContributorOf return the contributor of a submission which is made by the user who is carried by ctx, only the
+//admins submit on behalf of another contributor like the trusted sources
+func ContributorOf(ctx context.Context, requested string) string {
+	if u, ok := UserFromContext(ctx); ok && u.IsAdmin() && requested != "" {
+		return requested
+	}
+
+	return ActorOf(ctx)
+}
+
+//ActorAnonymous is the actor of the changes which are made by the requests without an user
+const ActorAnonymous = "anonymous"
+
+//ActorOf return the name of the user who is carried by ctx, ActorAnonymous is returned when there is no user
+func ActorOf(ctx context.Context) string {
+	if u, ok := UserFromContext(ctx); ok {
+		return u.GetName()
+	}
+
+	return ActorAnonymous
+}
+
+//WithUser return a copy of ctx which is carrying the authenticated user
+func WithUser(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+//UserFromContext return the authenticated user which is carried by ctx
+func UserFromContext(ctx context.Context) (u User, ok bool) {
+	u, ok = ctx.Value(userKey{}).(User)
+	return
 }
 
 func Authenticate(tokenString string) error {
-	fmt.Println("[Authenticate]", tokenString)
+	_, e := ParseToken(tokenString)
+	return e
+}
+
+//ParseToken validate the token and return the user who is owning it, the user is identified by the `id` claim
+//or by the `sub` claim and the role is the highest of the `role` claim and the `roles` claim
+func ParseToken(tokenString string) (u User, e error) {
 	if tokenString == "" {
-		return errors.New("Token is empty")
+		return u, errors.New("Token is empty")
+	}
+
+	ring, e := keys()
+	if e != nil {
+		return u, e
+	}
+
+	claims, e := ring.Parse(tokenString)
+	if e != nil {
+		log.Println("[Authenticate]", e.Error())
+		return u, e
+	}
+
+	u.Subject, _ = claims["sub"].(string)
+	if id, ok := claims["id"].(float64); ok {
+		u.Id = int64(id)
+	} else if u.Id, e = strconv.ParseInt(u.Subject, 10, 64); e != nil {
+		return u, errors.New("Invalid token")
+	}
+
+	if u.Subject == "" {
+		u.Subject = u.GetName()
+	}
+
+	if role, ok := claims["role"].(float64); ok {
+		u.Role = int(role)
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+	if roles, ok := claims["roles"].([]interface{}); ok {
+		for _, r := range roles {
+			if role, ok := roleNames[fmt.Sprint(r)]; ok {
+				u.Roles = append(u.Roles, fmt.Sprint(r))
+				if role > u.Role {
+					u.Role = role
+				}
+			}
 		}
+	}
 
-		// hmacSampleSecret is a []byte containing your secret, e.g. []byte("my_secret_key")
-		return []byte("secret-key-0985399536aA"), nil
-	})
+	if len(u.Roles) == 0 {
+		u.Roles = []string{RoleName(u.Role)}
+	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		_, ok := claims["id"]
-		if !ok {
-			return errors.New("Invalid token")
+	if permissions, ok := claims["permissions"].([]interface{}); ok {
+		for _, p := range permissions {
+			if p, ok := p.(string); ok {
+				u.Permissions = append(u.Permissions, p)
+			}
 		}
-		return nil
-	} else {
-		log.Println("[Authenticate]", err.Error())
-		return err
 	}
+
+	return u, nil
 }
@@ -0,0 +1,71 @@
+package model_test
+
+import (
+	"context"
+	"streelity/v1/model"
+	"testing"
+)
+
+func TestPermissions(t *testing.T) {
+	token, _ := model.CreateRoleToken(1, model.RoleUser, model.PermissionImport)
+	u, e := model.ParseToken(token)
+	if e != nil {
+		t.Fatalf("ParseToken returned error: %v", e)
+	}
+
+	tests := []struct {
+		name       string
+		user       model.User
+		permission string
+		can        bool
+	}{
+		{"user contribute", model.User{Role: model.RoleUser}, model.PermissionContribute, true},
+		{"user moderate", model.User{Role: model.RoleUser}, model.PermissionModerate, false},
+		{"moderator moderate", model.User{Role: model.RoleModerator}, model.PermissionModerate, true},
+		{"moderator import", model.User{Role: model.RoleModerator}, model.PermissionImport, false},
+		{"admin administer", model.User{Role: model.RoleAdmin}, model.PermissionAdminister, true},
+		{"granted by token", u, model.PermissionImport, true},
+		{"not granted by token", u, model.PermissionAdminister, false},
+	}
+
+	for _, test := range tests {
+		if can := test.user.Can(test.permission); can != test.can {
+			t.Errorf("%v: got %v want %v", test.name, can, test.can)
+		}
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	owner := model.WithUser(context.Background(), model.User{Id: 1})
+	other := model.WithUser(context.Background(), model.User{Id: 2})
+	moderator := model.WithUser(context.Background(), model.User{Id: 3, Role: model.RoleModerator})
+	admin := model.WithUser(context.Background(), model.User{Id: 4, Role: model.RoleAdmin})
+
+	if !model.CanEdit(owner, "1") || model.CanEdit(other, "1") || !model.CanEdit(moderator, "1") {
+		t.Errorf("CanEdit returned wrong result for the owner, the other user or the moderator")
+	}
+
+	if model.CanEdit(context.Background(), "1") || model.CanEdit(other, "") {
+		t.Errorf("CanEdit allowed an anonymous user or an unowned contribution")
+	}
+
+	if c := model.ContributorOf(owner, "Streetlity"); c != "1" {
+		t.Errorf("ContributorOf of an user: got %v want 1", c)
+	}
+
+	if c := model.ContributorOf(admin, "Streetlity"); c != "Streetlity" {
+		t.Errorf("ContributorOf of an admin: got %v want Streetlity", c)
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	token, _ := model.CreateRoleToken(7, model.RoleModerator)
+	u, e := model.ParseToken(token)
+	if e != nil {
+		t.Fatalf("ParseToken returned error: %v", e)
+	}
+
+	if u.Id != 7 || u.Subject != "7" || u.Role != model.RoleModerator || len(u.Roles) != 1 || u.Roles[0] != "moderator" {
+		t.Errorf("ParseToken returned wrong user: %+v", u)
+	}
+}
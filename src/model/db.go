@@ -9,6 +9,7 @@ import (
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
 	"github.com/nvnamsss/goinf/event"
 )
 
@@ -20,6 +21,7 @@ import (
 // }
 
 var Db *gorm.DB
+var models []interface{}
 
 // var Config Configuration
 var OnDisconnect *event.Event = event.NewEvent()
@@ -62,18 +64,61 @@ func DeleteTable(tablename string, entity map[string]string) (e error) {
 	return
 }
 
+//AutoMigrate register the models whose tables will be created when the database is connected
+//
+//The tables are only created if the migration is enabled in the configuration or the driver is sqlite3
+func AutoMigrate(values ...interface{}) {
+	models = append(models, values...)
+}
+
+//dataSource determine the driver and the data source name from the configuration
+func dataSource() (driver string, source string) {
+	driver = config.Config.Driver
+	source = config.Config.Dsn
+	if driver == "" {
+		driver = "mysql"
+	}
+
+	if source == "" && driver == "mysql" {
+		source = fmt.Sprintf("%s:%s@tcp(%s)/%s",
+			config.Config.Username, config.Config.Password, config.Config.Server, config.Config.Database)
+	}
+
+	return
+}
+
+func migrate(db *gorm.DB, driver string) (e error) {
+	if !config.Config.Migrate && driver != "sqlite3" {
+		return
+	}
+
+	if e = db.AutoMigrate(models...).Error; e != nil {
+		log.Println("[Database]", "migrate", e.Error())
+	}
+
+	return
+}
+
 func connect() {
-	connectionString := fmt.Sprintf("%s:%s@tcp(%s)/%s",
-		config.Config.Username, config.Config.Password, config.Config.Server, config.Config.Database)
-	db, err := gorm.Open("mysql", connectionString)
+	driver, source := dataSource()
+	db, err := gorm.Open(driver, source)
 	Db = db
 
+	if err == nil && driver == "sqlite3" {
+		//sqlite only allows one writer at a time, serialize the access instead of failing with a locked database
+		db.DB().SetMaxOpenConns(1)
+	}
+
+	if err == nil {
+		err = migrate(db, driver)
+	}
+
 	if err != nil {
 		OnDisconnect.Invoke()
 		log.Println(err.Error())
 	} else {
 		OnConnected.Invoke()
-		log.Println("[Database] connect success")
+		log.Println("[Database] connect success", driver)
 	}
 
 }
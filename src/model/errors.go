@@ -0,0 +1,79 @@
+package model
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/mattn/go-sqlite3"
+)
+
+//The kinds of the errors of the data access, the errors of model are one of them by errors.Is so the routers map
+//them to their responses without model knowing the responses
+var (
+	ErrNotFound = errors.New("record was not found")
+	ErrConflict = errors.New("record is conflicted")
+	ErrInvalid  = errors.New("request is invalid")
+	//ErrQuotaExceeded is returned when an api key is used over its daily or monthly quota
+	ErrQuotaExceeded = errors.New("api key quota is exceeded")
+)
+
+//Error is an error of a kind with its own message
+type Error struct {
+	kind    error
+	Message string
+}
+
+//NewError create an error of kind, kind is one of the kinds like ErrConflict
+func NewError(kind error, message string) *Error {
+	return &Error{kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+//FieldError is an invalid param of a query like the cursor of a page, it is ErrInvalid
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+func (e FieldError) Unwrap() error {
+	return ErrInvalid
+}
+
+//KindOf return the kind of err, the missing records of the database are ErrNotFound and the violated unique keys are
+//ErrConflict. nil is returned for the other errors like the failures of the database
+func KindOf(err error) error {
+	for _, kind := range []error{ErrNotFound, ErrConflict, ErrInvalid, ErrQuotaExceeded} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	if gorm.IsRecordNotFoundError(err) {
+		return ErrNotFound
+	}
+
+	switch e := err.(type) {
+	case *mysql.MySQLError:
+		if e.Number == 1062 {
+			return ErrConflict
+		}
+	case sqlite3.Error:
+		if e.ExtendedCode == sqlite3.ErrConstraintUnique || e.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			return ErrConflict
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package model_test
+
+import (
+	"streelity/v1/model"
+	"streelity/v1/sres"
+	"testing"
+)
+
+func TestClassifyDatabase(t *testing.T) {
+	model.ConnectSync()
+
+	var key model.ApiKey
+	e := model.GetById("api_key", 1<<40, &key)
+	if got := sres.ErrorOf(e); got == nil || got.Code != sres.CodeNotFound {
+		t.Errorf("missing record is mapped to %v", got)
+	}
+
+	e = model.GetById("missing_table", 1, &key)
+	if got := sres.ErrorOf(e); got == nil || got.Code != sres.CodeInternal {
+		t.Errorf("database failure is mapped to %v", got)
+	}
+}
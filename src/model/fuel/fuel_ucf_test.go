@@ -1,7 +1,52 @@
 package fuel_test
 
-import "testing"
+import (
+	"streelity/v1/model"
+	"streelity/v1/model/fuel"
+	"testing"
+)
 
 func TestCreateUcf(t *testing.T) {
 
 }
+
+func TestUpvoteUcf(t *testing.T) {
+	model.ConnectSync()
+	var s fuel.FuelUcf
+	s.Lat = 9.25
+	s.Lon = 105.25
+	s.Address = "upvote ucf"
+	ucf, e := fuel.CreateUcf(s)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	fuel.UpvoteUcf(ucf.Id, "1")
+	fuel.UpvoteUcf(ucf.Id, "1")
+	if ucf, _ = fuel.UcfById(ucf.Id); ucf.Confident != 1 {
+		t.Errorf("wrong confident after repeated upvotes: got %v want 1", ucf.Confident)
+	}
+
+	//Streetlity is a trusted source in config.json
+	if e := fuel.UpvoteUcf(ucf.Id, "Streetlity"); e != nil {
+		t.Fatal(e)
+	}
+
+	if _, e := fuel.UcfById(ucf.Id); e == nil {
+		t.Error("confirmed service is still unconfirmed")
+	}
+
+	service, e := fuel.ServiceByLocation(9.25, 105.25)
+	if e != nil {
+		t.Fatal("confirmed service is not moved to the services", e)
+	}
+
+	//the voters of the unconfirmed service keep their votes on the service
+	if votes, _ := fuel.VotesByService(service.Id); len(votes) != 2 {
+		t.Errorf("wrong voters after the service is moved: got %v want 2", len(votes))
+	}
+
+	if e := fuel.WithdrawVote(service.Id, "1"); e != nil {
+		t.Error("withdraw the vote on the unconfirmed service is rejected", e)
+	}
+}
@@ -0,0 +1,10 @@
+package fuel_test
+
+import (
+	"streelity/v1/config/configtest"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	configtest.Main(m)
+}
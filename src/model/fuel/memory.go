@@ -0,0 +1,334 @@
+package fuel
+
+import (
+	"net/url"
+	"streelity/v1/model"
+
+	"github.com/golang/geo/r2"
+)
+
+//Memory is the in-memory Repository, it is using for running the handlers without a database
+type Memory struct {
+	store *model.MemoryStore
+}
+
+//NewMemory create an empty in-memory Repository
+func NewMemory() *Memory {
+	return &Memory{store: model.NewMemoryStore(model.MemoryKind{
+		ServiceTable: ServiceTableName,
+		UcfTable:     UcfServiceTableName,
+		Set:          func(s model.Stored, values url.Values) { s.(*Fuel).setValues(values) },
+		Promote: func(ucf model.StoredUcf, base model.Service) model.Stored {
+			return &Fuel{Service: base}
+		},
+	})}
+}
+
+func fuelOf(s model.Stored) (service Fuel) {
+	if s != nil {
+		service = *s.(*Fuel)
+	}
+
+	return
+}
+
+func fuelsOf(stored []model.Stored) []Fuel {
+	services := make([]Fuel, len(stored))
+	for i, s := range stored {
+		services[i] = fuelOf(s)
+	}
+
+	return services
+}
+
+func ucfOf(s model.StoredUcf) (ucf FuelUcf) {
+	if s != nil {
+		ucf = *s.(*FuelUcf)
+	}
+
+	return
+}
+
+//UseReputations set the reputations which scale the votes like the database, see model.MemoryStore.UseReputations
+func (m *Memory) UseReputations(r model.ReputationRepository) {
+	m.store.UseReputations(r)
+}
+
+func (m *Memory) AllServices() ([]Fuel, error) {
+	return fuelsOf(m.store.All()), nil
+}
+
+func (m *Memory) PageServices(q model.PageQuery, states ...string) (services []Fuel, page model.Page, e error) {
+	if len(states) > 0 {
+		services = fuelsOf(m.store.ByState(states...))
+	} else {
+		services = fuelsOf(m.store.All())
+	}
+
+	q.Ratings = m.store.ReviewAverageScores
+	page, e = model.Paginate(&services, q)
+	return
+}
+
+func (m *Memory) ServiceById(id int64) (Fuel, error) {
+	s, e := m.store.ById(id)
+	return fuelOf(s), e
+}
+
+func (m *Memory) ServiceByLocation(lat, lon float64) (Fuel, error) {
+	s, e := m.store.ByLocation(lat, lon)
+	return fuelOf(s), e
+}
+
+func (m *Memory) ServiceByAddress(address string) (service Fuel, e error) {
+	services, e := m.ServicesByAddress(address)
+	if e != nil {
+		return
+	}
+
+	return services[0], nil
+}
+
+func (m *Memory) ServicesByAddress(address string) ([]Fuel, error) {
+	services, e := m.store.ByAddress(address)
+	return fuelsOf(services), e
+}
+
+func (m *Memory) PageServicesByAddress(address string, q model.PageQuery) (services []Fuel, page model.Page, e error) {
+	//no services are an empty page instead of an error
+	stored, _ := m.store.ByAddress(address)
+	services = fuelsOf(stored)
+	q.Ratings = m.store.ReviewAverageScores
+	page, e = model.Paginate(&services, q)
+	return
+}
+
+func (m *Memory) ServicesByIds(ids ...int64) []Fuel {
+	return fuelsOf(m.store.ByIds(ids...))
+}
+
+func (m *Memory) ServicesInRange(p r2.Point, max_range float64) []Fuel {
+	return fuelsOf(m.store.InRange(p, max_range))
+}
+
+func (m *Memory) PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) (services []Fuel, page model.Page, e error) {
+	if len(states) == 0 {
+		states = model.ListedStates
+	}
+
+	services = fuelsOf(m.store.InRangeByState(p, max_range, states...))
+	q.Ratings = m.store.ReviewAverageScores
+	page, e = model.Paginate(&services, q)
+	return
+}
+
+func (m *Memory) CreateService(s Fuel) (Fuel, error) {
+	service, e := m.store.Create(&s)
+	if e != nil {
+		return s, e
+	}
+
+	return fuelOf(service), nil
+}
+
+func (m *Memory) UpdateService(id int64, values url.Values) (Fuel, error) {
+	s, e := m.store.Set(id, values)
+	return fuelOf(s), e
+}
+
+func (m *Memory) UpvoteService(id int64, voter string) error {
+	return m.store.Vote(id, voter, model.VoteUp)
+}
+
+func (m *Memory) DownvoteService(id int64, voter string) error {
+	return m.store.Vote(id, voter, model.VoteDown)
+}
+
+func (m *Memory) WithdrawVote(id int64, voter string) error {
+	return m.store.Vote(id, voter, model.VoteWithdraw)
+}
+
+func (m *Memory) VotesByService(id int64) ([]model.Vote, error) {
+	return m.store.Votes(id)
+}
+
+func (m *Memory) TransitService(id int64, to, actor, reason string) (Fuel, error) {
+	s, e := m.store.Update(id, func(s model.Stored) error { return s.Base().Transit(to, actor, reason) })
+	return fuelOf(s), e
+}
+
+func (m *Memory) TransitionsByService(id int64) ([]model.Transition, error) {
+	return m.store.Transitions(id), nil
+}
+
+func (m *Memory) ServicesByState(states ...string) ([]Fuel, error) {
+	return fuelsOf(m.store.ByState(states...)), nil
+}
+
+func (m *Memory) ArchiveStale() (int, error) {
+	return m.store.ArchiveStale(), nil
+}
+
+func (m *Memory) VerifyService(id int64) (Fuel, error) {
+	s, e := m.store.Verify(id)
+	return fuelOf(s), e
+}
+
+func (m *Memory) DecayServices() (int, error) {
+	return m.store.Decay(), nil
+}
+
+func (m *Memory) ReverifyQueue(p r2.Point, max_range float64) []Fuel {
+	return fuelsOf(m.store.ReverifyQueue(p, max_range))
+}
+
+func (m *Memory) ReportService(id int64, r model.Report) (model.Report, error) {
+	return m.store.Report(id, r)
+}
+
+func (m *Memory) ReportsByService(id int64) ([]model.Report, error) {
+	return m.store.Reports(id)
+}
+
+func (m *Memory) ReportsByServices(ids ...int64) ([]model.Report, error) {
+	return m.store.ReportsByServices(ids...), nil
+}
+
+func (m *Memory) AcceptReport(id int64, kind string, actor string) (model.Report, error) {
+	return m.store.AcceptReport(id, kind, actor)
+}
+
+func (m *Memory) DismissReports(id int64, kind string, actor string) error {
+	return m.store.DismissReports(id, kind, actor)
+}
+
+func (m *Memory) Import(bytes []byte, t string) (e error) {
+	switch t {
+	case "RawText":
+		for _, s := range parseRawText(string(bytes)) {
+			m.CreateService(s)
+		}
+	}
+
+	return
+}
+
+func (m *Memory) AllUcfs() []FuelUcf {
+	stored := m.store.Ucfs()
+	ucfs := make([]FuelUcf, len(stored))
+	for i, s := range stored {
+		ucfs[i] = ucfOf(s)
+	}
+
+	return ucfs
+}
+
+func (m *Memory) PageUcfs(q model.PageQuery) (ucfs []FuelUcf, page model.Page, e error) {
+	ucfs = m.AllUcfs()
+	page, e = model.Paginate(&ucfs, q)
+	return
+}
+
+func (m *Memory) UcfById(id int64) (FuelUcf, error) {
+	s, e := m.store.UcfById(id)
+	return ucfOf(s), e
+}
+
+func (m *Memory) UcfByLocation(lat, lon float64) (FuelUcf, error) {
+	s, e := m.store.UcfByLocation(lat, lon)
+	return ucfOf(s), e
+}
+
+func (m *Memory) UcfByAddress(address string) (ucf FuelUcf, e error) {
+	ucfs, e := m.UcfsByAddress(address)
+	if e != nil {
+		return
+	}
+
+	return ucfs[0], nil
+}
+
+func (m *Memory) UcfsByAddress(address string) (ucfs []FuelUcf, e error) {
+	stored, e := m.store.UcfsByAddress(address)
+	for _, s := range stored {
+		ucfs = append(ucfs, ucfOf(s))
+	}
+
+	return
+}
+
+func (m *Memory) PageUcfsByAddress(address string, q model.PageQuery) (ucfs []FuelUcf, page model.Page, e error) {
+	ucfs, _ = m.UcfsByAddress(address)
+	page, e = model.Paginate(&ucfs, q)
+	return
+}
+
+func (m *Memory) UcfInRange(p r2.Point, max_range float64) []Fuel {
+	return fuelsOf(m.store.UcfInRange(p, max_range))
+}
+
+func (m *Memory) CreateUcf(s FuelUcf) (FuelUcf, error) {
+	ucf, e := m.store.CreateUcf(&s)
+	return ucfOf(ucf), e
+}
+
+func (m *Memory) DeleteUcf(id int64, actor string) error {
+	return m.store.DeleteUcf(id, actor)
+}
+
+func (m *Memory) TransitUcf(id int64, to, actor, reason string) error {
+	return m.store.TransitUcf(id, to, actor, reason)
+}
+
+func (m *Memory) UpvoteUcf(id int64, voter string) error {
+	return m.store.UpvoteUcf(id, voter)
+}
+
+func (m *Memory) CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error) {
+	return Review{Review: m.store.CreateReview(service_id, reviewer, score, body)}, nil
+}
+
+func (m *Memory) DeleteReview(review_id int64) error {
+	m.store.DeleteReview(review_id)
+	return nil
+}
+
+func (m *Memory) ReviewByService(service_id, order int64, limit int) (reviews []Review, e error) {
+	for _, r := range m.store.Reviews(service_id, order, int64(limit)) {
+		reviews = append(reviews, Review{Review: r})
+	}
+
+	return
+}
+
+func (m *Memory) PageReviews(service_id, order int64, q model.PageQuery) (reviews []Review, page model.Page, e error) {
+	reviews, _ = m.ReviewByService(service_id, order, -1)
+	page, e = model.Paginate(&reviews, q)
+	return
+}
+
+func (m *Memory) ReviewsByServices(ids ...int64) (reviews []Review, e error) {
+	for _, r := range m.store.ReviewsByServices(ids...) {
+		reviews = append(reviews, Review{Review: r})
+	}
+
+	return
+}
+
+func (m *Memory) ReviewById(review_id int64) (Review, error) {
+	r, e := m.store.ReviewById(review_id)
+	return Review{Review: r}, e
+}
+
+func (m *Memory) ReviewAverageScore(service_id int64) float64 {
+	return m.store.ReviewAverageScore(service_id)
+}
+
+func (m *Memory) ReviewAverageScores(ids ...int64) map[int64]float64 {
+	return m.store.ReviewAverageScores(ids...)
+}
+
+func (m *Memory) SaveReview(r Review) error {
+	m.store.SaveReview(r.Review)
+	return nil
+}
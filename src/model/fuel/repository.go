@@ -0,0 +1,282 @@
+package fuel
+
+import (
+	"net/url"
+	"streelity/v1/model"
+
+	"github.com/golang/geo/r2"
+)
+
+//ServiceRepository determine the data access of the fuel services
+type ServiceRepository interface {
+	AllServices() ([]Fuel, error)
+	PageServices(q model.PageQuery, states ...string) ([]Fuel, model.Page, error)
+	ServiceById(id int64) (Fuel, error)
+	ServiceByLocation(lat, lon float64) (Fuel, error)
+	ServiceByAddress(address string) (Fuel, error)
+	ServicesByAddress(address string) ([]Fuel, error)
+	PageServicesByAddress(address string, q model.PageQuery) ([]Fuel, model.Page, error)
+	ServicesByIds(ids ...int64) []Fuel
+	ServicesInRange(p r2.Point, max_range float64) []Fuel
+	PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) ([]Fuel, model.Page, error)
+	CreateService(s Fuel) (Fuel, error)
+	UpdateService(id int64, values url.Values) (Fuel, error)
+	UpvoteService(id int64, voter string) error
+	DownvoteService(id int64, voter string) error
+	WithdrawVote(id int64, voter string) error
+	VotesByService(id int64) ([]model.Vote, error)
+	TransitService(id int64, to, actor, reason string) (Fuel, error)
+	TransitionsByService(id int64) ([]model.Transition, error)
+	ServicesByState(states ...string) ([]Fuel, error)
+	ArchiveStale() (int, error)
+	VerifyService(id int64) (Fuel, error)
+	DecayServices() (int, error)
+	ReverifyQueue(p r2.Point, max_range float64) []Fuel
+	ReportService(id int64, r model.Report) (model.Report, error)
+	ReportsByService(id int64) ([]model.Report, error)
+	ReportsByServices(ids ...int64) ([]model.Report, error)
+	AcceptReport(id int64, kind string, actor string) (model.Report, error)
+	DismissReports(id int64, kind string, actor string) error
+	Import(bytes []byte, t string) error
+}
+
+//UcfRepository determine the data access of the unconfirmed fuel services
+type UcfRepository interface {
+	AllUcfs() []FuelUcf
+	PageUcfs(q model.PageQuery) ([]FuelUcf, model.Page, error)
+	UcfById(id int64) (FuelUcf, error)
+	UcfByLocation(lat, lon float64) (FuelUcf, error)
+	UcfByAddress(address string) (FuelUcf, error)
+	UcfsByAddress(address string) ([]FuelUcf, error)
+	PageUcfsByAddress(address string, q model.PageQuery) ([]FuelUcf, model.Page, error)
+	UcfInRange(p r2.Point, max_range float64) []Fuel
+	CreateUcf(s FuelUcf) (FuelUcf, error)
+	DeleteUcf(id int64, actor string) error
+	TransitUcf(id int64, to, actor, reason string) error
+	UpvoteUcf(id int64, voter string) error
+}
+
+//ReviewRepository determine the data access of the fuel reviews
+type ReviewRepository interface {
+	CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error)
+	DeleteReview(review_id int64) error
+	ReviewByService(service_id, order int64, limit int) ([]Review, error)
+	PageReviews(service_id, order int64, q model.PageQuery) ([]Review, model.Page, error)
+	ReviewsByServices(ids ...int64) ([]Review, error)
+	ReviewById(review_id int64) (Review, error)
+	ReviewAverageScore(service_id int64) float64
+	ReviewAverageScores(ids ...int64) map[int64]float64
+	SaveReview(r Review) error
+}
+
+//Repository is the whole data access of the fuel package which is injected into the router
+type Repository interface {
+	ServiceRepository
+	UcfRepository
+	ReviewRepository
+	model.Moderated
+}
+
+//Database is the Repository which is stored in model.Db
+type Database struct{}
+
+func (Database) AllServices() ([]Fuel, error) {
+	return AllServices()
+}
+
+func (Database) PageServices(q model.PageQuery, states ...string) ([]Fuel, model.Page, error) {
+	return PageServices(q, states...)
+}
+
+func (Database) ServiceById(id int64) (Fuel, error) {
+	return ServiceById(id)
+}
+
+func (Database) ServiceByLocation(lat, lon float64) (Fuel, error) {
+	return ServiceByLocation(lat, lon)
+}
+
+func (Database) ServiceByAddress(address string) (Fuel, error) {
+	return ServiceByAddress(address)
+}
+
+func (Database) ServicesByAddress(address string) ([]Fuel, error) {
+	return ServicesByAddress(address)
+}
+
+func (Database) PageServicesByAddress(address string, q model.PageQuery) ([]Fuel, model.Page, error) {
+	return PageServicesByAddress(address, q)
+}
+
+func (Database) ServicesByIds(ids ...int64) []Fuel {
+	return ServicesByIds(ids...)
+}
+
+func (Database) ServicesInRange(p r2.Point, max_range float64) []Fuel {
+	return ServicesInRange(p, max_range)
+}
+
+func (Database) PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) ([]Fuel, model.Page, error) {
+	return PageServicesInRange(p, max_range, q, states...)
+}
+
+func (Database) CreateService(s Fuel) (Fuel, error) {
+	return CreateService(s)
+}
+
+func (Database) UpdateService(id int64, values url.Values) (Fuel, error) {
+	return UpdateService(id, values)
+}
+
+func (Database) UpvoteService(id int64, voter string) error {
+	return UpvoteService(id, voter)
+}
+
+func (Database) DownvoteService(id int64, voter string) error {
+	return DownvoteService(id, voter)
+}
+
+func (Database) WithdrawVote(id int64, voter string) error {
+	return WithdrawVote(id, voter)
+}
+
+func (Database) VotesByService(id int64) ([]model.Vote, error) {
+	return VotesByService(id)
+}
+
+func (Database) TransitService(id int64, to, actor, reason string) (Fuel, error) {
+	return TransitService(id, to, actor, reason)
+}
+
+func (Database) TransitionsByService(id int64) ([]model.Transition, error) {
+	return TransitionsByService(id)
+}
+
+func (Database) ServicesByState(states ...string) ([]Fuel, error) {
+	return ServicesByState(states...)
+}
+
+func (Database) ArchiveStale() (int, error) {
+	return ArchiveStale()
+}
+
+func (Database) VerifyService(id int64) (Fuel, error) {
+	return VerifyService(id)
+}
+
+func (Database) DecayServices() (int, error) {
+	return DecayServices()
+}
+
+func (Database) ReverifyQueue(p r2.Point, max_range float64) []Fuel {
+	return ReverifyQueue(p, max_range)
+}
+
+func (Database) ReportService(id int64, r model.Report) (model.Report, error) {
+	return ReportService(id, r)
+}
+
+func (Database) ReportsByService(id int64) ([]model.Report, error) {
+	return ReportsByService(id)
+}
+
+func (Database) ReportsByServices(ids ...int64) ([]model.Report, error) {
+	return ReportsByServices(ids...)
+}
+
+func (Database) AcceptReport(id int64, kind string, actor string) (model.Report, error) {
+	return AcceptReport(id, kind, actor)
+}
+
+func (Database) DismissReports(id int64, kind string, actor string) error {
+	return DismissReports(id, kind, actor)
+}
+
+func (Database) Import(bytes []byte, t string) error {
+	return Import(bytes, t)
+}
+
+func (Database) AllUcfs() []FuelUcf {
+	return AllUcfs()
+}
+
+func (Database) PageUcfs(q model.PageQuery) ([]FuelUcf, model.Page, error) {
+	return PageUcfs(q)
+}
+
+func (Database) UcfById(id int64) (FuelUcf, error) {
+	return UcfById(id)
+}
+
+func (Database) UcfByLocation(lat, lon float64) (FuelUcf, error) {
+	return UcfByLocation(lat, lon)
+}
+
+func (Database) UcfByAddress(address string) (FuelUcf, error) {
+	return UcfByAddress(address)
+}
+
+func (Database) UcfsByAddress(address string) ([]FuelUcf, error) {
+	return UcfsByAddress(address)
+}
+
+func (Database) PageUcfsByAddress(address string, q model.PageQuery) ([]FuelUcf, model.Page, error) {
+	return PageUcfsByAddress(address, q)
+}
+
+func (Database) UcfInRange(p r2.Point, max_range float64) []Fuel {
+	return UcfInRange(p, max_range)
+}
+
+func (Database) CreateUcf(s FuelUcf) (FuelUcf, error) {
+	return CreateUcf(s)
+}
+
+func (Database) DeleteUcf(id int64, actor string) error {
+	return DeleteUcf(id, actor)
+}
+
+func (Database) TransitUcf(id int64, to, actor, reason string) error {
+	return TransitUcf(id, to, actor, reason)
+}
+
+func (Database) UpvoteUcf(id int64, voter string) error {
+	return UpvoteUcf(id, voter)
+}
+
+func (Database) CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error) {
+	return CreateReview(service_id, reviewer, score, body)
+}
+
+func (Database) DeleteReview(review_id int64) error {
+	return DeleteReview(review_id)
+}
+
+func (Database) ReviewByService(service_id, order int64, limit int) ([]Review, error) {
+	return ReviewByService(service_id, order, limit)
+}
+
+func (Database) PageReviews(service_id, order int64, q model.PageQuery) ([]Review, model.Page, error) {
+	return PageReviews(service_id, order, q)
+}
+
+func (Database) ReviewsByServices(ids ...int64) ([]Review, error) {
+	return ReviewsByServices(ids...)
+}
+
+func (Database) ReviewById(review_id int64) (Review, error) {
+	return ReviewById(review_id)
+}
+
+func (Database) ReviewAverageScore(service_id int64) float64 {
+	return ReviewAverageScore(service_id)
+}
+
+func (Database) ReviewAverageScores(ids ...int64) map[int64]float64 {
+	return ReviewAverageScores(ids...)
+}
+
+func (Database) SaveReview(r Review) error {
+	r.db = model.Db
+	return r.Save()
+}
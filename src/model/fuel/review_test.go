@@ -29,9 +29,22 @@ func TestCreateReview(t *testing.T) {
 func TestReviewAverageScore(t *testing.T) {
 	model.ConnectSync()
 
-	average := fuel.ReviewAverageScore(6)
+	var service_id int64 = 1000
+	scores := []float32{1, 2, 4, 5}
+	for _, score := range scores {
+		if _, e := fuel.CreateReview(service_id, "reviewer", score, "body"); e != nil {
+			t.Error(e)
+		}
+	}
 
+	average := fuel.ReviewAverageScore(service_id)
 	log.Println(average)
 
-	t.Error("Completed")
+	if average != 3 {
+		t.Errorf("Review average score failed, expected %v, got %v", 3, average)
+	}
+
+	if empty := fuel.ReviewAverageScore(service_id + 1); empty != 0 {
+		t.Errorf("Review average score of a service without review failed, expected %v, got %v", 0, empty)
+	}
 }
@@ -0,0 +1,225 @@
+package model
+
+import (
+	"log"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+//The states of the service lifecycle
+const (
+	StatePending   = "pending"
+	StateConfirmed = "confirmed"
+	StateDisputed  = "disputed"
+	StateRejected  = "rejected"
+	StateClosed    = "closed"
+	StateArchived  = "archived"
+)
+
+//ActorSystem is the actor of the transitions which are made by the confirmation policy
+const ActorSystem = "system"
+
+//transitions are the allowed transitions of the lifecycle
+var transitions map[string][]string = map[string][]string{
+	StatePending:   {StateConfirmed, StateRejected, StateArchived},
+	StateConfirmed: {StateDisputed, StateClosed, StateArchived},
+	StateDisputed:  {StateConfirmed, StateRejected, StateClosed, StateArchived},
+	StateRejected:  {StatePending, StateArchived},
+	StateClosed:    {StateConfirmed, StateArchived},
+	StateArchived:  {},
+}
+
+//IsState determine whether state is a state of the lifecycle
+func IsState(state string) bool {
+	_, ok := transitions[state]
+	return ok
+}
+
+//CanTransit determine whether the lifecycle allow to change the state from to the state to
+func CanTransit(from, to string) bool {
+	if from == "" {
+		from = StatePending
+	}
+
+	for _, state := range transitions[from] {
+		if state == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+//IsListed determine whether a service in the state is placed in an index, confirmed and disputed services are
+//placed in the services index, pending services are placed in the unconfirmed index
+func IsListed(state string) bool {
+	return state == StateConfirmed || state == StateDisputed
+}
+
+//ListedStates are the states of the services which are listed by default, see IsListed
+var ListedStates = []string{StateConfirmed, StateDisputed}
+
+//QueriedStates return the states of a query by the states, the services which are created before the lifecycle have
+//no state and they are pending
+func QueriedStates(states []string) []string {
+	for _, state := range states {
+		if state == StatePending {
+			return append(append([]string{}, states...), "")
+		}
+	}
+
+	return states
+}
+
+//IsUnconfirmed determine whether a service in the state is placed in the unconfirmed index
+func IsUnconfirmed(state string) bool {
+	return state == StatePending || state == ""
+}
+
+//Transition is an entry of the lifecycle log of the services
+type Transition struct {
+	Id          int64     `gorm:"column:id"`
+	ServiceType string    `gorm:"column:service_type;index:idx_transition_service"`
+	ServiceId   int64     `gorm:"column:service_id;index:idx_transition_service"`
+	From        string    `gorm:"column:from_state"`
+	To          string    `gorm:"column:to_state"`
+	Actor       string    `gorm:"column:actor"`
+	Reason      string    `gorm:"column:reason"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+const TransitionTableName = "transition"
+
+func (Transition) TableName() string {
+	return TransitionTableName
+}
+
+//Lifecycle is embedded into the services to keep the state and the transition which is waiting to be logged
+type Lifecycle struct {
+	State string `gorm:"column:state;index"`
+	//CreatedAt is nil for the services which are created before the lifecycle
+	CreatedAt *time.Time `gorm:"column:created_at"`
+	//VerifiedAt is the time of the latest positive signal of the service, it is nil when there is no signal
+	VerifiedAt *time.Time `gorm:"column:verified_at;index"`
+	//UpdatedAt is the time of the latest change of the service, it is set by gorm when the service is saved
+	UpdatedAt  *time.Time `gorm:"column:updated_at"`
+	transition *Transition
+}
+
+//ModifiedAt return the latest of the creation, the verification and the change of the service, it is nil when none
+//of them is known
+func (l Lifecycle) ModifiedAt() (modified *time.Time) {
+	for _, t := range []*time.Time{l.CreatedAt, l.VerifiedAt, l.UpdatedAt} {
+		if t != nil && (modified == nil || t.After(*modified)) {
+			modified = t
+		}
+	}
+
+	return
+}
+
+//GetState return the state of the lifecycle, the services which are created before the lifecycle is pending
+func (l Lifecycle) GetState() string {
+	if l.State == "" {
+		return StatePending
+	}
+
+	return l.State
+}
+
+//Transit change the state to the state to by actor, the transition is logged when the service is saved
+func (l *Lifecycle) Transit(to, actor, reason string) error {
+	from := l.GetState()
+	if !CanTransit(from, to) {
+		return NewError(ErrConflict, "cannot change state from "+from+" to "+to)
+	}
+
+	l.State = to
+	l.transition = &Transition{From: from, To: to, Actor: actor, Reason: reason}
+	if to == StateConfirmed {
+		l.Verify(time.Now())
+	}
+
+	return nil
+}
+
+//Evaluate change the state which is driven by votes: pending and disputed services are confirmed when they reach
+//the confirmation policy and confirmed services are disputed when they drop out of it. Nothing is changed when
+//there is a transition which is waiting to be logged, so the explicit transitions aren't overridden by the votes
+func (l *Lifecycle) Evaluate(confirmed bool) {
+	if l.transition != nil {
+		return
+	}
+
+	state := l.GetState()
+	switch {
+	case confirmed && (state == StatePending || state == StateDisputed):
+		l.Transit(StateConfirmed, ActorSystem, "confirmation policy is reached")
+	case !confirmed && state == StateConfirmed:
+		l.Transit(StateDisputed, ActorSystem, "confirmation policy is no longer reached")
+	case l.State == "":
+		l.State = StatePending
+	}
+}
+
+//Reject change the state of a pending or disputed service to rejected by the system when reason isn't empty.
+//Like Evaluate, nothing is changed when there is a transition which is waiting to be logged
+func (l *Lifecycle) Reject(reason string) {
+	if reason == "" || l.transition != nil {
+		return
+	}
+
+	if state := l.GetState(); state == StatePending || state == StateDisputed {
+		l.Transit(StateRejected, ActorSystem, reason)
+	}
+}
+
+//Transited return the transition which is waiting to be logged
+func (l Lifecycle) Transited() *Transition {
+	return l.transition
+}
+
+//PopTransition return the transition which is waiting to be logged of the row of tablename which is having id
+//and clear it, nil is returned when there is no transition
+func (l *Lifecycle) PopTransition(tablename string, id int64) *Transition {
+	if l.transition == nil {
+		return nil
+	}
+
+	t := *l.transition
+	t.ServiceType = tablename
+	t.ServiceId = id
+	t.CreatedAt = time.Now()
+	l.transition = nil
+	return &t
+}
+
+//LogTransition write the transition which is waiting to be logged of the row of tablename which is having id
+//
+//db is passed in order to be used in the hooks which are ran in a transaction
+func (l *Lifecycle) LogTransition(db *gorm.DB, tablename string, id int64) (e error) {
+	t := l.PopTransition(tablename, id)
+	if t == nil {
+		return
+	}
+
+	if e = db.Create(t).Error; e != nil {
+		log.Println("[Database]", "log transition", tablename, id, ":", e.Error())
+	}
+
+	return
+}
+
+//TransitionsByService query the lifecycle log of the row of tablename which is having id, the oldest is the first
+func TransitionsByService(tablename string, id int64) (transitions []Transition, e error) {
+	if e = Db.Where("service_type = ? AND service_id = ?", tablename, id).Order("id").Find(&transitions).Error; e != nil {
+		log.Println("[Database]", "transitions", tablename, id, ":", e.Error())
+	}
+
+	return
+}
+
+func init() {
+	AutoMigrate(&Transition{})
+}
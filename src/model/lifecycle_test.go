@@ -0,0 +1,60 @@
+package model_test
+
+import (
+	"streelity/v1/model"
+	"testing"
+)
+
+func TestCanTransit(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		can  bool
+	}{
+		{"", model.StateConfirmed, true},
+		{model.StatePending, model.StateClosed, false},
+		{model.StateConfirmed, model.StateDisputed, true},
+		{model.StateDisputed, model.StateRejected, true},
+		{model.StateRejected, model.StateConfirmed, false},
+		{model.StateClosed, model.StateConfirmed, true},
+		{model.StateArchived, model.StatePending, false},
+	}
+
+	for _, test := range tests {
+		if can := model.CanTransit(test.from, test.to); can != test.can {
+			t.Errorf("%v to %v: got %v want %v", test.from, test.to, can, test.can)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	var l model.Lifecycle
+	l.Evaluate(false)
+	if l.State != model.StatePending || l.Transited() != nil {
+		t.Fatalf("new lifecycle is not pending: got %v", l.State)
+	}
+
+	l.Evaluate(true)
+	if tr := l.PopTransition("atm", 1); l.State != model.StateConfirmed || tr == nil || tr.Actor != model.ActorSystem {
+		t.Fatalf("confirmed lifecycle is not logged: got %v %v", l.State, tr)
+	}
+
+	l.Evaluate(false)
+	if tr := l.PopTransition("atm", 1); l.State != model.StateDisputed || tr == nil || tr.From != model.StateConfirmed {
+		t.Fatalf("disputed lifecycle is not logged: got %v %v", l.State, tr)
+	}
+
+	//the explicit transition isn't overridden by the votes
+	if e := l.Transit(model.StateClosed, "admin", "moved"); e != nil {
+		t.Fatal(e)
+	}
+
+	l.Evaluate(true)
+	if l.State != model.StateClosed {
+		t.Errorf("explicit transition is overridden: got %v", l.State)
+	}
+
+	if e := l.Transit(model.StateRejected, "admin", ""); e == nil {
+		t.Errorf("closed lifecycle is rejected")
+	}
+}
@@ -68,6 +68,18 @@ func HistoriesByCUser(common_user string) (histories []MaintenanceHistory, e err
 	return
 }
 
+//PageHistoriesByCUser return the page of the histories of the common user which is queried by q
+func PageHistoriesByCUser(common_user string, q model.PageQuery) (histories []MaintenanceHistory, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.Db.Where("common_user=?", common_user), &histories, q)
+	return
+}
+
+//PageHistoriesByMUser return the page of the histories of the maintenance user which is queried by q
+func PageHistoriesByMUser(maintenance_user string, q model.PageQuery) (histories []MaintenanceHistory, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.Db.Where("maintenance_user=?", maintenance_user), &histories, q)
+	return
+}
+
 func HistoryById(id int64) (h MaintenanceHistory, e error) {
 	model.GetById(HistoryTableName, id, &h)
 	return
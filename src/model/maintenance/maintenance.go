@@ -2,13 +2,14 @@ package maintenance
 
 import (
 	"encoding/json"
-	"errors"
 	"log"
 	"math"
 	"net/url"
+	"sort"
 	"strconv"
 	"streelity/v1/model"
 	"strings"
+	"time"
 
 	"github.com/golang/geo/r2"
 	"github.com/jinzhu/gorm"
@@ -27,7 +28,7 @@ type Maintenance struct {
 const ServiceTableName = "maintenance"
 
 var services spatial.RTree
-var map_services map[int64]Maintenance
+var map_services map[int64]Maintenance = make(map[int64]Maintenance)
 
 func (Maintenance) TableName() string {
 	return ServiceTableName
@@ -56,51 +57,293 @@ func AllServices() (services []Maintenance, e error) {
 //
 //return error if there is something wrong when doing transaction
 func CreateService(s Maintenance) (service Maintenance, e error) {
+	return createService(model.Db, s)
+}
+
+func createService(db *gorm.DB, s Maintenance) (service Maintenance, e error) {
 	service = s
-	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&Maintenance{}).Error; e == nil {
-		return s, errors.New("The service location is existed or some problems is occured")
+	if e = db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&Maintenance{}).Error; e == nil {
+		return s, model.NewError(model.ErrConflict, "The service location is existed or some problems is occured")
 	}
 
-	if e = model.Db.Create(&service).Error; e != nil {
+	if e = db.Create(&service).Error; e != nil {
 		log.Println("[Database]", "add maintennace", e.Error())
 	}
 
 	return
 }
 
-//UpvoteService upvote the unconfirmed atm by specific id
-func UpvoteService(id int64) error {
-	return upvoteService(id, 1)
+//UpvoteService record the upvote of voter on the maintenance service by specific id,
+//the previous vote of voter on the service is replaced. The upvote of a trusted source confirm the service at once
+func UpvoteService(id int64, voter string) error {
+	return voteService(id, model.Vote{Voter: voter, Direction: model.VoteUp, Trusted: policy().IsTrusted(voter)})
+}
+
+//DownvoteService record the downvote of voter on the maintenance service by specific id
+func DownvoteService(id int64, voter string) error {
+	return voteService(id, model.Vote{Voter: voter, Direction: model.VoteDown})
+}
+
+//WithdrawVote remove the vote of voter on the maintenance service by specific id
+func WithdrawVote(id int64, voter string) error {
+	return voteService(id, model.Vote{Voter: voter, Direction: model.VoteWithdraw})
+}
+
+//VotesByService query the active votes on the maintenance service by specific id
+func VotesByService(id int64) ([]model.Vote, error) {
+	return model.VotesByService(ServiceTableName, id)
+}
+
+//TransitService change the state of the maintenance service by specific id, the transition is logged with actor and reason
+func TransitService(id int64, to, actor, reason string) (service Maintenance, e error) {
+	if service, e = ServiceById(id); e != nil {
+		return
+	}
+
+	if e = service.Transit(to, actor, reason); e != nil {
+		return
+	}
+
+	if e = model.Db.Save(&service).Error; e != nil {
+		log.Println("[Database]", "transit maintenance", id, ":", e.Error())
+	}
+
+	return
+}
+
+//TransitionsByService query the lifecycle log of the maintenance service by specific id
+func TransitionsByService(id int64) ([]model.Transition, error) {
+	return model.TransitionsByService(ServiceTableName, id)
+}
+
+//ServicesByState query the maintenance services which are in one of the states
+func ServicesByState(states ...string) (services []Maintenance, e error) {
+	if e = model.Db.Where("state IN (?)", model.QueriedStates(states)).Find(&services).Error; e != nil {
+		log.Println("[Database]", "maintenance by state", e.Error())
+	}
+
+	return
+}
+
+//PageServices return the page of the maintenance services which is queried by q, the services are filtered by the states
+//when there are some
+func PageServices(q model.PageQuery, states ...string) (services []Maintenance, page model.Page, e error) {
+	db := model.Db
+	if len(states) > 0 {
+		db = db.Where("state IN (?)", model.QueriedStates(states))
+	}
+
+	q.RatingTable = ReviewTableName
+	page, e = model.PaginateQuery(db, &services, q)
+	return
+}
+
+//PageServicesByAddress return the page of the maintenance services whose address contain address which is queried by q
+func PageServicesByAddress(address string, q model.PageQuery) (services []Maintenance, page model.Page, e error) {
+	q.RatingTable = ReviewTableName
+	page, e = model.PaginateQuery(model.Db.Where("address LIKE ?", "%"+address+"%"), &services, q)
+	return
+}
+
+//PageServicesInRange return the page of the maintenance services in the radius of a location which is queried by q, the
+//services are filtered by the states when there are some and they are the listed ones otherwise
+func PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) (services []Maintenance, page model.Page, e error) {
+	if len(states) == 0 {
+		states = model.ListedStates
+	}
+
+	db := model.InRange(model.Db.Where("state IN (?)", model.QueriedStates(states)), ServiceTableName, p, max_range)
+	q.RatingTable = ReviewTableName
+	page, e = model.PaginateQuery(db, &services, q)
+	return
+}
+
+//VerifyService record a positive signal like a check-in on the maintenance service by specific id
+func VerifyService(id int64) (service Maintenance, e error) {
+	if service, e = ServiceById(id); e != nil {
+		return
+	}
+
+	service.Verify(time.Now())
+	if e = model.Db.Save(&service).Error; e != nil {
+		log.Println("[Database]", "verify maintenance", id, ":", e.Error())
+	}
+
+	return
+}
+
+//DecayServices evaluate the confirmed maintenance services whose confident is decayed again, the services which
+//drop out of the confirmation policy are disputed
+func DecayServices() (disputed int, e error) {
+	ids, e := model.DecayingIds(ServiceTableName, policy(), time.Now())
+	if e != nil {
+		return
+	}
+
+	for _, id := range ids {
+		s, e := ServiceById(id)
+		if e != nil {
+			continue
+		}
+
+		if e = model.Db.Save(&s).Error; e != nil {
+			log.Println("[Database]", "decay maintenance", id, ":", e.Error())
+		} else if s.State == model.StateDisputed {
+			disputed++
+		}
+	}
+
+	return
+}
+
+//ReverifyQueue query the maintenance services in the radius of a location which should be verified again by the nearby
+//users, the service which is verified longest ago is the first
+func ReverifyQueue(p r2.Point, max_range float64) []Maintenance {
+	return reverifyQueue(ServicesInRange(p, max_range))
+}
+
+func reverifyQueue(services []Maintenance) []Maintenance {
+	var result []Maintenance = []Maintenance{}
+	now := time.Now()
+	for _, s := range services {
+		if model.NeedsReverification(policy(), s.Lifecycle, now) {
+			result = append(result, s)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return model.SignalBefore(result[i].Lifecycle, result[j].Lifecycle) })
+	return result
+}
+
+//ReportService file the problem report on the maintenance service by specific id, the service is changed when enough
+//reporters concur on the kind of the report, see model.ReportTransition
+func ReportService(id int64, r model.Report) (report model.Report, e error) {
+	s, e := ServiceById(id)
+	if e != nil {
+		return
+	}
+
+	if r.Kind == model.ReportDuplicate {
+		if _, e = ServiceById(r.DuplicateOf); e != nil {
+			return
+		}
+	}
+
+	r.ServiceType = ServiceTableName
+	r.ServiceId = id
+	report, concurring, e := model.FileReport(r)
+	if e != nil {
+		return
+	}
+
+	//the reports are queued for the moderation until they are accepted, the concurring reporters raise the priority
+	model.Enqueue(model.Db, model.ModerationItem{Kind: model.ModerationReport, ServiceType: ServiceTableName, ServiceId: id, Subject: report.Kind,
+		Author: report.Reporter, Note: report.Note, Lat: s.Lat, Lon: s.Lon, Priority: concurring})
+	if !reported(s, report, concurring) {
+		return
+	}
+
+	if report, e = acceptReport(s, report, model.ActorSystem); e == nil {
+		model.ResolveQueued(model.Db, model.ModerationReport, ServiceTableName, id, report.Kind, true, model.ActorSystem, "concurring reports reach the policy")
+	}
+
+	return
+}
+
+//reported determine whether the concurring reports of the kind of r change the state of s, the services which
+//cannot be changed to the state of the report are left for the moderation
+func reported(s Maintenance, r model.Report, concurring int) bool {
+	p := policy()
+	to, _ := model.ReportTransition(r)
+	return p.ReportThreshold > 0 && concurring >= p.ReportThreshold && s.GetState() != to && model.CanTransit(s.GetState(), to)
 }
 
-func DownvoteService(id int64) error {
-	return upvoteService(id, -1)
+//ReportsByService query the problem reports on the maintenance service by specific id
+func ReportsByService(id int64) ([]model.Report, error) {
+	return model.ReportsByService(ServiceTableName, id)
 }
 
-func UpvoteServiceImmediately(id int64) error {
-	return upvoteService(id, confident)
+//ReportsByServices query the reports on the services by specific ids, the newest is the first
+func ReportsByServices(ids ...int64) ([]model.Report, error) {
+	return model.ReportsByServices(ServiceTableName, ids...)
 }
 
-func upvoteService(id int64, value int) (e error) {
+//AcceptReport change the maintenance service by specific id like the concurring reports of kind do, the open reports
+//of kind on the service are accepted by actor
+func AcceptReport(id int64, kind string, actor string) (report model.Report, e error) {
 	s, e := ServiceById(id)
+	if e != nil {
+		return
+	}
+
+	if report, e = model.OpenReport(ServiceTableName, id, kind); e != nil {
+		return
+	}
+
+	return acceptReport(s, report, actor)
+}
+
+func acceptReport(s Maintenance, report model.Report, actor string) (model.Report, error) {
+	to, reason := model.ReportTransition(report)
+	if _, e := TransitService(s.Id, to, actor, reason); e != nil {
+		return report, e
+	}
+
+	model.ResolveReports(model.Db, ServiceTableName, s.Id, report.Kind, model.ReportAccepted, actor)
+	if report.Kind == model.ReportDuplicate {
+		model.ChangeReputation(model.Db, s.Contributor, model.ReasonDuplicate, ServiceTableName, s.Id)
+	}
+
+	report.Status = model.ReportAccepted
+	return report, nil
+}
+
+//DismissReports dismiss the open reports of kind on the maintenance service by specific id by actor
+func DismissReports(id int64, kind string, actor string) (e error) {
+	if _, e = ServiceById(id); e != nil {
+		return
+	}
+
+	return model.ResolveReports(model.Db, ServiceTableName, id, kind, model.ReportDismissed, actor)
+}
 
+//ArchiveStale archive the pending maintenance services and unconfirmed maintenance which are not voted in the max age of the policy
+func ArchiveStale() (archived int, e error) {
+	reason := model.StaleReason(policy())
+	ids, e := model.StaleIds(ServiceTableName, policy(), time.Now())
 	if e != nil {
-		return e
+		return
+	}
+
+	for _, id := range ids {
+		if _, e := TransitService(id, model.StateArchived, model.ActorSystem, reason); e == nil {
+			archived++
+		}
 	}
 
-	s.Confident += value
-	if e := model.Db.Save(&s).Error; e != nil {
-		log.Println("[Database]", "upvote unconfirmed maintenance", id, ":", e.Error())
+	if ids, e = model.StaleIds(UcfServiceTableName, policy(), time.Now()); e != nil {
+		return
+	}
+
+	for _, id := range ids {
+		if e := transitUcf(id, model.StateArchived, model.ActorSystem, reason); e == nil {
+			archived++
+		}
 	}
 
 	return
 }
 
+func voteService(id int64, v model.Vote) error {
+	var s Maintenance
+	return model.CastVote(id, v, &s)
+}
+
 func (m *Maintenance) AddMaintainer(maintainer string) (e error) {
 	ms := m.GetMaintainers()
 	if _, ok := ms[maintainer]; ok {
 		log.Println("[Maintenance]", "Add", maintainer, "is already work for", m.Name)
-		return errors.New("maintainer is already exist")
+		return model.NewError(model.ErrConflict, "maintainer is already exist")
 	} else {
 		ms[maintainer] = "Employee"
 	}
@@ -114,7 +357,7 @@ func (m *Maintenance) RemoveMaintainer(maintainer string) (e error) {
 	_, ok := ms[maintainer]
 	if !ok {
 		log.Println("[Maintenance]", "Remove", maintainer, "is not work for", m.Name)
-		return errors.New("maintainer is not exist")
+		return model.NewError(model.ErrNotFound, "maintainer is not exist")
 	}
 
 	delete(ms, maintainer)
@@ -141,6 +384,16 @@ func (m Maintenance) GetMaintainers() (maintainer map[string]string) {
 	return maintainer
 }
 
+//Owners return the users who can edit the service, its contributor and its maintainers
+func (m Maintenance) Owners() (owners []string) {
+	owners = append(owners, m.Contributor)
+	for maintainer := range m.GetMaintainers() {
+		owners = append(owners, maintainer)
+	}
+
+	return
+}
+
 func queryMaintenance(s Maintenance) (service Maintenance, e error) {
 	service = s
 
@@ -160,7 +413,7 @@ func ServiceById(id int64) (service Maintenance, e error) {
 	// }
 
 	// if db.RowsAffected == 0 {
-	// 	e = errors.New("Ucf Maintenance service was not found")
+	// 	e = model.NewError(model.ErrNotFound, "Ucf Maintenance service was not found")
 	// 	log.Println("[Database]", "Maintenance ucf", e.Error())
 	// }
 
@@ -190,13 +443,21 @@ func ServiceByService(s model.Service) (services Maintenance, e error) {
 
 //ServicesByIds query the maintenances service by specific id
 func ServicesByIds(ids ...int64) (services []Maintenance) {
+	var found []Maintenance
+	if e := model.Db.Where("id IN (?)", ids).Find(&found).Error; e != nil {
+		log.Println("[Database]", "maintenance by ids", e.Error())
+	}
+
+	//the services are returned in the order of ids like they are queried one by one
+	byId := make(map[int64]Maintenance)
+	for _, s := range found {
+		byId[s.Id] = s
+	}
+
 	for _, id := range ids {
-		s, e := ServiceById(id)
-		if e != nil {
-			continue
+		if s, ok := byId[id]; ok {
+			services = append(services, s)
 		}
-
-		services = append(services, s)
 	}
 
 	return
@@ -227,42 +488,46 @@ func ServicesInRange(p r2.Point, max_range float64) []Maintenance {
 	return result
 }
 
-func UpdateService(id int64, values url.Values) (service Maintenance, e error) {
-	service, e = ServiceById(id)
-	if e != nil {
-		return
-	}
-
+//setValues update the fields of service by the provided values, unknown or malformed values are ignored
+func (s *Maintenance) setValues(values url.Values) {
 	_, ok := values["lat"]
 	if ok {
-		if lat, e := strconv.ParseFloat(values["lat"][0], 64); e != nil {
-			service.Lat = float32(lat)
+		if lat, e := strconv.ParseFloat(values["lat"][0], 64); e == nil {
+			s.Lat = float32(lat)
 		}
 	}
 	_, ok = values["lon"]
 	if ok {
-		if lon, e := strconv.ParseFloat(values["lon"][0], 64); e != nil {
-			service.Lon = float32(lon)
+		if lon, e := strconv.ParseFloat(values["lon"][0], 64); e == nil {
+			s.Lon = float32(lon)
 		}
 	}
 
 	_, ok = values["note"]
 	if ok {
-		service.Note = values["note"][0]
+		s.Note = values["note"][0]
 	}
 
 	if _, ok = values["address"]; ok {
-		service.Address = values["address"][0]
+		s.Address = values["address"][0]
 	}
 
 	if _, ok = values["images"]; ok {
-		service.SetImages(values["images"]...)
+		s.SetImages(values["images"]...)
 	}
 
 	if _, ok = values["name"]; ok {
-		service.Name = values["name"][0]
+		s.Name = values["name"][0]
 	}
+}
 
+func UpdateService(id int64, values url.Values) (service Maintenance, e error) {
+	service, e = ServiceById(id)
+	if e != nil {
+		return
+	}
+
+	service.setValues(values)
 	if e := model.Db.Save(&service).Error; e != nil {
 		log.Println("[Database]", "update ", ServiceTableName, e.Error())
 	}
@@ -296,6 +561,15 @@ func Import(bytes []byte, t string) (e error) {
 }
 
 func ImportByRawText(data string) (e error) {
+	for _, s := range parseRawText(data) {
+		CreateService(s)
+	}
+
+	return
+}
+
+//parseRawText parse the services from the raw text, each line is a service with fields separated by ";"
+func parseRawText(data string) (services []Maintenance) {
 	lines := strings.Split(data, "\n")
 	for _, line := range lines {
 		fields := strings.Split(line, ";")
@@ -334,8 +608,8 @@ func ImportByRawText(data string) (e error) {
 			s.Note = note
 		}
 		s.Contributor = "Streetlity"
-		s.Confident = confident + 1
-		CreateService(s)
+		s.Confident = policy().Threshold
+		services = append(services, s)
 	}
 
 	return
@@ -356,26 +630,58 @@ func RemoveMaintainer(id int64, maintainer string) (service Maintenance, e error
 	return
 }
 
+//BeforeSave change the state of the service which is driven by the votes, see model.Lifecycle. The service which
+//isn't confirmed is rejected when it is matching the rejection rules of the policy, see model.Rejection
+func (s *Maintenance) BeforeSave(scope *gorm.Scope) (e error) {
+	db := scope.NewDB()
+	voters := model.CountVoters(db, ServiceTableName, s.Id)
+	decayed := model.Decayed(policy(), s.Confident, s.Lifecycle, time.Now())
+	confirmed := model.Confirmed(policy(), s.Contributor, decayed, model.ReputationOf(db, s.Contributor), voters)
+	if !confirmed {
+		s.Reject(model.Rejection(policy(), s.Confident, model.IsSpammer(db, s.Contributor)))
+	}
+
+	s.Evaluate(confirmed)
+	return
+}
+
 func (s *Maintenance) AfterSave(scope *gorm.Scope) (e error) {
-	if s.Confident > confident {
+	db := scope.NewDB()
+	model.Settle(db, policy(), s.Contributor, s.Transited(), ServiceTableName, s.Id)
+	model.QueueSubmission(db, ServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
+	s.LogTransition(db, ServiceTableName, s.Id)
+	place(*s)
+	return
+}
+
+//place put the service into the index of its state, the services which are not listed or unconfirmed
+//are taken out of the indexes
+func place(s Maintenance) {
+	state := s.GetState()
+	if _, ok := map_services[s.Id]; ok && !model.IsListed(state) {
+		services.RemoveItem(s)
+		delete(map_services, s.Id)
+	}
+
+	if _, ok := map_ucfservices[s.Id]; ok && !model.IsUnconfirmed(state) {
+		ucf_services.RemoveItem(s)
+		delete(map_ucfservices, s.Id)
+	}
+
+	switch {
+	case model.IsListed(state):
 		if _, ok := map_services[s.Id]; !ok {
-			ucf_services.RemoveItem(s)
-			if e = services.AddItem(*s); e != nil {
+			if e := services.AddItem(s); e != nil {
 				log.Println("[Database]", "maintenance offical", e.Error())
 			}
-			delete(map_ucfservices, s.Id)
 		}
-		map_services[s.Id] = *s
-	} else {
+		map_services[s.Id] = s
+	case model.IsUnconfirmed(state):
 		if _, ok := map_ucfservices[s.Id]; !ok {
-			services.RemoveItem(s)
-			map_ucfservices[s.Id] = *s
-			delete(map_services, s.Id)
+			ucf_services.AddItem(s)
 		}
-		ucf_services.AddItem(*s)
+		map_ucfservices[s.Id] = s
 	}
-
-	return
 }
 
 // func (s Maintenance) AfterCreate(scope *gorm.Scope) (e error) {
@@ -394,19 +700,20 @@ func LoadService() {
 	map_services = make(map[int64]Maintenance)
 	map_ucfservices = make(map[int64]Maintenance)
 	ss, _ := AllServices()
+	reputations := model.AllReputations()
+	voters := model.AllVoters(ServiceTableName)
 	for _, s := range ss {
-		if s.Confident > confident {
-			services.AddItem(s)
-			map_services[s.Id] = s
-		} else {
-			ucf_services.AddItem(s)
-			map_ucfservices[s.Id] = s
+		//the services which are created before the lifecycle don't have a state
+		if s.State == "" && model.Confirmed(policy(), s.Contributor, s.Confident, reputations[s.Contributor], voters[s.Id]) {
+			s.State = model.StateConfirmed
 		}
 
+		place(s)
 	}
 }
 
 func init() {
+	model.AutoMigrate(&Maintenance{}, &MaintenanceUcf{}, &Review{}, &MaintenanceHistory{})
 	model.OnConnected.Subscribe(LoadService)
 	model.OnDisconnect.Subscribe(func() {
 		model.OnConnected.Unsubscribe(LoadService)
@@ -1,8 +1,8 @@
 package maintenance
 
 import (
-	"errors"
 	"log"
+	"streelity/v1/config"
 	"streelity/v1/model"
 
 	"github.com/golang/geo/r2"
@@ -10,8 +10,7 @@ import (
 	"github.com/nvnamsss/goinf/spatial"
 )
 
-var confident int = 5
-var map_ucfservices map[int64]Maintenance
+var map_ucfservices map[int64]Maintenance = make(map[int64]Maintenance)
 var ucf_services spatial.RTree
 
 type MaintenanceUcf struct {
@@ -21,6 +20,11 @@ type MaintenanceUcf struct {
 
 const UcfServiceTableName = "maintenance_ucf"
 
+//policy return the confirmation policy of the maintenance services
+func policy() config.Policy {
+	return config.PolicyOf(ServiceTableName)
+}
+
 func (MaintenanceUcf) TableName() string {
 	return UcfServiceTableName
 }
@@ -40,30 +44,27 @@ func AllUcfs() []MaintenanceUcf {
 	return services
 }
 
-//UpvoteUcf upvote the unconfirmed maintainer by specific id
-func UpvoteUcf(id int64) (e error) {
-	return upvoteMaintenanceUcf(id, 1)
+//PageUcfs return the page of the unconfirmed maintenance services which is queried by q
+func PageUcfs(q model.PageQuery) (ucfs []MaintenanceUcf, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.Db, &ucfs, q)
+	return
 }
 
-//UpvoteMaintenanceUcfById upvote the unconfirmed maintainer by specific id
-//with out caring about the remaining confident
-func UpvoteUcfImmediately(id int64) (e error) {
-	return upvoteMaintenanceUcf(id, confident)
+//PageUcfsByAddress return the page of the unconfirmed maintenance services whose address contain address which is queried
+//by q
+func PageUcfsByAddress(address string, q model.PageQuery) (ucfs []MaintenanceUcf, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.Db.Where("address LIKE ?", "%"+address+"%"), &ucfs, q)
+	return
 }
 
-func upvoteMaintenanceUcf(id int64, value int) (e error) {
-	s, e := UcfById(id)
-
-	if e != nil {
-		return e
-	}
-
-	s.Confident += value
-	if e = model.Db.Save(&s).Error; e != nil {
-		log.Println("[Database]", "Upvote maintenance service", id, ":", e.Error())
-	}
+//UpvoteUcf record the upvote of voter on the unconfirmed maintenance by specific id
+func UpvoteUcf(id int64, voter string) error {
+	return voteUcf(id, model.Vote{Voter: voter, Direction: model.VoteUp, Trusted: policy().IsTrusted(voter)})
+}
 
-	return
+func voteUcf(id int64, v model.Vote) error {
+	var s MaintenanceUcf
+	return model.CastVote(id, v, &s)
 }
 
 //CreateUcf add new unconfirmed maintainer service to the database
@@ -71,11 +72,11 @@ func upvoteMaintenanceUcf(id int64, value int) (e error) {
 //return error if there is something wrong when doing transaction
 func CreateUcf(s MaintenanceUcf) (ucf MaintenanceUcf, e error) {
 	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&Maintenance{}).Error; e == nil {
-		return ucf, errors.New("The service location is existed")
+		return ucf, model.NewError(model.ErrConflict, "The service location is existed")
 	}
 
 	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&MaintenanceUcf{}).Error; e == nil {
-		return ucf, errors.New("The service location is existed or some problems is occured")
+		return ucf, model.NewError(model.ErrConflict, "The service location is existed or some problems is occured")
 	}
 
 	if e = model.Db.Create(&s).Error; e != nil {
@@ -89,11 +90,11 @@ func CreateUcf(s MaintenanceUcf) (ucf MaintenanceUcf, e error) {
 
 func CreateUcfAlt(s MaintenanceUcf) (ucf MaintenanceUcf, e error) {
 	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&Maintenance{}).Error; e == nil {
-		return ucf, errors.New("The service location is existed")
+		return ucf, model.NewError(model.ErrConflict, "The service location is existed")
 	}
 
 	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&MaintenanceUcf{}).Error; e == nil {
-		return ucf, errors.New("The service location is existed or some problems is occured")
+		return ucf, model.NewError(model.ErrConflict, "The service location is existed or some problems is occured")
 	}
 
 	if e = model.Db.Create(&s).Error; e != nil {
@@ -132,7 +133,7 @@ func UcfById(id int64) (service MaintenanceUcf, e error) {
 	}
 
 	if db.RowsAffected == 0 {
-		e = errors.New("Ucf Maintenance service was not found")
+		e = model.NewError(model.ErrNotFound, "Ucf Maintenance service was not found")
 		log.Println("[Database]", "Maintenance ucf", e.Error())
 	}
 
@@ -154,43 +155,150 @@ func UcfsByAddress(address string) (services []MaintenanceUcf, e error) {
 	return
 }
 
-func DeleteUcf(id int64) (e error) {
-	var ucf MaintenanceUcf
-	ucf.Id = id
-	if e := model.Db.Delete(&ucf).Error; e != nil {
-		log.Println("[Database]", "delete ucf fuel", e.Error())
+//DeleteUcf reject the unconfirmed maintenance by specific id, the row is removed and the rejection is logged with actor
+func DeleteUcf(id int64, actor string) (e error) {
+	ucf, e := UcfById(id)
+	if e != nil {
+		return
+	}
+
+	if e = ucf.Transit(model.StateRejected, actor, "unconfirmed service is deleted"); e != nil {
+		return
+	}
+
+	if e = model.Db.Delete(&ucf).Error; e != nil {
+		log.Println("[Database]", "delete ucf maintenance", e.Error())
+		return
+	}
+
+	//the unconfirmed service is rejected, its contributor lose the reputation and is notified
+	model.Settle(model.Db, policy(), ucf.Contributor, ucf.Transited(), UcfServiceTableName, id)
+	model.QueueSubmission(model.Db, UcfServiceTableName, id, ucf.Lifecycle, ucf.Transited(), ucf.Contributor, ucf.Location())
+	ucf.LogTransition(model.Db, UcfServiceTableName, id)
+	return
+}
+
+//transitUcf change the state of the unconfirmed maintenance by specific id, the transition is logged with actor and reason
+func transitUcf(id int64, to, actor, reason string) (e error) {
+	ucf, e := UcfById(id)
+	if e != nil {
+		return
+	}
+
+	if e = ucf.Transit(to, actor, reason); e != nil {
+		return
+	}
+
+	if e = model.Db.Save(&ucf).Error; e != nil {
+		log.Println("[Database]", "transit ucf maintenance", id, ":", e.Error())
 	}
 
 	return
 }
 
-//UcfInRange query the unconfirmed fuel services that are in the radius of a location
+//TransitUcf change the state of the unconfirmed maintenance by specific id by actor, the confirmed one is moved to the
+//services like the confirmation policy does
+func TransitUcf(id int64, to, actor, reason string) error {
+	if to == model.StateConfirmed {
+		return confirmUcf(id, actor, reason)
+	}
+
+	return transitUcf(id, to, actor, reason)
+}
+
+func confirmUcf(id int64, actor, reason string) (e error) {
+	ucf, e := UcfById(id)
+	if e != nil {
+		return
+	}
+
+	if e = ucf.Transit(model.StateConfirmed, actor, reason); e != nil {
+		return
+	}
+
+	var s Maintenance = Maintenance{Service: ucf.GetService(), Name: ucf.Name}
+	s.Transit(model.StateConfirmed, actor, reason)
+	//the service is created and the unconfirmed one is removed together, a failure leave the unconfirmed one as is
+	e = model.Db.Transaction(func(tx *gorm.DB) error {
+		service, e := createService(tx, s)
+		if e != nil {
+			return e
+		}
+
+		if e := model.MoveVotes(tx, UcfServiceTableName, id, ServiceTableName, service.Id); e != nil {
+			return e
+		}
+
+		if e := tx.Delete(&ucf).Error; e != nil {
+			log.Println("[Database]", "confirm ucf maintenance", e.Error())
+			return e
+		}
+
+		model.QueueSubmission(tx, UcfServiceTableName, id, ucf.Lifecycle, ucf.Transited(), ucf.Contributor, ucf.Location())
+		return ucf.LogTransition(tx, UcfServiceTableName, id)
+	})
+
+	return
+}
+
+//BeforeSave reject the unconfirmed service which is matching the rejection rules of the policy, see model.Rejection
+func (s *MaintenanceUcf) BeforeSave(scope *gorm.Scope) (e error) {
+	db := scope.NewDB()
+	voters := model.CountVoters(db, UcfServiceTableName, s.Id)
+	if !model.Confirmed(policy(), s.Contributor, s.Confident, model.ReputationOf(db, s.Contributor), voters) {
+		s.Reject(model.Rejection(policy(), s.Confident, model.IsSpammer(db, s.Contributor)))
+	}
+
+	return
+}
+
+//UcfInRange query the unconfirmed maintenance services that are in the radius of a location, they are the pending services
+//and the submissions which are waiting for their confirmation. Both are matched by the bounding box of the radius
 func UcfInRange(p r2.Point, max_range float64) []Maintenance {
+	pending := model.QueriedStates([]string{model.StatePending})
 	var result []Maintenance = []Maintenance{}
-	trees := ucf_services.InRange(p, max_range)
+	if e := model.InRange(model.Db.Where("state IN (?)", pending), ServiceTableName, p, max_range).Order("id").Find(&result).Error; e != nil {
+		log.Println("[Database]", "pending maintenance in range", e.Error())
+	}
 
-	for _, tree := range trees {
-		for _, item := range tree.Items {
-			location := item.Location()
+	var ucfs []MaintenanceUcf
+	if e := model.InRange(model.Db.Where("state IN (?)", pending), UcfServiceTableName, p, max_range).Order("id").Find(&ucfs).Error; e != nil {
+		log.Println("[Database]", "unconfirmed maintenance in range", e.Error())
+	}
 
-			d := distance(location, p)
-			s, isService := item.(Maintenance)
-			if isService && d < max_range {
-				result = append(result, map_ucfservices[s.Id])
-			}
-		}
+	for _, ucf := range ucfs {
+		result = append(result, Maintenance{Service: ucf.Listed(), Name: ucf.Name})
 	}
+
 	return result
 }
 
 func (s *MaintenanceUcf) AfterSave(scope *gorm.Scope) (err error) {
-	if s.Confident >= confident {
+	db := scope.NewDB()
+	if !model.IsUnconfirmed(s.GetState()) {
+		//the rejected and archived services are taken out of the index
+		ucf_services.RemoveItem(s)
+		model.Settle(db, policy(), s.Contributor, s.Transited(), UcfServiceTableName, s.Id)
+		model.QueueSubmission(db, UcfServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
+		s.LogTransition(db, UcfServiceTableName, s.Id)
+		return
+	}
+
+	voters := model.CountVoters(db, UcfServiceTableName, s.Id)
+	if model.Confirmed(policy(), s.Contributor, s.Confident, model.ReputationOf(db, s.Contributor), voters) {
 		var m Maintenance = Maintenance{Service: s.GetService(), Name: s.Name}
-		CreateService(m)
+		if service, e := createService(db, m); e == nil {
+			//the voters of the unconfirmed service keep their votes on the service
+			model.MoveVotes(db, UcfServiceTableName, s.Id, ServiceTableName, service.Id)
+		}
+		s.Transit(model.StateConfirmed, model.ActorSystem, "unconfirmed service is moved to the services")
+		model.QueueSubmission(db, UcfServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
+		s.LogTransition(db, UcfServiceTableName, s.Id)
 		scope.DB().Delete(s)
 		log.Println("[Unconfirmed Maintenance]", "Confident is enough. Added", m)
 	} else {
 		ucf_services.AddItem(s)
+		model.QueueSubmission(db, UcfServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
 	}
 
 	return
@@ -0,0 +1,415 @@
+package maintenance
+
+import (
+	"net/url"
+	"sort"
+	"streelity/v1/model"
+	"sync"
+
+	"github.com/golang/geo/r2"
+	"github.com/jinzhu/gorm"
+)
+
+//Memory is the in-memory Repository, it is using for running the handlers without a database
+type Memory struct {
+	store   *model.MemoryStore
+	mutex   sync.Mutex
+	history map[int64]MaintenanceHistory
+}
+
+//NewMemory create an empty in-memory Repository
+func NewMemory() *Memory {
+	return &Memory{history: make(map[int64]MaintenanceHistory), store: model.NewMemoryStore(model.MemoryKind{
+		ServiceTable: ServiceTableName,
+		UcfTable:     UcfServiceTableName,
+		Set:          func(s model.Stored, values url.Values) { s.(*Maintenance).setValues(values) },
+		Promote: func(ucf model.StoredUcf, base model.Service) model.Stored {
+			return &Maintenance{Service: base, Name: ucf.(*MaintenanceUcf).Name}
+		},
+	})}
+}
+
+func maintenanceOf(s model.Stored) (service Maintenance) {
+	if s != nil {
+		service = *s.(*Maintenance)
+	}
+
+	return
+}
+
+func maintenancesOf(stored []model.Stored) []Maintenance {
+	services := make([]Maintenance, len(stored))
+	for i, s := range stored {
+		services[i] = maintenanceOf(s)
+	}
+
+	return services
+}
+
+func ucfOf(s model.StoredUcf) (ucf MaintenanceUcf) {
+	if s != nil {
+		ucf = *s.(*MaintenanceUcf)
+	}
+
+	return
+}
+
+//UseReputations set the reputations which scale the votes like the database, see model.MemoryStore.UseReputations
+func (m *Memory) UseReputations(r model.ReputationRepository) {
+	m.store.UseReputations(r)
+}
+
+func (m *Memory) AllServices() ([]Maintenance, error) {
+	return maintenancesOf(m.store.All()), nil
+}
+
+func (m *Memory) PageServices(q model.PageQuery, states ...string) (services []Maintenance, page model.Page, e error) {
+	if len(states) > 0 {
+		services = maintenancesOf(m.store.ByState(states...))
+	} else {
+		services = maintenancesOf(m.store.All())
+	}
+
+	q.Ratings = m.store.ReviewAverageScores
+	page, e = model.Paginate(&services, q)
+	return
+}
+
+func (m *Memory) ServiceById(id int64) (Maintenance, error) {
+	s, e := m.store.ById(id)
+	return maintenanceOf(s), e
+}
+
+func (m *Memory) ServiceByLocation(lat, lon float64) (Maintenance, error) {
+	s, e := m.store.ByLocation(lat, lon)
+	return maintenanceOf(s), e
+}
+
+func (m *Memory) ServiceByAddress(address string) (service Maintenance, e error) {
+	services, e := m.ServicesByAddress(address)
+	if e != nil {
+		return
+	}
+
+	return services[0], nil
+}
+
+func (m *Memory) ServicesByAddress(address string) ([]Maintenance, error) {
+	services, e := m.store.ByAddress(address)
+	return maintenancesOf(services), e
+}
+
+func (m *Memory) PageServicesByAddress(address string, q model.PageQuery) (services []Maintenance, page model.Page, e error) {
+	//no services are an empty page instead of an error
+	stored, _ := m.store.ByAddress(address)
+	services = maintenancesOf(stored)
+	q.Ratings = m.store.ReviewAverageScores
+	page, e = model.Paginate(&services, q)
+	return
+}
+
+func (m *Memory) ServicesByIds(ids ...int64) []Maintenance {
+	return maintenancesOf(m.store.ByIds(ids...))
+}
+
+func (m *Memory) ServicesInRange(p r2.Point, max_range float64) []Maintenance {
+	return maintenancesOf(m.store.InRange(p, max_range))
+}
+
+func (m *Memory) PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) (services []Maintenance, page model.Page, e error) {
+	if len(states) == 0 {
+		states = model.ListedStates
+	}
+
+	services = maintenancesOf(m.store.InRangeByState(p, max_range, states...))
+	q.Ratings = m.store.ReviewAverageScores
+	page, e = model.Paginate(&services, q)
+	return
+}
+
+func (m *Memory) CreateService(s Maintenance) (Maintenance, error) {
+	service, e := m.store.Create(&s)
+	if e != nil {
+		return s, e
+	}
+
+	return maintenanceOf(service), nil
+}
+
+func (m *Memory) UpdateService(id int64, values url.Values) (Maintenance, error) {
+	s, e := m.store.Set(id, values)
+	return maintenanceOf(s), e
+}
+
+func (m *Memory) UpvoteService(id int64, voter string) error {
+	return m.store.Vote(id, voter, model.VoteUp)
+}
+
+func (m *Memory) DownvoteService(id int64, voter string) error {
+	return m.store.Vote(id, voter, model.VoteDown)
+}
+
+func (m *Memory) WithdrawVote(id int64, voter string) error {
+	return m.store.Vote(id, voter, model.VoteWithdraw)
+}
+
+func (m *Memory) VotesByService(id int64) ([]model.Vote, error) {
+	return m.store.Votes(id)
+}
+
+func (m *Memory) TransitService(id int64, to, actor, reason string) (Maintenance, error) {
+	s, e := m.store.Update(id, func(s model.Stored) error { return s.Base().Transit(to, actor, reason) })
+	return maintenanceOf(s), e
+}
+
+func (m *Memory) TransitionsByService(id int64) ([]model.Transition, error) {
+	return m.store.Transitions(id), nil
+}
+
+func (m *Memory) ServicesByState(states ...string) ([]Maintenance, error) {
+	return maintenancesOf(m.store.ByState(states...)), nil
+}
+
+func (m *Memory) ArchiveStale() (int, error) {
+	return m.store.ArchiveStale(), nil
+}
+
+func (m *Memory) VerifyService(id int64) (Maintenance, error) {
+	s, e := m.store.Verify(id)
+	return maintenanceOf(s), e
+}
+
+func (m *Memory) DecayServices() (int, error) {
+	return m.store.Decay(), nil
+}
+
+func (m *Memory) ReverifyQueue(p r2.Point, max_range float64) []Maintenance {
+	return maintenancesOf(m.store.ReverifyQueue(p, max_range))
+}
+
+func (m *Memory) ReportService(id int64, r model.Report) (model.Report, error) {
+	return m.store.Report(id, r)
+}
+
+func (m *Memory) ReportsByService(id int64) ([]model.Report, error) {
+	return m.store.Reports(id)
+}
+
+func (m *Memory) ReportsByServices(ids ...int64) ([]model.Report, error) {
+	return m.store.ReportsByServices(ids...), nil
+}
+
+func (m *Memory) AcceptReport(id int64, kind string, actor string) (model.Report, error) {
+	return m.store.AcceptReport(id, kind, actor)
+}
+
+func (m *Memory) DismissReports(id int64, kind string, actor string) error {
+	return m.store.DismissReports(id, kind, actor)
+}
+
+func (m *Memory) Import(bytes []byte, t string) (e error) {
+	switch t {
+	case "RawText":
+		for _, s := range parseRawText(string(bytes)) {
+			m.CreateService(s)
+		}
+	}
+
+	return
+}
+
+func (m *Memory) AddMaintainer(id int64, maintainer string) (Maintenance, error) {
+	s, e := m.store.Update(id, func(s model.Stored) error { return s.(*Maintenance).AddMaintainer(maintainer) })
+	return maintenanceOf(s), e
+}
+
+func (m *Memory) RemoveMaintainer(id int64, maintainer string) (Maintenance, error) {
+	s, e := m.store.Update(id, func(s model.Stored) error { return s.(*Maintenance).RemoveMaintainer(maintainer) })
+	return maintenanceOf(s), e
+}
+
+func (m *Memory) AllUcfs() []MaintenanceUcf {
+	stored := m.store.Ucfs()
+	ucfs := make([]MaintenanceUcf, len(stored))
+	for i, s := range stored {
+		ucfs[i] = ucfOf(s)
+	}
+
+	return ucfs
+}
+
+func (m *Memory) PageUcfs(q model.PageQuery) (ucfs []MaintenanceUcf, page model.Page, e error) {
+	ucfs = m.AllUcfs()
+	page, e = model.Paginate(&ucfs, q)
+	return
+}
+
+func (m *Memory) UcfById(id int64) (MaintenanceUcf, error) {
+	s, e := m.store.UcfById(id)
+	return ucfOf(s), e
+}
+
+func (m *Memory) UcfByLocation(lat, lon float64) (MaintenanceUcf, error) {
+	s, e := m.store.UcfByLocation(lat, lon)
+	return ucfOf(s), e
+}
+
+func (m *Memory) UcfByAddress(address string) (ucf MaintenanceUcf, e error) {
+	ucfs, e := m.UcfsByAddress(address)
+	if e != nil {
+		return
+	}
+
+	return ucfs[0], nil
+}
+
+func (m *Memory) UcfsByAddress(address string) (ucfs []MaintenanceUcf, e error) {
+	stored, e := m.store.UcfsByAddress(address)
+	for _, s := range stored {
+		ucfs = append(ucfs, ucfOf(s))
+	}
+
+	return
+}
+
+func (m *Memory) PageUcfsByAddress(address string, q model.PageQuery) (ucfs []MaintenanceUcf, page model.Page, e error) {
+	ucfs, _ = m.UcfsByAddress(address)
+	page, e = model.Paginate(&ucfs, q)
+	return
+}
+
+func (m *Memory) UcfInRange(p r2.Point, max_range float64) []Maintenance {
+	return maintenancesOf(m.store.UcfInRange(p, max_range))
+}
+
+func (m *Memory) CreateUcf(s MaintenanceUcf) (MaintenanceUcf, error) {
+	ucf, e := m.store.CreateUcf(&s)
+	return ucfOf(ucf), e
+}
+
+func (m *Memory) DeleteUcf(id int64, actor string) error {
+	return m.store.DeleteUcf(id, actor)
+}
+
+func (m *Memory) TransitUcf(id int64, to, actor, reason string) error {
+	return m.store.TransitUcf(id, to, actor, reason)
+}
+
+func (m *Memory) UpvoteUcf(id int64, voter string) error {
+	return m.store.UpvoteUcf(id, voter)
+}
+
+func (m *Memory) CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error) {
+	return Review{Review: m.store.CreateReview(service_id, reviewer, score, body)}, nil
+}
+
+func (m *Memory) DeleteReview(review_id int64) error {
+	m.store.DeleteReview(review_id)
+	return nil
+}
+
+func (m *Memory) ReviewByService(service_id, order int64, limit int) (reviews []Review, e error) {
+	for _, r := range m.store.Reviews(service_id, order, int64(limit)) {
+		reviews = append(reviews, Review{Review: r})
+	}
+
+	return
+}
+
+func (m *Memory) PageReviews(service_id, order int64, q model.PageQuery) (reviews []Review, page model.Page, e error) {
+	reviews, _ = m.ReviewByService(service_id, order, -1)
+	page, e = model.Paginate(&reviews, q)
+	return
+}
+
+func (m *Memory) ReviewsByServices(ids ...int64) (reviews []Review, e error) {
+	for _, r := range m.store.ReviewsByServices(ids...) {
+		reviews = append(reviews, Review{Review: r})
+	}
+
+	return
+}
+
+func (m *Memory) ReviewById(review_id int64) (Review, error) {
+	r, e := m.store.ReviewById(review_id)
+	return Review{Review: r}, e
+}
+
+func (m *Memory) ReviewAverageScore(service_id int64) float64 {
+	return m.store.ReviewAverageScore(service_id)
+}
+
+func (m *Memory) ReviewAverageScores(ids ...int64) map[int64]float64 {
+	return m.store.ReviewAverageScores(ids...)
+}
+
+func (m *Memory) SaveReview(r Review) error {
+	m.store.SaveReview(r.Review)
+	return nil
+}
+
+func (m *Memory) AddMaintenanceHistory(h MaintenanceHistory) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	h.Id = m.store.NextId()
+	m.history[h.Id] = h
+	return nil
+}
+
+func (m *Memory) RemoveMaintenanceHistoriesById(ids ...int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, id := range ids {
+		delete(m.history, id)
+	}
+
+	return nil
+}
+
+func (m *Memory) historiesBy(match func(h MaintenanceHistory) bool) (histories []MaintenanceHistory) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, h := range m.history {
+		if match(h) {
+			histories = append(histories, h)
+		}
+	}
+
+	sort.Slice(histories, func(i, j int) bool { return histories[i].Id < histories[j].Id })
+	return
+}
+
+func (m *Memory) HistoriesByMUser(maintenance_user string) ([]MaintenanceHistory, error) {
+	return m.historiesBy(func(h MaintenanceHistory) bool { return h.MaintenanceUser == maintenance_user }), nil
+}
+
+func (m *Memory) HistoriesByCUser(common_user string) ([]MaintenanceHistory, error) {
+	return m.historiesBy(func(h MaintenanceHistory) bool { return h.CommonUser == common_user }), nil
+}
+
+func (m *Memory) PageHistoriesByMUser(maintenance_user string, q model.PageQuery) (histories []MaintenanceHistory, page model.Page, e error) {
+	histories, _ = m.HistoriesByMUser(maintenance_user)
+	page, e = model.Paginate(&histories, q)
+	return
+}
+
+func (m *Memory) PageHistoriesByCUser(common_user string, q model.PageQuery) (histories []MaintenanceHistory, page model.Page, e error) {
+	histories, _ = m.HistoriesByCUser(common_user)
+	page, e = model.Paginate(&histories, q)
+	return
+}
+
+func (m *Memory) HistoryById(id int64) (h MaintenanceHistory, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	h, ok := m.history[id]
+	if !ok {
+		e = gorm.ErrRecordNotFound
+	}
+
+	return
+}
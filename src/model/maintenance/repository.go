@@ -0,0 +1,332 @@
+package maintenance
+
+import (
+	"net/url"
+	"streelity/v1/model"
+
+	"github.com/golang/geo/r2"
+)
+
+//ServiceRepository determine the data access of the maintenance services
+type ServiceRepository interface {
+	AllServices() ([]Maintenance, error)
+	PageServices(q model.PageQuery, states ...string) ([]Maintenance, model.Page, error)
+	ServiceById(id int64) (Maintenance, error)
+	ServiceByLocation(lat, lon float64) (Maintenance, error)
+	ServiceByAddress(address string) (Maintenance, error)
+	ServicesByAddress(address string) ([]Maintenance, error)
+	PageServicesByAddress(address string, q model.PageQuery) ([]Maintenance, model.Page, error)
+	ServicesByIds(ids ...int64) []Maintenance
+	ServicesInRange(p r2.Point, max_range float64) []Maintenance
+	PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) ([]Maintenance, model.Page, error)
+	CreateService(s Maintenance) (Maintenance, error)
+	UpdateService(id int64, values url.Values) (Maintenance, error)
+	UpvoteService(id int64, voter string) error
+	DownvoteService(id int64, voter string) error
+	WithdrawVote(id int64, voter string) error
+	VotesByService(id int64) ([]model.Vote, error)
+	TransitService(id int64, to, actor, reason string) (Maintenance, error)
+	TransitionsByService(id int64) ([]model.Transition, error)
+	ServicesByState(states ...string) ([]Maintenance, error)
+	ArchiveStale() (int, error)
+	VerifyService(id int64) (Maintenance, error)
+	DecayServices() (int, error)
+	ReverifyQueue(p r2.Point, max_range float64) []Maintenance
+	ReportService(id int64, r model.Report) (model.Report, error)
+	ReportsByService(id int64) ([]model.Report, error)
+	ReportsByServices(ids ...int64) ([]model.Report, error)
+	AcceptReport(id int64, kind string, actor string) (model.Report, error)
+	DismissReports(id int64, kind string, actor string) error
+	Import(bytes []byte, t string) error
+	AddMaintainer(id int64, maintainer string) (Maintenance, error)
+	RemoveMaintainer(id int64, maintainer string) (Maintenance, error)
+}
+
+//UcfRepository determine the data access of the unconfirmed maintenance services
+type UcfRepository interface {
+	AllUcfs() []MaintenanceUcf
+	PageUcfs(q model.PageQuery) ([]MaintenanceUcf, model.Page, error)
+	UcfById(id int64) (MaintenanceUcf, error)
+	UcfByLocation(lat, lon float64) (MaintenanceUcf, error)
+	UcfByAddress(address string) (MaintenanceUcf, error)
+	UcfsByAddress(address string) ([]MaintenanceUcf, error)
+	PageUcfsByAddress(address string, q model.PageQuery) ([]MaintenanceUcf, model.Page, error)
+	UcfInRange(p r2.Point, max_range float64) []Maintenance
+	CreateUcf(s MaintenanceUcf) (MaintenanceUcf, error)
+	DeleteUcf(id int64, actor string) error
+	TransitUcf(id int64, to, actor, reason string) error
+	UpvoteUcf(id int64, voter string) error
+}
+
+//ReviewRepository determine the data access of the maintenance reviews
+type ReviewRepository interface {
+	CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error)
+	DeleteReview(review_id int64) error
+	ReviewByService(service_id, order int64, limit int) ([]Review, error)
+	PageReviews(service_id, order int64, q model.PageQuery) ([]Review, model.Page, error)
+	ReviewsByServices(ids ...int64) ([]Review, error)
+	ReviewById(review_id int64) (Review, error)
+	ReviewAverageScore(service_id int64) float64
+	ReviewAverageScores(ids ...int64) map[int64]float64
+	SaveReview(r Review) error
+}
+
+//HistoryRepository determine the data access of the maintenance histories
+type HistoryRepository interface {
+	AddMaintenanceHistory(h MaintenanceHistory) error
+	RemoveMaintenanceHistoriesById(ids ...int64) error
+	HistoriesByMUser(maintenance_user string) ([]MaintenanceHistory, error)
+	HistoriesByCUser(common_user string) ([]MaintenanceHistory, error)
+	PageHistoriesByMUser(maintenance_user string, q model.PageQuery) ([]MaintenanceHistory, model.Page, error)
+	PageHistoriesByCUser(common_user string, q model.PageQuery) ([]MaintenanceHistory, model.Page, error)
+	HistoryById(id int64) (MaintenanceHistory, error)
+}
+
+//Repository is the whole data access of the maintenance package which is injected into the router
+type Repository interface {
+	ServiceRepository
+	UcfRepository
+	ReviewRepository
+	model.Moderated
+	HistoryRepository
+}
+
+//Database is the Repository which is stored in model.Db
+type Database struct{}
+
+func (Database) AllServices() ([]Maintenance, error) {
+	return AllServices()
+}
+
+func (Database) PageServices(q model.PageQuery, states ...string) ([]Maintenance, model.Page, error) {
+	return PageServices(q, states...)
+}
+
+func (Database) ServiceById(id int64) (Maintenance, error) {
+	return ServiceById(id)
+}
+
+func (Database) ServiceByLocation(lat, lon float64) (Maintenance, error) {
+	return ServiceByLocation(lat, lon)
+}
+
+func (Database) ServiceByAddress(address string) (Maintenance, error) {
+	return ServiceByAddress(address)
+}
+
+func (Database) ServicesByAddress(address string) ([]Maintenance, error) {
+	return ServicesByAddress(address)
+}
+
+func (Database) PageServicesByAddress(address string, q model.PageQuery) ([]Maintenance, model.Page, error) {
+	return PageServicesByAddress(address, q)
+}
+
+func (Database) ServicesByIds(ids ...int64) []Maintenance {
+	return ServicesByIds(ids...)
+}
+
+func (Database) ServicesInRange(p r2.Point, max_range float64) []Maintenance {
+	return ServicesInRange(p, max_range)
+}
+
+func (Database) PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) ([]Maintenance, model.Page, error) {
+	return PageServicesInRange(p, max_range, q, states...)
+}
+
+func (Database) CreateService(s Maintenance) (Maintenance, error) {
+	return CreateService(s)
+}
+
+func (Database) UpdateService(id int64, values url.Values) (Maintenance, error) {
+	return UpdateService(id, values)
+}
+
+func (Database) UpvoteService(id int64, voter string) error {
+	return UpvoteService(id, voter)
+}
+
+func (Database) DownvoteService(id int64, voter string) error {
+	return DownvoteService(id, voter)
+}
+
+func (Database) WithdrawVote(id int64, voter string) error {
+	return WithdrawVote(id, voter)
+}
+
+func (Database) VotesByService(id int64) ([]model.Vote, error) {
+	return VotesByService(id)
+}
+
+func (Database) TransitService(id int64, to, actor, reason string) (Maintenance, error) {
+	return TransitService(id, to, actor, reason)
+}
+
+func (Database) TransitionsByService(id int64) ([]model.Transition, error) {
+	return TransitionsByService(id)
+}
+
+func (Database) ServicesByState(states ...string) ([]Maintenance, error) {
+	return ServicesByState(states...)
+}
+
+func (Database) ArchiveStale() (int, error) {
+	return ArchiveStale()
+}
+
+func (Database) VerifyService(id int64) (Maintenance, error) {
+	return VerifyService(id)
+}
+
+func (Database) DecayServices() (int, error) {
+	return DecayServices()
+}
+
+func (Database) ReverifyQueue(p r2.Point, max_range float64) []Maintenance {
+	return ReverifyQueue(p, max_range)
+}
+
+func (Database) ReportService(id int64, r model.Report) (model.Report, error) {
+	return ReportService(id, r)
+}
+
+func (Database) ReportsByService(id int64) ([]model.Report, error) {
+	return ReportsByService(id)
+}
+
+func (Database) ReportsByServices(ids ...int64) ([]model.Report, error) {
+	return ReportsByServices(ids...)
+}
+
+func (Database) AcceptReport(id int64, kind string, actor string) (model.Report, error) {
+	return AcceptReport(id, kind, actor)
+}
+
+func (Database) DismissReports(id int64, kind string, actor string) error {
+	return DismissReports(id, kind, actor)
+}
+
+func (Database) Import(bytes []byte, t string) error {
+	return Import(bytes, t)
+}
+
+func (Database) AddMaintainer(id int64, maintainer string) (Maintenance, error) {
+	return AddMaintainer(id, maintainer)
+}
+
+func (Database) RemoveMaintainer(id int64, maintainer string) (Maintenance, error) {
+	return RemoveMaintainer(id, maintainer)
+}
+
+func (Database) AllUcfs() []MaintenanceUcf {
+	return AllUcfs()
+}
+
+func (Database) PageUcfs(q model.PageQuery) ([]MaintenanceUcf, model.Page, error) {
+	return PageUcfs(q)
+}
+
+func (Database) UcfById(id int64) (MaintenanceUcf, error) {
+	return UcfById(id)
+}
+
+func (Database) UcfByLocation(lat, lon float64) (MaintenanceUcf, error) {
+	return UcfByLocation(lat, lon)
+}
+
+func (Database) UcfByAddress(address string) (MaintenanceUcf, error) {
+	return UcfByAddress(address)
+}
+
+func (Database) UcfsByAddress(address string) ([]MaintenanceUcf, error) {
+	return UcfsByAddress(address)
+}
+
+func (Database) PageUcfsByAddress(address string, q model.PageQuery) ([]MaintenanceUcf, model.Page, error) {
+	return PageUcfsByAddress(address, q)
+}
+
+func (Database) UcfInRange(p r2.Point, max_range float64) []Maintenance {
+	return UcfInRange(p, max_range)
+}
+
+func (Database) CreateUcf(s MaintenanceUcf) (MaintenanceUcf, error) {
+	return CreateUcf(s)
+}
+
+func (Database) DeleteUcf(id int64, actor string) error {
+	return DeleteUcf(id, actor)
+}
+
+func (Database) TransitUcf(id int64, to, actor, reason string) error {
+	return TransitUcf(id, to, actor, reason)
+}
+
+func (Database) UpvoteUcf(id int64, voter string) error {
+	return UpvoteUcf(id, voter)
+}
+
+func (Database) CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error) {
+	return CreateReview(service_id, reviewer, score, body)
+}
+
+func (Database) DeleteReview(review_id int64) error {
+	return DeleteReview(review_id)
+}
+
+func (Database) ReviewByService(service_id, order int64, limit int) ([]Review, error) {
+	return ReviewByService(service_id, order, limit)
+}
+
+func (Database) PageReviews(service_id, order int64, q model.PageQuery) ([]Review, model.Page, error) {
+	return PageReviews(service_id, order, q)
+}
+
+func (Database) ReviewsByServices(ids ...int64) ([]Review, error) {
+	return ReviewsByServices(ids...)
+}
+
+func (Database) ReviewById(review_id int64) (Review, error) {
+	return ReviewById(review_id)
+}
+
+func (Database) ReviewAverageScore(service_id int64) float64 {
+	return ReviewAverageScore(service_id)
+}
+
+func (Database) ReviewAverageScores(ids ...int64) map[int64]float64 {
+	return ReviewAverageScores(ids...)
+}
+
+func (Database) SaveReview(r Review) error {
+	r.db = model.Db
+	return r.Save()
+}
+
+func (Database) AddMaintenanceHistory(h MaintenanceHistory) error {
+	return AddMaintenanceHistory(h)
+}
+
+func (Database) RemoveMaintenanceHistoriesById(ids ...int64) error {
+	return RemoveMaintenanceHistoriesById(ids...)
+}
+
+func (Database) HistoriesByMUser(maintenance_user string) ([]MaintenanceHistory, error) {
+	return HistoriesByMUser(maintenance_user)
+}
+
+func (Database) HistoriesByCUser(common_user string) ([]MaintenanceHistory, error) {
+	return HistoriesByCUser(common_user)
+}
+
+func (Database) PageHistoriesByMUser(maintenance_user string, q model.PageQuery) ([]MaintenanceHistory, model.Page, error) {
+	return PageHistoriesByMUser(maintenance_user, q)
+}
+
+func (Database) PageHistoriesByCUser(common_user string, q model.PageQuery) ([]MaintenanceHistory, model.Page, error) {
+	return PageHistoriesByCUser(common_user, q)
+}
+
+func (Database) HistoryById(id int64) (MaintenanceHistory, error) {
+	return HistoryById(id)
+}
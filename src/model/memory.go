@@ -0,0 +1,908 @@
+package model
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"streelity/v1/config"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r2"
+	"github.com/jinzhu/gorm"
+)
+
+//Stored is a service which is kept by a MemoryStore, the pointers of the service types like *fuel.Fuel are Stored
+//because they embed Service
+type Stored interface {
+	Base() *Service
+}
+
+//StoredUcf is an unconfirmed service which is kept by a MemoryStore, the pointers of the unconfirmed service types
+//are StoredUcf because they embed ServiceUcf
+type StoredUcf interface {
+	BaseUcf() *ServiceUcf
+}
+
+func (s *Service) Base() *Service {
+	return s
+}
+
+func (s *ServiceUcf) BaseUcf() *ServiceUcf {
+	return s
+}
+
+//Listed return the unconfirmed service as a service, the id, the confident and the lifecycle are kept so it can be
+//listed with the services which are waiting for their confirmation
+func (s ServiceUcf) Listed() (service Service) {
+	service = s.GetService()
+	service.Id = s.Id
+	service.Confident = s.Confident
+	service.Lifecycle = s.Lifecycle
+	return
+}
+
+//MemoryKind is the part of a MemoryStore which depends on the service type
+type MemoryKind struct {
+	ServiceTable string
+	UcfTable     string
+	//Set change the fields of the service by the values of an update
+	Set func(s Stored, values url.Values)
+	//Promote create the service of the unconfirmed service, base is the service which is copied from ucf
+	Promote func(ucf StoredUcf, base Service) Stored
+}
+
+//MemoryStore is the in-memory data access of a service type, it mirrors the hooks of the database so the in-memory
+//repositories of the service types behave like their databases. The services are copied in and out, the callers
+//never share the stored services
+type MemoryStore struct {
+	kind        MemoryKind
+	mutex       sync.Mutex
+	lastId      int64
+	services    map[int64]Stored
+	ucfs        map[int64]StoredUcf
+	reviews     map[int64]Review
+	votes       VoteBook
+	transitions []Transition
+	reports     ReportBook
+}
+
+//NewMemoryStore create an empty MemoryStore of the service type
+func NewMemoryStore(kind MemoryKind) *MemoryStore {
+	return &MemoryStore{
+		kind:     kind,
+		services: make(map[int64]Stored),
+		ucfs:     make(map[int64]StoredUcf),
+		reviews:  make(map[int64]Review),
+	}
+}
+
+//clone copy the service which v is pointing to
+func clone(v interface{}) interface{} {
+	copied := reflect.New(reflect.TypeOf(v).Elem())
+	copied.Elem().Set(reflect.ValueOf(v).Elem())
+	return copied.Interface()
+}
+
+func cloneService(s Stored) Stored {
+	return clone(s).(Stored)
+}
+
+func cloneUcf(s StoredUcf) StoredUcf {
+	return clone(s).(StoredUcf)
+}
+
+func cloneServices(services []Stored) []Stored {
+	copied := make([]Stored, len(services))
+	for i, s := range services {
+		copied[i] = cloneService(s)
+	}
+
+	return copied
+}
+
+func cloneUcfs(ucfs []StoredUcf) []StoredUcf {
+	copied := make([]StoredUcf, len(ucfs))
+	for i, s := range ucfs {
+		copied[i] = cloneUcf(s)
+	}
+
+	return copied
+}
+
+func sameLocation(lat1, lon1, lat2, lon2 float64) bool {
+	return lat1-lat2 < 0.00001 && lat2-lat1 < 0.00001 && lon1-lon2 < 0.00001 && lon2-lon1 < 0.00001
+}
+
+func containsAddress(address, search string) bool {
+	return strings.Contains(strings.ToLower(address), strings.ToLower(search))
+}
+
+func locationOf(lat, lon float32) r2.Point {
+	return r2.Point{X: float64(lat), Y: float64(lon)}
+}
+
+func (m *MemoryStore) policy() config.Policy {
+	return config.PolicyOf(m.kind.ServiceTable)
+}
+
+//NextId return a new id of the store, it is shared by the services, the unconfirmed services and the reviews
+func (m *MemoryStore) NextId() int64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.nextId()
+}
+
+func (m *MemoryStore) nextId() int64 {
+	m.lastId++
+	return m.lastId
+}
+
+//UseReputations set the reputations which scale the weight of the votes and the threshold of the confirmation
+//policy like the database, the reputations of everyone are 0 until it is set
+func (m *MemoryStore) UseReputations(r ReputationRepository) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.votes.Reputations = r
+}
+
+func (m *MemoryStore) reputationOf(user string) int {
+	if m.votes.Reputations == nil {
+		return 0
+	}
+
+	return m.votes.Reputations.ReputationOf(user).Reputation
+}
+
+//confirmed determine whether a submission reach the confirmation policy
+func (m *MemoryStore) confirmed(tablename string, id int64, contributor string, confident int) bool {
+	return Confirmed(m.policy(), contributor, confident, m.reputationOf(contributor), m.votes.Voters(tablename, id))
+}
+
+//save mirror the hooks of the services, the state which is driven by votes is evaluated and the transition is
+//logged. The spam flags are not kept in memory
+func (m *MemoryStore) save(s Stored) Stored {
+	b := s.Base()
+	confirmed := m.confirmed(m.kind.ServiceTable, b.Id, b.Contributor, Decayed(m.policy(), b.Confident, b.Lifecycle, time.Now()))
+	if !confirmed {
+		b.Reject(Rejection(m.policy(), b.Confident, false))
+	}
+
+	b.Evaluate(confirmed)
+	m.logTransition(m.kind.ServiceTable, b.Id, &b.Lifecycle)
+	now := time.Now()
+	b.UpdatedAt = &now
+	m.services[b.Id] = s
+	return cloneService(s)
+}
+
+func (m *MemoryStore) logTransition(tablename string, id int64, l *Lifecycle) {
+	if t := l.PopTransition(tablename, id); t != nil {
+		t.Id = int64(len(m.transitions) + 1)
+		m.transitions = append(m.transitions, *t)
+	}
+}
+
+func (m *MemoryStore) sortedServices() (services []Stored) {
+	services = []Stored{}
+	for _, s := range m.services {
+		services = append(services, s)
+	}
+
+	sort.Slice(services, func(i, j int) bool { return services[i].Base().Id < services[j].Base().Id })
+	return
+}
+
+func (m *MemoryStore) sortedUcfs() (ucfs []StoredUcf) {
+	ucfs = []StoredUcf{}
+	for _, s := range m.ucfs {
+		ucfs = append(ucfs, s)
+	}
+
+	sort.Slice(ucfs, func(i, j int) bool { return ucfs[i].BaseUcf().Id < ucfs[j].BaseUcf().Id })
+	return
+}
+
+func (m *MemoryStore) All() []Stored {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return cloneServices(m.sortedServices())
+}
+
+func (m *MemoryStore) ById(id int64) (Stored, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.services[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return cloneService(s), nil
+}
+
+func (m *MemoryStore) ByLocation(lat, lon float64) (Stored, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, s := range m.sortedServices() {
+		if b := s.Base(); sameLocation(float64(b.Lat), float64(b.Lon), lat, lon) {
+			return cloneService(s), nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MemoryStore) ByAddress(address string) (services []Stored, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, s := range m.sortedServices() {
+		if containsAddress(s.Base().Address, address) {
+			services = append(services, cloneService(s))
+		}
+	}
+
+	if len(services) == 0 {
+		e = gorm.ErrRecordNotFound
+	}
+
+	return
+}
+
+func (m *MemoryStore) ByIds(ids ...int64) (services []Stored) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, id := range ids {
+		if s, ok := m.services[id]; ok {
+			services = append(services, cloneService(s))
+		}
+	}
+
+	return
+}
+
+//InRange return the listed services in the range of p
+func (m *MemoryStore) InRange(p r2.Point, max_range float64) []Stored {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return cloneServices(m.inRange(p, max_range))
+}
+
+func (m *MemoryStore) inRange(p r2.Point, max_range float64) []Stored {
+	result := []Stored{}
+	for _, s := range m.sortedServices() {
+		if b := s.Base(); IsListed(b.GetState()) && distance(locationOf(b.Lat, b.Lon), p) < max_range {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+//InRangeByState return the services in one of the states in the range of p, see QueriedStates
+func (m *MemoryStore) InRangeByState(p r2.Point, max_range float64, states ...string) []Stored {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := []Stored{}
+	for _, s := range m.sortedServices() {
+		if b := s.Base(); distance(locationOf(b.Lat, b.Lon), p) < max_range {
+			for _, state := range QueriedStates(states) {
+				if b.GetState() == state {
+					result = append(result, cloneService(s))
+					break
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func (m *MemoryStore) Create(s Stored) (Stored, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.create(cloneService(s))
+}
+
+func (m *MemoryStore) create(s Stored) (Stored, error) {
+	if e := m.prepare(s); e != nil {
+		return nil, e
+	}
+
+	return m.save(s), nil
+}
+
+//prepare give the new service its id, the service must not be at the location of another service
+func (m *MemoryStore) prepare(s Stored) error {
+	b := s.Base()
+	for _, existed := range m.services {
+		if existed.Base().Lat == b.Lat && existed.Base().Lon == b.Lon {
+			return NewError(ErrConflict, "The service location is existed or some problems is occured")
+		}
+	}
+
+	now := time.Now()
+	b.Id = m.nextId()
+	b.CreatedAt = &now
+	return nil
+}
+
+//Update apply change on a copy of the service by specific id and save it, the service is not changed when change
+//return an error
+func (m *MemoryStore) Update(id int64, change func(s Stored) error) (Stored, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.services[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	s = cloneService(s)
+	if e := change(s); e != nil {
+		return nil, e
+	}
+
+	return m.save(s), nil
+}
+
+//Set change the fields of the service by specific id by the values of an update
+func (m *MemoryStore) Set(id int64, values url.Values) (Stored, error) {
+	return m.Update(id, func(s Stored) error {
+		m.kind.Set(s, values)
+		return nil
+	})
+}
+
+//Vote cast the vote on the service by specific id, the upvotes are positive signals of the service
+func (m *MemoryStore) Vote(id int64, voter string, direction int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.services[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	v := Vote{Voter: voter, Direction: direction, Trusted: direction == VoteUp && m.policy().IsTrusted(voter)}
+	confident, e := m.votes.Cast(m.kind.ServiceTable, id, v)
+	if e != nil {
+		return e
+	}
+
+	s = cloneService(s)
+	s.Base().Confident = confident
+	if direction == VoteUp {
+		s.Base().Verify(time.Now())
+	}
+
+	m.save(s)
+	return nil
+}
+
+func (m *MemoryStore) Votes(id int64) ([]Vote, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.services[id]; !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return m.votes.Votes(m.kind.ServiceTable, id), nil
+}
+
+func (m *MemoryStore) Transitions(id int64) (transitions []Transition) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, t := range m.transitions {
+		if t.ServiceType == m.kind.ServiceTable && t.ServiceId == id {
+			transitions = append(transitions, t)
+		}
+	}
+
+	return
+}
+
+func (m *MemoryStore) ByState(states ...string) (services []Stored) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, s := range m.sortedServices() {
+		for _, state := range states {
+			if s.Base().GetState() == state {
+				services = append(services, cloneService(s))
+				break
+			}
+		}
+	}
+
+	return
+}
+
+//ArchiveStale archive the services and the unconfirmed services which are not voted in the max age of the policy
+func (m *MemoryStore) ArchiveStale() (archived int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	p := m.policy()
+	now := time.Now()
+	for _, s := range m.sortedServices() {
+		if b := s.Base(); IsStale(p, b.Lifecycle, m.votes.LastVoted(m.kind.ServiceTable, b.Id), now) {
+			s = cloneService(s)
+			s.Base().Transit(StateArchived, ActorSystem, StaleReason(p))
+			m.save(s)
+			archived++
+		}
+	}
+
+	for _, s := range m.sortedUcfs() {
+		if b := s.BaseUcf(); IsStale(p, b.Lifecycle, m.votes.LastVoted(m.kind.UcfTable, b.Id), now) {
+			s = cloneUcf(s)
+			b = s.BaseUcf()
+			b.Transit(StateArchived, ActorSystem, StaleReason(p))
+			m.logTransition(m.kind.UcfTable, b.Id, &b.Lifecycle)
+			m.ucfs[b.Id] = s
+			archived++
+		}
+	}
+
+	return
+}
+
+//Verify record a positive signal of the service by specific id
+func (m *MemoryStore) Verify(id int64) (Stored, error) {
+	return m.Update(id, func(s Stored) error {
+		s.Base().Verify(time.Now())
+		return nil
+	})
+}
+
+//Decay evaluate the confirmed services again by their decayed confident, the number of the disputed services is
+//returned
+func (m *MemoryStore) Decay() (disputed int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, s := range m.sortedServices() {
+		if s.Base().GetState() != StateConfirmed {
+			continue
+		}
+
+		if s = m.save(cloneService(s)); s.Base().State == StateDisputed {
+			disputed++
+		}
+	}
+
+	return
+}
+
+//ReverifyQueue return the listed services in the range of p which should be verified again, the service which is
+//verified longest ago is the first
+func (m *MemoryStore) ReverifyQueue(p r2.Point, max_range float64) []Stored {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := []Stored{}
+	now := time.Now()
+	for _, s := range m.inRange(p, max_range) {
+		if NeedsReverification(m.policy(), s.Base().Lifecycle, now) {
+			result = append(result, cloneService(s))
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return SignalBefore(result[i].Base().Lifecycle, result[j].Base().Lifecycle) })
+	return result
+}
+
+//reported determine whether the concurring reports of the kind of r change the state of the service, the services
+//which cannot be changed to the state of the report are left for the moderation
+func (m *MemoryStore) reported(state string, r Report, concurring int) bool {
+	p := m.policy()
+	to, _ := ReportTransition(r)
+	return p.ReportThreshold > 0 && concurring >= p.ReportThreshold && state != to && CanTransit(state, to)
+}
+
+//Report file the problem report on the service by specific id, the service is changed when enough reporters concur
+func (m *MemoryStore) Report(id int64, r Report) (report Report, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.services[id]
+	if !ok {
+		return report, gorm.ErrRecordNotFound
+	}
+
+	if _, ok := m.services[r.DuplicateOf]; r.Kind == ReportDuplicate && !ok {
+		return report, gorm.ErrRecordNotFound
+	}
+
+	r.ServiceType = m.kind.ServiceTable
+	r.ServiceId = id
+	report, concurring, e := m.reports.File(r)
+	if e != nil || !m.reported(s.Base().GetState(), report, concurring) {
+		return
+	}
+
+	return m.acceptReport(s, report, ActorSystem)
+}
+
+//acceptReport mirror the acceptance of the reports in the database, the reputations are not kept in memory
+func (m *MemoryStore) acceptReport(s Stored, report Report, actor string) (Report, error) {
+	s = cloneService(s)
+	to, reason := ReportTransition(report)
+	if e := s.Base().Transit(to, actor, reason); e != nil {
+		return report, e
+	}
+
+	m.save(s)
+	m.reports.Resolve(m.kind.ServiceTable, s.Base().Id, report.Kind, ReportAccepted, actor)
+	report.Status = ReportAccepted
+	return report, nil
+}
+
+func (m *MemoryStore) Reports(id int64) ([]Report, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.services[id]; !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return m.reports.Reports(m.kind.ServiceTable, id), nil
+}
+
+//ReportsByServices return the reports on the services which are having ids, the newest is the first
+func (m *MemoryStore) ReportsByServices(ids ...int64) []Report {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.reports.Reports(m.kind.ServiceTable, ids...)
+}
+
+func (m *MemoryStore) AcceptReport(id int64, kind string, actor string) (report Report, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.services[id]
+	if !ok {
+		return report, gorm.ErrRecordNotFound
+	}
+
+	if report, e = m.reports.Open(m.kind.ServiceTable, id, kind); e != nil {
+		return
+	}
+
+	return m.acceptReport(s, report, actor)
+}
+
+func (m *MemoryStore) DismissReports(id int64, kind string, actor string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.services[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	m.reports.Resolve(m.kind.ServiceTable, id, kind, ReportDismissed, actor)
+	return nil
+}
+
+func (m *MemoryStore) Ucfs() []StoredUcf {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return cloneUcfs(m.sortedUcfs())
+}
+
+func (m *MemoryStore) UcfById(id int64) (StoredUcf, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.ucfs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return cloneUcf(s), nil
+}
+
+func (m *MemoryStore) UcfByLocation(lat, lon float64) (StoredUcf, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, s := range m.sortedUcfs() {
+		if b := s.BaseUcf(); sameLocation(float64(b.Lat), float64(b.Lon), lat, lon) {
+			return cloneUcf(s), nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MemoryStore) UcfsByAddress(address string) (ucfs []StoredUcf, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, s := range m.sortedUcfs() {
+		if containsAddress(s.BaseUcf().Address, address) {
+			ucfs = append(ucfs, cloneUcf(s))
+		}
+	}
+
+	if len(ucfs) == 0 {
+		e = gorm.ErrRecordNotFound
+	}
+
+	return
+}
+
+//UcfInRange return the unconfirmed services in the range of p, they are the pending services and the submissions
+//which are waiting for their confirmation
+func (m *MemoryStore) UcfInRange(p r2.Point, max_range float64) []Stored {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := []Stored{}
+	for _, s := range m.sortedUcfs() {
+		if b := s.BaseUcf(); IsUnconfirmed(b.GetState()) && distance(locationOf(b.Lat, b.Lon), p) < max_range {
+			result = append(result, m.kind.Promote(cloneUcf(s), b.Listed()))
+		}
+	}
+
+	for _, s := range m.sortedServices() {
+		if b := s.Base(); IsUnconfirmed(b.GetState()) && distance(locationOf(b.Lat, b.Lon), p) < max_range {
+			result = append(result, cloneService(s))
+		}
+	}
+
+	return result
+}
+
+func (m *MemoryStore) CreateUcf(s StoredUcf) (StoredUcf, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s = cloneUcf(s)
+	b := s.BaseUcf()
+	for _, existed := range m.services {
+		if existed.Base().Lat == b.Lat && existed.Base().Lon == b.Lon {
+			return nil, NewError(ErrConflict, "The service location is existed or some problems is occured")
+		}
+	}
+
+	for _, existed := range m.ucfs {
+		if existed.BaseUcf().Lat == b.Lat && existed.BaseUcf().Lon == b.Lon {
+			return nil, NewError(ErrConflict, "The service location is existed or some problems is occured")
+		}
+	}
+
+	now := time.Now()
+	b.Id = m.nextId()
+	b.CreatedAt = &now
+	m.ucfs[b.Id] = s
+	return cloneUcf(s), nil
+}
+
+//DeleteUcf reject the unconfirmed service by specific id and remove it
+func (m *MemoryStore) DeleteUcf(id int64, actor string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.ucfs[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	b := cloneUcf(s).BaseUcf()
+	if e := b.Transit(StateRejected, actor, "unconfirmed service is deleted"); e != nil {
+		return e
+	}
+
+	delete(m.ucfs, id)
+	m.logTransition(m.kind.UcfTable, id, &b.Lifecycle)
+	return nil
+}
+
+//TransitUcf mirror TransitUcf of the database, the confirmed unconfirmed service is moved to the services
+func (m *MemoryStore) TransitUcf(id int64, to, actor, reason string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.ucfs[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	s = cloneUcf(s)
+	b := s.BaseUcf()
+	if e := b.Transit(to, actor, reason); e != nil {
+		return e
+	}
+
+	m.logTransition(m.kind.UcfTable, id, &b.Lifecycle)
+	if to != StateConfirmed {
+		m.ucfs[id] = s
+		return nil
+	}
+
+	delete(m.ucfs, id)
+	service := m.kind.Promote(s, b.GetService())
+	service.Base().Transit(StateConfirmed, actor, reason)
+	return m.promote(id, service)
+}
+
+//UpvoteUcf mirror the hooks of the unconfirmed services, the unconfirmed service is moved to the services when the
+//confident is enough
+func (m *MemoryStore) UpvoteUcf(id int64, voter string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.ucfs[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	value, e := m.votes.Cast(m.kind.UcfTable, id, Vote{Voter: voter, Direction: VoteUp, Trusted: m.policy().IsTrusted(voter)})
+	if e != nil {
+		return e
+	}
+
+	s = cloneUcf(s)
+	b := s.BaseUcf()
+	b.Confident = value
+	if !m.confirmed(m.kind.UcfTable, id, b.Contributor, b.Confident) {
+		b.Reject(Rejection(m.policy(), b.Confident, false))
+		m.logTransition(m.kind.UcfTable, id, &b.Lifecycle)
+		m.ucfs[id] = s
+		return nil
+	}
+
+	delete(m.ucfs, id)
+	b.Transit(StateConfirmed, ActorSystem, "unconfirmed service is moved to the services")
+	m.logTransition(m.kind.UcfTable, id, &b.Lifecycle)
+	return m.promote(id, m.kind.Promote(s, b.GetService()))
+}
+
+//promote create the service of the unconfirmed service by specific id, the voters of the unconfirmed service keep
+//their votes on the service
+func (m *MemoryStore) promote(ucf_id int64, service Stored) error {
+	if e := m.prepare(service); e != nil {
+		return e
+	}
+
+	m.votes.Move(m.kind.UcfTable, ucf_id, m.kind.ServiceTable, service.Base().Id)
+	m.save(service)
+	return nil
+}
+
+//CreateReview save the review, it is a positive signal of the service
+func (m *MemoryStore) CreateReview(service_id int64, reviewer string, score float32, body string) Review {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	review := Review{Id: m.nextId(), ServiceId: service_id, Reviewer: reviewer, Score: score, Body: body}
+	m.reviews[review.Id] = review
+	if s, ok := m.services[service_id]; ok {
+		s = cloneService(s)
+		s.Base().Verify(time.Now())
+		m.save(s)
+	}
+
+	return review
+}
+
+func (m *MemoryStore) DeleteReview(review_id int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.reviews, review_id)
+}
+
+//Reviews return the reviews of the service by specific id in the order of their ids, order reviews are skipped
+//and all the others are returned when limit is negative
+func (m *MemoryStore) Reviews(service_id, order int64, limit int64) (reviews []Review) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, r := range m.reviews {
+		if r.ServiceId == service_id {
+			reviews = append(reviews, r)
+		}
+	}
+
+	sort.Slice(reviews, func(i, j int) bool { return reviews[i].Id < reviews[j].Id })
+	if order > int64(len(reviews)) {
+		order = int64(len(reviews))
+	}
+
+	reviews = reviews[order:]
+	if limit >= 0 && limit < int64(len(reviews)) {
+		reviews = reviews[:limit]
+	}
+
+	return
+}
+
+//ReviewsByServices return the reviews of the services which are having ids, the newest is the first
+func (m *MemoryStore) ReviewsByServices(ids ...int64) (reviews []Review) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	wanted := make(map[int64]bool)
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	for _, r := range m.reviews {
+		if wanted[r.ServiceId] {
+			reviews = append(reviews, r)
+		}
+	}
+
+	sort.Slice(reviews, func(i, j int) bool { return reviews[i].Id > reviews[j].Id })
+	return
+}
+
+func (m *MemoryStore) ReviewById(review_id int64) (Review, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	review, ok := m.reviews[review_id]
+	if !ok {
+		return review, gorm.ErrRecordNotFound
+	}
+
+	return review, nil
+}
+
+func (m *MemoryStore) ReviewAverageScore(service_id int64) (average float64) {
+	return m.ReviewAverageScores(service_id)[service_id]
+}
+
+//ReviewAverageScores return the average scores of the services by their id, the services without reviews are missing
+func (m *MemoryStore) ReviewAverageScores(ids ...int64) map[int64]float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	wanted := make(map[int64]bool)
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	sums := make(map[int64]float64)
+	counts := make(map[int64]int)
+	for _, r := range m.reviews {
+		if wanted[r.ServiceId] {
+			sums[r.ServiceId] += float64(r.Score)
+			counts[r.ServiceId]++
+		}
+	}
+
+	for id, count := range counts {
+		sums[id] /= float64(count)
+	}
+
+	return sums
+}
+
+func (m *MemoryStore) SaveReview(r Review) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if r.Id == 0 {
+		r.Id = m.nextId()
+	}
+
+	m.reviews[r.Id] = r
+}
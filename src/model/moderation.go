@@ -0,0 +1,740 @@
+package model
+
+import (
+	"log"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r2"
+	"github.com/jinzhu/gorm"
+)
+
+//The kinds of the moderation items
+const (
+	ModerationSubmission = "submission"
+	ModerationReport     = "report"
+	ModerationReview     = "review"
+	ModerationImage      = "image"
+	ModerationEdit       = "edit"
+)
+
+//The statuses of the moderation items
+const (
+	ModerationOpen     = "open"
+	ModerationClaimed  = "claimed"
+	ModerationApproved = "approved"
+	ModerationRejected = "rejected"
+)
+
+//The actions which are recorded in the moderation audit
+const (
+	ActionEnqueue = "enqueue"
+	ActionClaim   = "claim"
+	ActionRelease = "release"
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+)
+
+//ModerationItem is an item of the moderation queue, it is identified by its kind, its service and its subject
+type ModerationItem struct {
+	Id          int64  `gorm:"column:id"`
+	Kind        string `gorm:"column:kind;index:idx_moderation_target"`
+	ServiceType string `gorm:"column:service_type;index:idx_moderation_target"`
+	ServiceId   int64  `gorm:"column:service_id;index:idx_moderation_target"`
+	//Subject is the report kind of the reports, the review id of the flagged reviews, the url of the flagged images
+	//and the encoded values of the proposed edits
+	Subject string `gorm:"column:subject"`
+	//Author is the user who submit, report, flag or propose the item
+	Author     string     `gorm:"column:author"`
+	Note       string     `gorm:"column:note"`
+	Lat        float32    `gorm:"column:lat"`
+	Lon        float32    `gorm:"column:lon"`
+	Priority   int        `gorm:"column:priority"`
+	Status     string     `gorm:"column:status;index"`
+	ClaimedBy  string     `gorm:"column:claimed_by"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at"`
+}
+
+const ModerationItemTableName = "moderation_item"
+
+func (ModerationItem) TableName() string {
+	return ModerationItemTableName
+}
+
+//ModerationAction is an entry of the moderation audit
+type ModerationAction struct {
+	Id        int64     `gorm:"column:id"`
+	ItemId    int64     `gorm:"column:item_id;index"`
+	Actor     string    `gorm:"column:actor;index"`
+	Action    string    `gorm:"column:action"`
+	Note      string    `gorm:"column:note"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+const ModerationActionTableName = "moderation_action"
+
+func (ModerationAction) TableName() string {
+	return ModerationActionTableName
+}
+
+//IsModerationKind determine whether kind is a kind of the moderation items
+func IsModerationKind(kind string) bool {
+	switch kind {
+	case ModerationSubmission, ModerationReport, ModerationReview, ModerationImage, ModerationEdit:
+		return true
+	}
+
+	return false
+}
+
+//IsResolved determine whether the item is approved or rejected
+func (item ModerationItem) IsResolved() bool {
+	return item.Status == ModerationApproved || item.Status == ModerationRejected
+}
+
+//Location determine the location of the service of the item as r2.Point
+func (item ModerationItem) Location() r2.Point {
+	return r2.Point{X: float64(item.Lat), Y: float64(item.Lon)}
+}
+
+//sameTarget determine whether a and b are the items of the same kind, service and subject
+func (a ModerationItem) sameTarget(b ModerationItem) bool {
+	return a.Kind == b.Kind && a.ServiceType == b.ServiceType && a.ServiceId == b.ServiceId && a.Subject == b.Subject
+}
+
+//ModerationFilter determine the items which are listed from the moderation queue, the zero values are not filtered
+type ModerationFilter struct {
+	Kinds        []string
+	ServiceTypes []string
+	//Statuses are open and claimed when it is empty
+	Statuses []string
+	//the items whose service is in the Range of Location
+	Location r2.Point
+	Range    float64
+	//the items which are created MinAge to MaxAge ago
+	MinAge      time.Duration
+	MaxAge      time.Duration
+	MinPriority int
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Match determine whether the item is listed by the filter at now
+func (f ModerationFilter) Match(item ModerationItem, now time.Time) bool {
+	statuses := f.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{ModerationOpen, ModerationClaimed}
+	}
+
+	if !contains(statuses, item.Status) {
+		return false
+	}
+
+	if len(f.Kinds) > 0 && !contains(f.Kinds, item.Kind) {
+		return false
+	}
+
+	if len(f.ServiceTypes) > 0 && !contains(f.ServiceTypes, item.ServiceType) {
+		return false
+	}
+
+	if f.Range > 0 && math.Hypot(float64(item.Lat)-f.Location.X, float64(item.Lon)-f.Location.Y) >= f.Range {
+		return false
+	}
+
+	age := now.Sub(item.CreatedAt)
+	if age < f.MinAge || f.MaxAge > 0 && age > f.MaxAge {
+		return false
+	}
+
+	return item.Priority >= f.MinPriority
+}
+
+//sortQueue sort the items by priority, the items which are having the same priority are sorted by age
+func sortQueue(items []ModerationItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Priority != items[j].Priority {
+			return items[i].Priority > items[j].Priority
+		}
+
+		return items[i].Id < items[j].Id
+	})
+}
+
+//audit record the action of actor on the item
+func audit(db *gorm.DB, item_id int64, actor, action, note string) {
+	a := ModerationAction{ItemId: item_id, Actor: actor, Action: action, Note: note}
+	if e := db.Create(&a).Error; e != nil {
+		log.Println("[Database]", "moderation audit", item_id, ":", e.Error())
+	}
+}
+
+//Enqueue put the item into the moderation queue, the pending item of the same target is updated instead
+//
+//db is passed in order to be used in the hooks which are ran in a transaction
+func Enqueue(db *gorm.DB, item ModerationItem) (queued ModerationItem, e error) {
+	e = db.Where("kind = ? AND service_type = ? AND service_id = ? AND subject = ? AND status IN (?)",
+		item.Kind, item.ServiceType, item.ServiceId, item.Subject, []string{ModerationOpen, ModerationClaimed}).First(&queued).Error
+	switch {
+	case e == nil:
+		if queued.Priority == item.Priority && queued.Note == item.Note && queued.Author == item.Author {
+			return
+		}
+
+		e = db.Model(&queued).Updates(map[string]interface{}{"priority": item.Priority, "note": item.Note, "author": item.Author}).Error
+	case gorm.IsRecordNotFoundError(e):
+		item.Status = ModerationOpen
+		if e = db.Create(&item).Error; e == nil {
+			queued = item
+			audit(db, item.Id, item.Author, ActionEnqueue, item.Note)
+		}
+	}
+
+	if e != nil {
+		log.Println("[Database]", "enqueue", item.Kind, item.ServiceType, item.ServiceId, ":", e.Error())
+	}
+
+	return
+}
+
+//ResolveQueued resolve the pending item of the target by actor when it is resolved outside of the moderation,
+//nothing is done when there is no pending item
+func ResolveQueued(db *gorm.DB, kind string, service_type string, service_id int64, subject string, approve bool, actor string, note string) {
+	var item ModerationItem
+	if e := db.Where("kind = ? AND service_type = ? AND service_id = ? AND subject = ? AND status IN (?)",
+		kind, service_type, service_id, subject, []string{ModerationOpen, ModerationClaimed}).First(&item).Error; e != nil {
+		return
+	}
+
+	resolve(db, db.Model(&item).Where("status IN (?)", []string{ModerationOpen, ModerationClaimed}), item.Id, approve, actor, note)
+}
+
+//resolve approve or reject the item by specific id when it is matched by query, the item is checked and changed by
+//the same statement so resolved is false when it is changed by someone else in the meantime
+func resolve(db *gorm.DB, query *gorm.DB, id int64, approve bool, actor string, note string) (resolved bool, e error) {
+	status, action := ModerationRejected, ActionReject
+	if approve {
+		status, action = ModerationApproved, ActionApprove
+	}
+
+	now := time.Now()
+	query = query.Updates(map[string]interface{}{"status": status, "resolved_at": &now})
+	if e = query.Error; e != nil {
+		log.Println("[Database]", "resolve moderation", id, ":", e.Error())
+		return
+	}
+
+	if query.RowsAffected == 0 {
+		return
+	}
+
+	audit(db, id, actor, action, note)
+	return true, nil
+}
+
+//QueueSubmission keep the moderation item of a submission in line with its lifecycle: a pending submission is queued
+//and the queued submission is resolved by the actor of the transition t when it leaves the pending state
+//
+//db is passed in order to be used in the hooks which are ran in a transaction
+func QueueSubmission(db *gorm.DB, service_type string, service_id int64, l Lifecycle, t *Transition, contributor string, location r2.Point) {
+	if IsUnconfirmed(l.GetState()) {
+		Enqueue(db, ModerationItem{Kind: ModerationSubmission, ServiceType: service_type, ServiceId: service_id, Author: contributor, Lat: float32(location.X), Lon: float32(location.Y)})
+		return
+	}
+
+	if t != nil {
+		ResolveQueued(db, ModerationSubmission, service_type, service_id, "", IsListed(t.To), t.Actor, t.Reason)
+	}
+}
+
+//ImageRemoval return the values which remove image from the images of a service, see UpdateService of the
+//service packages
+func ImageRemoval(images []string, image string) (values url.Values, e error) {
+	kept := []string{}
+	found := false
+	for _, i := range images {
+		if i == image {
+			found = true
+			continue
+		}
+
+		kept = append(kept, i)
+	}
+
+	if !found {
+		return nil, NewError(ErrNotFound, "image is not found")
+	}
+
+	//an empty value clear the images
+	if len(kept) == 0 {
+		kept = []string{""}
+	}
+
+	return url.Values{"images": kept}, nil
+}
+
+//Moderated is the data access of a service package which the moderation decisions are applied through
+type Moderated interface {
+	//Locate return the location of the row of service_type which is having id
+	Locate(service_type string, id int64) (r2.Point, error)
+	//Moderate apply the approval or the rejection of the item by actor
+	Moderate(item ModerationItem, approve bool, actor string, note string) error
+}
+
+//ModerationTarget is the data access of a service type which the moderation decisions are applied through, the
+//service packages adapt their repositories to it so Locate and Moderate are shared by every service type
+type ModerationTarget interface {
+	//Tables return the tables of the services and the unconfirmed services
+	Tables() (service_table string, ucf_table string)
+	BaseService(id int64) (Service, error)
+	BaseUcf(id int64) (ServiceUcf, error)
+	BaseReview(review_id int64) (Review, error)
+	TransitBase(id int64, to, actor, reason string) error
+	TransitUcf(id int64, to, actor, reason string) error
+	UpdateBase(id int64, values url.Values) error
+	AcceptReport(id int64, kind string, actor string) (Report, error)
+	DismissReports(id int64, kind string, actor string) error
+	DeleteReview(review_id int64) error
+}
+
+//Locate return the location of the service or the unconfirmed service of t by specific id
+func Locate(t ModerationTarget, service_type string, id int64) (r2.Point, error) {
+	services, ucfs := t.Tables()
+	switch service_type {
+	case services:
+		s, e := t.BaseService(id)
+		return locationOf(s.Lat, s.Lon), e
+	case ucfs:
+		s, e := t.BaseUcf(id)
+		return locationOf(s.Lat, s.Lon), e
+	}
+
+	return r2.Point{}, NewError(ErrInvalid, "service type is invalid")
+}
+
+//Moderate apply the moderation decision on the item through t. The approved submissions are confirmed and the
+//rejected ones are rejected, the approved reports change the service and the rejected ones are dismissed. The
+//approved flagged reviews and images are removed and the approved edits are applied, nothing is changed when they
+//are rejected
+func Moderate(t ModerationTarget, item ModerationItem, approve bool, actor string, note string) (e error) {
+	if _, e = Locate(t, item.ServiceType, item.ServiceId); e != nil {
+		return
+	}
+
+	services, ucfs := t.Tables()
+	if item.Kind == ModerationSubmission {
+		to := StateRejected
+		if approve {
+			to = StateConfirmed
+		}
+
+		reason := "moderated by " + actor
+		if note != "" {
+			reason += ": " + note
+		}
+
+		if item.ServiceType == ucfs {
+			return t.TransitUcf(item.ServiceId, to, actor, reason)
+		}
+
+		return t.TransitBase(item.ServiceId, to, actor, reason)
+	}
+
+	if item.ServiceType != services {
+		return NewError(ErrInvalid, "service type is invalid")
+	}
+
+	if !approve {
+		if item.Kind == ModerationReport {
+			e = t.DismissReports(item.ServiceId, item.Subject, actor)
+		}
+
+		return
+	}
+
+	var values url.Values
+	switch item.Kind {
+	case ModerationReport:
+		_, e = t.AcceptReport(item.ServiceId, item.Subject, actor)
+		return
+	case ModerationReview:
+		return removeReview(t, item.ServiceId, item.Subject)
+	case ModerationImage:
+		var s Service
+		if s, e = t.BaseService(item.ServiceId); e != nil {
+			return
+		}
+
+		values, e = ImageRemoval(s.GetImagesArray(), item.Subject)
+	case ModerationEdit:
+		values, e = url.ParseQuery(item.Subject)
+	default:
+		return NewError(ErrInvalid, "moderation kind is invalid")
+	}
+
+	if e != nil {
+		return
+	}
+
+	return t.UpdateBase(item.ServiceId, values)
+}
+
+//removeReview delete the flagged review which is having the id of subject on the service by specific id
+func removeReview(t ModerationTarget, service_id int64, subject string) (e error) {
+	id, e := strconv.ParseInt(subject, 10, 64)
+	if e != nil {
+		return
+	}
+
+	review, e := t.BaseReview(id)
+	if e != nil {
+		return
+	}
+
+	if review.ServiceId != service_id {
+		return NewError(ErrNotFound, "review is not found on the service")
+	}
+
+	return t.DeleteReview(id)
+}
+
+//checkClaim return an error when the item cannot be moderated by actor
+func checkClaim(item ModerationItem, actor string) error {
+	if item.IsResolved() {
+		return NewError(ErrConflict, "moderation item is resolved")
+	}
+
+	if item.Status == ModerationClaimed && item.ClaimedBy != actor {
+		return NewError(ErrConflict, "moderation item is claimed by "+item.ClaimedBy)
+	}
+
+	return nil
+}
+
+//claimable filter db to the item by specific id when it can be moderated by actor, it is checkClaim in sql
+func claimable(db *gorm.DB, id int64, actor string) *gorm.DB {
+	return db.Model(&ModerationItem{}).Where("id = ? AND (status = ? OR (status = ? AND claimed_by = ?))", id, ModerationOpen, ModerationClaimed, actor)
+}
+
+//conflict return the error of a conditional update of the item by specific id which changed nothing, the item is
+//read again so the error tell whether it is missing, resolved or claimed by another moderator
+func conflict(db *gorm.DB, id int64, actor string) error {
+	var item ModerationItem
+	if e := db.Where("id = ?", id).First(&item).Error; e != nil {
+		return e
+	}
+
+	if e := checkClaim(item, actor); e != nil {
+		return e
+	}
+
+	return NewError(ErrConflict, "moderation item is changed by another moderator")
+}
+
+//ModerationRepository determine the data access of the moderation queue
+type ModerationRepository interface {
+	Enqueue(item ModerationItem) (ModerationItem, error)
+	Queue(filter ModerationFilter) ([]ModerationItem, error)
+	Item(id int64) (ModerationItem, error)
+	//Claim assign the item to actor, the claimed item is moderated by its claimer only
+	Claim(id int64, actor string, note string) (ModerationItem, error)
+	Release(id int64, actor string, note string) (ModerationItem, error)
+	//Check return an error when the item cannot be moderated by actor
+	Check(id int64, actor string) (ModerationItem, error)
+	Resolve(id int64, actor string, approve bool, note string) (ModerationItem, error)
+	//Audit query the moderation actions, the actions of every item or every actor are queried when item_id is 0 or
+	//actor is empty
+	Audit(item_id int64, actor string) ([]ModerationAction, error)
+}
+
+//ModerationDatabase is the ModerationRepository which is working on model.Db
+type ModerationDatabase struct{}
+
+func (ModerationDatabase) Enqueue(item ModerationItem) (ModerationItem, error) {
+	return Enqueue(Db, item)
+}
+
+func (ModerationDatabase) Queue(filter ModerationFilter) (items []ModerationItem, e error) {
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{ModerationOpen, ModerationClaimed}
+	}
+
+	var candidates []ModerationItem
+	if e = Db.Where("status IN (?) AND priority >= ?", statuses, filter.MinPriority).Find(&candidates).Error; e != nil {
+		log.Println("[Database]", "moderation queue", e.Error())
+		return
+	}
+
+	items = []ModerationItem{}
+	now := time.Now()
+	for _, item := range candidates {
+		if filter.Match(item, now) {
+			items = append(items, item)
+		}
+	}
+
+	sortQueue(items)
+	return
+}
+
+func (ModerationDatabase) Item(id int64) (item ModerationItem, e error) {
+	e = Db.Where("id = ?", id).First(&item).Error
+	return
+}
+
+func (d ModerationDatabase) Check(id int64, actor string) (item ModerationItem, e error) {
+	if item, e = d.Item(id); e != nil {
+		return
+	}
+
+	e = checkClaim(item, actor)
+	return
+}
+
+func (d ModerationDatabase) Claim(id int64, actor string, note string) (item ModerationItem, e error) {
+	e = Db.Transaction(func(tx *gorm.DB) error {
+		db := claimable(tx, id, actor).Updates(map[string]interface{}{"status": ModerationClaimed, "claimed_by": actor})
+		if db.Error != nil {
+			return db.Error
+		}
+
+		if db.RowsAffected == 0 {
+			return conflict(tx, id, actor)
+		}
+
+		audit(tx, id, actor, ActionClaim, note)
+		return tx.Where("id = ?", id).First(&item).Error
+	})
+
+	return
+}
+
+func (d ModerationDatabase) Release(id int64, actor string, note string) (item ModerationItem, e error) {
+	e = Db.Transaction(func(tx *gorm.DB) error {
+		db := tx.Model(&ModerationItem{}).Where("id = ? AND status = ? AND claimed_by = ?", id, ModerationClaimed, actor).
+			Updates(map[string]interface{}{"status": ModerationOpen, "claimed_by": ""})
+		if db.Error != nil {
+			return db.Error
+		}
+
+		if db.RowsAffected == 0 {
+			if e := tx.Where("id = ?", id).First(&item).Error; e != nil {
+				return e
+			}
+
+			return NewError(ErrConflict, "moderation item is not claimed by "+actor)
+		}
+
+		audit(tx, id, actor, ActionRelease, note)
+		return tx.Where("id = ?", id).First(&item).Error
+	})
+
+	return
+}
+
+func (d ModerationDatabase) Resolve(id int64, actor string, approve bool, note string) (item ModerationItem, e error) {
+	e = Db.Transaction(func(tx *gorm.DB) error {
+		resolved, e := resolve(tx, claimable(tx, id, actor), id, approve, actor, note)
+		if e != nil {
+			return e
+		}
+
+		if !resolved {
+			return conflict(tx, id, actor)
+		}
+
+		return tx.Where("id = ?", id).First(&item).Error
+	})
+
+	return
+}
+
+func (ModerationDatabase) Audit(item_id int64, actor string) (actions []ModerationAction, e error) {
+	db := Db
+	if item_id != 0 {
+		db = db.Where("item_id = ?", item_id)
+	}
+
+	if actor != "" {
+		db = db.Where("actor = ?", actor)
+	}
+
+	if e = db.Order("id").Find(&actions).Error; e != nil {
+		log.Println("[Database]", "moderation audit", e.Error())
+	}
+
+	return
+}
+
+//ModerationMemory is the in-memory ModerationRepository, the items are only queued through Enqueue
+type ModerationMemory struct {
+	mutex   sync.Mutex
+	items   []ModerationItem
+	actions []ModerationAction
+}
+
+func (m *ModerationMemory) audit(item_id int64, actor, action, note string) {
+	m.actions = append(m.actions, ModerationAction{Id: int64(len(m.actions) + 1), ItemId: item_id, Actor: actor, Action: action, Note: note, CreatedAt: time.Now()})
+}
+
+func (m *ModerationMemory) find(id int64) (int, error) {
+	for i, item := range m.items {
+		if item.Id == id {
+			return i, nil
+		}
+	}
+
+	return -1, gorm.ErrRecordNotFound
+}
+
+func (m *ModerationMemory) Enqueue(item ModerationItem) (ModerationItem, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i, queued := range m.items {
+		if queued.sameTarget(item) && !queued.IsResolved() {
+			m.items[i].Priority, m.items[i].Note, m.items[i].Author = item.Priority, item.Note, item.Author
+			return m.items[i], nil
+		}
+	}
+
+	now := time.Now()
+	item.Id = int64(len(m.items) + 1)
+	item.Status = ModerationOpen
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = now
+	}
+	item.UpdatedAt = now
+	m.items = append(m.items, item)
+	m.audit(item.Id, item.Author, ActionEnqueue, item.Note)
+	return item, nil
+}
+
+func (m *ModerationMemory) Queue(filter ModerationFilter) (items []ModerationItem, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	items = []ModerationItem{}
+	now := time.Now()
+	for _, item := range m.items {
+		if filter.Match(item, now) {
+			items = append(items, item)
+		}
+	}
+
+	sortQueue(items)
+	return
+}
+
+func (m *ModerationMemory) Item(id int64) (item ModerationItem, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i, e := m.find(id)
+	if e != nil {
+		return
+	}
+
+	return m.items[i], nil
+}
+
+func (m *ModerationMemory) Check(id int64, actor string) (item ModerationItem, e error) {
+	if item, e = m.Item(id); e != nil {
+		return
+	}
+
+	e = checkClaim(item, actor)
+	return
+}
+
+func (m *ModerationMemory) Claim(id int64, actor string, note string) (item ModerationItem, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i, e := m.find(id)
+	if e != nil {
+		return
+	}
+
+	if e = checkClaim(m.items[i], actor); e != nil {
+		return
+	}
+
+	m.items[i].Status, m.items[i].ClaimedBy = ModerationClaimed, actor
+	m.audit(id, actor, ActionClaim, note)
+	return m.items[i], nil
+}
+
+func (m *ModerationMemory) Release(id int64, actor string, note string) (item ModerationItem, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i, e := m.find(id)
+	if e != nil {
+		return
+	}
+
+	if m.items[i].Status != ModerationClaimed || m.items[i].ClaimedBy != actor {
+		return item, NewError(ErrConflict, "moderation item is not claimed by "+actor)
+	}
+
+	m.items[i].Status, m.items[i].ClaimedBy = ModerationOpen, ""
+	m.audit(id, actor, ActionRelease, note)
+	return m.items[i], nil
+}
+
+func (m *ModerationMemory) Resolve(id int64, actor string, approve bool, note string) (item ModerationItem, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i, e := m.find(id)
+	if e != nil {
+		return
+	}
+
+	if e = checkClaim(m.items[i], actor); e != nil {
+		return
+	}
+
+	status, action := ModerationRejected, ActionReject
+	if approve {
+		status, action = ModerationApproved, ActionApprove
+	}
+
+	now := time.Now()
+	m.items[i].Status, m.items[i].ResolvedAt = status, &now
+	m.audit(id, actor, action, note)
+	return m.items[i], nil
+}
+
+func (m *ModerationMemory) Audit(item_id int64, actor string) (actions []ModerationAction, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, a := range m.actions {
+		if (item_id == 0 || a.ItemId == item_id) && (actor == "" || a.Actor == actor) {
+			actions = append(actions, a)
+		}
+	}
+
+	return
+}
+
+func init() {
+	AutoMigrate(&ModerationItem{}, &ModerationAction{})
+}
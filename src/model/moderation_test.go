@@ -0,0 +1,153 @@
+package model_test
+
+import (
+	"strconv"
+	"streelity/v1/model"
+	"streelity/v1/sres"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r2"
+)
+
+func TestModerationFilter(t *testing.T) {
+	now := time.Now()
+	item := model.ModerationItem{Kind: model.ModerationReport, ServiceType: "atm", Lat: 10, Lon: 106, Priority: 2,
+		Status: model.ModerationOpen, CreatedAt: now.Add(-48 * time.Hour)}
+	tests := []struct {
+		name   string
+		filter model.ModerationFilter
+		match  bool
+	}{
+		{"empty", model.ModerationFilter{}, true},
+		{"kind", model.ModerationFilter{Kinds: []string{model.ModerationReport}}, true},
+		{"other kind", model.ModerationFilter{Kinds: []string{model.ModerationEdit}}, false},
+		{"other type", model.ModerationFilter{ServiceTypes: []string{"fuel"}}, false},
+		{"resolved", model.ModerationFilter{Statuses: []string{model.ModerationApproved}}, false},
+		{"in region", model.ModerationFilter{Location: r2.Point{X: 10.1, Y: 106}, Range: 0.5}, true},
+		{"out of region", model.ModerationFilter{Location: r2.Point{X: 11, Y: 106}, Range: 0.5}, false},
+		{"old enough", model.ModerationFilter{MinAge: 24 * time.Hour}, true},
+		{"too new", model.ModerationFilter{MinAge: 72 * time.Hour}, false},
+		{"too old", model.ModerationFilter{MaxAge: 24 * time.Hour}, false},
+		{"low priority", model.ModerationFilter{MinPriority: 3}, false},
+	}
+
+	for _, test := range tests {
+		if match := test.filter.Match(item, now); match != test.match {
+			t.Errorf("%v: got %v want %v", test.name, match, test.match)
+		}
+	}
+}
+
+func TestImageRemoval(t *testing.T) {
+	values, e := model.ImageRemoval([]string{"a.png", "b.png"}, "a.png")
+	if e != nil || len(values["images"]) != 1 || values["images"][0] != "b.png" {
+		t.Errorf("wrong removal: got %v %v", values, e)
+	}
+
+	//the last image is cleared by an empty value
+	if values, _ = model.ImageRemoval([]string{"a.png"}, "a.png"); len(values["images"]) != 1 || values["images"][0] != "" {
+		t.Errorf("wrong removal of the last image: got %v", values)
+	}
+
+	if _, e = model.ImageRemoval([]string{"a.png"}, "c.png"); e == nil {
+		t.Errorf("missing image is removed")
+	}
+}
+
+func TestModerationRepositories(t *testing.T) {
+	model.ConnectSync()
+	repositories := map[string]model.ModerationRepository{
+		"database": model.ModerationDatabase{},
+		"memory":   new(model.ModerationMemory),
+	}
+
+	for name, m := range repositories {
+		item, e := m.Enqueue(model.ModerationItem{Kind: model.ModerationEdit, ServiceType: "atm", ServiceId: 1, Subject: name, Author: "author", Priority: 1})
+		if e != nil || item.Status != model.ModerationOpen {
+			t.Fatalf("%v: wrong enqueue: got %v %v", name, item, e)
+		}
+
+		//the pending item of the same target is updated
+		if again, _ := m.Enqueue(model.ModerationItem{Kind: model.ModerationEdit, ServiceType: "atm", ServiceId: 1, Subject: name, Author: "author", Priority: 3}); again.Id != item.Id {
+			t.Errorf("%v: item of the same target is queued twice", name)
+		}
+
+		if item, _ = m.Item(item.Id); item.Priority != 3 {
+			t.Errorf("%v: priority is not updated: got %v", name, item.Priority)
+		}
+
+		if _, e = m.Claim(item.Id, "moderator-1", "mine"); e != nil {
+			t.Fatalf("%v: %v", name, e)
+		}
+
+		if _, e = m.Claim(item.Id, "moderator-2", ""); e == nil {
+			t.Errorf("%v: claimed item is claimed by another moderator", name)
+		}
+
+		if _, e = m.Resolve(item.Id, "moderator-2", true, ""); e == nil {
+			t.Errorf("%v: claimed item is resolved by another moderator", name)
+		}
+
+		if item, e = m.Resolve(item.Id, "moderator-1", false, "not needed"); e != nil || item.Status != model.ModerationRejected || item.ResolvedAt == nil {
+			t.Fatalf("%v: wrong resolve: got %v %v", name, item, e)
+		}
+
+		if _, e = m.Check(item.Id, "moderator-1"); e == nil {
+			t.Errorf("%v: resolved item can be moderated", name)
+		}
+
+		actions, _ := m.Audit(item.Id, "")
+		want := []string{model.ActionEnqueue, model.ActionClaim, model.ActionReject}
+		if len(actions) != len(want) {
+			t.Fatalf("%v: wrong audit: got %v", name, actions)
+		}
+
+		for i, a := range actions {
+			if a.Action != want[i] {
+				t.Errorf("%v: wrong action %v: got %v want %v", name, i, a.Action, want[i])
+			}
+		}
+
+		if actions, _ = m.Audit(0, "moderator-1"); len(actions) != 2 {
+			t.Errorf("%v: wrong audit of moderator-1: got %v", name, len(actions))
+		}
+	}
+}
+
+func TestConcurrentClaim(t *testing.T) {
+	model.ConnectSync()
+	m := model.ModerationDatabase{}
+	item, e := m.Enqueue(model.ModerationItem{Kind: model.ModerationEdit, ServiceType: "atm", ServiceId: 2, Subject: "concurrent", Author: "author"})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	const moderators = 8
+	errors := make(chan error, moderators)
+	var wg sync.WaitGroup
+	for i := 0; i < moderators; i++ {
+		wg.Add(1)
+		go func(actor string) {
+			defer wg.Done()
+			_, e := m.Claim(item.Id, actor, "")
+			errors <- e
+		}("moderator-" + strconv.Itoa(i))
+	}
+
+	wg.Wait()
+	close(errors)
+	claimed := 0
+	for e := range errors {
+		if e == nil {
+			claimed++
+		} else if sres.ErrorOf(e).Code != sres.CodeConflict {
+			t.Errorf("wrong error of a lost claim: got %v", e)
+		}
+	}
+
+	if actions, _ := m.Audit(item.Id, ""); claimed != 1 || len(actions) != 2 {
+		t.Errorf("item is claimed %v times with %v actions, want once", claimed, len(actions))
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"log"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+//Notification is a message to a contributor about one of the submissions
+type Notification struct {
+	Id          int64     `gorm:"column:id"`
+	User        string    `gorm:"column:user;index:idx_notification_user"`
+	Message     string    `gorm:"column:message"`
+	ServiceType string    `gorm:"column:service_type"`
+	ServiceId   int64     `gorm:"column:service_id"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+const NotificationTableName = "notification"
+
+func (Notification) TableName() string {
+	return NotificationTableName
+}
+
+//Notifier deliver the notifications to the users, it is set by the server and nothing is delivered when it is nil
+var Notifier func(n Notification)
+
+//Notify store the notification for user and deliver it by Notifier
+//
+//db is passed in order to be used in the hooks which are ran in a transaction
+func Notify(db *gorm.DB, user string, message string, service_type string, service_id int64) (e error) {
+	if user == "" {
+		return
+	}
+
+	n := Notification{User: user, Message: message, ServiceType: service_type, ServiceId: service_id}
+	if e = db.Create(&n).Error; e != nil {
+		log.Println("[Database]", "notify", user, ":", e.Error())
+		return
+	}
+
+	if Notifier != nil {
+		go Notifier(n)
+	}
+
+	return
+}
+
+//NotificationsOf query the notifications of user, the newest is the first
+func NotificationsOf(user string) (notifications []Notification, e error) {
+	if e = Db.Where("user = ?", user).Order("id desc").Find(&notifications).Error; e != nil {
+		log.Println("[Database]", "notifications of", user, ":", e.Error())
+	}
+
+	return
+}
+
+func init() {
+	AutoMigrate(&Notification{})
+}
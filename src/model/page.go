@@ -0,0 +1,469 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"streelity/v1/config"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/golang/geo/r2"
+	"github.com/jinzhu/gorm"
+)
+
+//The sort keys of the listings, they are descending when they are prefixed by `-`
+const (
+	//SortCreated sort by the creation time, the items without it are sorted by their id
+	SortCreated    = "created"
+	SortConfidence = "confidence"
+	//SortRating sort by the average score of the services or the score of the reviews
+	SortRating = "rating"
+	//SortDistance sort by the distance to the location of the query
+	SortDistance = "distance"
+)
+
+//PageQuery is the query of a page of a listing
+type PageQuery struct {
+	//Cursor is the Next of the previous page, the first page is returned when it is empty
+	Cursor string
+	//Limit is the size of the page, see config.PagingConfig
+	Limit int
+	//Sort is one of the sort keys, the items are sorted by their id when it is empty
+	Sort string
+	//Filters are the values of the fields which the items must have by their column names
+	Filters map[string]string
+	//Location is the origin of SortDistance
+	Location *r2.Point
+	//Ratings return the average scores of the services by their id for SortRating, they are read once per page
+	Ratings func(ids ...int64) map[int64]float64
+	//RatingTable is the review table whose average score is the rating of the services, it is used by PaginateQuery
+	//for SortRating
+	RatingTable string
+}
+
+//Page is the position of a page in its listing
+type Page struct {
+	//Next is the cursor of the next page, it is empty on the last page
+	Next string `json:",omitempty"`
+	//Total is the number of the items which are matched by the filters
+	Total int
+}
+
+type cursor struct {
+	Sort string  `json:"s"`
+	Key  float64 `json:"k"`
+	Id   int64   `json:"i"`
+}
+
+func pageError(field, message string) error {
+	return FieldError{Field: field, Message: message}
+}
+
+//columnName return the column of a struct field, it is the gorm column or the snake case of the name
+func columnName(f reflect.StructField) string {
+	for _, setting := range strings.Split(f.Tag.Get("gorm"), ";") {
+		if strings.HasPrefix(setting, "column:") {
+			return strings.TrimPrefix(setting, "column:")
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range f.Name {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+//filterFields return the scalar fields of t by their column names, the fields of the embedded structs are included
+func filterFields(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for name, index := range filterFields(f.Type) {
+				fields[name] = append([]int{i}, index...)
+			}
+
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		switch f.Type.Kind() {
+		case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Float32, reflect.Float64:
+			fields[columnName(f)] = []int{i}
+		}
+	}
+
+	return fields
+}
+
+//keyOf return the function which compute the sort key of an item, ids are the items which are sorted
+func keyOf(t reflect.Type, q PageQuery, sortKey string, ids []int64) (func(v reflect.Value) float64, error) {
+	numberOf := func(name string) (func(v reflect.Value) float64, bool) {
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return nil, false
+		}
+
+		return func(v reflect.Value) float64 {
+			field := v.FieldByIndex(f.Index)
+			switch field.Kind() {
+			case reflect.Float32, reflect.Float64:
+				return field.Float()
+			}
+
+			return float64(field.Int())
+		}, true
+	}
+
+	id, _ := numberOf("Id")
+	switch sortKey {
+	case "":
+		return id, nil
+	case SortCreated:
+		f, ok := t.FieldByName("CreatedAt")
+		if !ok {
+			return id, nil
+		}
+
+		return func(v reflect.Value) float64 {
+			if created, ok := v.FieldByIndex(f.Index).Interface().(*time.Time); ok && created != nil {
+				return float64(created.Unix())
+			}
+
+			return 0
+		}, nil
+	case SortConfidence:
+		if key, ok := numberOf("Confident"); ok {
+			return key, nil
+		}
+	case SortRating:
+		if q.Ratings != nil {
+			ratings := q.Ratings(ids...)
+			return func(v reflect.Value) float64 {
+				return ratings[int64(id(v))]
+			}, nil
+		}
+
+		if key, ok := numberOf("Score"); ok {
+			return key, nil
+		}
+	case SortDistance:
+		lat, latOk := numberOf("Lat")
+		lon, lonOk := numberOf("Lon")
+		if q.Location == nil {
+			return nil, pageError("location", "location param is missing for sorting by distance")
+		}
+
+		if latOk && lonOk {
+			return func(v reflect.Value) float64 {
+				return q.Location.Sub(r2.Point{X: lat(v), Y: lon(v)}).Norm()
+			}, nil
+		}
+	default:
+		return nil, pageError("sort", "sort "+sortKey+" is unknown")
+	}
+
+	return nil, pageError("sort", "sort "+sortKey+" is not supported by this listing")
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (c cursor, e error) {
+	data, e := base64.RawURLEncoding.DecodeString(s)
+	if e == nil {
+		e = json.Unmarshal(data, &c)
+	}
+
+	if e != nil {
+		return c, pageError("cursor", "cursor is invalid")
+	}
+
+	return
+}
+
+//limitOf return the page size of limit by the paging config
+func limitOf(limit int) int {
+	c := config.Config.Paging
+	if c.DefaultLimit <= 0 {
+		c.DefaultLimit = 50
+	}
+
+	if c.MaxLimit <= 0 {
+		c.MaxLimit = 200
+	}
+
+	if limit <= 0 {
+		limit = c.DefaultLimit
+	}
+
+	return int(math.Min(float64(limit), float64(c.MaxLimit)))
+}
+
+//Paginate replace the slice which items point to by a page of it. The items are filtered, sorted by the sort key
+//and their id so the order is stable between the pages, and the items after the cursor are kept
+func Paginate(items interface{}, q PageQuery) (page Page, e error) {
+	slice := reflect.ValueOf(items).Elem()
+	t := slice.Type().Elem()
+	desc := strings.HasPrefix(q.Sort, "-")
+	fields := filterFields(t)
+	for name := range q.Filters {
+		if _, ok := fields[name]; !ok {
+			return page, pageError("filter["+name+"]", "filter "+name+" is unknown")
+		}
+	}
+
+	type entry struct {
+		key float64
+		id  int64
+		v   reflect.Value
+	}
+
+	id, _ := t.FieldByName("Id")
+	var entries []entry
+	var ids []int64
+	for i := 0; i < slice.Len(); i++ {
+		v := slice.Index(i)
+		matched := true
+		for name, value := range q.Filters {
+			if !strings.EqualFold(fmt.Sprint(v.FieldByIndex(fields[name]).Interface()), value) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			entries = append(entries, entry{id: v.FieldByIndex(id.Index).Int(), v: v})
+			ids = append(ids, v.FieldByIndex(id.Index).Int())
+		}
+	}
+
+	key, e := keyOf(t, q, strings.TrimPrefix(q.Sort, "-"), ids)
+	if e != nil {
+		return
+	}
+
+	for i := range entries {
+		entries[i].key = key(entries[i].v)
+	}
+
+	before := func(a, b entry) bool {
+		if a.key != b.key && desc {
+			return a.key > b.key
+		}
+
+		if a.key != b.key {
+			return a.key < b.key
+		}
+
+		return a.id < b.id
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return before(entries[i], entries[j]) })
+	page.Total = len(entries)
+	if q.Cursor != "" {
+		c, e := decodeCursor(q.Cursor)
+		if e != nil {
+			return page, e
+		}
+
+		if c.Sort != q.Sort {
+			return page, pageError("cursor", "cursor is not sorted by "+q.Sort)
+		}
+
+		last := entry{key: c.Key, id: c.Id}
+		start := sort.Search(len(entries), func(i int) bool { return before(last, entries[i]) })
+		entries = entries[start:]
+	}
+
+	limit := limitOf(q.Limit)
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		page.Next = encodeCursor(cursor{q.Sort, last.key, last.id})
+	}
+
+	result := reflect.MakeSlice(slice.Type(), len(entries), len(entries))
+	for i, en := range entries {
+		result.Index(i).Set(en.v)
+	}
+
+	slice.Set(result)
+	return
+}
+
+//keyExpression return the sql expression of the sort key of the items of table, it is the sql counterpart of keyOf
+//except the distance which is squared since the databases don't share a square root
+func keyExpression(db *gorm.DB, t reflect.Type, table string, q PageQuery, sortKey string) (expr string, args []interface{}, e error) {
+	column := func(name string) string {
+		return table + "." + name
+	}
+
+	has := func(name string) bool {
+		_, ok := t.FieldByName(name)
+		return ok
+	}
+
+	switch sortKey {
+	case "":
+		return column("id"), nil, nil
+	case SortCreated:
+		if !has("CreatedAt") {
+			return column("id"), nil, nil
+		}
+
+		if db.Dialect().GetName() == "sqlite3" {
+			return "COALESCE(CAST(strftime('%s', " + column("created_at") + ") AS INTEGER), 0)", nil, nil
+		}
+
+		return "COALESCE(UNIX_TIMESTAMP(" + column("created_at") + "), 0)", nil, nil
+	case SortConfidence:
+		if has("Confident") {
+			return column("confident"), nil, nil
+		}
+	case SortRating:
+		if q.RatingTable != "" {
+			return "(SELECT COALESCE(AVG(score), 0) FROM " + q.RatingTable + " WHERE service_id = " + column("id") + ")", nil, nil
+		}
+
+		if has("Score") {
+			return column("score"), nil, nil
+		}
+	case SortDistance:
+		if q.Location == nil {
+			return "", nil, pageError("location", "location param is missing for sorting by distance")
+		}
+
+		if has("Lat") && has("Lon") {
+			expr = "((" + column("lat") + " - ?) * (" + column("lat") + " - ?) + (" + column("lon") + " - ?) * (" + column("lon") + " - ?))"
+			return expr, []interface{}{q.Location.X, q.Location.X, q.Location.Y, q.Location.Y}, nil
+		}
+	default:
+		return "", nil, pageError("sort", "sort "+sortKey+" is unknown")
+	}
+
+	return "", nil, pageError("sort", "sort "+sortKey+" is not supported by this listing")
+}
+
+//filterValue return the value of a filter which is compared to the column of a field
+func filterValue(f reflect.StructField, name, value string) (interface{}, error) {
+	switch f.Type.Kind() {
+	case reflect.Bool:
+		b, e := strconv.ParseBool(value)
+		if e != nil {
+			return nil, pageError("filter["+name+"]", "filter "+name+" is not a boolean")
+		}
+
+		return b, nil
+	case reflect.String:
+		return strings.ToLower(value), nil
+	}
+
+	return value, nil
+}
+
+//PaginateQuery read the page of db into the slice which items point to, it is Paginate in sql: the filters, the
+//cursor, the order and the limit are added to db so only the page is read. db may be filtered already, the total
+//is counted after its conditions
+func PaginateQuery(db *gorm.DB, items interface{}, q PageQuery) (page Page, e error) {
+	t := reflect.TypeOf(items).Elem().Elem()
+	table := db.NewScope(items).TableName()
+	desc := strings.HasPrefix(q.Sort, "-")
+	key, args, e := keyExpression(db, t, table, q, strings.TrimPrefix(q.Sort, "-"))
+	if e != nil {
+		return
+	}
+
+	fields := filterFields(t)
+	for name, value := range q.Filters {
+		index, ok := fields[name]
+		if !ok {
+			return page, pageError("filter["+name+"]", "filter "+name+" is unknown")
+		}
+
+		f := t.FieldByIndex(index)
+		v, e := filterValue(f, name, value)
+		if e != nil {
+			return page, e
+		}
+
+		if f.Type.Kind() == reflect.String {
+			db = db.Where("LOWER("+table+"."+name+") = ?", v)
+		} else {
+			db = db.Where(table+"."+name+" = ?", v)
+		}
+	}
+
+	if e = db.Model(items).Count(&page.Total).Error; e != nil {
+		log.Println("[Database]", "count", table, e.Error())
+		return
+	}
+
+	if q.Cursor != "" {
+		c, e := decodeCursor(q.Cursor)
+		if e != nil {
+			return page, e
+		}
+
+		if c.Sort != q.Sort {
+			return page, pageError("cursor", "cursor is not sorted by "+q.Sort)
+		}
+
+		after := ">"
+		if desc {
+			after = "<"
+		}
+
+		where := append(append(append([]interface{}{}, args...), c.Key), args...)
+		db = db.Where("("+key+" "+after+" ? OR ("+key+" = ? AND "+table+".id > ?))", append(append(where, c.Key), c.Id)...)
+	}
+
+	order := key
+	if desc {
+		order += " DESC"
+	}
+
+	limit := limitOf(q.Limit)
+	db = db.Order(gorm.Expr(order+", "+table+".id", args...)).Limit(limit + 1)
+	if e = db.Find(items).Error; e != nil {
+		log.Println("[Database]", "page", table, e.Error())
+		return
+	}
+
+	slice := reflect.ValueOf(items).Elem()
+	if slice.Len() <= limit {
+		return
+	}
+
+	slice.Set(slice.Slice(0, limit))
+	id, _ := t.FieldByName("Id")
+	last := slice.Index(limit - 1).FieldByIndex(id.Index).Int()
+	c := cursor{Sort: q.Sort, Id: last}
+	//the key is read from the database instead of the item so it is equal to the key of the cursor condition
+	if e = db.New().Table(table).Select(key, args...).Where(table+".id = ?", last).Row().Scan(&c.Key); e != nil {
+		log.Println("[Database]", "page key", table, e.Error())
+		return
+	}
+
+	page.Next = encodeCursor(c)
+	return
+}
@@ -0,0 +1,182 @@
+package model_test
+
+import (
+	"streelity/v1/config"
+	"streelity/v1/model"
+	"streelity/v1/sres"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r2"
+	"github.com/jinzhu/gorm"
+)
+
+type listed struct {
+	model.Service
+	Name string `gorm:"column:name"`
+}
+
+func ids(items []listed) (result []int64) {
+	for _, item := range items {
+		result = append(result, item.Id)
+	}
+
+	return
+}
+
+func equalIds(a []int64, b ...int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+type pageCase struct {
+	name     string
+	query    model.PageQuery
+	expected []int64
+}
+
+//pageCases return the items of the pagination tests, the average scores of the items and the cases
+func pageCases() ([]listed, map[int64]float64, []pageCase) {
+	created := func(days int) *time.Time {
+		t := time.Date(2026, 1, 1+days, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	all := []listed{
+		{model.Service{Id: 1, Lat: 10, Lon: 106, Confident: 3, Contributor: "a", Lifecycle: model.Lifecycle{CreatedAt: created(2)}}, "First"},
+		{model.Service{Id: 2, Lat: 11, Lon: 107, Confident: 5, Contributor: "b", Lifecycle: model.Lifecycle{CreatedAt: created(1)}}, "Second"},
+		{model.Service{Id: 3, Lat: 12, Lon: 108, Confident: 3, Contributor: "a", Lifecycle: model.Lifecycle{CreatedAt: created(3)}}, "Third"},
+		{model.Service{Id: 4, Lat: 10.1, Lon: 106.1, Confident: 1, Contributor: "b"}, "Fourth"},
+	}
+
+	rating := map[int64]float64{1: 4, 2: 2, 3: 5, 4: 4}
+	tests := []pageCase{
+		{"by id", model.PageQuery{}, []int64{1, 2, 3, 4}},
+		{"created", model.PageQuery{Sort: "created"}, []int64{4, 2, 1, 3}},
+		{"confidence desc", model.PageQuery{Sort: "-confidence"}, []int64{2, 1, 3, 4}},
+		{"rating desc", model.PageQuery{Sort: "-rating"}, []int64{3, 1, 4, 2}},
+		{"distance", model.PageQuery{Sort: "distance", Location: &r2.Point{X: 10, Y: 106}}, []int64{1, 4, 2, 3}},
+		{"filter", model.PageQuery{Filters: map[string]string{"contributor": "A"}}, []int64{1, 3}},
+		{"filter number", model.PageQuery{Filters: map[string]string{"confident": "3", "name": "third"}}, []int64{3}},
+	}
+
+	return all, rating, tests
+}
+
+//followPages read every page of a case by their cursor, every page has 1 item
+func followPages(t *testing.T, test pageCase, paginate func(items *[]listed, q model.PageQuery) (model.Page, error)) {
+	var got []int64
+	q := test.query
+	q.Limit = 1
+	for i := 0; i <= 4; i++ {
+		var items []listed
+		page, e := paginate(&items, q)
+		if e != nil {
+			t.Fatalf("%v: paginate returned error: %v", test.name, e)
+		}
+
+		if page.Total != len(test.expected) {
+			t.Errorf("%v: wrong total: got %v want %v", test.name, page.Total, len(test.expected))
+		}
+
+		got = append(got, ids(items)...)
+		if q.Cursor = page.Next; q.Cursor == "" {
+			break
+		}
+	}
+
+	if !equalIds(got, test.expected...) {
+		t.Errorf("%v: wrong order: got %v want %v", test.name, got, test.expected)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	all, rating, tests := pageCases()
+	for _, test := range tests {
+		test.query.Ratings = func(...int64) map[int64]float64 { return rating }
+		followPages(t, test, func(items *[]listed, q model.PageQuery) (model.Page, error) {
+			*items = append([]listed{}, all...)
+			return model.Paginate(items, q)
+		})
+	}
+}
+
+func TestPaginateQuery(t *testing.T) {
+	model.ConnectSync()
+	db := model.Db
+	db.DropTableIfExists(&listed{}, "listed_review")
+	db.AutoMigrate(&listed{})
+	db.Table("listed_review").AutoMigrate(&model.Review{})
+
+	all, rating, tests := pageCases()
+	for _, item := range all {
+		db.Create(&item)
+		db.Table("listed_review").Create(&model.Review{ServiceId: item.Id, Score: float32(rating[item.Id])})
+	}
+
+	//gorm set the creation time of the items which have none
+	db.Model(&listed{}).Where("created_at > ?", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)).UpdateColumn("created_at", gorm.Expr("NULL"))
+
+	for _, test := range tests {
+		test.query.RatingTable = "listed_review"
+		followPages(t, test, func(items *[]listed, q model.PageQuery) (model.Page, error) {
+			return model.PaginateQuery(db, items, q)
+		})
+	}
+}
+
+func TestPaginateErrors(t *testing.T) {
+	sorted, _ := func() (model.Page, error) {
+		items := []listed{{Service: model.Service{Id: 1}}, {Service: model.Service{Id: 2}}}
+		return model.Paginate(&items, model.PageQuery{Sort: "created", Limit: 1})
+	}()
+
+	tests := []struct {
+		name  string
+		query model.PageQuery
+		field string
+	}{
+		{"unknown sort", model.PageQuery{Sort: "name"}, "sort"},
+		{"distance without location", model.PageQuery{Sort: "distance"}, "location"},
+		{"rating without scores", model.PageQuery{Sort: "rating"}, "sort"},
+		{"unknown filter", model.PageQuery{Filters: map[string]string{"secret": "x"}}, "filter[secret]"},
+		{"invalid cursor", model.PageQuery{Cursor: "???"}, "cursor"},
+		{"cursor of another sort", model.PageQuery{Cursor: sorted.Next, Sort: "-created"}, "cursor"},
+	}
+
+	for _, test := range tests {
+		items := []listed{{Service: model.Service{Id: 1}}}
+		_, e := model.Paginate(&items, test.query)
+		got := sres.ErrorOf(e)
+		if got == nil || got.Code != sres.CodeValidation || len(got.Fields) != 1 || got.Fields[0].Field != test.field {
+			t.Errorf("%v: wrong error: got %v", test.name, got)
+		}
+	}
+}
+
+func TestPaginateLimit(t *testing.T) {
+	paging := config.Config.Paging
+	defer func() { config.Config.Paging = paging }()
+	config.Config.Paging = config.PagingConfig{DefaultLimit: 2, MaxLimit: 3}
+
+	for _, test := range []struct{ limit, expected int }{{0, 2}, {1, 1}, {10, 3}} {
+		items := make([]listed, 5)
+		for i := range items {
+			items[i].Id = int64(i + 1)
+		}
+
+		page, _ := model.Paginate(&items, model.PageQuery{Limit: test.limit})
+		if len(items) != test.expected || page.Next == "" {
+			t.Errorf("limit %v: got %v items, next %v", test.limit, len(items), page.Next)
+		}
+	}
+}
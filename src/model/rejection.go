@@ -0,0 +1,83 @@
+package model
+
+import (
+	"log"
+	"strconv"
+	"streelity/v1/config"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+//Rejection return the reason which a submission is rejected for by the policy, empty is returned when it is not rejected.
+//confident is the net confident of the submission and spam is the spam flag of its contributor
+func Rejection(p config.Policy, confident int, spam bool) string {
+	if spam {
+		return "contributor is flagged as spam"
+	}
+
+	if p.RejectThreshold != 0 && confident <= p.RejectThreshold {
+		return "downvotes reach the reject threshold"
+	}
+
+	return ""
+}
+
+//Settle apply the consequences of the transition of a submission to its contributor: the contributor gain reputation
+//when it is confirmed, lose reputation when it is rejected and is notified when it is rejected or archived. The
+//contributor is flagged as spam when the rejected submissions reach the policy
+//
+//db is passed in order to be used in the hooks which are ran in a transaction
+func Settle(db *gorm.DB, p config.Policy, contributor string, t *Transition, service_type string, service_id int64) {
+	if t == nil || contributor == "" {
+		return
+	}
+
+	switch t.To {
+	case StateConfirmed:
+		ChangeReputation(db, contributor, ReasonConfirmed, service_type, service_id)
+	case StateRejected:
+		ChangeReputation(db, contributor, ReasonRejected, service_type, service_id)
+		Notify(db, contributor, "Your submission is rejected: "+t.Reason, service_type, service_id)
+		if p.SpamRejections > 0 && countRejections(db, contributor) >= p.SpamRejections {
+			FlagSpammer(db, contributor, true)
+		}
+	case StateArchived:
+		Notify(db, contributor, "Your submission is archived: "+t.Reason, service_type, service_id)
+	}
+}
+
+//StaleReason return the reason of archiving the submissions which are not voted in the max age of the policy
+func StaleReason(p config.Policy) string {
+	return "not voted in " + strconv.Itoa(p.MaxAgeDays) + " days"
+}
+
+//StaleIds query the pending rows of tablename which are older than the max age of the policy and are not voted
+//since then. The rows which are created before the lifecycle don't have an age so they are never stale
+func StaleIds(tablename string, p config.Policy, now time.Time) (ids []int64, e error) {
+	if p.MaxAgeDays <= 0 {
+		return
+	}
+
+	cutoff := now.AddDate(0, 0, -p.MaxAgeDays)
+	e = Db.Table(tablename).
+		Where("(state = ? OR state = '' OR state IS NULL) AND created_at < ?", StatePending, cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM "+VoteTableName+" WHERE service_type = ? AND service_id = "+tablename+".id AND updated_at >= ?)", tablename, cutoff).
+		Pluck("id", &ids).Error
+	if e != nil {
+		log.Println("[Database]", "stale", tablename, ":", e.Error())
+	}
+
+	return
+}
+
+//IsStale determine whether the submission of the lifecycle l is stale like StaleIds, voted is the time of its last vote
+//and is zero when it is not voted. It is using for the in-memory repositories
+func IsStale(p config.Policy, l Lifecycle, voted time.Time, now time.Time) bool {
+	if p.MaxAgeDays <= 0 || l.CreatedAt == nil || !IsUnconfirmed(l.GetState()) {
+		return false
+	}
+
+	cutoff := now.AddDate(0, 0, -p.MaxAgeDays)
+	return l.CreatedAt.Before(cutoff) && voted.Before(cutoff)
+}
@@ -0,0 +1,72 @@
+package model_test
+
+import (
+	"streelity/v1/config"
+	"streelity/v1/model"
+	"testing"
+	"time"
+)
+
+func TestRejection(t *testing.T) {
+	p := config.Policy{Threshold: 5, RejectThreshold: -3}
+	tests := []struct {
+		name      string
+		confident int
+		spam      bool
+		rejected  bool
+	}{
+		{"new", 0, false, false},
+		{"above threshold", -2, false, false},
+		{"reach threshold", -3, false, true},
+		{"spam", 2, true, true},
+	}
+
+	for _, test := range tests {
+		if reason := model.Rejection(p, test.confident, test.spam); (reason != "") != test.rejected {
+			t.Errorf("%v: got %q want rejected %v", test.name, reason, test.rejected)
+		}
+	}
+
+	if reason := model.Rejection(config.Policy{}, -100, false); reason != "" {
+		t.Errorf("disabled reject threshold rejects: got %q", reason)
+	}
+}
+
+func TestReject(t *testing.T) {
+	var l model.Lifecycle
+	l.Reject("spam")
+	if l.State != model.StateRejected || l.Transited() == nil || l.Transited().Actor != model.ActorSystem {
+		t.Fatalf("pending lifecycle is not rejected: got %v", l.State)
+	}
+
+	l = model.Lifecycle{State: model.StateConfirmed}
+	l.Reject("spam")
+	if l.State != model.StateConfirmed {
+		t.Errorf("confirmed lifecycle is rejected")
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	p := config.Policy{MaxAgeDays: 30}
+	now := time.Now()
+	old := now.AddDate(0, 0, -31)
+	recent := now.AddDate(0, 0, -1)
+	tests := []struct {
+		name  string
+		l     model.Lifecycle
+		voted time.Time
+		stale bool
+	}{
+		{"old without votes", model.Lifecycle{CreatedAt: &old}, time.Time{}, true},
+		{"old with recent vote", model.Lifecycle{CreatedAt: &old}, recent, false},
+		{"recent", model.Lifecycle{CreatedAt: &recent}, time.Time{}, false},
+		{"confirmed", model.Lifecycle{State: model.StateConfirmed, CreatedAt: &old}, time.Time{}, false},
+		{"without age", model.Lifecycle{}, time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		if stale := model.IsStale(p, test.l, test.voted, now); stale != test.stale {
+			t.Errorf("%v: got %v want %v", test.name, stale, test.stale)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package model
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+//The kinds of the problem reports
+const (
+	ReportClosed            = "closed"
+	ReportTemporarilyClosed = "temporarily-closed"
+	ReportWrongLocation     = "wrong-location"
+	ReportWrongDetails      = "wrong-details"
+	ReportDuplicate         = "duplicate"
+)
+
+//The statuses of the problem reports
+const (
+	ReportOpen      = "open"
+	ReportAccepted  = "accepted"
+	ReportDismissed = "dismissed"
+)
+
+//reportTransitions are the states which a service is changed to when enough reporters concur on a kind
+var reportTransitions map[string]string = map[string]string{
+	ReportClosed:            StateClosed,
+	ReportTemporarilyClosed: StateDisputed,
+	ReportWrongLocation:     StateDisputed,
+	ReportWrongDetails:      StateDisputed,
+	ReportDuplicate:         StateArchived,
+}
+
+//Report is a problem which a user report on a service, a reporter has one report of a kind on a service
+type Report struct {
+	Id          int64   `gorm:"column:id"`
+	ServiceType string  `gorm:"column:service_type;unique_index:idx_report_reporter;index:idx_report_service"`
+	ServiceId   int64   `gorm:"column:service_id;unique_index:idx_report_reporter;index:idx_report_service"`
+	Reporter    string  `gorm:"column:reporter;unique_index:idx_report_reporter"`
+	Kind        string  `gorm:"column:kind;unique_index:idx_report_reporter"`
+	Note        string  `gorm:"column:note"`
+	Lat         float32 `gorm:"column:suggested_lat"`
+	Lon         float32 `gorm:"column:suggested_lon"`
+	DuplicateOf int64   `gorm:"column:duplicate_of"`
+	Status      string  `gorm:"column:status;index"`
+	//ResolvedBy is the actor who accept or dismiss the report
+	ResolvedBy string     `gorm:"column:resolved_by"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at"`
+}
+
+const ReportTableName = "report"
+
+func (Report) TableName() string {
+	return ReportTableName
+}
+
+//IsReportKind determine whether kind is a kind of the problem reports
+func IsReportKind(kind string) bool {
+	_, ok := reportTransitions[kind]
+	return ok
+}
+
+//ReportTransition return the state which a service is changed to and the reason when enough reporters concur on
+//the kind of r
+func ReportTransition(r Report) (to string, reason string) {
+	reason = "reported as " + r.Kind
+	if r.Kind == ReportDuplicate && r.DuplicateOf != 0 {
+		reason += " of " + strconv.FormatInt(r.DuplicateOf, 10)
+	}
+
+	return reportTransitions[r.Kind], reason
+}
+
+func (r Report) validate() error {
+	if r.Reporter == "" {
+		return NewError(ErrInvalid, "reporter is missing")
+	}
+
+	if !IsReportKind(r.Kind) {
+		return NewError(ErrInvalid, "report kind is invalid")
+	}
+
+	if r.Kind == ReportWrongLocation && r.Lat == 0 && r.Lon == 0 {
+		return NewError(ErrInvalid, "suggested location is missing")
+	}
+
+	if r.Kind == ReportDuplicate && (r.DuplicateOf == 0 || r.DuplicateOf == r.ServiceId) {
+		return NewError(ErrInvalid, "duplicate_of is invalid")
+	}
+
+	return nil
+}
+
+//FileReport save the report of the reporter on a service, the previous report of the same kind is replaced and opened
+//again. The number of the open reports of the kind on the service is returned
+func FileReport(r Report) (report Report, concurring int, e error) {
+	if e = r.validate(); e != nil {
+		return
+	}
+
+	e = Db.Transaction(func(tx *gorm.DB) error {
+		var current Report
+		e := tx.Where("reporter = ? AND service_type = ? AND service_id = ? AND kind = ?", r.Reporter, r.ServiceType, r.ServiceId, r.Kind).First(&current).Error
+		switch {
+		case gorm.IsRecordNotFoundError(e):
+			r.Status = ReportOpen
+			if e := tx.Create(&r).Error; e != nil {
+				return e
+			}
+		case e != nil:
+			return e
+		default:
+			current.Note, current.Lat, current.Lon, current.DuplicateOf = r.Note, r.Lat, r.Lon, r.DuplicateOf
+			current.Status, current.ResolvedBy, current.ResolvedAt = ReportOpen, "", nil
+			if e := tx.Save(&current).Error; e != nil {
+				return e
+			}
+			r = current
+		}
+
+		report = r
+		return tx.Model(&Report{}).Where("service_type = ? AND service_id = ? AND kind = ? AND status = ?", r.ServiceType, r.ServiceId, r.Kind, ReportOpen).Count(&concurring).Error
+	})
+
+	if e != nil {
+		log.Println("[Database]", "report", r.ServiceType, r.ServiceId, ":", e.Error())
+	}
+
+	return
+}
+
+//ResolveReports change the status of the open reports of kind on a service to status by actor
+func ResolveReports(db *gorm.DB, service_type string, service_id int64, kind string, status string, actor string) (e error) {
+	now := time.Now()
+	e = db.Model(&Report{}).Where("service_type = ? AND service_id = ? AND kind = ? AND status = ?", service_type, service_id, kind, ReportOpen).
+		Updates(map[string]interface{}{"status": status, "resolved_by": actor, "resolved_at": &now}).Error
+	if e != nil {
+		log.Println("[Database]", "resolve reports", service_type, service_id, ":", e.Error())
+	}
+
+	return
+}
+
+//ReportsByService query the reports on the row of tablename which is having id, the newest is the first
+func ReportsByService(tablename string, id int64) (reports []Report, e error) {
+	if e = Db.Where("service_type = ? AND service_id = ?", tablename, id).Order("id desc").Find(&reports).Error; e != nil {
+		log.Println("[Database]", "reports", tablename, id, ":", e.Error())
+	}
+
+	return
+}
+
+//ReportsByServices query the reports on the rows of tablename which are having ids, the newest is the first
+func ReportsByServices(tablename string, ids ...int64) (reports []Report, e error) {
+	if e = Db.Where("service_type = ? AND service_id IN (?)", tablename, ids).Order("id desc").Find(&reports).Error; e != nil {
+		log.Println("[Database]", "reports", tablename, ids, ":", e.Error())
+	}
+
+	return
+}
+
+//OpenReport query the newest open report of kind on the row of tablename which is having id
+func OpenReport(tablename string, id int64, kind string) (report Report, e error) {
+	e = Db.Where("service_type = ? AND service_id = ? AND kind = ? AND status = ?", tablename, id, kind, ReportOpen).Order("id desc").First(&report).Error
+	return
+}
+
+//OpenReports query the reports which are waiting for the moderation, the oldest is the first
+func OpenReports() (reports []Report, e error) {
+	if e = Db.Where("status = ?", ReportOpen).Order("id").Find(&reports).Error; e != nil {
+		log.Println("[Database]", "open reports", e.Error())
+	}
+
+	return
+}
+
+//ReportBook is the in-memory reports of the in-memory repositories
+type ReportBook struct {
+	lastId  int64
+	reports []Report
+}
+
+//File save the report like FileReport
+func (b *ReportBook) File(r Report) (report Report, concurring int, e error) {
+	if e = r.validate(); e != nil {
+		return
+	}
+
+	now := time.Now()
+	r.Status = ReportOpen
+	r.UpdatedAt = now
+	index := -1
+	for i, current := range b.reports {
+		if current.Reporter == r.Reporter && current.ServiceType == r.ServiceType && current.ServiceId == r.ServiceId && current.Kind == r.Kind {
+			index = i
+			break
+		}
+	}
+
+	if index >= 0 {
+		r.Id = b.reports[index].Id
+		r.CreatedAt = b.reports[index].CreatedAt
+		b.reports[index] = r
+	} else {
+		b.lastId++
+		r.Id = b.lastId
+		r.CreatedAt = now
+		b.reports = append(b.reports, r)
+	}
+
+	for _, current := range b.reports {
+		if current.ServiceType == r.ServiceType && current.ServiceId == r.ServiceId && current.Kind == r.Kind && current.Status == ReportOpen {
+			concurring++
+		}
+	}
+
+	return r, concurring, nil
+}
+
+//Resolve change the status of the open reports like ResolveReports
+func (b *ReportBook) Resolve(service_type string, service_id int64, kind string, status string, actor string) {
+	now := time.Now()
+	for i, r := range b.reports {
+		if r.ServiceType == service_type && r.ServiceId == service_id && r.Kind == kind && r.Status == ReportOpen {
+			b.reports[i].Status = status
+			b.reports[i].ResolvedBy = actor
+			b.reports[i].ResolvedAt = &now
+		}
+	}
+}
+
+//Open return the newest open report of kind on a service like OpenReport
+func (b *ReportBook) Open(service_type string, service_id int64, kind string) (report Report, e error) {
+	for _, r := range b.Reports(service_type, service_id) {
+		if r.Kind == kind && r.Status == ReportOpen {
+			return r, nil
+		}
+	}
+
+	return report, gorm.ErrRecordNotFound
+}
+
+//Reports return the reports on the services, the newest is the first
+func (b *ReportBook) Reports(service_type string, service_ids ...int64) (reports []Report) {
+	for _, r := range b.reports {
+		for _, id := range service_ids {
+			if r.ServiceType == service_type && r.ServiceId == id {
+				reports = append(reports, r)
+				break
+			}
+		}
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Id > reports[j].Id })
+	return
+}
+
+func init() {
+	AutoMigrate(&Report{})
+}
+
+//IsOpen determine whether a service in state is operating, it is not when it is closed, rejected or archived or one
+//of its open reports says it is closed
+func IsOpen(state string, reports []Report) bool {
+	switch state {
+	case StateClosed, StateRejected, StateArchived:
+		return false
+	}
+
+	for _, r := range reports {
+		if r.Kind == ReportClosed && r.Status == ReportOpen {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,293 @@
+package model
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	ReasonConfirmed = "confirmed"
+	ReasonRejected  = "rejected"
+	ReasonDuplicate = "duplicate"
+)
+
+//the reputation changes of the contributor of a submission
+var reputationChanges map[string]int = map[string]int{
+	ReasonConfirmed: 10,
+	ReasonRejected:  -10,
+	ReasonDuplicate: -5,
+}
+
+const (
+	//ReputationPerLevel is the reputation which a contributor need to gain one more level,
+	//each level add 1 to the weight of votes and reduce 1 from the threshold of submissions
+	ReputationPerLevel = 50
+	MaxVoteWeight      = 5
+)
+
+//Reputation is the current reputation of a contributor
+type Reputation struct {
+	User       string    `gorm:"column:user;primary_key"`
+	Reputation int       `gorm:"column:reputation"`
+	//Spam is set when the contributor is flagged as spam, the submissions of the contributor are rejected
+	Spam      bool      `gorm:"column:spam"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+const ReputationTableName = "reputation"
+
+func (Reputation) TableName() string {
+	return ReputationTableName
+}
+
+//ReputationEvent is an entry of the reputation history of a contributor
+type ReputationEvent struct {
+	Id          int64     `gorm:"column:id"`
+	User        string    `gorm:"column:user;index:idx_reputation_event_user"`
+	Delta       int       `gorm:"column:delta"`
+	Reason      string    `gorm:"column:reason"`
+	ServiceType string    `gorm:"column:service_type"`
+	ServiceId   int64     `gorm:"column:service_id"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+const ReputationEventTableName = "reputation_event"
+
+func (ReputationEvent) TableName() string {
+	return ReputationEventTableName
+}
+
+//Level return the level of the reputation, the level of negative reputation is negative
+func (r Reputation) Level() int {
+	return r.Reputation / ReputationPerLevel
+}
+
+//VoteWeight return the weight of a vote which is casted by a voter who is having the reputation
+func VoteWeight(reputation int) int {
+	weight := 1 + reputation/ReputationPerLevel
+	if weight < 1 {
+		return 1
+	}
+
+	if weight > MaxVoteWeight {
+		return MaxVoteWeight
+	}
+
+	return weight
+}
+
+//Threshold return the confident which a submission need to be confirmed, base is the threshold of
+//the service type and reputation is the reputation of contributor
+func Threshold(base int, reputation int) int {
+	threshold := base - reputation/ReputationPerLevel
+	if threshold < 1 {
+		return 1
+	}
+
+	if threshold > base*2 {
+		return base * 2
+	}
+
+	return threshold
+}
+
+//ReputationOf query the reputation of user, the reputation of unknown user is 0
+//
+//db is passed in order to be used in the hooks which are ran in a transaction
+func ReputationOf(db *gorm.DB, user string) int {
+	var r Reputation
+	if e := db.Where("user = ?", user).First(&r).Error; e != nil && !gorm.IsRecordNotFoundError(e) {
+		log.Println("[Database]", "reputation of", user, ":", e.Error())
+	}
+
+	return r.Reputation
+}
+
+//AllReputations query the reputation of every contributor, it is using for loading many services at once
+func AllReputations() map[string]int {
+	var reputations []Reputation
+	if e := Db.Find(&reputations).Error; e != nil {
+		log.Println("[Database]", "all reputations", e.Error())
+	}
+
+	result := make(map[string]int)
+	for _, r := range reputations {
+		result[r.User] = r.Reputation
+	}
+
+	return result
+}
+
+//ChangeReputation change the reputation of the contributor of a submission by the reason,
+//a reason is applied once for a submission so saving a service many times doesn't farm the reputation
+func ChangeReputation(db *gorm.DB, user string, reason string, service_type string, service_id int64) (e error) {
+	change, ok := reputationChanges[reason]
+	if !ok || user == "" {
+		return
+	}
+
+	var count int
+	if e = db.Model(&ReputationEvent{}).Where("user = ? AND reason = ? AND service_type = ? AND service_id = ?", user, reason, service_type, service_id).Count(&count).Error; e != nil || count > 0 {
+		return
+	}
+
+	event := ReputationEvent{User: user, Delta: change, Reason: reason, ServiceType: service_type, ServiceId: service_id}
+	if e = db.Create(&event).Error; e != nil {
+		log.Println("[Database]", "reputation event", user, ":", e.Error())
+		return
+	}
+
+	var r Reputation
+	if e = db.Where("user = ?", user).First(&r).Error; gorm.IsRecordNotFoundError(e) {
+		r = Reputation{User: user, Reputation: change}
+		e = db.Create(&r).Error
+	} else if e == nil {
+		e = db.Model(&r).UpdateColumns(map[string]interface{}{"reputation": gorm.Expr("reputation + ?", change), "updated_at": time.Now()}).Error
+	}
+
+	if e != nil {
+		log.Println("[Database]", "change reputation", user, ":", e.Error())
+	}
+
+	return
+}
+
+//IsSpammer determine whether user is flagged as spam
+//
+//db is passed in order to be used in the hooks which are ran in a transaction
+func IsSpammer(db *gorm.DB, user string) bool {
+	var r Reputation
+	if e := db.Where("user = ?", user).First(&r).Error; e != nil && !gorm.IsRecordNotFoundError(e) {
+		log.Println("[Database]", "spam of", user, ":", e.Error())
+	}
+
+	return r.Spam
+}
+
+//FlagSpammer set or clear the spam flag of user
+func FlagSpammer(db *gorm.DB, user string, spam bool) (e error) {
+	var r Reputation
+	if e = db.Where("user = ?", user).First(&r).Error; gorm.IsRecordNotFoundError(e) {
+		r = Reputation{User: user, Spam: spam}
+		e = db.Create(&r).Error
+	} else if e == nil {
+		e = db.Model(&r).UpdateColumns(map[string]interface{}{"spam": spam, "updated_at": time.Now()}).Error
+	}
+
+	if e != nil {
+		log.Println("[Database]", "flag spammer", user, ":", e.Error())
+	}
+
+	return
+}
+
+//countRejections count the rejected submissions of user
+func countRejections(db *gorm.DB, user string) (count int) {
+	if e := db.Model(&ReputationEvent{}).Where("user = ? AND reason = ?", user, ReasonRejected).Count(&count).Error; e != nil {
+		log.Println("[Database]", "rejections of", user, ":", e.Error())
+	}
+
+	return
+}
+
+//ReputationHistory query the reputation events of user, the newest is the first
+func ReputationHistory(user string) (events []ReputationEvent, e error) {
+	if e = Db.Where("user = ?", user).Order("id desc").Find(&events).Error; e != nil {
+		log.Println("[Database]", "reputation history", user, ":", e.Error())
+	}
+
+	return
+}
+
+//ReputationRepository determine the data access of the reputations
+type ReputationRepository interface {
+	ReputationOf(user string) Reputation
+	ReputationHistory(user string) ([]ReputationEvent, error)
+	FlagSpammer(user string, spam bool) error
+}
+
+//ReputationDatabase is the ReputationRepository which is working on model.Db
+type ReputationDatabase struct{}
+
+func (ReputationDatabase) ReputationOf(user string) Reputation {
+	return Reputation{User: user, Reputation: ReputationOf(Db, user)}
+}
+
+func (ReputationDatabase) ReputationHistory(user string) ([]ReputationEvent, error) {
+	return ReputationHistory(user)
+}
+
+func (ReputationDatabase) FlagSpammer(user string, spam bool) error {
+	return FlagSpammer(Db, user, spam)
+}
+
+//ReputationMemory is the in-memory ReputationRepository, its reputations are changed by Change
+type ReputationMemory struct {
+	mutex    sync.Mutex
+	events   []ReputationEvent
+	spammers map[string]bool
+}
+
+//Change change the reputation of user like ChangeReputation but without the once checking
+func (m *ReputationMemory) Change(user string, reason string, service_type string, service_id int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.events = append(m.events, ReputationEvent{
+		Id:          int64(len(m.events) + 1),
+		User:        user,
+		Delta:       reputationChanges[reason],
+		Reason:      reason,
+		ServiceType: service_type,
+		ServiceId:   service_id,
+		CreatedAt:   time.Now(),
+	})
+}
+
+func (m *ReputationMemory) ReputationOf(user string) (r Reputation) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	r.User = user
+	r.Spam = m.spammers[user]
+	for _, e := range m.events {
+		if e.User == user {
+			r.Reputation += e.Delta
+			r.UpdatedAt = e.CreatedAt
+		}
+	}
+
+	return
+}
+
+func (m *ReputationMemory) ReputationHistory(user string) (events []ReputationEvent, e error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for loop := len(m.events) - 1; loop >= 0; loop-- {
+		if m.events[loop].User == user {
+			events = append(events, m.events[loop])
+		}
+	}
+
+	return
+}
+
+func (m *ReputationMemory) FlagSpammer(user string, spam bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.spammers == nil {
+		m.spammers = make(map[string]bool)
+	}
+
+	m.spammers[user] = spam
+	return nil
+}
+
+func init() {
+	AutoMigrate(&Reputation{}, &ReputationEvent{})
+}
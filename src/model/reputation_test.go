@@ -0,0 +1,59 @@
+package model_test
+
+import (
+	"streelity/v1/model"
+	"testing"
+)
+
+func TestVoteWeight(t *testing.T) {
+	tests := []struct {
+		reputation int
+		weight     int
+		threshold  int
+	}{
+		{-200, 1, 9},
+		{-50, 1, 6},
+		{0, 1, 5},
+		{49, 1, 5},
+		{50, 2, 4},
+		{150, 4, 2},
+		{1000, model.MaxVoteWeight, 1},
+	}
+
+	for _, test := range tests {
+		if weight := model.VoteWeight(test.reputation); weight != test.weight {
+			t.Errorf("wrong weight of reputation %v: got %v want %v", test.reputation, weight, test.weight)
+		}
+
+		if threshold := model.Threshold(5, test.reputation); threshold != test.threshold {
+			t.Errorf("wrong threshold of reputation %v: got %v want %v", test.reputation, threshold, test.threshold)
+		}
+	}
+}
+
+func TestChangeReputation(t *testing.T) {
+	model.ConnectSync()
+	user := "reputation-test"
+
+	model.ChangeReputation(model.Db, user, model.ReasonConfirmed, "atm", 1)
+	model.ChangeReputation(model.Db, user, model.ReasonConfirmed, "atm", 1)
+	model.ChangeReputation(model.Db, user, model.ReasonConfirmed, "atm", 2)
+	model.ChangeReputation(model.Db, user, model.ReasonDuplicate, "atm", 3)
+
+	if r := model.ReputationOf(model.Db, user); r != 15 {
+		t.Errorf("wrong reputation: got %v want 15", r)
+	}
+
+	history, e := model.ReputationHistory(user)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if len(history) != 3 {
+		t.Fatalf("wrong history: got %v want 3", len(history))
+	}
+
+	if history[0].Reason != model.ReasonDuplicate || history[0].Delta != -5 {
+		t.Errorf("the newest event is not the first: %v", history[0])
+	}
+}
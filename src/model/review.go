@@ -1,9 +1,22 @@
 package model
 
+import "github.com/jinzhu/gorm"
+
 type Review struct {
 	Id        int64
 	ServiceId int64   `gorm:"column:service_id"`
 	Reviewer  string   `gorm:"column:reviewer"`
 	Score     float32 `gorm:"column:score"`
 	Body      string  `gorm:"column:body"`
-}
\ No newline at end of file
+}
+
+//ReviewsFrom filter db to the reviews of table of the service by specific id from the review of order, the reviews
+//are ordered by their id so the skipped reviews are found by a subquery instead of an offset
+func ReviewsFrom(db *gorm.DB, table string, service_id, order int64) *gorm.DB {
+	db = db.Where(table+".service_id = ?", service_id)
+	if order > 0 {
+		db = db.Where(table+".id >= (SELECT id FROM "+table+" WHERE service_id = ? ORDER BY id LIMIT 1 OFFSET ?)", service_id, order)
+	}
+
+	return db
+}
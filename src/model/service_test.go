@@ -6,6 +6,7 @@ import (
 )
 
 func TestQueryService(t *testing.T) {
+	model.ConnectSync()
 	s := model.Service{Lat: 2, Lon: 2}
 	model.Db.Find(&s)
 
@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"github.com/golang/geo/r2"
+	"github.com/jinzhu/gorm"
 	"github.com/nvnamsss/goinf/spatial"
 )
 
@@ -27,6 +28,7 @@ type ServiceUcf struct {
 	Confident   int     `gorm:"column:confident"`
 	Images      string  `gorm:"column:images"`
 	Contributor string  `gorm:"column:contributor"`
+	Lifecycle
 }
 
 type Service struct {
@@ -38,6 +40,7 @@ type Service struct {
 	Images      string  `gorm:"column:images"`
 	Contributor string  `gorm:"column:contributor"`
 	Confident   int     `gorm:"column:confident"`
+	Lifecycle
 }
 
 func (s Service) GetImagesArray() (images []string) {
@@ -103,6 +106,14 @@ func distance(p1 r2.Point, p2 r2.Point) float64 {
 	return math.Sqrt(x + y)
 }
 
+//InRange filter db to the rows of table which are in the radius of p, the bounding box of the radius is matched
+//first so the rows are found by an index on lat and lon instead of computing the distance of every row
+func InRange(db *gorm.DB, table string, p r2.Point, max_range float64) *gorm.DB {
+	lat, lon := table+".lat", table+".lon"
+	return db.Where(lat+" BETWEEN ? AND ? AND "+lon+" BETWEEN ? AND ?", p.X-max_range, p.X+max_range, p.Y-max_range, p.Y+max_range).
+		Where("("+lat+" - ?) * ("+lat+" - ?) + ("+lon+" - ?) * ("+lon+" - ?) < ?", p.X, p.X, p.Y, p.Y, max_range*max_range)
+}
+
 // func QueryService(s Service) {
 // 	if e := Db.Find(&s).Error; e != nil {
 // 		log.Println(e)
@@ -0,0 +1,58 @@
+package toilet
+
+import (
+	"net/url"
+	"streelity/v1/model"
+
+	"github.com/golang/geo/r2"
+)
+
+//moderated adapt a Repository to model.ModerationTarget
+type moderated struct {
+	Repository
+}
+
+func (moderated) Tables() (string, string) {
+	return ServiceTableName, UcfServiceTableName
+}
+
+func (r moderated) BaseService(id int64) (model.Service, error) {
+	s, e := r.ServiceById(id)
+	return s.Service, e
+}
+
+func (r moderated) BaseUcf(id int64) (model.ServiceUcf, error) {
+	s, e := r.UcfById(id)
+	return s.ServiceUcf, e
+}
+
+func (r moderated) BaseReview(review_id int64) (model.Review, error) {
+	review, e := r.ReviewById(review_id)
+	return review.Review, e
+}
+
+func (r moderated) TransitBase(id int64, to, actor, reason string) error {
+	_, e := r.TransitService(id, to, actor, reason)
+	return e
+}
+
+func (r moderated) UpdateBase(id int64, values url.Values) error {
+	_, e := r.UpdateService(id, values)
+	return e
+}
+
+func (d Database) Locate(service_type string, id int64) (r2.Point, error) {
+	return model.Locate(moderated{d}, service_type, id)
+}
+
+func (d Database) Moderate(item model.ModerationItem, approve bool, actor string, note string) error {
+	return model.Moderate(moderated{d}, item, approve, actor, note)
+}
+
+func (m *Memory) Locate(service_type string, id int64) (r2.Point, error) {
+	return model.Locate(moderated{m}, service_type, id)
+}
+
+func (m *Memory) Moderate(item model.ModerationItem, approve bool, actor string, note string) error {
+	return model.Moderate(moderated{m}, item, approve, actor, note)
+}
@@ -0,0 +1,282 @@
+package toilet
+
+import (
+	"net/url"
+	"streelity/v1/model"
+
+	"github.com/golang/geo/r2"
+)
+
+//ServiceRepository determine the data access of the toilet services
+type ServiceRepository interface {
+	AllServices() ([]Toilet, error)
+	PageServices(q model.PageQuery, states ...string) ([]Toilet, model.Page, error)
+	ServiceById(id int64) (Toilet, error)
+	ServiceByLocation(lat, lon float64) (Toilet, error)
+	ServiceByAddress(address string) (Toilet, error)
+	ServicesByAddress(address string) ([]Toilet, error)
+	PageServicesByAddress(address string, q model.PageQuery) ([]Toilet, model.Page, error)
+	ServicesByIds(ids ...int64) []Toilet
+	ServicesInRange(p r2.Point, max_range float64) []Toilet
+	PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) ([]Toilet, model.Page, error)
+	CreateService(s Toilet) (Toilet, error)
+	UpdateService(id int64, values url.Values) (Toilet, error)
+	UpvoteService(id int64, voter string) error
+	DownvoteService(id int64, voter string) error
+	WithdrawVote(id int64, voter string) error
+	VotesByService(id int64) ([]model.Vote, error)
+	TransitService(id int64, to, actor, reason string) (Toilet, error)
+	TransitionsByService(id int64) ([]model.Transition, error)
+	ServicesByState(states ...string) ([]Toilet, error)
+	ArchiveStale() (int, error)
+	VerifyService(id int64) (Toilet, error)
+	DecayServices() (int, error)
+	ReverifyQueue(p r2.Point, max_range float64) []Toilet
+	ReportService(id int64, r model.Report) (model.Report, error)
+	ReportsByService(id int64) ([]model.Report, error)
+	ReportsByServices(ids ...int64) ([]model.Report, error)
+	AcceptReport(id int64, kind string, actor string) (model.Report, error)
+	DismissReports(id int64, kind string, actor string) error
+	Import(bytes []byte, t string) error
+}
+
+//UcfRepository determine the data access of the unconfirmed toilet services
+type UcfRepository interface {
+	AllUcfs() []ToiletUcf
+	PageUcfs(q model.PageQuery) ([]ToiletUcf, model.Page, error)
+	UcfById(id int64) (ToiletUcf, error)
+	UcfByLocation(lat, lon float64) (ToiletUcf, error)
+	UcfByAddress(address string) (ToiletUcf, error)
+	UcfsByAddress(address string) ([]ToiletUcf, error)
+	PageUcfsByAddress(address string, q model.PageQuery) ([]ToiletUcf, model.Page, error)
+	UcfInRange(p r2.Point, max_range float64) []Toilet
+	CreateUcf(s ToiletUcf) (ToiletUcf, error)
+	DeleteUcf(id int64, actor string) error
+	TransitUcf(id int64, to, actor, reason string) error
+	UpvoteUcf(id int64, voter string) error
+}
+
+//ReviewRepository determine the data access of the toilet reviews
+type ReviewRepository interface {
+	CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error)
+	DeleteReview(review_id int64) error
+	ReviewByService(service_id, order int64, limit int) ([]Review, error)
+	PageReviews(service_id, order int64, q model.PageQuery) ([]Review, model.Page, error)
+	ReviewsByServices(ids ...int64) ([]Review, error)
+	ReviewById(review_id int64) (Review, error)
+	ReviewAverageScore(service_id int64) float64
+	ReviewAverageScores(ids ...int64) map[int64]float64
+	SaveReview(r Review) error
+}
+
+//Repository is the whole data access of the toilet package which is injected into the router
+type Repository interface {
+	ServiceRepository
+	UcfRepository
+	ReviewRepository
+	model.Moderated
+}
+
+//Database is the Repository which is stored in model.Db
+type Database struct{}
+
+func (Database) AllServices() ([]Toilet, error) {
+	return AllServices()
+}
+
+func (Database) PageServices(q model.PageQuery, states ...string) ([]Toilet, model.Page, error) {
+	return PageServices(q, states...)
+}
+
+func (Database) ServiceById(id int64) (Toilet, error) {
+	return ServiceById(id)
+}
+
+func (Database) ServiceByLocation(lat, lon float64) (Toilet, error) {
+	return ServiceByLocation(lat, lon)
+}
+
+func (Database) ServiceByAddress(address string) (Toilet, error) {
+	return ServiceByAddress(address)
+}
+
+func (Database) ServicesByAddress(address string) ([]Toilet, error) {
+	return ServicesByAddress(address)
+}
+
+func (Database) PageServicesByAddress(address string, q model.PageQuery) ([]Toilet, model.Page, error) {
+	return PageServicesByAddress(address, q)
+}
+
+func (Database) ServicesByIds(ids ...int64) []Toilet {
+	return ServicesByIds(ids...)
+}
+
+func (Database) ServicesInRange(p r2.Point, max_range float64) []Toilet {
+	return ServicesInRange(p, max_range)
+}
+
+func (Database) PageServicesInRange(p r2.Point, max_range float64, q model.PageQuery, states ...string) ([]Toilet, model.Page, error) {
+	return PageServicesInRange(p, max_range, q, states...)
+}
+
+func (Database) CreateService(s Toilet) (Toilet, error) {
+	return CreateService(s)
+}
+
+func (Database) UpdateService(id int64, values url.Values) (Toilet, error) {
+	return UpdateService(id, values)
+}
+
+func (Database) UpvoteService(id int64, voter string) error {
+	return UpvoteService(id, voter)
+}
+
+func (Database) DownvoteService(id int64, voter string) error {
+	return DownvoteService(id, voter)
+}
+
+func (Database) WithdrawVote(id int64, voter string) error {
+	return WithdrawVote(id, voter)
+}
+
+func (Database) VotesByService(id int64) ([]model.Vote, error) {
+	return VotesByService(id)
+}
+
+func (Database) TransitService(id int64, to, actor, reason string) (Toilet, error) {
+	return TransitService(id, to, actor, reason)
+}
+
+func (Database) TransitionsByService(id int64) ([]model.Transition, error) {
+	return TransitionsByService(id)
+}
+
+func (Database) ServicesByState(states ...string) ([]Toilet, error) {
+	return ServicesByState(states...)
+}
+
+func (Database) ArchiveStale() (int, error) {
+	return ArchiveStale()
+}
+
+func (Database) VerifyService(id int64) (Toilet, error) {
+	return VerifyService(id)
+}
+
+func (Database) DecayServices() (int, error) {
+	return DecayServices()
+}
+
+func (Database) ReverifyQueue(p r2.Point, max_range float64) []Toilet {
+	return ReverifyQueue(p, max_range)
+}
+
+func (Database) ReportService(id int64, r model.Report) (model.Report, error) {
+	return ReportService(id, r)
+}
+
+func (Database) ReportsByService(id int64) ([]model.Report, error) {
+	return ReportsByService(id)
+}
+
+func (Database) ReportsByServices(ids ...int64) ([]model.Report, error) {
+	return ReportsByServices(ids...)
+}
+
+func (Database) AcceptReport(id int64, kind string, actor string) (model.Report, error) {
+	return AcceptReport(id, kind, actor)
+}
+
+func (Database) DismissReports(id int64, kind string, actor string) error {
+	return DismissReports(id, kind, actor)
+}
+
+func (Database) Import(bytes []byte, t string) error {
+	return Import(bytes, t)
+}
+
+func (Database) AllUcfs() []ToiletUcf {
+	return AllToiletUcfs()
+}
+
+func (Database) PageUcfs(q model.PageQuery) ([]ToiletUcf, model.Page, error) {
+	return PageUcfs(q)
+}
+
+func (Database) UcfById(id int64) (ToiletUcf, error) {
+	return UcfById(id)
+}
+
+func (Database) UcfByLocation(lat, lon float64) (ToiletUcf, error) {
+	return UcfByLocation(lat, lon)
+}
+
+func (Database) UcfByAddress(address string) (ToiletUcf, error) {
+	return UcfByAddress(address)
+}
+
+func (Database) UcfsByAddress(address string) ([]ToiletUcf, error) {
+	return UcfsByAddress(address)
+}
+
+func (Database) PageUcfsByAddress(address string, q model.PageQuery) ([]ToiletUcf, model.Page, error) {
+	return PageUcfsByAddress(address, q)
+}
+
+func (Database) UcfInRange(p r2.Point, max_range float64) []Toilet {
+	return UcfInRange(p, max_range)
+}
+
+func (Database) CreateUcf(s ToiletUcf) (ToiletUcf, error) {
+	return CreateUcf(s)
+}
+
+func (Database) DeleteUcf(id int64, actor string) error {
+	return DeleteUcf(id, actor)
+}
+
+func (Database) TransitUcf(id int64, to, actor, reason string) error {
+	return TransitUcf(id, to, actor, reason)
+}
+
+func (Database) UpvoteUcf(id int64, voter string) error {
+	return UpvoteUcf(id, voter)
+}
+
+func (Database) CreateReview(service_id int64, reviewer string, score float32, body string) (Review, error) {
+	return CreateReview(service_id, reviewer, score, body)
+}
+
+func (Database) DeleteReview(review_id int64) error {
+	return DeleteReview(review_id)
+}
+
+func (Database) ReviewByService(service_id, order int64, limit int) ([]Review, error) {
+	return ReviewByService(service_id, order, limit)
+}
+
+func (Database) PageReviews(service_id, order int64, q model.PageQuery) ([]Review, model.Page, error) {
+	return PageReviews(service_id, order, q)
+}
+
+func (Database) ReviewsByServices(ids ...int64) ([]Review, error) {
+	return ReviewsByServices(ids...)
+}
+
+func (Database) ReviewById(review_id int64) (Review, error) {
+	return ReviewById(review_id)
+}
+
+func (Database) ReviewAverageScore(service_id int64) float64 {
+	return ReviewAverageScore(service_id)
+}
+
+func (Database) ReviewAverageScores(ids ...int64) map[int64]float64 {
+	return ReviewAverageScores(ids...)
+}
+
+func (Database) SaveReview(r Review) error {
+	r.db = model.Db
+	return r.Save()
+}
@@ -2,7 +2,6 @@ package toilet
 
 import (
 	"log"
-	"math"
 	"streelity/v1/model"
 
 	"github.com/jinzhu/gorm"
@@ -27,8 +26,11 @@ func CreateReview(service_id int64, reviewer string, score float32, body string)
 
 	if e := model.Db.Create(&review).Error; e != nil {
 		log.Println("[Database]", "create toilet review", e.Error())
+		return review, e
 	}
 
+	//a review is a positive signal that the service is still there
+	VerifyService(service_id)
 	return
 }
 
@@ -43,16 +45,41 @@ func DeleteReview(review_id int64) (e error) {
 }
 
 func ReviewByService(service_id, order int64, limit int) (reviews []Review, e error) {
-	if limit < 0 {
-		limit = math.MaxInt64
+	//the reviews are ordered by their id, so an order is the same review between the requests
+	db := model.ReviewsFrom(model.Db, ReviewTableName, service_id, order).Order("id")
+	if limit >= 0 {
+		db = db.Limit(limit)
 	}
 
-	if e := model.Db.Where("service_id=?", service_id).Offset(order).Limit(limit).Find(&reviews).Error; e != nil {
+	if e = db.Find(&reviews).Error; e != nil {
 		log.Println("[Database]", "get toilet reviews", e.Error())
 	}
 
-	for _, review := range reviews {
-		review.db = model.Db
+	for i := range reviews {
+		reviews[i].db = model.Db
+	}
+	return
+}
+
+//PageReviews return the page of the reviews of the toilet service by specific id from the review of order which is
+//queried by q
+func PageReviews(service_id, order int64, q model.PageQuery) (reviews []Review, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.ReviewsFrom(model.Db, ReviewTableName, service_id, order), &reviews, q)
+	for i := range reviews {
+		reviews[i].db = model.Db
+	}
+
+	return
+}
+
+//ReviewsByServices query the reviews of the toilet services by specific ids, the newest is the first
+func ReviewsByServices(ids ...int64) (reviews []Review, e error) {
+	if e = model.Db.Where("service_id IN (?)", ids).Order("id desc").Find(&reviews).Error; e != nil {
+		log.Println("[Database]", "get toilet reviews of services", e.Error())
+	}
+
+	for i := range reviews {
+		reviews[i].db = model.Db
 	}
 	return
 }
@@ -68,13 +95,35 @@ func ReviewById(review_id int64) (review Review, e error) {
 }
 
 func ReviewAverageScore(service_id int64) (average float64) {
-	if e := model.Db.Table(ReviewTableName).Select("avg(score)").Where("service_id=?", service_id).Row().Scan(&average); e != nil {
+	if e := model.Db.Table(ReviewTableName).Select("COALESCE(avg(score), 0)").Where("service_id=?", service_id).Row().Scan(&average); e != nil {
 		log.Println("[Database]", "toilet review average score", e.Error())
 	}
 
 	return
 }
 
+//ReviewAverageScores return the average scores of the toilet services by their id, the services without reviews are
+//missing
+func ReviewAverageScores(ids ...int64) (averages map[int64]float64) {
+	averages = make(map[int64]float64)
+	rows, e := model.Db.Table(ReviewTableName).Select("service_id, AVG(score)").Where("service_id IN (?)", ids).Group("service_id").Rows()
+	if e != nil {
+		log.Println("[Database]", "toilet review average scores", e.Error())
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var average float64
+		if e := rows.Scan(&id, &average); e == nil {
+			averages[id] = average
+		}
+	}
+
+	return
+}
+
 func (r Review) Save() (e error) {
 	if e := r.db.Save(&r).Error; e != nil {
 		log.Println("[Database]", "save toilet review", e.Error())
@@ -30,3 +30,24 @@ func TestCreateService(t *testing.T) {
 
 	t.Logf("Completed")
 }
+
+func TestServiceByLocation(t *testing.T) {
+	model.ConnectSync()
+
+	var s toilet.Toilet
+	s.Lat = 10.762622
+	s.Lon = 106.660172
+	s.Name = "Location"
+	if _, e := toilet.CreateService(s); e != nil {
+		t.Fatal(e)
+	}
+
+	service, e := toilet.ServiceByLocation(float64(s.Lat), float64(s.Lon))
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if service.Name != s.Name {
+		t.Errorf("Service by location failed, expected %v, got %v", s.Name, service.Name)
+	}
+}
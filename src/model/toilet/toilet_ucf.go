@@ -1,8 +1,8 @@
 package toilet
 
 import (
-	"errors"
 	"log"
+	"streelity/v1/config"
 	"streelity/v1/model"
 
 	"github.com/golang/geo/r2"
@@ -14,12 +14,16 @@ type ToiletUcf struct {
 	model.ServiceUcf
 }
 
-var confident int = 5
-var map_ucfservices map[int64]Toilet
+var map_ucfservices map[int64]Toilet = make(map[int64]Toilet)
 var ucf_services spatial.RTree
 
 const UcfServiceTableName = "toilet_ucf"
 
+//policy return the confirmation policy of the toilet services
+func policy() config.Policy {
+	return config.PolicyOf(ServiceTableName)
+}
+
 //TableName determine the table name in database which is using for gorm
 func (ToiletUcf) TableName() string {
 	return "toilet_ucf"
@@ -38,28 +42,27 @@ func AllToiletUcfs() []ToiletUcf {
 	return services
 }
 
-//UpvoteUcf upvote the unconfirmed toilet by specific id
-func UpvoteUcf(id int64) error {
-	return upvoteToiletUcf(id, 1)
+//PageUcfs return the page of the unconfirmed toilet services which is queried by q
+func PageUcfs(q model.PageQuery) (ucfs []ToiletUcf, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.Db, &ucfs, q)
+	return
 }
 
-func UpvoteUcfImmediately(id int64) error {
-	return upvoteToiletUcf(id, confident)
+//PageUcfsByAddress return the page of the unconfirmed toilet services whose address contain address which is queried
+//by q
+func PageUcfsByAddress(address string, q model.PageQuery) (ucfs []ToiletUcf, page model.Page, e error) {
+	page, e = model.PaginateQuery(model.Db.Where("address LIKE ?", "%"+address+"%"), &ucfs, q)
+	return
 }
 
-func upvoteToiletUcf(id int64, value int) (e error) {
-	s, e := UcfById(id)
-
-	if e != nil {
-		return
-	}
-
-	s.Confident += value
-	if e := model.Db.Save(&s).Error; e != nil {
-		log.Println("[Database]", "upvote unconfirmed toilet", id, ":", e.Error())
-	}
+//UpvoteUcf record the upvote of voter on the unconfirmed toilet by specific id
+func UpvoteUcf(id int64, voter string) error {
+	return voteUcf(id, model.Vote{Voter: voter, Direction: model.VoteUp, Trusted: policy().IsTrusted(voter)})
+}
 
-	return
+func voteUcf(id int64, v model.Vote) error {
+	var s ToiletUcf
+	return model.CastVote(id, v, &s)
 }
 
 func queryToiletUcf(s ToiletUcf) (service ToiletUcf, e error) {
@@ -103,11 +106,11 @@ func UcfsByAddress(address string) (services []ToiletUcf, e error) {
 //return error if there is something wrong when doing transaction
 func CreateUcf(s ToiletUcf) (ucf ToiletUcf, e error) {
 	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&Toilet{}).Error; e == nil {
-		return ucf, errors.New("The service location is existed or some problems is occured")
+		return ucf, model.NewError(model.ErrConflict, "The service location is existed or some problems is occured")
 	}
 
 	if e = model.Db.Where("lat=? AND lon=?", s.Lat, s.Lon).Find(&ToiletUcf{}).Error; e == nil {
-		return ucf, errors.New("The service location is existed or some problems is occured")
+		return ucf, model.NewError(model.ErrConflict, "The service location is existed or some problems is occured")
 	}
 
 	if e = model.Db.Create(&s).Error; e != nil {
@@ -119,30 +122,119 @@ func CreateUcf(s ToiletUcf) (ucf ToiletUcf, e error) {
 	return
 }
 
-//UcfInRange query the unconfirmed fuel services that are in the radius of a location
+//UcfInRange query the unconfirmed toilet services that are in the radius of a location, they are the pending services
+//and the submissions which are waiting for their confirmation. Both are matched by the bounding box of the radius
 func UcfInRange(p r2.Point, max_range float64) []Toilet {
+	pending := model.QueriedStates([]string{model.StatePending})
 	var result []Toilet = []Toilet{}
-	trees := ucf_services.InRange(p, max_range)
+	if e := model.InRange(model.Db.Where("state IN (?)", pending), ServiceTableName, p, max_range).Order("id").Find(&result).Error; e != nil {
+		log.Println("[Database]", "pending toilet in range", e.Error())
+	}
 
-	for _, tree := range trees {
-		for _, item := range tree.Items {
-			location := item.Location()
+	var ucfs []ToiletUcf
+	if e := model.InRange(model.Db.Where("state IN (?)", pending), UcfServiceTableName, p, max_range).Order("id").Find(&ucfs).Error; e != nil {
+		log.Println("[Database]", "unconfirmed toilet in range", e.Error())
+	}
 
-			d := distance(location, p)
-			s, isService := item.(Toilet)
-			if isService && d < max_range {
-				result = append(result, map_ucfservices[s.Id])
-			}
-		}
+	for _, ucf := range ucfs {
+		result = append(result, Toilet{Service: ucf.Listed()})
 	}
+
 	return result
 }
 
-func DeleteUcf(id int64) (e error) {
-	var ucf ToiletUcf
-	ucf.Id = id
-	if e := model.Db.Delete(&ucf).Error; e != nil {
-		log.Println("[Database]", "delete ucf fuel", e.Error())
+//DeleteUcf reject the unconfirmed toilet by specific id, the row is removed and the rejection is logged with actor
+func DeleteUcf(id int64, actor string) (e error) {
+	ucf, e := UcfById(id)
+	if e != nil {
+		return
+	}
+
+	if e = ucf.Transit(model.StateRejected, actor, "unconfirmed service is deleted"); e != nil {
+		return
+	}
+
+	if e = model.Db.Delete(&ucf).Error; e != nil {
+		log.Println("[Database]", "delete ucf toilet", e.Error())
+		return
+	}
+
+	//the unconfirmed service is rejected, its contributor lose the reputation and is notified
+	model.Settle(model.Db, policy(), ucf.Contributor, ucf.Transited(), UcfServiceTableName, id)
+	model.QueueSubmission(model.Db, UcfServiceTableName, id, ucf.Lifecycle, ucf.Transited(), ucf.Contributor, ucf.Location())
+	ucf.LogTransition(model.Db, UcfServiceTableName, id)
+	return
+}
+
+//transitUcf change the state of the unconfirmed toilet by specific id, the transition is logged with actor and reason
+func transitUcf(id int64, to, actor, reason string) (e error) {
+	ucf, e := UcfById(id)
+	if e != nil {
+		return
+	}
+
+	if e = ucf.Transit(to, actor, reason); e != nil {
+		return
+	}
+
+	if e = model.Db.Save(&ucf).Error; e != nil {
+		log.Println("[Database]", "transit ucf toilet", id, ":", e.Error())
+	}
+
+	return
+}
+
+//TransitUcf change the state of the unconfirmed toilet by specific id by actor, the confirmed one is moved to the
+//services like the confirmation policy does
+func TransitUcf(id int64, to, actor, reason string) error {
+	if to == model.StateConfirmed {
+		return confirmUcf(id, actor, reason)
+	}
+
+	return transitUcf(id, to, actor, reason)
+}
+
+func confirmUcf(id int64, actor, reason string) (e error) {
+	ucf, e := UcfById(id)
+	if e != nil {
+		return
+	}
+
+	if e = ucf.Transit(model.StateConfirmed, actor, reason); e != nil {
+		return
+	}
+
+	var s Toilet = Toilet{Service: ucf.GetService()}
+	s.Transit(model.StateConfirmed, actor, reason)
+	//the service is created and the unconfirmed one is removed together, a failure leave the unconfirmed one as is
+	e = model.Db.Transaction(func(tx *gorm.DB) error {
+		service, e := createService(tx, s)
+		if e != nil {
+			return e
+		}
+
+		if e := model.MoveVotes(tx, UcfServiceTableName, id, ServiceTableName, service.Id); e != nil {
+			return e
+		}
+
+		if e := tx.Delete(&ucf).Error; e != nil {
+			log.Println("[Database]", "confirm ucf toilet", e.Error())
+			return e
+		}
+
+		model.QueueSubmission(tx, UcfServiceTableName, id, ucf.Lifecycle, ucf.Transited(), ucf.Contributor, ucf.Location())
+		return ucf.LogTransition(tx, UcfServiceTableName, id)
+	})
+
+	return
+}
+
+//BeforeSave reject the unconfirmed service which is matching the rejection rules of the policy, see model.Rejection
+func (s *ToiletUcf) BeforeSave(scope *gorm.Scope) (e error) {
+	db := scope.NewDB()
+	voters := model.CountVoters(db, UcfServiceTableName, s.Id)
+	if !model.Confirmed(policy(), s.Contributor, s.Confident, model.ReputationOf(db, s.Contributor), voters) {
+		s.Reject(model.Rejection(policy(), s.Confident, model.IsSpammer(db, s.Contributor)))
 	}
 
 	return
@@ -150,13 +242,31 @@ func DeleteUcf(id int64) (e error) {
 
 //AfterSave automatically run everytime the update transaction is done
 func (s *ToiletUcf) AfterSave(scope *gorm.Scope) (err error) {
-	if s.Confident >= confident {
+	db := scope.NewDB()
+	if !model.IsUnconfirmed(s.GetState()) {
+		//the rejected and archived services are taken out of the index
+		ucf_services.RemoveItem(s)
+		model.Settle(db, policy(), s.Contributor, s.Transited(), UcfServiceTableName, s.Id)
+		model.QueueSubmission(db, UcfServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
+		s.LogTransition(db, UcfServiceTableName, s.Id)
+		return
+	}
+
+	voters := model.CountVoters(db, UcfServiceTableName, s.Id)
+	if model.Confirmed(policy(), s.Contributor, s.Confident, model.ReputationOf(db, s.Contributor), voters) {
 		var t Toilet = Toilet{Service: s.GetService()}
-		CreateService(t)
+		if service, e := createService(db, t); e == nil {
+			//the voters of the unconfirmed service keep their votes on the service
+			model.MoveVotes(db, UcfServiceTableName, s.Id, ServiceTableName, service.Id)
+		}
+		s.Transit(model.StateConfirmed, model.ActorSystem, "unconfirmed service is moved to the services")
+		model.QueueSubmission(db, UcfServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
+		s.LogTransition(db, UcfServiceTableName, s.Id)
 		scope.DB().Delete(s)
 		log.Println("[Unconfirmed Toilet]", "Confident is enough. Added", t)
 	} else {
 		ucf_services.AddItem(s)
+		model.QueueSubmission(db, UcfServiceTableName, s.Id, s.Lifecycle, s.Transited(), s.Contributor, s.Location())
 	}
 
 	return
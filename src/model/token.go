@@ -0,0 +1,294 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"streelity/v1/config"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+//tokenKey is a loaded key of the keyring, sign is nil when the key only verifies the tokens
+type tokenKey struct {
+	kid    string
+	method jwt.SigningMethod
+	sign   interface{}
+	verify interface{}
+}
+
+//Keyring is the keys which the tokens are signed and verified by, the tokens are signed by the active key and
+//verified by the key of their `kid` header so the keys can be rotated without invalidating the issued tokens
+type Keyring struct {
+	keys     map[string]tokenKey
+	active   string
+	issuer   string
+	audience string
+	expire   time.Duration
+}
+
+var keyring *Keyring
+var keyringMutex sync.RWMutex
+
+//LoadKeys replace the keyring by the keys of c, the relative key files are read from the config directory
+func LoadKeys(c config.JwtConfig) error {
+	ring, e := NewKeyring(c, filepath.Dir(config.ConfigPath()))
+	if e != nil {
+		return e
+	}
+
+	keyringMutex.Lock()
+	keyring = ring
+	keyringMutex.Unlock()
+
+	log.Println("[Authenticate]", "Loaded", len(ring.keys), "keys, signing by", ring.active)
+	return nil
+}
+
+//keys return the loaded keyring, it is loaded from the config on the first use
+func keys() (*Keyring, error) {
+	keyringMutex.RLock()
+	ring := keyring
+	keyringMutex.RUnlock()
+	if ring != nil {
+		return ring, nil
+	}
+
+	if e := LoadKeys(config.Config.Jwt); e != nil {
+		return nil, e
+	}
+
+	keyringMutex.RLock()
+	defer keyringMutex.RUnlock()
+	return keyring, nil
+}
+
+//NewKeyring create the keyring of c, the relative key files are read from dir
+func NewKeyring(c config.JwtConfig, dir string) (*Keyring, error) {
+	if len(c.Keys) == 0 {
+		return nil, errors.New("no jwt key is configured")
+	}
+
+	ring := &Keyring{
+		keys:     make(map[string]tokenKey),
+		active:   c.Kid,
+		issuer:   c.Issuer,
+		audience: c.Audience,
+		expire:   time.Duration(c.ExpireMinutes) * time.Minute,
+	}
+
+	if ring.active == "" {
+		ring.active = c.Keys[0].Kid
+	}
+
+	if ring.expire == 0 {
+		ring.expire = 10 * time.Minute
+	}
+
+	for _, k := range c.Keys {
+		if _, ok := ring.keys[k.Kid]; ok {
+			return nil, fmt.Errorf("jwt key %v is duplicated", k.Kid)
+		}
+
+		key, e := loadKey(k, dir)
+		if e != nil {
+			return nil, fmt.Errorf("jwt key %v: %v", k.Kid, e.Error())
+		}
+
+		ring.keys[k.Kid] = key
+	}
+
+	if key, ok := ring.keys[ring.active]; !ok || key.sign == nil {
+		return nil, fmt.Errorf("jwt key %v cannot sign the tokens", ring.active)
+	}
+
+	return ring, nil
+}
+
+//loadKey parse the secret or the PEM files of k, the public key is derived from the private key when the
+//public key file is not given
+func loadKey(k config.JwtKey, dir string) (key tokenKey, e error) {
+	key.kid = k.Kid
+	algorithm := k.Algorithm
+	if algorithm == "" {
+		algorithm = jwt.SigningMethodHS256.Alg()
+	}
+
+	read := func(path string) ([]byte, error) {
+		if path != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		return ioutil.ReadFile(path)
+	}
+
+	switch algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		secret := k.Secret
+		switch {
+		case k.SecretEnv != "":
+			secret = os.Getenv(k.SecretEnv)
+		case k.SecretFile != "":
+			bytes, e := read(k.SecretFile)
+			if e != nil {
+				return key, e
+			}
+
+			secret = strings.TrimSpace(string(bytes))
+		}
+
+		if secret == "" {
+			return key, errors.New("secret is missing")
+		}
+
+		key.method = jwt.SigningMethodHS256
+		key.sign, key.verify = []byte(secret), []byte(secret)
+	case jwt.SigningMethodRS256.Alg():
+		key.method = jwt.SigningMethodRS256
+		if k.PrivateKeyFile != "" {
+			pem, e := read(k.PrivateKeyFile)
+			if e != nil {
+				return key, e
+			}
+
+			private, e := jwt.ParseRSAPrivateKeyFromPEM(pem)
+			if e != nil {
+				return key, e
+			}
+
+			key.sign, key.verify = private, &private.PublicKey
+		}
+
+		if k.PublicKeyFile != "" {
+			pem, e := read(k.PublicKeyFile)
+			if e != nil {
+				return key, e
+			}
+
+			if key.verify, e = jwt.ParseRSAPublicKeyFromPEM(pem); e != nil {
+				return key, e
+			}
+		}
+	case jwt.SigningMethodES256.Alg():
+		key.method = jwt.SigningMethodES256
+		if k.PrivateKeyFile != "" {
+			pem, e := read(k.PrivateKeyFile)
+			if e != nil {
+				return key, e
+			}
+
+			private, e := jwt.ParseECPrivateKeyFromPEM(pem)
+			if e != nil {
+				return key, e
+			}
+
+			key.sign, key.verify = private, &private.PublicKey
+		}
+
+		if k.PublicKeyFile != "" {
+			pem, e := read(k.PublicKeyFile)
+			if e != nil {
+				return key, e
+			}
+
+			if key.verify, e = jwt.ParseECPublicKeyFromPEM(pem); e != nil {
+				return key, e
+			}
+		}
+	default:
+		return key, fmt.Errorf("algorithm %v is not supported", algorithm)
+	}
+
+	if key.verify == nil {
+		return key, errors.New("private-key-file or public-key-file is missing")
+	}
+
+	return
+}
+
+//Sign create the token of the claims by the active key, the issuer, the audience and the times are added
+func (ring *Keyring) Sign(claims jwt.MapClaims) (string, error) {
+	key := ring.keys[ring.active]
+	now := time.Now()
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = now.Add(ring.expire).Unix()
+	}
+
+	if ring.issuer != "" {
+		claims["iss"] = ring.issuer
+	}
+
+	if ring.audience != "" {
+		claims["aud"] = ring.audience
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.sign)
+}
+
+//Parse verify the token by the key of its `kid` header, the active key is used when it doesn't have one.
+//The expiration, the not-before, the issuer and the audience are validated
+func (ring *Keyring) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, e := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = ring.active
+		}
+
+		key, ok := ring.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("Unknown key: %v", kid)
+		}
+
+		//the algorithm of the token must be the one of the key, otherwise a public key could be used as a secret
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return key.verify, nil
+	})
+
+	if e != nil {
+		return nil, e
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("Invalid token")
+	}
+
+	if ring.issuer != "" && !claims.VerifyIssuer(ring.issuer, true) {
+		return nil, errors.New("Invalid issuer")
+	}
+
+	if ring.audience != "" && !hasAudience(claims, ring.audience) {
+		return nil, errors.New("Invalid audience")
+	}
+
+	return claims, nil
+}
+
+//hasAudience determine whether the `aud` claim, a string or a list of strings, contain audience
+func hasAudience(claims jwt.MapClaims, audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+package model_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"streelity/v1/config"
+	"streelity/v1/model"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+//writeKeys write the PEM files of a RSA and an ECDSA private key into dir
+func writeKeys(t *testing.T, dir string) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecBytes, _ := x509.MarshalECPrivateKey(ecKey)
+	ecPublic, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+
+	files := map[string]*pem.Block{
+		"rsa.pem":    {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		"ec.pem":     {Type: "EC PRIVATE KEY", Bytes: ecBytes},
+		"ec.pub.pem": {Type: "PUBLIC KEY", Bytes: ecPublic},
+	}
+
+	for name, block := range files {
+		if e := ioutil.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0600); e != nil {
+			t.Fatal(e)
+		}
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "keys")
+	defer os.RemoveAll(dir)
+	writeKeys(t, dir)
+
+	keys := []config.JwtKey{
+		{Kid: "old", Secret: "old-secret"},
+		{Kid: "hs", Algorithm: "HS256", Secret: "new-secret"},
+		{Kid: "rs", Algorithm: "RS256", PrivateKeyFile: "rsa.pem"},
+		{Kid: "es", Algorithm: "ES256", PrivateKeyFile: "ec.pem"},
+		{Kid: "es-public", Algorithm: "ES256", PublicKeyFile: "ec.pub.pem"},
+	}
+
+	for _, kid := range []string{"hs", "rs", "es"} {
+		ring, e := model.NewKeyring(config.JwtConfig{Kid: kid, Keys: keys, Issuer: "streetlity", Audience: "api"}, dir)
+		if e != nil {
+			t.Fatalf("%v: NewKeyring returned error: %v", kid, e)
+		}
+
+		token, e := ring.Sign(jwt.MapClaims{"sub": "1"})
+		if e != nil {
+			t.Fatalf("%v: Sign returned error: %v", kid, e)
+		}
+
+		claims, e := ring.Parse(token)
+		if e != nil || claims["sub"] != "1" {
+			t.Errorf("%v: Parse returned %v, %v", kid, claims, e)
+		}
+
+		//the tokens of the rotated key are still verified while the key is kept
+		rotated, _ := model.NewKeyring(config.JwtConfig{Kid: "old", Keys: keys, Issuer: "streetlity", Audience: "api"}, dir)
+		if _, e := rotated.Parse(token); e != nil {
+			t.Errorf("%v: token is not verified after rotation: %v", kid, e)
+		}
+
+		removed, _ := model.NewKeyring(config.JwtConfig{Keys: keys[:1], Issuer: "streetlity", Audience: "api"}, dir)
+		if _, e := removed.Parse(token); e == nil {
+			t.Errorf("%v: token is verified after its key is removed", kid)
+		}
+
+		other, _ := model.NewKeyring(config.JwtConfig{Kid: kid, Keys: keys, Issuer: "other", Audience: "api"}, dir)
+		if _, e := other.Parse(token); e == nil {
+			t.Errorf("%v: token of another issuer is verified", kid)
+		}
+
+		other, _ = model.NewKeyring(config.JwtConfig{Kid: kid, Keys: keys, Issuer: "streetlity", Audience: "web"}, dir)
+		if _, e := other.Parse(token); e == nil {
+			t.Errorf("%v: token of another audience is verified", kid)
+		}
+	}
+
+	if _, e := model.NewKeyring(config.JwtConfig{Kid: "es-public", Keys: keys}, dir); e == nil {
+		t.Errorf("a public key is used to sign the tokens")
+	}
+
+	if _, e := model.NewKeyring(config.JwtConfig{Keys: []config.JwtKey{{Kid: "ps", Algorithm: "PS256"}}}, dir); e == nil {
+		t.Errorf("an unsupported algorithm is loaded")
+	}
+
+	if _, e := model.NewKeyring(config.JwtConfig{}, dir); e == nil {
+		t.Errorf("a keyring without keys is loaded")
+	}
+}
+
+func TestSecretSources(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "keys")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "secret"), []byte("file-secret\n"), 0600)
+	os.Setenv("STREETLITY_TEST_SECRET", "env-secret")
+	defer os.Unsetenv("STREETLITY_TEST_SECRET")
+
+	tests := []struct {
+		name   string
+		key    config.JwtKey
+		secret string
+	}{
+		{"env", config.JwtKey{Kid: "env", SecretEnv: "STREETLITY_TEST_SECRET"}, "env-secret"},
+		{"file", config.JwtKey{Kid: "file", SecretFile: "secret"}, "file-secret"},
+		{"unset env", config.JwtKey{Kid: "unset", SecretEnv: "STREETLITY_UNSET_SECRET"}, ""},
+		{"missing file", config.JwtKey{Kid: "missing", SecretFile: "missing"}, ""},
+	}
+
+	for _, test := range tests {
+		ring, e := model.NewKeyring(config.JwtConfig{Keys: []config.JwtKey{test.key}}, dir)
+		if test.secret == "" {
+			if e == nil {
+				t.Errorf("%v: a key without secret is loaded", test.name)
+			}
+
+			continue
+		}
+
+		if e != nil {
+			t.Fatalf("%v: NewKeyring returned error: %v", test.name, e)
+		}
+
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "1"}).SignedString([]byte(test.secret))
+		if _, e := ring.Parse(token); e != nil {
+			t.Errorf("%v: token signed by the secret is not verified: %v", test.name, e)
+		}
+	}
+}
+
+func TestKeyringClaims(t *testing.T) {
+	ring, _ := model.NewKeyring(config.JwtConfig{Keys: []config.JwtKey{{Kid: "hs", Secret: "secret"}}}, "")
+	later := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		method jwt.SigningMethod
+		key    interface{}
+		valid  bool
+	}{
+		{"valid", jwt.MapClaims{"sub": "1"}, jwt.SigningMethodHS256, []byte("secret"), true},
+		{"not before", jwt.MapClaims{"sub": "1", "nbf": later}, jwt.SigningMethodHS256, []byte("secret"), false},
+		{"expired", jwt.MapClaims{"sub": "1", "exp": time.Now().Add(-time.Hour).Unix()}, jwt.SigningMethodHS256, []byte("secret"), false},
+		{"wrong secret", jwt.MapClaims{"sub": "1"}, jwt.SigningMethodHS256, []byte("other"), false},
+		{"wrong algorithm", jwt.MapClaims{"sub": "1"}, jwt.SigningMethodHS384, []byte("secret"), false},
+	}
+
+	for _, test := range tests {
+		token := jwt.NewWithClaims(test.method, test.claims)
+		token.Header["kid"] = "hs"
+		s, _ := token.SignedString(test.key)
+		if _, e := ring.Parse(s); (e == nil) != test.valid {
+			t.Errorf("%v: got error %v want valid %v", test.name, e, test.valid)
+		}
+	}
+}
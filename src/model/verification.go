@@ -0,0 +1,77 @@
+package model
+
+import (
+	"log"
+	"streelity/v1/config"
+	"time"
+)
+
+//Verify record a positive signal of the service at now
+func (l *Lifecycle) Verify(now time.Time) {
+	l.VerifiedAt = &now
+}
+
+//LastSignal return the time of the latest positive signal, the services which are never verified are counted
+//from their creation. Nil is returned for the services which are created before the lifecycle
+func (l Lifecycle) LastSignal() *time.Time {
+	if l.VerifiedAt != nil {
+		return l.VerifiedAt
+	}
+
+	return l.CreatedAt
+}
+
+//Decayed return the confident of a service after the decay of the policy, the confident lose 1 for every
+//DecayDays without positive signals
+func Decayed(p config.Policy, confident int, l Lifecycle, now time.Time) int {
+	last := l.LastSignal()
+	if p.DecayDays <= 0 || last == nil {
+		return confident
+	}
+
+	days := int(now.Sub(*last).Hours() / 24)
+	return confident - days/p.DecayDays
+}
+
+//NeedsReverification determine whether a listed service should be verified again by the nearby users, they are
+//the disputed services and the services which don't have positive signals for StaleDays
+func NeedsReverification(p config.Policy, l Lifecycle, now time.Time) bool {
+	state := l.GetState()
+	if !IsListed(state) {
+		return false
+	}
+
+	if state == StateDisputed {
+		return true
+	}
+
+	last := l.LastSignal()
+	return p.StaleDays > 0 && last != nil && last.Before(now.AddDate(0, 0, -p.StaleDays))
+}
+
+//DecayingIds query the confirmed rows of tablename which don't have positive signals for DecayDays, their
+//confident is decayed so they should be evaluated again
+func DecayingIds(tablename string, p config.Policy, now time.Time) (ids []int64, e error) {
+	if p.DecayDays <= 0 {
+		return
+	}
+
+	cutoff := now.AddDate(0, 0, -p.DecayDays)
+	e = Db.Table(tablename).Where("state = ? AND COALESCE(verified_at, created_at) < ?", StateConfirmed, cutoff).Pluck("id", &ids).Error
+	if e != nil {
+		log.Println("[Database]", "decaying", tablename, ":", e.Error())
+	}
+
+	return
+}
+
+//SignalBefore determine whether the latest positive signal of a is older than b, it is using for sorting the
+//re-verification queue. The services which are created before the lifecycle are the oldest
+func SignalBefore(a, b Lifecycle) bool {
+	x, y := a.LastSignal(), b.LastSignal()
+	if x == nil || y == nil {
+		return x == nil && y != nil
+	}
+
+	return x.Before(*y)
+}
@@ -0,0 +1,60 @@
+package model_test
+
+import (
+	"streelity/v1/config"
+	"streelity/v1/model"
+	"testing"
+	"time"
+)
+
+func TestDecayed(t *testing.T) {
+	p := config.Policy{DecayDays: 30}
+	now := time.Now()
+	created := now.AddDate(0, 0, -100)
+	verified := now.AddDate(0, 0, -40)
+	tests := []struct {
+		name    string
+		p       config.Policy
+		l       model.Lifecycle
+		decayed int
+	}{
+		{"never verified", p, model.Lifecycle{CreatedAt: &created}, 2},
+		{"verified", p, model.Lifecycle{CreatedAt: &created, VerifiedAt: &verified}, 4},
+		{"without age", p, model.Lifecycle{}, 5},
+		{"disabled", config.Policy{}, model.Lifecycle{CreatedAt: &created}, 5},
+	}
+
+	for _, test := range tests {
+		if decayed := model.Decayed(test.p, 5, test.l, now); decayed != test.decayed {
+			t.Errorf("%v: got %v want %v", test.name, decayed, test.decayed)
+		}
+	}
+}
+
+func TestNeedsReverification(t *testing.T) {
+	p := config.Policy{StaleDays: 90}
+	now := time.Now()
+	old := now.AddDate(0, 0, -100)
+	recent := now.AddDate(0, 0, -10)
+	tests := []struct {
+		name   string
+		l      model.Lifecycle
+		needed bool
+	}{
+		{"stale", model.Lifecycle{State: model.StateConfirmed, VerifiedAt: &old}, true},
+		{"recent", model.Lifecycle{State: model.StateConfirmed, VerifiedAt: &recent}, false},
+		{"disputed", model.Lifecycle{State: model.StateDisputed, VerifiedAt: &recent}, true},
+		{"pending", model.Lifecycle{State: model.StatePending, CreatedAt: &old}, false},
+		{"without age", model.Lifecycle{State: model.StateConfirmed}, false},
+	}
+
+	for _, test := range tests {
+		if needed := model.NeedsReverification(p, test.l, now); needed != test.needed {
+			t.Errorf("%v: got %v want %v", test.name, needed, test.needed)
+		}
+	}
+
+	if !model.SignalBefore(model.Lifecycle{VerifiedAt: &old}, model.Lifecycle{VerifiedAt: &recent}) {
+		t.Errorf("older signal is not the first")
+	}
+}
@@ -0,0 +1,325 @@
+package model
+
+import (
+	"log"
+	"sort"
+	"streelity/v1/config"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	VoteWithdraw = 0
+	VoteUp       = 1
+	VoteDown     = -1
+)
+
+//Vote is an entry of the vote ledger, a voter has at most one active vote on a service
+//
+//The confident of a service is the sum of Direction * Weight of its votes
+type Vote struct {
+	Id          int64     `gorm:"column:id"`
+	Voter       string    `gorm:"column:voter;unique_index:idx_vote_voter"`
+	ServiceType string    `gorm:"column:service_type;unique_index:idx_vote_voter;index:idx_vote_service"`
+	ServiceId   int64     `gorm:"column:service_id;unique_index:idx_vote_voter;index:idx_vote_service"`
+	Direction   int       `gorm:"column:direction"`
+	Weight      int       `gorm:"column:weight"`
+	Trusted     bool      `gorm:"column:trusted"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+const VoteTableName = "vote"
+
+func (Vote) TableName() string {
+	return VoteTableName
+}
+
+func (v Vote) validate() error {
+	if v.Voter == "" {
+		return NewError(ErrInvalid, "voter is missing")
+	}
+
+	if v.Direction != VoteUp && v.Direction != VoteDown && v.Direction != VoteWithdraw {
+		return NewError(ErrInvalid, "vote direction is invalid")
+	}
+
+	return nil
+}
+
+//CastVote record the vote of a voter on the row of ref which is having id, the previous vote of the voter
+//on the row is replaced, a vote with VoteWithdraw direction remove it.
+//
+//The weight of vote is determined by the reputation of voter if it is not set.
+//The confident of the row is computed again from the ledger in the same transaction, ref must be a pointer
+//to the model of the voted table, it is saved after the confident is updated so the hooks of model are ran.
+func CastVote(id int64, v Vote, ref interface{}) (e error) {
+	if e = v.validate(); e != nil {
+		return
+	}
+
+	tablename := Db.NewScope(ref).TableName()
+	v.ServiceType = tablename
+	v.ServiceId = id
+
+	e = Db.Transaction(func(tx *gorm.DB) error {
+		//touching the row before reading the ledger lock it until the end of transaction,
+		//concurrent votes on the same service are waiting here instead of overriding each other
+		if e := tx.Table(tablename).Where("id = ?", id).UpdateColumn("confident", gorm.Expr("confident")).Error; e != nil {
+			return e
+		}
+
+		if e := tx.Where("id = ?", id).First(ref).Error; e != nil {
+			return e
+		}
+
+		//the weight of a normal vote is scaled by the reputation of voter
+		if v.Weight == 0 {
+			v.Weight = VoteWeight(ReputationOf(tx, v.Voter))
+		}
+
+		if e := saveVote(tx, v); e != nil {
+			return e
+		}
+
+		var confident int
+		row := tx.Table(VoteTableName).Where("service_type = ? AND service_id = ?", tablename, id).Select("COALESCE(SUM(direction * weight), 0)").Row()
+		if e := row.Scan(&confident); e != nil {
+			return e
+		}
+
+		scope := tx.NewScope(ref)
+		if e := scope.SetColumn("Confident", confident); e != nil {
+			return e
+		}
+
+		//an upvote is a positive signal which verify the service again
+		if v.Direction == VoteUp {
+			now := time.Now()
+			if e := scope.SetColumn("VerifiedAt", &now); e != nil {
+				return e
+			}
+		}
+
+		return tx.Save(ref).Error
+	})
+
+	if e != nil {
+		log.Println("[Database]", "vote", tablename, id, ":", e.Error())
+	}
+
+	return
+}
+
+func saveVote(tx *gorm.DB, v Vote) error {
+	var current Vote
+	e := tx.Where("voter = ? AND service_type = ? AND service_id = ?", v.Voter, v.ServiceType, v.ServiceId).First(&current).Error
+	if e != nil && !gorm.IsRecordNotFoundError(e) {
+		return e
+	}
+	found := e == nil
+
+	switch {
+	case v.Direction == VoteWithdraw && !found:
+		return NewError(ErrNotFound, "Vote was not found")
+	case v.Direction == VoteWithdraw:
+		return tx.Delete(&current).Error
+	case found:
+		return tx.Model(&current).Updates(map[string]interface{}{"direction": v.Direction, "weight": v.Weight, "trusted": v.Trusted}).Error
+	default:
+		return tx.Create(&v).Error
+	}
+}
+
+//VotesByService query the active votes of a service
+func VotesByService(tablename string, id int64) (votes []Vote, e error) {
+	if e = Db.Where("service_type = ? AND service_id = ?", tablename, id).Order("id").Find(&votes).Error; e != nil {
+		log.Println("[Database]", "votes by service", tablename, id, ":", e.Error())
+	}
+
+	return
+}
+
+//MoveVotes move the active votes of the row of from_type which is having from_id to the row of to_type which is
+//having to_id, it is using when an unconfirmed service is moved to the services so its voters keep their votes
+//
+//db is passed in order to be used in the transaction which move the service
+func MoveVotes(db *gorm.DB, from_type string, from_id int64, to_type string, to_id int64) (e error) {
+	e = db.Model(&Vote{}).Where("service_type = ? AND service_id = ?", from_type, from_id).
+		Updates(map[string]interface{}{"service_type": to_type, "service_id": to_id}).Error
+	if e != nil {
+		log.Println("[Database]", "move votes", from_type, from_id, ":", e.Error())
+	}
+
+	return
+}
+
+//Voters is the summary of the upvotes on a submission which is used by the confirmation policy
+type Voters struct {
+	//Count is the number of distinct upvoters
+	Count int
+	//Trusted determine whether there is an upvote from a trusted source
+	Trusted bool
+}
+
+//CountVoters summary the upvotes on the row of tablename which is having id
+//
+//db is passed in order to be used in the hooks which are ran in a transaction
+func CountVoters(db *gorm.DB, tablename string, id int64) (voters Voters) {
+	var trusted int
+	row := db.Table(VoteTableName).Where("service_type = ? AND service_id = ? AND direction = ?", tablename, id, VoteUp).Select("COUNT(*), COALESCE(MAX(trusted), 0)").Row()
+	if e := row.Scan(&voters.Count, &trusted); e != nil {
+		log.Println("[Database]", "count voters", tablename, id, ":", e.Error())
+	}
+
+	voters.Trusted = trusted > 0
+	return
+}
+
+//AllVoters summary the upvotes on every row of tablename, it is using for loading many services at once
+func AllVoters(tablename string) map[int64]Voters {
+	result := make(map[int64]Voters)
+	rows, e := Db.Table(VoteTableName).Where("service_type = ? AND direction = ?", tablename, VoteUp).Select("service_id, COUNT(*), COALESCE(MAX(trusted), 0)").Group("service_id").Rows()
+	if e != nil {
+		log.Println("[Database]", "all voters", tablename, ":", e.Error())
+		return result
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var count, trusted int
+		if e := rows.Scan(&id, &count, &trusted); e != nil {
+			log.Println("[Database]", "all voters", tablename, ":", e.Error())
+			continue
+		}
+
+		result[id] = Voters{Count: count, Trusted: trusted > 0}
+	}
+
+	return result
+}
+
+//Confirmed determine whether a submission reach the confirmation policy p. The threshold is scaled by the
+//reputation of contributor, the submissions of trusted sources and the ones which are upvoted by a trusted
+//source are confirmed at once
+func Confirmed(p config.Policy, contributor string, confident int, reputation int, voters Voters) bool {
+	if p.IsTrusted(contributor) || voters.Trusted {
+		return true
+	}
+
+	return confident >= Threshold(p.Threshold, reputation) && voters.Count >= p.MinVoters
+}
+
+//VoteBook is the in-memory vote ledger which is using by the memory repositories, it is not safe for
+//concurrent use, the owner must hold its own lock
+type VoteBook struct {
+	//Reputations scale the weight of the votes like CastVote, every vote weight 1 when it is nil
+	Reputations ReputationRepository
+	lastId      int64
+	votes       []Vote
+}
+
+//Cast record the vote like CastVote and return the confident which is computed from the ledger
+func (b *VoteBook) Cast(service_type string, id int64, v Vote) (confident int, e error) {
+	if e = v.validate(); e != nil {
+		return
+	}
+
+	if v.Weight == 0 {
+		v.Weight = b.weight(v.Voter)
+	}
+	v.ServiceType = service_type
+	v.ServiceId = id
+
+	index := -1
+	for i, current := range b.votes {
+		if current.Voter == v.Voter && current.ServiceType == service_type && current.ServiceId == id {
+			index = i
+			break
+		}
+	}
+
+	now := time.Now()
+	switch {
+	case v.Direction == VoteWithdraw && index < 0:
+		return 0, NewError(ErrNotFound, "Vote was not found")
+	case v.Direction == VoteWithdraw:
+		b.votes = append(b.votes[:index], b.votes[index+1:]...)
+	case index >= 0:
+		b.votes[index].Direction = v.Direction
+		b.votes[index].Weight = v.Weight
+		b.votes[index].Trusted = v.Trusted
+		b.votes[index].UpdatedAt = now
+	default:
+		b.lastId++
+		v.Id = b.lastId
+		v.CreatedAt = now
+		v.UpdatedAt = now
+		b.votes = append(b.votes, v)
+	}
+
+	for _, vote := range b.Votes(service_type, id) {
+		confident += vote.Direction * vote.Weight
+	}
+
+	return
+}
+
+func (b *VoteBook) weight(voter string) int {
+	if b.Reputations == nil {
+		return VoteWeight(0)
+	}
+
+	return VoteWeight(b.Reputations.ReputationOf(voter).Reputation)
+}
+
+//Move move the active votes of a service to another like MoveVotes
+func (b *VoteBook) Move(from_type string, from_id int64, to_type string, to_id int64) {
+	for i, v := range b.votes {
+		if v.ServiceType == from_type && v.ServiceId == from_id {
+			b.votes[i].ServiceType = to_type
+			b.votes[i].ServiceId = to_id
+		}
+	}
+}
+
+//Voters summary the upvotes on a service like CountVoters
+func (b *VoteBook) Voters(service_type string, id int64) (voters Voters) {
+	for _, v := range b.Votes(service_type, id) {
+		if v.Direction == VoteUp {
+			voters.Count++
+			voters.Trusted = voters.Trusted || v.Trusted
+		}
+	}
+
+	return
+}
+
+//LastVoted return the time of the latest active vote on a service, zero is returned when it is not voted
+func (b *VoteBook) LastVoted(service_type string, id int64) (voted time.Time) {
+	for _, v := range b.Votes(service_type, id) {
+		if v.UpdatedAt.After(voted) {
+			voted = v.UpdatedAt
+		}
+	}
+
+	return
+}
+
+//Votes return the active votes of a service
+func (b *VoteBook) Votes(service_type string, id int64) (votes []Vote) {
+	for _, v := range b.votes {
+		if v.ServiceType == service_type && v.ServiceId == id {
+			votes = append(votes, v)
+		}
+	}
+
+	sort.Slice(votes, func(i, j int) bool { return votes[i].Id < votes[j].Id })
+	return
+}
+
+func init() {
+	AutoMigrate(&Vote{})
+}
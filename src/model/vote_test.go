@@ -0,0 +1,65 @@
+package model_test
+
+import (
+	"streelity/v1/config"
+	"streelity/v1/model"
+	"testing"
+)
+
+func TestConfirmed(t *testing.T) {
+	p := config.Policy{Threshold: 5, MinVoters: 3, TrustedSources: []string{"trusted"}}
+	tests := []struct {
+		name        string
+		contributor string
+		confident   int
+		reputation  int
+		voters      model.Voters
+		confirmed   bool
+	}{
+		{"not enough confident", "user", 4, 0, model.Voters{Count: 4}, false},
+		{"not enough voters", "user", 5, 0, model.Voters{Count: 2}, false},
+		{"enough", "user", 5, 0, model.Voters{Count: 3}, true},
+		{"reputed contributor", "user", 4, 100, model.Voters{Count: 3}, true},
+		{"trusted contributor", "trusted", 0, 0, model.Voters{}, true},
+		{"trusted voter", "user", 1, 0, model.Voters{Count: 1, Trusted: true}, true},
+		{"distrusted contributor", "user", 5, -100, model.Voters{Count: 5}, false},
+	}
+
+	for _, test := range tests {
+		if confirmed := model.Confirmed(p, test.contributor, test.confident, test.reputation, test.voters); confirmed != test.confirmed {
+			t.Errorf("%v: got %v want %v", test.name, confirmed, test.confirmed)
+		}
+	}
+}
+
+func TestVoteBook(t *testing.T) {
+	reputations := new(model.ReputationMemory)
+	for id := int64(0); id < 5; id++ {
+		reputations.Change("reputed", model.ReasonConfirmed, "book", id)
+	}
+
+	book := model.VoteBook{Reputations: reputations}
+	book.Cast("ucf", 1, model.Vote{Voter: "user", Direction: model.VoteUp})
+	confident, e := book.Cast("ucf", 1, model.Vote{Voter: "reputed", Direction: model.VoteUp})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	//the weight of a vote is scaled by the reputation of voter like CastVote
+	if want := 1 + model.VoteWeight(50); confident != want {
+		t.Errorf("wrong confident: got %v want %v", confident, want)
+	}
+
+	book.Move("ucf", 1, "service", 2)
+	if votes := book.Votes("ucf", 1); len(votes) != 0 {
+		t.Errorf("wrong votes after they are moved: got %v want 0", len(votes))
+	}
+
+	if voters := book.Voters("service", 2); voters.Count != 2 {
+		t.Errorf("wrong voters after they are moved: got %v want 2", voters.Count)
+	}
+
+	if _, e := book.Cast("service", 2, model.Vote{Voter: "user", Direction: model.VoteWithdraw}); e != nil {
+		t.Error("withdraw a moved vote is rejected", e)
+	}
+}
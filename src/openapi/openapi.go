@@ -0,0 +1,290 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"streelity/v1/sres"
+	"streelity/v1/stages"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+//Version is the version of the OpenAPI specification of the documents
+const Version = "3.0.3"
+
+//Schema is the schema of a param or a body
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Default              string             `json:"default,omitempty"`
+	AdditionalProperties bool               `json:"additionalProperties,omitempty"`
+}
+
+//Param is a param which is not in the body, In is query or header
+type Param struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+	Style       string  `json:"style,omitempty"`
+	Explode     *bool   `json:"explode,omitempty"`
+}
+
+//MediaType is the schema of a body by its content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+//RequestBody is the body of a request
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+//Response is a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+//Operation is the document of a method of a path
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Param               `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+//SecurityScheme is a way which the requests are authenticated by
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+//Components are the schemas and the security schemes which are referred by the operations
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+//Info is the metadata of the api
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+//Spec is an OpenAPI document
+type Spec struct {
+	OpenApi    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+	Security   []map[string][]string            `json:"security,omitempty"`
+}
+
+//Doc is the documentation of a route, it is declared with the route by Declare. The params are declared by the
+//structs of stages, see stages.Params
+type Doc struct {
+	Summary string
+	Tags    []string
+	//Params are the declared structs of the params
+	Params []interface{}
+	//Extra are the params which are not declared like the headers of the middlewares
+	Extra []Param
+	//Body is the json body of the routes which are not read by the stages, the declared params are ignored with it
+	Body *Schema
+	//Security is the security scheme which is required, the operation is public when it is empty
+	Security string
+}
+
+//Declared is the handler of a route with the documentation of the route
+type Declared struct {
+	http.Handler
+	Doc Doc
+}
+
+//Declare attach doc to the handler of route, Generate document the route by it so the documentation is declared
+//where the route is registered like
+//
+//	openapi.Declare(s.HandleFunc("/range", h.ServiceInRange).Methods("GET"), doc)
+func Declare(route *mux.Route, doc Doc) *mux.Route {
+	return route.Handler(Declared{Handler: route.GetHandler(), Doc: doc})
+}
+
+//CoverageError is the routes without documentation, the document is generated without them
+type CoverageError struct {
+	Undocumented []string
+}
+
+func (e *CoverageError) Error() string {
+	return "routes are not documented: " + strings.Join(e.Undocumented, ", ")
+}
+
+//SchemaOf return the schema of a type of the declared params
+func SchemaOf(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: SchemaOf(t.Elem())}
+	}
+
+	return &Schema{}
+}
+
+//paramSchema return the schema of p with its rules
+func paramSchema(p stages.Param) *Schema {
+	s := SchemaOf(p.Type)
+	rules := s
+	if s.Items != nil {
+		rules = s.Items
+	}
+
+	rules.Minimum, rules.Maximum, rules.Pattern = p.Min, p.Max, p.Pattern
+	s.Default = p.Default
+	return s
+}
+
+//inBody determine whether a param of the source is read from the body of the method
+func inBody(from string, method string) bool {
+	switch from {
+	case stages.SourceForm, stages.SourceJson:
+		return true
+	case stages.SourceAny:
+		return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+	}
+
+	return false
+}
+
+//operationOf create the operation of a method from its doc
+func operationOf(doc Doc, method string) *Operation {
+	op := &Operation{
+		Summary: doc.Summary,
+		Tags:    doc.Tags,
+		Responses: map[string]Response{
+			"200": {Description: "Status is true", Content: map[string]MediaType{"application/json": {&Schema{Ref: "#/components/schemas/Response"}}}},
+			"default": {Description: "Status is false and Code is the error, see README", Content: map[string]MediaType{
+				"application/json": {&Schema{Ref: "#/components/schemas/Response"}},
+			}},
+		},
+	}
+
+	body := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, v := range doc.Params {
+		for _, p := range stages.Params(v) {
+			if inBody(p.From, method) {
+				body.Properties[p.Name] = paramSchema(p)
+				if p.Required {
+					body.Required = append(body.Required, p.Name)
+				}
+
+				continue
+			}
+
+			//the repeated params are the default form style of the query params
+			op.Parameters = append(op.Parameters, Param{Name: p.Name, In: "query", Required: p.Required, Schema: paramSchema(p)})
+		}
+	}
+
+	op.Parameters = append(op.Parameters, doc.Extra...)
+	if doc.Body != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {doc.Body}}}
+	} else if len(body.Properties) > 0 {
+		op.RequestBody = &RequestBody{Required: len(body.Required) > 0, Content: map[string]MediaType{
+			"application/x-www-form-urlencoded": {body},
+			"application/json":                  {body},
+		}}
+	}
+
+	if doc.Security != "" {
+		op.Security = []map[string][]string{{doc.Security: {}}}
+	}
+
+	return op
+}
+
+//responseSchema return the schema of sres.Response, the data of the responses are the other fields of it
+func responseSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"Status":  {Type: "boolean"},
+			"Message": {Type: "string"},
+			"Code": {Type: "string", Enum: []string{sres.CodeBadRequest, sres.CodeUnauthorized, sres.CodeForbidden,
+				sres.CodeNotFound, sres.CodeConflict, sres.CodeValidation, sres.CodeTooManyRequests, sres.CodeInternal}},
+			"Fields": {Type: "array", Items: &Schema{Type: "object", Properties: map[string]*Schema{
+				"Field":   {Type: "string"},
+				"Message": {Type: "string"},
+			}}},
+			"RequestId": {Type: "string"},
+		},
+		Required:             []string{"Status", "Message"},
+		AdditionalProperties: true,
+	}
+}
+
+//Generate create the document of the routes of router by the docs which are declared with them, the routes without
+//docs are returned as a CoverageError with the document
+func Generate(router *mux.Router, info Info) (spec Spec, e error) {
+	spec = Spec{OpenApi: Version, Info: info, Paths: map[string]map[string]*Operation{}}
+	spec.Components.Schemas = map[string]*Schema{"Response": responseSchema()}
+	coverage := &CoverageError{}
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		methods, _ := route.GetMethods()
+		if err != nil || route.GetHandler() == nil {
+			return nil
+		}
+
+		declared, ok := route.GetHandler().(Declared)
+		for _, method := range methods {
+			if !ok {
+				coverage.Undocumented = append(coverage.Undocumented, method+" "+template)
+				continue
+			}
+
+			if spec.Paths[template] == nil {
+				spec.Paths[template] = map[string]*Operation{}
+			}
+
+			spec.Paths[template][strings.ToLower(method)] = operationOf(declared.Doc, method)
+		}
+
+		return nil
+	})
+
+	if len(coverage.Undocumented) > 0 {
+		sort.Strings(coverage.Undocumented)
+		e = coverage
+	}
+
+	return
+}
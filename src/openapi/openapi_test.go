@@ -0,0 +1,59 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/openapi"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type itemQuery struct {
+	Id   int64    `param:"id" from:"query" required:"true"`
+	Tags []string `param:"tag"`
+}
+
+type itemForm struct {
+	Name  string  `param:"name" from:"form" required:"true" regex:"^[a-z]+$"`
+	Score float32 `param:"score" min:"0" max:"5"`
+}
+
+func TestGenerate(t *testing.T) {
+	r := mux.NewRouter()
+	h := func(http.ResponseWriter, *http.Request) {}
+	openapi.Declare(r.HandleFunc("/item/", h).Methods("GET"), openapi.Doc{Params: []interface{}{itemQuery{}}})
+	openapi.Declare(r.HandleFunc("/item/", h).Methods("POST"), openapi.Doc{Params: []interface{}{itemForm{}, itemQuery{}}, Security: "token"})
+	openapi.Declare(r.PathPrefix("/group").Subrouter().HandleFunc("/a", h).Methods("GET", "PUT"), openapi.Doc{})
+	r.HandleFunc("/undocumented", h).Methods("GET")
+
+	spec, e := openapi.Generate(r, openapi.Info{Title: "test", Version: "1.0.0"})
+
+	coverage, ok := e.(*openapi.CoverageError)
+	if !ok || len(coverage.Undocumented) != 1 || coverage.Undocumented[0] != "GET /undocumented" {
+		t.Fatalf("wrong coverage: %v", e)
+	}
+
+	get := spec.Paths["/item/"]["get"]
+	if len(get.Parameters) != 2 || get.Parameters[0].Schema.Format != "int64" || !get.Parameters[0].Required ||
+		get.Parameters[1].Schema.Type != "array" || get.RequestBody != nil || get.Security != nil {
+		t.Errorf("wrong get: %+v", get)
+	}
+
+	post := spec.Paths["/item/"]["post"]
+	body := post.RequestBody.Content["application/x-www-form-urlencoded"].Schema
+	if len(post.Parameters) != 1 || len(body.Properties) != 3 || len(body.Required) != 1 ||
+		body.Properties["name"].Pattern != "^[a-z]+$" || *body.Properties["score"].Maximum != 5 || len(post.Security) != 1 {
+		t.Errorf("wrong post: %+v %+v", post, body)
+	}
+
+	if len(spec.Paths["/group/a"]) != 2 {
+		t.Errorf("methods of a route are not documented: %v", spec.Paths)
+	}
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", "/item/", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("declared route is not served: %v", rr.Code)
+	}
+}
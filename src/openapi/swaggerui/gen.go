@@ -0,0 +1,46 @@
+// +build ignore
+
+//gen write dist.go from the dist directory of a release of swagger-ui, it is run by go generate like
+//
+//	SWAGGER_UI_DIST=swagger-ui/dist go generate ./openapi/swaggerui
+//
+//the version of the release is in the go:generate line of swaggerui.go
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+//files are the files of the dist which are served, the page is written by Page instead of index.html
+var files = []string{"swagger-ui-bundle.js", "swagger-ui.css"}
+
+func main() {
+	if len(os.Args) != 3 {
+		log.Fatal("usage: go run gen.go <dist> <version>")
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by gen.go from the dist of swagger-ui %v. DO NOT EDIT.\n\n", os.Args[2])
+	fmt.Fprintf(&out, "package swaggerui\n\n")
+	fmt.Fprintf(&out, "//Version is the version of swagger-ui\nconst Version = %q\n\n", os.Args[2])
+	fmt.Fprintf(&out, "var files = map[string]string{\n")
+	for _, name := range files {
+		content, e := ioutil.ReadFile(filepath.Join(os.Args[1], name))
+		if e != nil {
+			log.Fatal(e)
+		}
+
+		fmt.Fprintf(&out, "\t%q: %v,\n", name, strconv.Quote(string(content)))
+	}
+
+	fmt.Fprintf(&out, "}\n")
+	if e := ioutil.WriteFile("dist.go", out.Bytes(), 0644); e != nil {
+		log.Fatal(e)
+	}
+}
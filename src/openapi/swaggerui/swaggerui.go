@@ -0,0 +1,59 @@
+//Package swaggerui serve the swagger-ui of an OpenAPI document, the bundle of swagger-ui is vendored in dist.go so
+//the page does not load any script from outside of the server
+package swaggerui
+
+//go:generate go run gen.go $SWAGGER_UI_DIST 4.11.0
+
+import (
+	"html/template"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+//modified is the time of the files for the conditional requests, the files are only changed by a new build
+var modified = time.Now()
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8"/>
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" type="text/css" href="{{.Prefix}}/swagger-ui.css"/>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.Prefix}}/swagger-ui-bundle.js" charset="utf-8"></script>
+<script>
+window.onload = function () {
+	window.ui = SwaggerUIBundle({url: {{.Url}}, dom_id: "#swagger-ui", deepLinking: true});
+};
+</script>
+</body>
+</html>
+`))
+
+//Handler serve the page of the document at url on prefix and the files of swagger-ui under prefix, it is registered
+//on a path prefix like
+//
+//	router.PathPrefix("/docs").Handler(swaggerui.Handler("/docs", "Api", "/openapi.json"))
+func Handler(prefix, title, url string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, prefix)
+		if name == "" || name == "/" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			page.Execute(w, struct{ Prefix, Title, Url string }{prefix, title, url})
+			return
+		}
+
+		content, ok := files[strings.TrimPrefix(name, "/")]
+		if !ok || path.Dir(name) != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeContent(w, r, name, modified, strings.NewReader(content))
+	})
+}
@@ -0,0 +1,34 @@
+package swaggerui_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/openapi/swaggerui"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	h := swaggerui.Handler("/docs", "Api", "/openapi.json")
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/docs", nil))
+	page := rr.Body.String()
+	if !strings.Contains(page, `url: "/openapi.json"`) || !strings.Contains(page, `src="/docs/swagger-ui-bundle.js"`) {
+		t.Errorf("page does not load the document by the bundle: %v", page)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/docs/swagger-ui-bundle.js", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Header().Get("Content-Type"), "javascript") || !strings.Contains(rr.Body.String(), "SwaggerUIBundle") {
+		t.Errorf("bundle is not served: %v %v", rr.Code, rr.Header())
+	}
+
+	for _, path := range []string{"/docs/gen.go", "/docs/x/swagger-ui.css"} {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("%v is served: %v", path, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
+	"streelity/v1/sres"
+	"streelity/v1/stages"
+
+	"github.com/gorilla/mux"
+	"github.com/nvnamsss/goinf/pipeline"
+)
+
+func (h handlers) getApiKeys(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Keys []model.ApiKey
+	}
+	res.Status = true
+
+	if keys, e := h.repositories.ApiKeys.Keys(); e != nil {
+		res.Error(e)
+	} else {
+		res.Keys = keys
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) issueApiKey(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Key model.ApiKey
+	}
+	res.Response = sres.Response{Status: true, Message: "Issue api key successfully"}
+
+	req.ParseForm()
+	p := pipeline.NewPipeline()
+	stage := stages.ApiKeyValidateStage(req.PostForm)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		key := model.ApiKey{
+			Name:         p.GetStringFirstOrDefault("Name"),
+			ServiceTypes: p.GetStringFirstOrDefault("Types"),
+			Endpoints:    p.GetStringFirstOrDefault("Endpoints"),
+			ReadOnly:     p.GetBoolFirstOrDefault("ReadOnly"),
+			DailyQuota:   int(p.GetIntFirstOrDefault("DailyQuota")),
+			MonthlyQuota: int(p.GetIntFirstOrDefault("MonthlyQuota")),
+		}
+
+		if key, e := h.repositories.ApiKeys.Issue(key); e != nil {
+			res.Error(e)
+		} else {
+			res.Key = key
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) revokeApiKey(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Key model.ApiKey
+	}
+	res.Response = sres.Response{Status: true, Message: "Revoke api key successfully"}
+
+	req.ParseForm()
+	p := pipeline.NewPipeline()
+	stage := stages.IdValidateStage(req.PostForm)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		if key, e := h.repositories.ApiKeys.Revoke(p.GetIntFirstOrDefault("Id")); e != nil {
+			res.Error(e)
+		} else {
+			res.Key = key
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) getApiUsage(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Usages []model.ApiUsage
+	}
+	res.Status = true
+
+	p := pipeline.NewPipeline()
+	stage := stages.IdValidateStage(req.URL.Query())
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		if usages, e := h.repositories.ApiKeys.Usage(p.GetIntFirstOrDefault("Id")); e != nil {
+			res.Error(e)
+		} else {
+			res.Usages = usages
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func HandleApiKey(router *mux.Router, repos Repositories) {
+	h := handlers{repositories: repos}
+	log.Println("[Router]", "Handling api key")
+	s := router.PathPrefix("/apikey").Subrouter()
+
+	admin := func(h http.HandlerFunc) http.Handler {
+		return middleware.Authenticate(middleware.Admin(h))
+	}
+
+	tags := []string{"api keys"}
+	openapi.Declare(s.Handle("/", admin(h.getApiKeys)).Methods("GET"),
+		openapi.Doc{Summary: "The api keys", Tags: tags, Security: routedoc.Token})
+	openapi.Declare(s.Handle("/issue", admin(h.issueApiKey)).Methods("POST"),
+		openapi.Doc{Summary: "Issue an api key", Tags: tags, Params: []interface{}{stages.ApiKeyRequest{}}, Security: routedoc.Token})
+	openapi.Declare(s.Handle("/revoke", admin(h.revokeApiKey)).Methods("POST"),
+		openapi.Doc{Summary: "Revoke an api key", Tags: tags, Params: []interface{}{stages.IdRequest{}}, Security: routedoc.Token})
+	openapi.Declare(s.Handle("/usage", admin(h.getApiUsage)).Methods("GET"),
+		openapi.Doc{Summary: "The usage of an api key", Tags: tags, Params: []interface{}{stages.IdRequest{}}, Security: routedoc.Token})
+}
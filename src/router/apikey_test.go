@@ -0,0 +1,93 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"streelity/v1/model"
+	"streelity/v1/router"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestApiKey(t *testing.T) {
+	repos := router.MemoryRepositories()
+	r := mux.NewRouter()
+	router.Handle(r, repos)
+
+	user, _ := model.CreateToken(1)
+	admin, _ := model.CreateRoleToken(2, model.RoleAdmin)
+
+	var res struct {
+		Status bool
+		Key    model.ApiKey
+		Usages []model.ApiUsage
+	}
+
+	serve := func(method, path, token, key string, form url.Values) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Version", "1.0.0")
+		if token != "" {
+			req.Header.Set("Auth", token)
+		}
+
+		if key != "" {
+			req.Header.Set("X-Api-Key", key)
+		}
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		res.Status, res.Key, res.Usages = false, model.ApiKey{}, nil
+		json.Unmarshal(rr.Body.Bytes(), &res)
+		return rr.Code
+	}
+
+	issue := url.Values{"name": {"fuel-prices"}, "type": {"fuel"}, "daily_quota": {"2"}}
+	if code := serve("POST", "/apikey/issue", user, "", issue); code != 403 {
+		t.Errorf("issue by user: got %v want 403", code)
+	}
+
+	if serve("POST", "/apikey/issue", admin, "", issue); !res.Status || res.Key.Secret == "" || !res.Key.ReadOnly {
+		t.Fatalf("key is not issued: got %v", res.Key)
+	}
+	key := res.Key
+	id := strconv.FormatInt(key.Id, 10)
+
+	requests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		code   int
+	}{
+		{"without key", "GET", "/service/fuel/all", "", 200},
+		{"invalid key", "GET", "/service/fuel/all", "sk_invalid", 401},
+		{"allowed", "GET", "/service/fuel/all", key.Secret, 200},
+		{"disallowed type", "GET", "/service/atm/all", key.Secret, 403},
+		{"read-only", "POST", "/service/fuel/create", key.Secret, 403},
+		{"in quota", "GET", "/service/fuel_ucf/range?location=10&location=106&range=1", key.Secret, 200},
+		{"over quota", "GET", "/service/fuel/all", key.Secret, 429},
+	}
+
+	for _, request := range requests {
+		if code := serve(request.method, request.path, "", request.key, nil); code != request.code {
+			t.Errorf("%v: got %v want %v", request.name, code, request.code)
+		}
+	}
+
+	if serve("GET", "/apikey/usage?id="+id, admin, "", nil); len(res.Usages) != 2 || res.Usages[0].Count != 2 {
+		t.Errorf("wrong usage: got %v", res.Usages)
+	}
+
+	if serve("POST", "/apikey/revoke", admin, "", url.Values{"id": {id}}); !res.Status || !res.Key.IsRevoked() {
+		t.Errorf("key is not revoked: got %v", res.Key)
+	}
+
+	if code := serve("GET", "/service/fuel/all", "", key.Secret, nil); code != 403 {
+		t.Errorf("revoked key: got %v want 403", code)
+	}
+}
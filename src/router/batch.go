@@ -0,0 +1,221 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"streelity/v1/config"
+	"streelity/v1/middleware"
+	"streelity/v1/openapi"
+	"streelity/v1/sres"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+//batchHeaders are the headers of the outer request which the sub-requests are sent with, so they are authenticated
+//and versioned like the outer request
+var batchHeaders []string = []string{"Auth", middleware.ApiKeyHeader, middleware.VersionHeader, "Accept-Language"}
+
+//subRequest is a request of a batch, Params are the query of the GET and DELETE requests and the form of the others
+type subRequest struct {
+	Id     string          `json:"id"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Params json.RawMessage `json:"params"`
+}
+
+//subResponse is the response of a sub-request, Body is the json of the response or a string of the other bodies
+type subResponse struct {
+	Id     string `json:",omitempty"`
+	Status int
+	Body   json.RawMessage
+}
+
+//batchLimits return the limits of the batches by the config
+func batchLimits() (maxRequests int, parallelism int) {
+	c := config.Config.Batch
+	if maxRequests = c.MaxRequests; maxRequests <= 0 {
+		maxRequests = 20
+	}
+
+	if parallelism = c.Parallelism; parallelism <= 0 {
+		parallelism = 4
+	}
+
+	return
+}
+
+func batchError(i int, message string) error {
+	field := fmt.Sprintf("requests[%v]", i)
+	return &sres.Error{Code: sres.CodeValidation, Message: field + " " + message, Fields: []sres.FieldError{{Field: field, Message: message}}}
+}
+
+//parseBatch read the sub-requests of the json body of a batch
+func parseBatch(w http.ResponseWriter, req *http.Request) (requests []subRequest, e error) {
+	var body struct {
+		Requests []subRequest `json:"requests"`
+	}
+
+	if e = json.NewDecoder(http.MaxBytesReader(w, req.Body, middleware.MaxJsonBody)).Decode(&body); e != nil {
+		return nil, sres.NewError(sres.CodeBadRequest, "body is not a batch: "+e.Error())
+	}
+
+	maxRequests, _ := batchLimits()
+	if len(body.Requests) == 0 || len(body.Requests) > maxRequests {
+		message := "requests must have 1 to " + strconv.Itoa(maxRequests) + " requests"
+		return nil, &sres.Error{Code: sres.CodeValidation, Message: message, Fields: []sres.FieldError{{Field: "requests", Message: message}}}
+	}
+
+	for i, r := range body.Requests {
+		switch r.Method = strings.ToUpper(r.Method); r.Method {
+		case "":
+			body.Requests[i].Method = http.MethodGet
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			body.Requests[i].Method = r.Method
+		default:
+			return nil, batchError(i, "method "+r.Method+" is not supported")
+		}
+
+		if !strings.HasPrefix(r.Path, "/") {
+			return nil, batchError(i, "path must start with /")
+		}
+
+		if u, e := url.Parse(r.Path); e != nil || u.Path == "/batch" {
+			return nil, batchError(i, "path cannot be a batch")
+		}
+	}
+
+	return body.Requests, nil
+}
+
+//newSubRequest create the http request of r, it has the headers and the client of the outer request and its id is
+//derived from parent
+func newSubRequest(outer *http.Request, parent string, r subRequest, index int) (*http.Request, error) {
+	values := url.Values{}
+	if len(r.Params) > 0 && string(r.Params) != "null" {
+		var e error
+		if values, e = middleware.JsonValues(r.Params); e != nil {
+			return nil, batchError(index, e.Error())
+		}
+	}
+
+	u, _ := url.Parse(r.Path)
+	var body string
+	switch r.Method {
+	case http.MethodGet, http.MethodDelete:
+		query := u.Query()
+		for param, v := range values {
+			query[param] = append(query[param], v...)
+		}
+
+		u.RawQuery = query.Encode()
+	default:
+		body = values.Encode()
+	}
+
+	sub, e := http.NewRequestWithContext(outer.Context(), r.Method, u.String(), strings.NewReader(body))
+	if e != nil {
+		return nil, batchError(index, e.Error())
+	}
+
+	sub.RemoteAddr = outer.RemoteAddr
+	if body != "" {
+		sub.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	for _, header := range batchHeaders {
+		if value := outer.Header.Get(header); value != "" {
+			sub.Header.Set(header, value)
+		}
+	}
+
+	sub.Header.Set(sres.RequestIdHeader, parent+"-"+strconv.Itoa(index))
+	return sub, nil
+}
+
+//serveBatch run the sub-requests through router, at most parallelism of them are ran at once
+func serveBatch(router http.Handler, outer *http.Request, requests []subRequest) ([]subResponse, error) {
+	//the batch has the id which is read or generated by the RequestId middleware, it is generated the same way when
+	//the batch is served without the middleware
+	parent := middleware.RequestIdFromContext(outer.Context())
+	if parent == "" {
+		parent = middleware.NewRequestId()
+	}
+
+	subs := make([]*http.Request, len(requests))
+	for i, r := range requests {
+		sub, e := newSubRequest(outer, parent, r, i)
+		if e != nil {
+			return nil, e
+		}
+
+		subs[i] = sub
+	}
+
+	_, parallelism := batchLimits()
+	responses := make([]subResponse, len(requests))
+	slots := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i := range subs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, subs[i])
+			body := rr.Body.Bytes()
+			if !json.Valid(body) {
+				body, _ = json.Marshal(string(body))
+			}
+
+			responses[i] = subResponse{Id: requests[i].Id, Status: rr.Code, Body: body}
+		}(i)
+	}
+
+	wg.Wait()
+	return responses, nil
+}
+
+//batchBody is the body of /batch, the params of a sub-request are its query or its form
+var batchBody *openapi.Schema = &openapi.Schema{Type: "object", Required: []string{"requests"}, Properties: map[string]*openapi.Schema{
+	"requests": {Type: "array", Items: &openapi.Schema{Type: "object", Required: []string{"path"}, Properties: map[string]*openapi.Schema{
+		"id":     {Type: "string"},
+		"method": {Type: "string", Enum: []string{"GET", "POST", "PUT", "PATCH", "DELETE"}, Default: "GET"},
+		"path":   {Type: "string", Pattern: "^/"},
+		"params": {Type: "object", AdditionalProperties: true},
+	}}},
+}}
+
+//HandleBatch register the batch endpoint of router, the sub-requests are served by router so they pass its
+//middlewares and are limited like the other requests
+func HandleBatch(router *mux.Router) {
+	log.Println("[Router]", "Handling batch")
+
+	doc := openapi.Doc{Summary: "Run the sub-requests with the headers of this request, their responses are in Responses in order",
+		Tags: []string{"batch"}, Body: batchBody}
+	openapi.Declare(middleware.RawBody(router.HandleFunc("/batch", func(w http.ResponseWriter, req *http.Request) {
+		var res struct {
+			sres.Response
+			Responses []subResponse
+		}
+		res.Status = true
+
+		requests, e := parseBatch(w, req)
+		if !res.Error(e) {
+			res.Responses, e = serveBatch(router, req, requests)
+			res.Error(e)
+		}
+
+		sres.WriteJson(w, res)
+	}).Methods("POST")), doc)
+}
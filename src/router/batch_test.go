@@ -0,0 +1,117 @@
+package router_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"streelity/v1/model"
+	"streelity/v1/router"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestBatch(t *testing.T) {
+	repos := router.MemoryRepositories()
+	r := mux.NewRouter()
+	router.Handle(r, repos)
+	token, _ := model.CreateToken(1)
+
+	type response struct {
+		Id     string
+		Status int
+		Body   json.RawMessage
+	}
+
+	var res struct {
+		Status    bool
+		Message   string
+		Responses []response
+	}
+
+	//id is the request id of the latest batch
+	var id string
+	serve := func(token string, body string) int {
+		req := httptest.NewRequest("POST", "/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Version", "1.0.0")
+		if token != "" {
+			req.Header.Set("Auth", token)
+		}
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		id = rr.Header().Get("X-Request-Id")
+		res.Status, res.Message, res.Responses = false, "", nil
+		json.Unmarshal(rr.Body.Bytes(), &res)
+		return rr.Code
+	}
+
+	create := `{"id": "create", "method": "post", "path": "/service/fuel/create", "params": {"location": [10, 106], "address": "1 Nguyen Hue", "name": "Petrolimex"}}`
+	if code := serve("", `{"requests": [`+create+`]}`); code != 200 || !res.Status || len(res.Responses) != 1 {
+		t.Fatalf("batch without auth: got %v %v %v", code, res.Status, res.Message)
+	}
+
+	if res.Responses[0].Status != 401 {
+		t.Errorf("create without auth: got %v want 401", res.Responses[0].Status)
+	}
+
+	//the batch is sent without an id, the sub-requests are traced by the generated one
+	var unauthorized struct{ RequestId string }
+	if json.Unmarshal(res.Responses[0].Body, &unauthorized); id == "" || unauthorized.RequestId != id+"-0" {
+		t.Errorf("request id of a sub-request: got %v want %v-0", unauthorized.RequestId, id)
+	}
+
+	reads := `{"id": "ranged", "path": "/service/fuel/range?range=10", "params": {"location": [10, 106]}},
+		{"id": "all", "method": "GET", "path": "/service/atm/bank/all"},
+		{"id": "missing", "path": "/nowhere"}`
+	if code := serve(token, `{"requests": [`+create+`, `+reads+`]}`); code != 200 || len(res.Responses) != 4 {
+		t.Fatalf("batch: got %v %v %v", code, res.Message, len(res.Responses))
+	}
+
+	expected := []struct {
+		id     string
+		status int
+	}{{"create", 200}, {"ranged", 200}, {"all", 200}, {"missing", 404}}
+	for i, e := range expected {
+		if got := res.Responses[i]; got.Id != e.id || got.Status != e.status {
+			t.Errorf("response %v: got %v %v want %v %v", i, got.Id, got.Status, e.id, e.status)
+		}
+	}
+
+	var created struct{ Status bool }
+	if json.Unmarshal(res.Responses[0].Body, &created); !created.Status {
+		t.Errorf("create: got %s", res.Responses[0].Body)
+	}
+
+	var missing string
+	if json.Unmarshal(res.Responses[3].Body, &missing); !strings.Contains(missing, "not found") {
+		t.Errorf("body of a text response: got %s", res.Responses[3].Body)
+	}
+
+	many := make([]string, 21)
+	for i := range many {
+		many[i] = fmt.Sprintf(`{"path": "/ping?i=%v"}`, i)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		code    int
+		message string
+	}{
+		{"empty", `{"requests": []}`, 422, "1 to 20"},
+		{"too many", `{"requests": [` + strings.Join(many, ",") + `]}`, 422, "1 to 20"},
+		{"nested", `{"requests": [{"method": "POST", "path": "/batch"}]}`, 422, "requests[0]"},
+		{"method", `{"requests": [{"path": "/ping"}, {"method": "TRACE", "path": "/ping"}]}`, 422, "requests[1]"},
+		{"relative path", `{"requests": [{"path": "ping"}]}`, 422, "path must start"},
+		{"not json", `requests=/ping`, 400, "not a batch"},
+	}
+
+	for _, test := range tests {
+		if code := serve(token, test.body); code != test.code || res.Status || !strings.Contains(res.Message, test.message) {
+			t.Errorf("%v: got %v %v %v want %v %v", test.name, code, res.Status, res.Message, test.code, test.message)
+		}
+	}
+}
@@ -1,11 +1,88 @@
 package router
 
 import (
+	"net/http"
+	"streelity/v1/config"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
+	"streelity/v1/model/atm"
+	"streelity/v1/model/fuel"
+	"streelity/v1/model/maintenance"
+	"streelity/v1/model/toilet"
+
 	"github.com/gorilla/mux"
 )
 
-func Handle(router *mux.Router) {
+//Repositories is the data access of every service type which is injected into the handlers
+type Repositories struct {
+	Atm         atm.Repository
+	Fuel        fuel.Repository
+	Toilet      toilet.Repository
+	Maintenance maintenance.Repository
+	Reputation  model.ReputationRepository
+	Moderation  model.ModerationRepository
+	ApiKeys     model.ApiKeyRepository
+	//RateLimits is the storage of the rate limits, it is in memory unless a shared storage is set
+	RateLimits middleware.RateStore
+	//Nonces is the replay cache of the signed requests
+	Nonces middleware.NonceStore
+}
+
+//DatabaseRepositories return the repositories which are stored in the database
+func DatabaseRepositories() Repositories {
+	return Repositories{
+		Atm:         atm.Database{},
+		Fuel:        fuel.Database{},
+		Toilet:      toilet.Database{},
+		Maintenance: maintenance.Database{},
+		Reputation:  model.ReputationDatabase{},
+		Moderation:  model.ModerationDatabase{},
+		ApiKeys:     model.ApiKeyDatabase{},
+		RateLimits:  middleware.NewMemoryRateStore(),
+		Nonces:      middleware.NewMemoryNonceStore(),
+	}
+}
+
+//MemoryRepositories return empty in-memory repositories, it is using for testing the handlers
+func MemoryRepositories() Repositories {
+	//the votes on every service type are weighted by the same reputations
+	reputations := new(model.ReputationMemory)
+	atms, fuels, toilets, maintenances := atm.NewMemory(), fuel.NewMemory(), toilet.NewMemory(), maintenance.NewMemory()
+	atms.UseReputations(reputations)
+	fuels.UseReputations(reputations)
+	toilets.UseReputations(reputations)
+	maintenances.UseReputations(reputations)
+
+	return Repositories{
+		Atm:         atms,
+		Fuel:        fuels,
+		Toilet:      toilets,
+		Maintenance: maintenances,
+		Reputation:  reputations,
+		Moderation:  new(model.ModerationMemory),
+		ApiKeys:     new(model.ApiKeyMemory),
+		RateLimits:  middleware.NewMemoryRateStore(),
+		Nonces:      middleware.NewMemoryNonceStore(),
+	}
+}
+
+func Handle(router *mux.Router, repos Repositories) {
+	router.Use(middleware.RequestId, func(h http.Handler) http.Handler {
+		return middleware.Signature(config.Config.Signing, repos.Nonces, h)
+	}, func(h http.Handler) http.Handler {
+		return middleware.ApiKey(repos.ApiKeys, h)
+	}, func(h http.Handler) http.Handler {
+		return middleware.RateLimit(repos.RateLimits, config.Config.RateLimits, h)
+	}, middleware.Compression, func(h http.Handler) http.Handler {
+		return middleware.Caching(config.Config.Cache, h)
+	}, middleware.Encoding, middleware.JsonBody)
 
-	HandleService(router)
+	HandleService(router, repos)
+	HandleReputation(router, repos)
+	HandleModeration(router, repos)
+	HandleApiKey(router, repos)
+	HandlePolicy(router)
 	HandlePing(router)
+	HandleBatch(router)
+	HandleOpenApi(router)
 }
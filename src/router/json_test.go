@@ -0,0 +1,52 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"streelity/v1/model"
+	"streelity/v1/router"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestJsonBody(t *testing.T) {
+	repos := router.MemoryRepositories()
+	r := mux.NewRouter()
+	router.Handle(r, repos)
+	token, _ := model.CreateToken(1)
+
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		code    int
+		status  bool
+		message string
+	}{
+		{"create", "/service/fuel/create", `{"location": [10, 106], "address": "1 Nguyen Hue", "name": "Petrolimex", "images": ["a.png", "b.png"]}`, 200, true, ""},
+		{"location of strings", "/service/fuel/create", `{"location": ["10.1", "106.1"], "address": "2 Le Loi", "name": "PVOil"}`, 200, true, ""},
+		{"wrong location", "/service/fuel/create", `{"location": ["north", 106], "address": "3 Le Loi"}`, 422, false, "cannot parse"},
+		{"nested location", "/service/fuel/create", `{"location": {"lat": 10, "lon": 106}}`, 400, false, "location must be"},
+		{"not an object", "/service/fuel/create", `[10, 106]`, 400, false, "json object"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", test.path, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Version", "1.0.0")
+		req.Header.Set("Auth", token)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		var res struct {
+			Status  bool
+			Message string
+		}
+		json.Unmarshal(rr.Body.Bytes(), &res)
+		if rr.Code != test.code || res.Status != test.status || !strings.Contains(res.Message, test.message) {
+			t.Errorf("%v: got %v %v %v want %v %v %v", test.name, rr.Code, res.Status, res.Message, test.code, test.status, test.message)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package router
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
+	"streelity/v1/model/atm"
+	"streelity/v1/model/fuel"
+	"streelity/v1/model/maintenance"
+	"streelity/v1/model/toilet"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
+	"streelity/v1/sres"
+	"streelity/v1/stages"
+	"time"
+
+	"github.com/golang/geo/r2"
+	"github.com/gorilla/mux"
+	"github.com/nvnamsss/goinf/pipeline"
+)
+
+//moderated return the data access of the service type which the rows of service_type belong to, the unconfirmed
+//services are included when ucf is true. nil is returned when service_type is unknown
+func (h handlers) moderated(service_type string, ucf bool) model.Moderated {
+	switch {
+	case service_type == atm.ServiceTableName || ucf && service_type == atm.UcfServiceTableName:
+		return h.repositories.Atm
+	case service_type == fuel.ServiceTableName || ucf && service_type == fuel.UcfServiceTableName:
+		return h.repositories.Fuel
+	case service_type == toilet.ServiceTableName || ucf && service_type == toilet.UcfServiceTableName:
+		return h.repositories.Toilet
+	case service_type == maintenance.ServiceTableName || ucf && service_type == maintenance.UcfServiceTableName:
+		return h.repositories.Maintenance
+	}
+
+	return nil
+}
+
+//enqueue put the item of the authenticated user on a service into the moderation queue
+func (h handlers) enqueue(req *http.Request, item model.ModerationItem) (model.ModerationItem, error) {
+	m := h.moderated(item.ServiceType, false)
+	if m == nil {
+		return item, sres.NewError(sres.CodeBadRequest, "type param is not a service type")
+	}
+
+	location, e := m.Locate(item.ServiceType, item.ServiceId)
+	if e != nil {
+		return item, e
+	}
+
+	item.Author = model.ActorOf(req.Context())
+	item.Lat, item.Lon = float32(location.X), float32(location.Y)
+	return h.repositories.Moderation.Enqueue(item)
+}
+
+//moderate apply the decision of actor on the moderation item by specific id and resolve it. The submissions which
+//are moderated in the database are resolved by their transition, the others are resolved here
+func (h handlers) moderate(id int64, actor string, approve bool, note string) (item model.ModerationItem, e error) {
+	if item, e = h.repositories.Moderation.Check(id, actor); e != nil {
+		return
+	}
+
+	m := h.moderated(item.ServiceType, true)
+	if m == nil {
+		return item, errors.New("service type of the item is unknown")
+	}
+
+	if e = m.Moderate(item, approve, actor, note); e != nil {
+		return
+	}
+
+	if item, e = h.repositories.Moderation.Item(id); e != nil || item.IsResolved() {
+		return
+	}
+
+	return h.repositories.Moderation.Resolve(id, actor, approve, note)
+}
+
+func (h handlers) getModerationQueue(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Items []model.ModerationItem
+	}
+	res.Status = true
+
+	p := pipeline.NewPipeline()
+	stage := stages.ModerationFilterValidateStage(req.URL.Query())
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		day := 24 * time.Hour
+		filter := model.ModerationFilter{
+			Kinds:        p.GetString("Kinds"),
+			ServiceTypes: p.GetString("Types"),
+			Statuses:     p.GetString("Statuses"),
+			Location:     r2.Point{X: p.GetFloatFirstOrDefault("Lat"), Y: p.GetFloatFirstOrDefault("Lon")},
+			Range:        p.GetFloatFirstOrDefault("Range"),
+			MinAge:       time.Duration(p.GetIntFirstOrDefault("MinAge")) * day,
+			MaxAge:       time.Duration(p.GetIntFirstOrDefault("MaxAge")) * day,
+			MinPriority:  int(p.GetIntFirstOrDefault("MinPriority")),
+		}
+
+		if items, e := h.repositories.Moderation.Queue(filter); e != nil {
+			res.Error(e)
+		} else {
+			res.Items = items
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+//moderationAction create the handler of an action of the moderators on a moderation item
+func (h handlers) moderationAction(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var res struct {
+			sres.Response
+			Item model.ModerationItem
+		}
+		res.Status = true
+
+		req.ParseForm()
+		p := pipeline.NewPipeline()
+		stage := stages.ModerationActionValidateStage(req.PostForm)
+		p.First = stage
+		res.Error(p.Run())
+
+		if res.Status {
+			id := p.GetIntFirstOrDefault("Id")
+			note := p.GetStringFirstOrDefault("Note")
+			actor := model.ActorOf(req.Context())
+
+			var item model.ModerationItem
+			var e error
+			switch action {
+			case model.ActionClaim:
+				item, e = h.repositories.Moderation.Claim(id, actor, note)
+			case model.ActionRelease:
+				item, e = h.repositories.Moderation.Release(id, actor, note)
+			default:
+				item, e = h.moderate(id, actor, action == model.ActionApprove, note)
+			}
+
+			if e != nil {
+				res.Error(e)
+			} else {
+				res.Item = item
+			}
+		}
+
+		sres.WriteJson(w, res)
+	}
+}
+
+func (h handlers) getModerationAudit(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Actions []model.ModerationAction
+	}
+	res.Status = true
+
+	p := pipeline.NewPipeline()
+	stage := stages.AuditValidateStage(req.URL.Query())
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		id, actor := p.GetIntFirstOrDefault("Id"), p.GetStringFirstOrDefault("Actor")
+		if actions, e := h.repositories.Moderation.Audit(id, actor); e != nil {
+			res.Error(e)
+		} else {
+			res.Actions = actions
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) flagService(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Item model.ModerationItem
+	}
+	res.Response = sres.Response{Status: true, Message: "Flag successfully"}
+
+	req.ParseForm()
+	p := pipeline.NewPipeline()
+	stage := stages.FlagValidateStage(req.PostForm)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		item := model.ModerationItem{
+			Kind:        p.GetStringFirstOrDefault("Kind"),
+			ServiceType: p.GetStringFirstOrDefault("Type"),
+			ServiceId:   p.GetIntFirstOrDefault("Id"),
+			Subject:     p.GetStringFirstOrDefault("Subject"),
+			Note:        p.GetStringFirstOrDefault("Note"),
+		}
+
+		if item, e := h.enqueue(req, item); e != nil {
+			res.Error(e)
+		} else {
+			res.Item = item
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) proposeEdit(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Item model.ModerationItem
+	}
+	res.Response = sres.Response{Status: true, Message: "Propose edit successfully"}
+
+	req.ParseForm()
+	p := pipeline.NewPipeline()
+	stage := stages.ProposeValidateStage(req.PostForm)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		item := model.ModerationItem{
+			Kind:        model.ModerationEdit,
+			ServiceType: p.GetStringFirstOrDefault("Type"),
+			ServiceId:   p.GetIntFirstOrDefault("Id"),
+			Subject:     p.GetStringFirstOrDefault("Edit"),
+			Note:        p.GetStringFirstOrDefault("Reason"),
+		}
+
+		if item, e := h.enqueue(req, item); e != nil {
+			res.Error(e)
+		} else {
+			res.Item = item
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func HandleModeration(router *mux.Router, repos Repositories) {
+	h := handlers{repositories: repos}
+	log.Println("[Router]", "Handling moderation")
+	s := router.PathPrefix("/moderation").Subrouter()
+
+	moderator := func(h http.HandlerFunc) http.Handler {
+		return middleware.Authenticate(middleware.Moderator(h))
+	}
+
+	//action is the doc of the actions on an item, they are read by the same stage
+	tags := []string{"moderation"}
+	action := func(summary string) openapi.Doc {
+		return openapi.Doc{Summary: summary, Tags: tags, Params: []interface{}{stages.ModerationActionRequest{}}, Security: routedoc.Token}
+	}
+
+	openapi.Declare(s.Handle("/", moderator(h.getModerationQueue)).Methods("GET"),
+		openapi.Doc{Summary: "The moderation queue", Tags: tags, Params: []interface{}{stages.ModerationFilterRequest{}}, Security: routedoc.Token})
+	openapi.Declare(s.Handle("/audit", moderator(h.getModerationAudit)).Methods("GET"),
+		openapi.Doc{Summary: "The moderation actions of an item or an actor", Tags: tags, Params: []interface{}{stages.AuditRequest{}}, Security: routedoc.Token})
+	openapi.Declare(s.Handle("/claim", moderator(h.moderationAction(model.ActionClaim))).Methods("POST"), action("Claim a moderation item"))
+	openapi.Declare(s.Handle("/release", moderator(h.moderationAction(model.ActionRelease))).Methods("POST"), action("Release a claimed moderation item"))
+	openapi.Declare(s.Handle("/approve", moderator(h.moderationAction(model.ActionApprove))).Methods("POST"), action("Approve a moderation item"))
+	openapi.Declare(s.Handle("/reject", moderator(h.moderationAction(model.ActionReject))).Methods("POST"), action("Reject a moderation item"))
+	openapi.Declare(s.Handle("/flag", middleware.Authenticate(http.HandlerFunc(h.flagService))).Methods("POST"),
+		openapi.Doc{Summary: "Flag a review or an image of a service", Tags: tags, Params: []interface{}{stages.FlagRequest{}}, Security: routedoc.Token})
+	openapi.Declare(s.Handle("/propose", middleware.Authenticate(http.HandlerFunc(h.proposeEdit))).Methods("POST"),
+		openapi.Doc{Summary: "Propose an edit of a service", Tags: tags, Params: []interface{}{stages.ProposeRequest{}, stages.EditRequest{}}, Security: routedoc.Token})
+}
@@ -0,0 +1,116 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"streelity/v1/model"
+	"streelity/v1/model/atm"
+	"streelity/v1/router"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestModeration(t *testing.T) {
+	repos := router.MemoryRepositories()
+	r := mux.NewRouter()
+	router.Handle(r, repos)
+
+	s, _ := repos.Atm.CreateService(atm.Atm{Service: model.Service{Lat: 10, Lon: 106, Address: "old"}})
+	review, _ := repos.Atm.CreateReview(s.Id, "reviewer", 1, "spam")
+
+	user, _ := model.CreateToken(1)
+	admin, _ := model.CreateRoleToken(2, model.RoleAdmin)
+	other, _ := model.CreateRoleToken(3, model.RoleAdmin)
+
+	var res struct {
+		Status bool
+		Item   model.ModerationItem
+		Items  []model.ModerationItem
+	}
+
+	serve := func(method, path, token string, form url.Values) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if token != "" {
+			req.Header.Set("Auth", token)
+		}
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		res.Status, res.Item, res.Items = false, model.ModerationItem{}, nil
+		json.Unmarshal(rr.Body.Bytes(), &res)
+		return rr.Code
+	}
+
+	id := strconv.FormatInt(s.Id, 10)
+	flag := url.Values{"type": {"atm"}, "id": {id}, "kind": {"review"}, "review_id": {strconv.FormatInt(review.Id, 10)}, "note": {"spam"}}
+	if code := serve("POST", "/moderation/flag", "", flag); code != 401 {
+		t.Errorf("anonymous flag: got %v want 401", code)
+	}
+
+	if serve("POST", "/moderation/flag", user, flag); !res.Status || res.Item.Author != "1" {
+		t.Fatalf("flag is not queued: got %v", res.Item)
+	}
+	flagged := strconv.FormatInt(res.Item.Id, 10)
+
+	if serve("POST", "/moderation/propose", user, url.Values{"type": {"atm"}, "id": {id}, "address": {"new"}, "reason": {"moved"}}); !res.Status {
+		t.Fatalf("edit is not queued")
+	}
+	proposed := strconv.FormatInt(res.Item.Id, 10)
+
+	if code := serve("GET", "/moderation/", user, nil); code != 403 {
+		t.Errorf("queue of user: got %v want 403", code)
+	}
+
+	if serve("GET", "/moderation/?kind=review", admin, nil); len(res.Items) != 1 || res.Items[0].Subject != strconv.FormatInt(review.Id, 10) {
+		t.Errorf("wrong queue of reviews: got %v", res.Items)
+	}
+
+	if serve("POST", "/moderation/claim", admin, url.Values{"id": {flagged}}); !res.Status || res.Item.ClaimedBy != "2" {
+		t.Fatalf("item is not claimed: got %v", res.Item)
+	}
+
+	if serve("POST", "/moderation/approve", other, url.Values{"id": {flagged}}); res.Status {
+		t.Errorf("claimed item is approved by another admin")
+	}
+
+	if serve("POST", "/moderation/approve", admin, url.Values{"id": {flagged}, "note": {"abusive"}}); !res.Status || res.Item.Status != model.ModerationApproved {
+		t.Fatalf("item is not approved: got %v", res.Item)
+	}
+
+	if _, e := repos.Atm.ReviewById(review.Id); e == nil {
+		t.Errorf("flagged review is not removed")
+	}
+
+	if serve("POST", "/moderation/reject", other, url.Values{"id": {proposed}}); !res.Status || res.Item.Status != model.ModerationRejected {
+		t.Fatalf("edit is not rejected: got %v", res.Item)
+	}
+
+	if s, _ = repos.Atm.ServiceById(s.Id); s.Address != "old" {
+		t.Errorf("rejected edit is applied: got %v", s.Address)
+	}
+
+	serve("POST", "/moderation/propose", user, url.Values{"type": {"atm"}, "id": {id}, "address": {"new"}})
+	serve("POST", "/moderation/approve", other, url.Values{"id": {strconv.FormatInt(res.Item.Id, 10)}})
+	if s, _ = repos.Atm.ServiceById(s.Id); s.Address != "new" {
+		t.Errorf("approved edit is not applied: got %v", s.Address)
+	}
+
+	var audit struct {
+		Status  bool
+		Actions []model.ModerationAction
+	}
+
+	req := httptest.NewRequest("GET", "/moderation/audit?actor=2", nil)
+	req.Header.Set("Auth", admin)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	json.Unmarshal(rr.Body.Bytes(), &audit)
+	if len(audit.Actions) != 2 || audit.Actions[1].Action != model.ActionApprove || audit.Actions[1].Note != "abusive" {
+		t.Errorf("wrong audit of admin 2: got %v", audit.Actions)
+	}
+}
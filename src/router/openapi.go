@@ -0,0 +1,71 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"streelity/v1/config"
+	"streelity/v1/middleware"
+	"streelity/v1/openapi"
+	"streelity/v1/openapi/swaggerui"
+	"streelity/v1/router/routedoc"
+	"streelity/v1/sres"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+//versionParam is the header of the service routes, see middleware.Versioning
+var versionParam openapi.Param = openapi.Param{Name: middleware.VersionHeader, In: "header", Required: true,
+	Description: "the api version like 1.0.0", Schema: &openapi.Schema{Type: "string"}}
+
+//OpenApi generate the OpenAPI document of the routes of router by the docs which are declared with them, the error is
+//the routes without documentation
+func OpenApi(router *mux.Router) (openapi.Spec, error) {
+	spec, e := openapi.Generate(router, openapi.Info{
+		Title:       "Streetlity",
+		Description: "The services of the streets: fuel stations, ATMs, toilets and maintenance shops. The errors and the conventions of the listings are described in the README",
+		Version:     config.Config.Versions.Max,
+	})
+
+	//the service routes are versioned by a middleware so the routes do not declare its header
+	for path, operations := range spec.Paths {
+		if strings.HasPrefix(path, "/service/") {
+			for _, op := range operations {
+				op.Parameters = append(op.Parameters, versionParam)
+			}
+		}
+	}
+
+	spec.Components.SecuritySchemes = map[string]openapi.SecurityScheme{
+		routedoc.Token:  {Type: "apiKey", In: "header", Name: "Auth", Description: "the token of the user"},
+		routedoc.ApiKey: {Type: "apiKey", In: "header", Name: middleware.ApiKeyHeader, Description: "the api key of the client, it is required when the api keys are enabled"},
+	}
+
+	//the api key is optional for every request
+	spec.Security = []map[string][]string{{}, {routedoc.ApiKey: {}}}
+	return spec, e
+}
+
+//HandleOpenApi register the OpenAPI document of router and its swagger-ui page, it is generated by the first request
+//so it must be registered after the other routes
+func HandleOpenApi(router *mux.Router) {
+	log.Println("[Router]", "Handling openapi")
+
+	var once sync.Once
+	var spec openapi.Spec
+	tags := []string{"documentation"}
+	openapi.Declare(router.HandleFunc("/openapi.json", func(w http.ResponseWriter, req *http.Request) {
+		once.Do(func() {
+			var e error
+			if spec, e = OpenApi(router); e != nil {
+				log.Println("[OpenApi]", e.Error())
+			}
+		})
+
+		sres.WriteJson(w, spec)
+	}).Methods("GET"), openapi.Doc{Summary: "This document", Tags: tags})
+
+	openapi.Declare(router.PathPrefix("/docs").Handler(swaggerui.Handler("/docs", "Streetlity API", "/openapi.json")).Methods("GET"),
+		openapi.Doc{Summary: "The swagger-ui page of this document and its files", Tags: tags})
+}
@@ -0,0 +1,84 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/openapi"
+	"streelity/v1/router"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+//TestOpenApiCoverage fail when a route is registered without declaring its documentation
+func TestOpenApiCoverage(t *testing.T) {
+	r := mux.NewRouter()
+	router.Handle(r, router.MemoryRepositories())
+
+	if _, e := router.OpenApi(r); e != nil {
+		t.Fatal(e)
+	}
+
+	r.HandleFunc("/undocumented", func(http.ResponseWriter, *http.Request) {}).Methods("GET")
+	_, e := router.OpenApi(r)
+	coverage, ok := e.(*openapi.CoverageError)
+	if !ok || len(coverage.Undocumented) != 1 || coverage.Undocumented[0] != "GET /undocumented" {
+		t.Errorf("undocumented route is not found: %v", e)
+	}
+}
+
+func TestOpenApi(t *testing.T) {
+	r := mux.NewRouter()
+	router.Handle(r, router.MemoryRepositories())
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/openapi.json", nil))
+	var spec openapi.Spec
+	if e := json.Unmarshal(rr.Body.Bytes(), &spec); e != nil || rr.Code != http.StatusOK || spec.OpenApi != openapi.Version {
+		t.Fatalf("document is not served: %v %v", rr.Code, e)
+	}
+
+	review := spec.Paths["/service/fuel/review/create"]["post"]
+	if review == nil || review.RequestBody == nil || len(review.Security) != 1 {
+		t.Fatalf("review is not documented: %v", review)
+	}
+
+	body := review.RequestBody.Content["application/json"].Schema
+	if score := body.Properties["score"]; score == nil || score.Type != "number" || *score.Maximum != 5 || len(body.Required) != 3 {
+		t.Errorf("review params are not documented from the stage: %+v", body)
+	}
+
+	names := map[string]bool{}
+	for _, p := range spec.Paths["/service/atm/range"]["get"].Parameters {
+		names[p.In+" "+p.Name] = p.Required
+	}
+
+	for _, name := range []string{"query location", "query range", "query cursor", "query sort", "query filter", "header Version"} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("range param %v is not documented: %v", name, names)
+		}
+	}
+
+	if _, ok := spec.Paths["/service/fuel_ucf/"]["delete"]; !ok {
+		t.Errorf("unconfirmed services are not documented")
+	}
+
+	update := spec.Paths["/service/fuel/update"]["post"].RequestBody.Content["application/json"].Schema
+	if images := update.Properties["images"]; images == nil || images.Type != "array" {
+		t.Errorf("update params are not documented from the stage: %+v", update)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/docs", nil))
+	if !strings.Contains(rr.Body.String(), "/openapi.json") || !strings.Contains(rr.Body.String(), `src="/docs/swagger-ui-bundle.js"`) {
+		t.Errorf("documentation page does not load the document by swagger-ui: %v", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/docs/swagger-ui-bundle.js", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("swagger-ui is not served: %v", rr.Code)
+	}
+}
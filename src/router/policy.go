@@ -0,0 +1,59 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"streelity/v1/config"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
+	"streelity/v1/sres"
+
+	"github.com/gorilla/mux"
+)
+
+func getPolicies(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Default  config.Policy
+		Policies map[string]config.Policy
+	}
+	res.Status = true
+
+	res.Default = config.PolicyOf("default")
+	res.Policies = config.Policies()
+	sres.WriteJson(w, res)
+}
+
+func reloadPolicies(w http.ResponseWriter, req *http.Request) {
+	var res sres.Response = sres.Response{Status: true, Message: "Reload policies successfully"}
+
+	res.Error(config.ReloadPolicies())
+	sres.WriteJson(w, res)
+}
+
+func reloadKeys(w http.ResponseWriter, req *http.Request) {
+	var res sres.Response = sres.Response{Status: true, Message: "Reload keys successfully"}
+
+	if c, e := config.ReloadJwt(); e != nil {
+		res.Error(e)
+	} else {
+		res.Error(model.LoadKeys(c))
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func HandlePolicy(router *mux.Router) {
+	log.Println("[Router]", "Handling policy")
+	s := router.PathPrefix("/policy").Subrouter()
+
+	tags := []string{"policies"}
+	openapi.Declare(s.HandleFunc("/", getPolicies).Methods("GET"),
+		openapi.Doc{Summary: "The verification policies", Tags: tags})
+	openapi.Declare(s.Handle("/reload", middleware.Authenticate(middleware.Admin(http.HandlerFunc(reloadPolicies)))).Methods("POST"),
+		openapi.Doc{Summary: "Reload the policies from the file", Tags: tags, Security: routedoc.Token})
+	openapi.Declare(s.Handle("/keys/reload", middleware.Authenticate(middleware.Admin(http.HandlerFunc(reloadKeys)))).Methods("POST"),
+		openapi.Doc{Summary: "Reload the keys of the tokens from the config", Tags: tags, Security: routedoc.Token})
+}
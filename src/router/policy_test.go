@@ -0,0 +1,40 @@
+package router_test
+
+import (
+	"net/http/httptest"
+	"streelity/v1/model"
+	"streelity/v1/router"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestReloadPolicies(t *testing.T) {
+	r := mux.NewRouter()
+	router.Handle(r, router.MemoryRepositories())
+
+	user, _ := model.CreateToken(1)
+	admin, _ := model.CreateRoleToken(2, model.RoleAdmin)
+	tests := []struct {
+		name  string
+		token string
+		code  int
+	}{
+		{"anonymous", "", 401},
+		{"user", user, 403},
+		{"admin", admin, 200},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/policy/reload", nil)
+		if test.token != "" {
+			req.Header.Set("Auth", test.token)
+		}
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != test.code {
+			t.Errorf("%v reload policies returned wrong code: got %v want %v", test.name, rr.Code, test.code)
+		}
+	}
+}
@@ -1,30 +1,38 @@
 package ratm
 
 import (
-	"errors"
 	"net/http"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
 	"streelity/v1/model/atm"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
 	"streelity/v1/sres"
+	"streelity/v1/stages"
 
 	"github.com/gorilla/mux"
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
-func GetBanks(w http.ResponseWriter, req *http.Request) {
+func (h handlers) GetBanks(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Banks []atm.Bank
 	}
 	res.Status = true
 
-	if res.Status {
-		res.Banks = atm.AllBanks()
+	q, e := stages.PageQuery(req)
+	if e == nil {
+		res.Banks, res.Page, e = h.repository.PageBanks(q)
 	}
 
-	sres.WriteJson(w, res)
+	res.Error(e)
+
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func CreateBank(w http.ResponseWriter, req *http.Request) {
+func (h handlers) CreateBank(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Bank atm.Bank
@@ -32,42 +40,33 @@ func CreateBank(w http.ResponseWriter, req *http.Request) {
 	res.Status = true
 
 	req.ParseForm()
-	form := req.PostForm
-
 	var pipe *pipeline.Pipeline = pipeline.NewPipeline()
-	validateParamsStage := pipeline.NewStage(func() (str struct{ Name string }, e error) {
-		name, nameOk := form["name"]
-		if !nameOk {
-			e = errors.New("name param is missing")
-		} else {
-			str.Name = name[0]
-		}
-
-		return
-	})
-
-	pipe.First = validateParamsStage
+	pipe.First = stages.NameValidate(req.PostForm)
 	res.Error(pipe.Run())
 
 	if res.Status {
 		var s atm.Bank
 		s.Name = pipe.GetString("Name")[0]
-		err := atm.CreateBank(s)
+		err := h.repository.CreateBank(s)
 
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
+			res.Error(err)
 		} else {
 			res.Message = "Add new bank successfully"
-			res.Bank, _ = atm.BankByName(s.Name)
+			res.Bank, _ = h.repository.BankByName(s.Name)
 		}
 	}
 
 	sres.WriteJson(w, res)
 }
 
-func HandleBank(router *mux.Router) {
+var (
+	getBanks   = openapi.Doc{Summary: "The banks of the ATMs", Tags: []string{"banks"}, Params: routedoc.Listing(routedoc.FieldsQuery{}), Extra: []openapi.Param{routedoc.Filter}}
+	createBank = openapi.Doc{Summary: "Add a bank", Tags: []string{"banks"}, Params: []interface{}{stages.NameRequest{}}, Security: routedoc.Token}
+)
+
+func (h handlers) HandleBank(router *mux.Router) {
 	s := router.PathPrefix("/bank").Subrouter()
-	s.HandleFunc("/all", GetBanks).Methods("GET")
-	s.HandleFunc("/create", CreateBank).Methods("POST")
+	openapi.Declare(s.HandleFunc("/all", h.GetBanks).Methods("GET"), getBanks)
+	openapi.Declare(s.Handle("/create", middleware.Authenticate(middleware.Require(model.PermissionAdminister, http.HandlerFunc(h.CreateBank)))).Methods("POST"), createBank)
 }
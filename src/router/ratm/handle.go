@@ -0,0 +1,23 @@
+package ratm
+
+import (
+	"streelity/v1/model/atm"
+
+	"github.com/gorilla/mux"
+)
+
+//handlers serve the atm routes, they access the data through repository which is injected by Handle
+type handlers struct {
+	repository atm.Repository
+}
+
+//Handle register the atm handlers to the router, every handler access the data through repo
+func Handle(router *mux.Router, repo atm.Repository) *mux.Router {
+	h := handlers{repository: repo}
+	s := h.HandleService(router)
+	h.HandleReview(s)
+	h.HandleBank(s)
+	h.HandleUnconfirmed(router)
+
+	return s
+}
@@ -1,11 +1,13 @@
 package ratm
 
 import (
-	"errors"
 	"log"
 	"net/http"
-	"strconv"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
 	"streelity/v1/model/atm"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
 	"streelity/v1/sres"
 	"streelity/v1/stages"
 
@@ -13,46 +15,30 @@ import (
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
-func ReviewById(w http.ResponseWriter, req *http.Request) {
+func (h handlers) ReviewById(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Review atm.Review
 	}
 	res.Status = true
 	p := pipeline.NewPipeline()
-	stage := pipeline.NewStage(func() (str struct {
-		ReviewId int64
-	}, e error) {
-		form := req.PostForm
-		review_ids, ok := form["review_id"]
-		if !ok {
-			return str, errors.New("review_id param is missing")
-		}
-
-		review_id, e := strconv.ParseInt(review_ids[0], 10, 64)
-		if e != nil {
-			return str, errors.New("review_id param cannot parse to int64")
-		}
-
-		str.ReviewId = review_id
-		return
-	})
+	stage := stages.ReviewIdValidate(req.URL.Query())
 
 	p.First = stage
 	res.Error(p.Run())
 
 	if res.Status {
 		review_id := p.GetIntFirstOrDefault("ReviewId")
-		if review, e := atm.ReviewById(review_id); e != nil {
+		if review, e := h.repository.ReviewById(review_id); e != nil {
 			res.Error(e)
 		} else {
 			res.Review = review
 		}
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
-func UpdateReview(w http.ResponseWriter, req *http.Request) {
+func (h handlers) UpdateReview(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Review atm.Review
@@ -70,11 +56,14 @@ func UpdateReview(w http.ResponseWriter, req *http.Request) {
 		review_id := p.GetIntFirstOrDefault("ReviewId")
 		new_body := p.GetStringFirstOrDefault("NewBody")
 
-		if review, e := atm.ReviewById(review_id); e != nil {
+		if review, e := h.repository.ReviewById(review_id); e != nil {
 			res.Error(e)
+		} else if !model.CanEdit(req.Context(), review.Reviewer) {
+			middleware.Forbid(w, req)
+			return
 		} else {
 			review.Body = new_body
-			res.Error(review.Save())
+			res.Error(h.repository.SaveReview(review))
 			res.Review = review
 		}
 	}
@@ -82,9 +71,10 @@ func UpdateReview(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func ReviewByServiceId(w http.ResponseWriter, req *http.Request) {
+func (h handlers) ReviewByServiceId(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Reviews []atm.Review
 	}
 	res.Status = true
@@ -98,18 +88,18 @@ func ReviewByServiceId(w http.ResponseWriter, req *http.Request) {
 	if res.Status {
 		service_id := p.GetIntFirstOrDefault("ServiceId")
 		order := p.GetIntFirstOrDefault("Order")
-		limit := p.GetIntFirstOrDefault("Limit")
-		if reviews, e := atm.ReviewByService(service_id, order, limit); e != nil {
-			res.Error(e)
-		} else {
-			res.Reviews = reviews
+		q, e := stages.PageQuery(req)
+		if e == nil {
+			res.Reviews, res.Page, e = h.repository.PageReviews(service_id, order, q)
 		}
+
+		res.Error(e)
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func CreateReview(w http.ResponseWriter, req *http.Request) {
+func (h handlers) CreateReview(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Review atm.Review
@@ -124,10 +114,10 @@ func CreateReview(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		service_id := p.GetIntFirstOrDefault("ServiceId")
-		reviewer := p.GetStringFirstOrDefault("Reviewer")
+		reviewer := model.ContributorOf(req.Context(), p.GetStringFirstOrDefault("Reviewer"))
 		score := p.GetFloatFirstOrDefault("Score")
 		body := p.GetStringFirstOrDefault("Body")
-		if review, e := atm.CreateReview(service_id, reviewer, float32(score), body); e != nil {
+		if review, e := h.repository.CreateReview(service_id, reviewer, float32(score), body); e != nil {
 			res.Error(e)
 		} else {
 			res.Review = review
@@ -137,7 +127,7 @@ func CreateReview(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func ReviewAverageScore(w http.ResponseWriter, req *http.Request) {
+func (h handlers) ReviewAverageScore(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Value float64
@@ -150,13 +140,13 @@ func ReviewAverageScore(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		service_id := p.GetIntFirstOrDefault("ServiceId")
-		res.Value = atm.ReviewAverageScore(service_id)
+		res.Value = h.repository.ReviewAverageScore(service_id)
 	}
 
 	sres.WriteJson(w, res)
 }
 
-func DeleteReview(w http.ResponseWriter, req *http.Request) {
+func (h handlers) DeleteReview(w http.ResponseWriter, req *http.Request) {
 	var res sres.Response = sres.Response{Status: true}
 	p := pipeline.NewPipeline()
 	stage := stages.ReviewIdValidate(req.URL.Query())
@@ -165,21 +155,26 @@ func DeleteReview(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		review_id := p.GetIntFirstOrDefault("ReviewId")
-		if e := atm.DeleteReview(review_id); e != nil {
+		if review, e := h.repository.ReviewById(review_id); e == nil && !model.CanEdit(req.Context(), review.Reviewer) {
+			middleware.Forbid(w, req)
+			return
+		}
+
+		if e := h.repository.DeleteReview(review_id); e != nil {
 			res.Error(e)
 		}
 	}
 	sres.WriteJson(w, res)
 }
 
-func HandleReview(router *mux.Router) {
+func (h handlers) HandleReview(router *mux.Router) {
 	log.Println("[Router]", "Handling review atm")
 	s := router.PathPrefix("/review").Subrouter()
 
-	s.HandleFunc("/", ReviewById).Methods("GET")
-	s.HandleFunc("/", UpdateReview).Methods("POST")
-	s.HandleFunc("/", DeleteReview).Methods("DELETE")
-	s.HandleFunc("/create", CreateReview).Methods("POST")
-	s.HandleFunc("/query", ReviewByServiceId).Methods("GET")
-	s.HandleFunc("/score", ReviewAverageScore).Methods("GET")
+	openapi.Declare(s.HandleFunc("/", h.ReviewById).Methods("GET"), routedoc.ReviewById)
+	openapi.Declare(s.Handle("/", middleware.Authenticate(http.HandlerFunc(h.UpdateReview))).Methods("POST"), routedoc.UpdateReview)
+	openapi.Declare(s.Handle("/", middleware.Authenticate(http.HandlerFunc(h.DeleteReview))).Methods("DELETE"), routedoc.DeleteReview)
+	openapi.Declare(s.Handle("/create", middleware.Authenticate(http.HandlerFunc(h.CreateReview))).Methods("POST"), routedoc.CreateReview)
+	openapi.Declare(s.HandleFunc("/query", h.ReviewByServiceId).Methods("GET"), routedoc.ReviewByServiceId)
+	openapi.Declare(s.HandleFunc("/score", h.ReviewAverageScore).Methods("GET"), routedoc.ReviewAverageScore)
 }
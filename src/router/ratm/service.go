@@ -2,12 +2,14 @@ package ratm
 
 import (
 	"bytes"
-	"errors"
 	"io"
 	"log"
 	"net/http"
-	"strconv"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
 	"streelity/v1/model/atm"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
 	"streelity/v1/sres"
 	"streelity/v1/stages"
 
@@ -16,7 +18,7 @@ import (
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
-func GetService(w http.ResponseWriter, req *http.Request) {
+func (h handlers) GetService(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Service atm.Atm
@@ -31,7 +33,7 @@ func GetService(w http.ResponseWriter, req *http.Request) {
 		switch c {
 		case 1:
 			id := p.GetInt("Id")[0]
-			if service, e := atm.ServiceById(id); e != nil {
+			if service, e := h.repository.ServiceById(id); e != nil {
 				res.Error(e)
 			} else {
 				res.Service = service
@@ -40,7 +42,7 @@ func GetService(w http.ResponseWriter, req *http.Request) {
 		case 2:
 			lat := p.GetFloat("Lat")[0]
 			lon := p.GetFloat("Lon")[0]
-			if service, e := atm.ServiceByLocation(lat, lon); e != nil {
+			if service, e := h.repository.ServiceByLocation(lat, lon); e != nil {
 				res.Error(e)
 			} else {
 				res.Service = service
@@ -48,7 +50,7 @@ func GetService(w http.ResponseWriter, req *http.Request) {
 			break
 		case 3:
 			address := p.GetString("Address")[0]
-			if service, e := atm.ServiceByAddress(address); e != nil {
+			if service, e := h.repository.ServiceByAddress(address); e != nil {
 				res.Error(e)
 			} else {
 				res.Service = service
@@ -56,12 +58,19 @@ func GetService(w http.ResponseWriter, req *http.Request) {
 			break
 		}
 	}
-	sres.WriteJson(w, res)
+
+	//the included data are not covered by the modification time of the service
+	if res.Status && len(req.URL.Query()["include"]) == 0 {
+		middleware.LastModified(w, res.Service.ModifiedAt())
+	}
+
+	sres.WriteShaped(w, req, res, h.includes())
 }
 
-func GetServices(w http.ResponseWriter, req *http.Request) {
+func (h handlers) GetServices(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Services []atm.Atm
 	}
 	res.Status = true
@@ -73,33 +82,44 @@ func GetServices(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		address := p.GetString("Address")[0]
-		if services, e := atm.ServicesByAddress(address); e != nil {
-			res.Error(e)
-		} else {
-			res.Services = services
+		q, e := stages.PageQuery(req)
+		if e == nil {
+			res.Services, res.Page, e = h.repository.PageServicesByAddress(address, q)
 		}
+
+		res.Error(e)
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, h.includes())
 }
 
-func AllServices(w http.ResponseWriter, req *http.Request) {
+func (h handlers) AllServices(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Services []atm.Atm
 	}
 	res.Status = true
 
-	if services, e := atm.AllServices(); e != nil {
+	p := pipeline.NewPipeline()
+	stage := stages.StatesValidateStage(req.URL.Query())
+	p.First = stage
+	res.Error(p.Run())
+
+	//the page is read by the repository so only its services are loaded
+	if res.Status {
+		q, e := stages.PageQuery(req)
+		if e == nil {
+			res.Services, res.Page, e = h.repository.PageServices(q, p.GetString("States")...)
+		}
+
 		res.Error(e)
-	} else {
-		res.Services = services
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, h.includes())
 }
 
-func CreateService(w http.ResponseWriter, req *http.Request) {
+func (h handlers) CreateService(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Service atm.Atm
@@ -107,24 +127,7 @@ func CreateService(w http.ResponseWriter, req *http.Request) {
 	res.Status = true
 	p := pipeline.NewPipeline()
 	stage := stages.CreateServiceValidate(req)
-	bankStage := pipeline.NewStage(func() (str struct {
-		BankId int64
-	}, e error) {
-		form := req.PostForm
-		ids, ok := form["bank_id"]
-		if !ok {
-			return str, errors.New("bank_id param is missing")
-		}
-
-		if id, e := strconv.ParseInt(ids[0], 10, 64); e != nil {
-			return str, errors.New("bank_id cannot parse to int64")
-		} else {
-			str.BankId = id
-		}
-
-		return
-	})
-	stage.NextStage(bankStage)
+	stage.NextStage(stages.BankValidate(req.PostForm))
 	p.First = stage
 
 	res.Error(p.Run())
@@ -136,7 +139,7 @@ func CreateService(w http.ResponseWriter, req *http.Request) {
 		note := p.GetStringFirstOrDefault("Note")
 		images := p.GetString("Images")
 		bank_id := p.GetIntFirstOrDefault("BankId")
-		contributor := p.GetStringFirstOrDefault("Contributor")
+		contributor := model.ContributorOf(req.Context(), p.GetStringFirstOrDefault("Contributor"))
 
 		var ucf atm.Atm
 		ucf.Lat = float32(lat)
@@ -147,7 +150,7 @@ func CreateService(w http.ResponseWriter, req *http.Request) {
 		ucf.Contributor = contributor
 		ucf.SetImages(images...)
 
-		if service, e := atm.CreateService(ucf); e != nil {
+		if service, e := h.repository.CreateService(ucf); e != nil {
 			res.Error(e)
 		} else {
 			res.Service = service
@@ -157,7 +160,7 @@ func CreateService(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func UpdateService(w http.ResponseWriter, req *http.Request) {
+func (h handlers) UpdateService(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Service atm.Atm
@@ -171,7 +174,12 @@ func UpdateService(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		id := p.GetInt("Id")[0]
-		if s, e := atm.UpdateService(id, req.PostForm); e != nil {
+		if s, e := h.repository.ServiceById(id); e == nil && !model.CanEdit(req.Context(), s.Contributor) {
+			middleware.Forbid(w, req)
+			return
+		}
+
+		if s, e := h.repository.UpdateService(id, req.PostForm); e != nil {
 			res.Error(e)
 		} else {
 			res.Service = s
@@ -181,14 +189,16 @@ func UpdateService(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func ServiceInRange(w http.ResponseWriter, req *http.Request) {
+func (h handlers) ServiceInRange(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Services []atm.Atm
 	}
 	res.Status = true
 	pipe := pipeline.NewPipeline()
-	stage := stages.InRangeServiceValidateStage(req)
+	stage := stages.StatesValidateStage(req.URL.Query())
+	stage.NextStage(stages.InRangeServiceValidateStage(req))
 	pipe.First = stage
 
 	res.Error(pipe.Run())
@@ -199,13 +209,170 @@ func ServiceInRange(w http.ResponseWriter, req *http.Request) {
 		max_range := pipe.GetFloatFirstOrDefault("Range")
 		var location r2.Point = r2.Point{X: lat, Y: lon}
 
-		res.Services = atm.ServicesInRange(location, max_range)
+		q, e := stages.PageQuery(req)
+		if e == nil {
+			res.Services, res.Page, e = h.repository.PageServicesInRange(location, max_range, q, pipe.GetString("States")...)
+		}
+
+		res.Error(e)
+	}
+
+	sres.WriteShaped(w, req, res, h.includes())
+}
+
+func (h handlers) TransitService(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Service atm.Atm
+	}
+	res.Status = true
+
+	req.ParseForm()
+	p := pipeline.NewPipeline()
+	stage := stages.TransitValidateStage(req.PostForm)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		id := p.GetIntFirstOrDefault("Id")
+		state := p.GetStringFirstOrDefault("State")
+		reason := p.GetStringFirstOrDefault("Reason")
+		if service, e := h.repository.TransitService(id, state, model.ActorOf(req.Context()), reason); e != nil {
+			res.Error(e)
+		} else {
+			res.Service = service
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) GetTransitions(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Transitions []model.Transition
+	}
+	res.Status = true
+
+	p := pipeline.NewPipeline()
+	stage := stages.IdValidateStage(req.URL.Query())
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		id := p.GetIntFirstOrDefault("Id")
+		if transitions, e := h.repository.TransitionsByService(id); e != nil {
+			res.Error(e)
+		} else {
+			res.Transitions = transitions
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) CheckinService(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Service atm.Atm
+	}
+	res.Status = true
+
+	req.ParseForm()
+	p := pipeline.NewPipeline()
+	stage := stages.IdValidateStage(req.PostForm)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		id := p.GetIntFirstOrDefault("Id")
+		if service, e := h.repository.VerifyService(id); e != nil {
+			res.Error(e)
+		} else {
+			res.Service = service
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) ReverifyQueue(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Services []atm.Atm
+	}
+	res.Status = true
+
+	p := pipeline.NewPipeline()
+	stage := stages.InRangeServiceValidateStage(req)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		location := r2.Point{X: p.GetFloatFirstOrDefault("Lat"), Y: p.GetFloatFirstOrDefault("Lon")}
+		res.Services = h.repository.ReverifyQueue(location, p.GetFloatFirstOrDefault("Range"))
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) ReportService(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Report model.Report
+	}
+	res.Status = true
+
+	req.ParseForm()
+	p := pipeline.NewPipeline()
+	stage := stages.ReportValidateStage(req.PostForm)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		report := model.Report{
+			Reporter:    model.ActorOf(req.Context()),
+			Kind:        p.GetStringFirstOrDefault("Kind"),
+			Note:        p.GetStringFirstOrDefault("Note"),
+			Lat:         float32(p.GetFloatFirstOrDefault("Lat")),
+			Lon:         float32(p.GetFloatFirstOrDefault("Lon")),
+			DuplicateOf: p.GetIntFirstOrDefault("DuplicateOf"),
+		}
+
+		if report, e := h.repository.ReportService(p.GetIntFirstOrDefault("Id"), report); e != nil {
+			res.Error(e)
+		} else {
+			res.Report = report
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) GetReports(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Reports []model.Report
+	}
+	res.Status = true
+
+	p := pipeline.NewPipeline()
+	stage := stages.IdValidateStage(req.URL.Query())
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		if reports, e := h.repository.ReportsByService(p.GetIntFirstOrDefault("Id")); e != nil {
+			res.Error(e)
+		} else {
+			res.Reports = reports
+		}
 	}
 
 	sres.WriteJson(w, res)
 }
 
-func Import(w http.ResponseWriter, req *http.Request) {
+func (h handlers) Import(w http.ResponseWriter, req *http.Request) {
 	var res sres.Response = sres.Response{Status: true}
 
 	req.ParseForm()
@@ -227,24 +394,33 @@ func Import(w http.ResponseWriter, req *http.Request) {
 
 		var buf bytes.Buffer
 		io.Copy(&buf, file)
-		atm.Import(buf.Bytes(), t)
+		h.repository.Import(buf.Bytes(), t)
 
 	}
 
 	sres.WriteJson(w, res)
 }
 
-func HandleService(router *mux.Router) *mux.Router {
+//createAtm is the doc of the creation of the ATMs, they are created with their bank instead of a name
+var createAtm = openapi.Doc{Summary: "Submit an ATM", Tags: []string{"services"}, Params: []interface{}{stages.CreateServiceRequest{}, stages.BankRequest{}}, Security: routedoc.Token}
+
+func (h handlers) HandleService(router *mux.Router) *mux.Router {
 	s := router.PathPrefix("/atm").Subrouter()
 
-	s.HandleFunc("/", CreateService).Methods("POST")
-	s.HandleFunc("/", GetService).Methods("GET")
-	s.HandleFunc("/s", GetServices).Methods("GET")
-	s.HandleFunc("/update", UpdateService).Methods("POST")
-	s.HandleFunc("/all", AllServices).Methods("GET")
-	s.HandleFunc("/create", CreateService).Methods("POST")
-	s.HandleFunc("/range", ServiceInRange).Methods("GET")
-	s.HandleFunc("/import", Import).Methods("POST")
+	openapi.Declare(s.Handle("/", middleware.Authenticate(http.HandlerFunc(h.CreateService))).Methods("POST"), createAtm)
+	openapi.Declare(s.HandleFunc("/", h.GetService).Methods("GET"), routedoc.GetService)
+	openapi.Declare(s.HandleFunc("/s", h.GetServices).Methods("GET"), routedoc.GetServices)
+	openapi.Declare(s.Handle("/update", middleware.Authenticate(http.HandlerFunc(h.UpdateService))).Methods("POST"), routedoc.UpdateService)
+	openapi.Declare(s.HandleFunc("/all", h.AllServices).Methods("GET"), routedoc.AllServices)
+	openapi.Declare(s.Handle("/create", middleware.Authenticate(http.HandlerFunc(h.CreateService))).Methods("POST"), createAtm)
+	openapi.Declare(s.HandleFunc("/range", h.ServiceInRange).Methods("GET"), routedoc.ServiceInRange)
+	openapi.Declare(s.Handle("/import", middleware.Authenticate(middleware.Require(model.PermissionImport, http.HandlerFunc(h.Import)))).Methods("POST"), routedoc.Import)
+	openapi.Declare(s.Handle("/state", middleware.Authenticate(middleware.Admin(http.HandlerFunc(h.TransitService)))).Methods("POST"), routedoc.TransitService)
+	openapi.Declare(s.HandleFunc("/transitions", h.GetTransitions).Methods("GET"), routedoc.GetTransitions)
+	openapi.Declare(s.Handle("/checkin", middleware.Authenticate(http.HandlerFunc(h.CheckinService))).Methods("POST"), routedoc.CheckinService)
+	openapi.Declare(s.HandleFunc("/reverify", h.ReverifyQueue).Methods("GET"), routedoc.ReverifyQueue)
+	openapi.Declare(s.Handle("/report", middleware.Authenticate(http.HandlerFunc(h.ReportService))).Methods("POST"), routedoc.ReportService)
+	openapi.Declare(s.HandleFunc("/reports", h.GetReports).Methods("GET"), routedoc.GetReports)
 
 	return s
 }
@@ -0,0 +1,275 @@
+package ratm_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"streelity/v1/model"
+	"streelity/v1/model/atm"
+	"streelity/v1/router/ratm"
+	"streelity/v1/router/routertest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandlers(t *testing.T) {
+	repo := atm.NewMemory()
+	router := mux.NewRouter()
+	ratm.Handle(router, repo)
+	token, _ := model.CreateToken(1)
+
+	repo.CreateBank(atm.Bank{Name: "ACB"})
+	repo.CreateService(atm.Atm{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Contributor: "Streetlity"}, BankId: 1})
+	repo.CreateService(atm.Atm{Service: model.Service{Lat: 11, Lon: 107, Address: "2 Le Loi", Confident: 1, Contributor: "1"}, BankId: 1})
+	repo.CreateReview(2, "1", 4, "good")
+
+	tests := []routertest.Case{
+		{Name: "get by id", Method: "GET", Path: "/atm/?id=2", Status: true},
+		{Name: "get missing id", Method: "GET", Path: "/atm/?id=100"},
+		{Name: "get without param", Method: "GET", Path: "/atm/"},
+		{Name: "get by address", Method: "GET", Path: "/atm/s?address=le+loi", Status: true, Field: "Services", Length: 1},
+		{Name: "all", Method: "GET", Path: "/atm/all", Status: true, Field: "Services", Length: 2},
+		{Name: "range", Method: "GET", Path: "/atm/range?location=10&location=106&range=0.5", Status: true, Field: "Services", Length: 1},
+		{Name: "range without location", Method: "GET", Path: "/atm/range?range=0.5"},
+		{Name: "create", Method: "POST", Path: "/atm/create", Form: url.Values{"location": {"12", "108"}, "address": {"3 Hai Ba Trung"}, "bank_id": {"1"}}, Status: true},
+		{Name: "create existed location", Method: "POST", Path: "/atm/create", Form: url.Values{"location": {"12", "108"}, "address": {"3 Hai Ba Trung"}, "bank_id": {"1"}}},
+		{Name: "create without bank", Method: "POST", Path: "/atm/create", Form: url.Values{"location": {"13", "108"}, "address": {"4 Hai Ba Trung"}}},
+		{Name: "update", Method: "POST", Path: "/atm/update", Form: url.Values{"id": {"3"}, "note": {"updated"}}, Status: true},
+		{Name: "create review", Method: "POST", Path: "/atm/review/create", Form: url.Values{"service_id": {"2"}, "reviewer": {"a"}, "score": {"5"}, "body": {"great"}}, Status: true},
+		{Name: "create review without score", Method: "POST", Path: "/atm/review/create", Form: url.Values{"service_id": {"2"}, "reviewer": {"a"}, "body": {"great"}}},
+		{Name: "query review", Method: "GET", Path: "/atm/review/query?service_id=2&order=0&limit=10", Status: true, Field: "Reviews", Length: 2},
+		{Name: "query review with limit", Method: "GET", Path: "/atm/review/query?service_id=2&order=0&limit=1", Status: true, Field: "Reviews", Length: 1},
+		{Name: "update review", Method: "POST", Path: "/atm/review/", Form: url.Values{"review_id": {"4"}, "new_body": {"changed"}}, Status: true},
+		{Name: "delete review", Method: "DELETE", Path: "/atm/review/?review_id=4", Status: true},
+		{Name: "all banks", Method: "GET", Path: "/atm/bank/all", Status: true, Field: "Banks", Length: 1},
+		{Name: "checkin", Method: "POST", Path: "/atm/checkin", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "checkin missing service", Method: "POST", Path: "/atm/checkin", Form: url.Values{"id": {"100"}}},
+		{Name: "reverify", Method: "GET", Path: "/atm/reverify?location=10&location=106&range=0.5", Status: true, Field: "Services"},
+		{Name: "reverify without location", Method: "GET", Path: "/atm/reverify?range=0.5"},
+		{Name: "report", Method: "POST", Path: "/atm/report", Form: url.Values{"id": {"2"}, "kind": {"wrong-location"}, "location": {"11.1", "107.1"}}, Status: true},
+		{Name: "report unknown kind", Method: "POST", Path: "/atm/report", Form: url.Values{"id": {"2"}, "kind": {"gone"}}},
+		{Name: "report wrong location without location", Method: "POST", Path: "/atm/report", Form: url.Values{"id": {"2"}, "kind": {"wrong-location"}}},
+		{Name: "report duplicate", Method: "POST", Path: "/atm/report", Form: url.Values{"id": {"2"}, "kind": {"duplicate"}, "duplicate_of": {"3"}}, Status: true},
+		{Name: "reports", Method: "GET", Path: "/atm/reports?id=2", Status: true, Field: "Reports", Length: 2},
+		{Name: "unconfirmed range", Method: "GET", Path: "/atm_ucf/range?location=11&location=107&range=0.5", Status: true, Field: "Services", Length: 1},
+		{Name: "upvote", Method: "POST", Path: "/atm_ucf/upvote", Form: url.Values{"id": {"3"}}, Status: true},
+		{Name: "upvote missing service", Method: "POST", Path: "/atm_ucf/upvote", Form: url.Values{"id": {"100"}}},
+		{Name: "downvote", Method: "POST", Path: "/atm_ucf/downvote", Form: url.Values{"id": {"3"}}, Status: true},
+		{Name: "voters", Method: "GET", Path: "/atm_ucf/voters?id=3", Status: true, Field: "Votes", Length: 1},
+		{Name: "withdraw", Method: "POST", Path: "/atm_ucf/withdraw", Form: url.Values{"id": {"3"}}, Status: true},
+		{Name: "withdraw missing vote", Method: "POST", Path: "/atm_ucf/withdraw", Form: url.Values{"id": {"3"}}},
+		{Name: "voters after withdraw", Method: "GET", Path: "/atm_ucf/voters?id=3", Status: true, Field: "Votes"},
+	}
+
+	routertest.Run(t, router, token, tests)
+}
+
+func TestUnconfirmedInRange(t *testing.T) {
+	repo := atm.NewMemory()
+	router := mux.NewRouter()
+	ratm.Handle(router, repo)
+
+	repo.CreateBank(atm.Bank{Name: "ACB"})
+	repo.CreateService(atm.Atm{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Confident: 1}, BankId: 1})
+	repo.CreateService(atm.Atm{Service: model.Service{Lat: 10.2, Lon: 106.2, Address: "2 Le Loi", Contributor: "Streetlity"}, BankId: 1})
+	repo.CreateService(atm.Atm{Service: model.Service{Lat: 12, Lon: 108, Address: "3 Hai Ba Trung"}, BankId: 1})
+
+	res := routertest.Serve(router, "GET", "/atm_ucf/range?location=10&location=106&range=0.5", nil, "")
+	services, _ := res["Services"].([]interface{})
+	if len(services) != 1 {
+		t.Fatalf("wrong services in range: got %v want the pending service, %v", len(services), res)
+	}
+
+	if pending := services[0].(map[string]interface{}); pending["Address"] != "1 Nguyen Hue" || pending["BankId"] != 1.0 {
+		t.Errorf("wrong pending service in range: got %v", pending)
+	}
+}
+
+func TestVotes(t *testing.T) {
+	repo := atm.NewMemory()
+	router := mux.NewRouter()
+	ratm.Handle(router, repo)
+
+	s, _ := repo.CreateService(atm.Atm{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue"}})
+	id := url.Values{"id": {strconv.FormatInt(s.Id, 10)}}
+	first, _ := model.CreateToken(1)
+	second, _ := model.CreateToken(2)
+
+	if res := routertest.Serve(router, "POST", "/atm_ucf/upvote", id, ""); res["Status"] != false {
+		t.Errorf("upvote without token is accepted")
+	}
+
+	votes := []struct {
+		path      string
+		token     string
+		confident int
+	}{
+		{"/atm_ucf/upvote", first, 1},
+		{"/atm_ucf/upvote", first, 1},
+		{"/atm_ucf/upvote", second, 2},
+		{"/atm_ucf/downvote", first, 0},
+		{"/atm_ucf/withdraw", second, -1},
+	}
+
+	for _, vote := range votes {
+		if res := routertest.Serve(router, "POST", vote.path, id, vote.token); res["Status"] != true {
+			t.Fatalf("%v returned wrong status, message %v", vote.path, res["Message"])
+		}
+
+		if s, _ := repo.ServiceById(s.Id); s.Confident != vote.confident {
+			t.Errorf("%v returned wrong confident: got %v want %v", vote.path, s.Confident, vote.confident)
+		}
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	repo := atm.NewMemory()
+	router := mux.NewRouter()
+	ratm.Handle(router, repo)
+
+	user, _ := model.CreateToken(1)
+	admin, _ := model.CreateRoleToken(2, model.RoleAdmin)
+	repo.CreateService(atm.Atm{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Contributor: "Streetlity"}})
+	repo.CreateService(atm.Atm{Service: model.Service{Lat: 10.1, Lon: 106.1, Address: "2 Le Loi"}})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		form   url.Values
+		token  string
+		status bool
+		field  string
+		length int
+	}{
+		{"confirmed", "GET", "/atm/all?state=confirmed", nil, "", true, "Services", 1},
+		{"pending", "GET", "/atm/all?state=pending", nil, "", true, "Services", 1},
+		{"unknown state", "GET", "/atm/all?state=lost", nil, "", false, "", 0},
+		{"range of states", "GET", "/atm/range?location=10&location=106&range=0.5&state=confirmed&state=pending", nil, "", true, "Services", 2},
+		{"transit by user", "POST", "/atm/state", url.Values{"id": {"1"}, "state": {"closed"}}, user, false, "", 0},
+		{"transit to unknown state", "POST", "/atm/state", url.Values{"id": {"1"}, "state": {"lost"}}, admin, false, "", 0},
+		{"transit", "POST", "/atm/state", url.Values{"id": {"1"}, "state": {"closed"}, "reason": {"moved"}}, admin, true, "", 0},
+		{"disallowed transit", "POST", "/atm/state", url.Values{"id": {"1"}, "state": {"pending"}}, admin, false, "", 0},
+		{"range after closed", "GET", "/atm/range?location=10&location=106&range=0.5", nil, "", true, "Services", 0},
+		{"transitions", "GET", "/atm/transitions?id=1", nil, "", true, "Transitions", 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := routertest.Serve(router, test.method, test.path, test.form, test.token)
+			if res["Status"] != test.status {
+				t.Fatalf("%v %v returned wrong status: got %v want %v, message %v", test.method, test.path, res["Status"], test.status, res["Message"])
+			}
+
+			if test.field != "" {
+				items, _ := res[test.field].([]interface{})
+				if len(items) != test.length {
+					t.Errorf("%v %v returned wrong %v: got %v want %v", test.method, test.path, test.field, len(items), test.length)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	repo := atm.NewMemory()
+	router := mux.NewRouter()
+	ratm.Handle(router, repo)
+
+	owner, _ := model.CreateToken(1)
+	other, _ := model.CreateToken(2)
+	moderator, _ := model.CreateRoleToken(3, model.RoleModerator)
+	importer, _ := model.CreateRoleToken(4, model.RoleUser, model.PermissionImport)
+	admin, _ := model.CreateRoleToken(5, model.RoleAdmin)
+	repo.CreateBank(atm.Bank{Name: "ACB"})
+	repo.CreateService(atm.Atm{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Contributor: "1"}, BankId: 1})
+	repo.CreateReview(2, "1", 4, "good")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		form   url.Values
+		token  string
+		code   int
+	}{
+		{"create without token", "POST", "/atm/create", url.Values{"location": {"12", "108"}, "bank_id": {"1"}}, "", http.StatusUnauthorized},
+		{"update by other", "POST", "/atm/update", url.Values{"id": {"2"}, "note": {"other"}}, other, http.StatusForbidden},
+		{"update by owner", "POST", "/atm/update", url.Values{"id": {"2"}, "note": {"owner"}}, owner, http.StatusOK},
+		{"update by moderator", "POST", "/atm/update", url.Values{"id": {"2"}, "note": {"moderator"}}, moderator, http.StatusOK},
+		{"update review by other", "POST", "/atm/review/", url.Values{"review_id": {"3"}, "new_body": {"other"}}, other, http.StatusForbidden},
+		{"delete review by other", "DELETE", "/atm/review/?review_id=3", nil, other, http.StatusForbidden},
+		{"delete review by moderator", "DELETE", "/atm/review/?review_id=3", nil, moderator, http.StatusOK},
+		{"create bank by user", "POST", "/atm/bank/create", url.Values{"name": {"VCB"}}, owner, http.StatusForbidden},
+		{"create bank by moderator", "POST", "/atm/bank/create", url.Values{"name": {"VCB"}}, moderator, http.StatusForbidden},
+		{"create bank by admin", "POST", "/atm/bank/create", url.Values{"name": {"VCB"}}, admin, http.StatusOK},
+		{"import by user", "POST", "/atm/import", nil, owner, http.StatusForbidden},
+		{"import by granted user without file", "POST", "/atm/import", nil, importer, http.StatusUnprocessableEntity},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if test.token != "" {
+				req.Header.Set("Auth", test.token)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != test.code {
+				t.Errorf("%v %v returned wrong code: got %v want %v", test.method, test.path, rr.Code, test.code)
+			}
+		})
+	}
+
+	if s, _ := repo.ServiceById(2); s.Note != "moderator" {
+		t.Errorf("service is updated by a forbidden request: got note %v", s.Note)
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	repo := atm.NewMemory()
+	router := mux.NewRouter()
+	ratm.Handle(router, repo)
+
+	repo.CreateBank(atm.Bank{Name: "ACB"})
+	s, _ := repo.CreateService(atm.Atm{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Contributor: "1"}, BankId: 1})
+	repo.CreateReview(s.Id, "1", 4, "good")
+	repo.CreateReview(s.Id, "2", 2, "slow")
+
+	//the includes of the page are read at once, every service gets its own data
+	other, _ := repo.CreateService(atm.Atm{Service: model.Service{Lat: 11, Lon: 107, Address: "2 Le Loi", Contributor: "1"}})
+	for _, reviewer := range []string{"1", "2", "3", "4"} {
+		repo.CreateReview(other.Id, reviewer, 5, "fine")
+	}
+
+	res := routertest.Serve(router, "GET", "/atm/all?fields=id,lat,lon&include=bank,score,reviews,open", nil, "")
+	services, _ := res["Services"].([]interface{})
+	if res["Status"] != true || len(services) != 2 {
+		t.Fatalf("wrong response: %v", res)
+	}
+
+	service := services[0].(map[string]interface{})
+	reviews, _ := service["Reviews"].([]interface{})
+	if len(service) != 7 || service["Bank"] != "ACB" || service["Score"] != 3.0 || service["Open"] != true || len(reviews) != 2 {
+		t.Errorf("wrong service: %v", service)
+	}
+
+	if _, ok := service["Address"]; ok {
+		t.Errorf("address is not in the fields: %v", service)
+	}
+
+	service = services[1].(map[string]interface{})
+	reviews, _ = service["Reviews"].([]interface{})
+	if service["Bank"] != nil || service["Score"] != 5.0 || len(reviews) != 3 || reviews[0].(map[string]interface{})["Reviewer"] != "4" {
+		t.Errorf("wrong other service: %v", service)
+	}
+
+	if res := routertest.Serve(router, "GET", "/atm/all?include=votes", nil, ""); res["Status"] != false {
+		t.Errorf("unknown include is accepted: %v", res)
+	}
+}
@@ -0,0 +1,73 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
+	"streelity/v1/sres"
+	"streelity/v1/stages"
+
+	"github.com/gorilla/mux"
+	"github.com/nvnamsss/goinf/pipeline"
+)
+
+func (h handlers) getReputation(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Reputation model.Reputation
+		Level      int
+		History    []model.ReputationEvent
+	}
+	res.Status = true
+
+	p := pipeline.NewPipeline()
+	stage := stages.UserValidateStage(req.URL.Query())
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		user := p.GetStringFirstOrDefault("User")
+		res.Reputation = h.repositories.Reputation.ReputationOf(user)
+		res.Level = res.Reputation.Level()
+		if history, e := h.repositories.Reputation.ReputationHistory(user); e != nil {
+			res.Error(e)
+		} else {
+			res.History = history
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) flagSpammer(w http.ResponseWriter, req *http.Request) {
+	var res sres.Response = sres.Response{Status: true}
+
+	req.ParseForm()
+	p := pipeline.NewPipeline()
+	stage := stages.SpamValidateStage(req.PostForm)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		user := p.GetStringFirstOrDefault("User")
+		spam := p.GetBoolFirstOrDefault("Spam")
+		if e := h.repositories.Reputation.FlagSpammer(user, spam); e != nil {
+			res.Error(e)
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func HandleReputation(router *mux.Router, repos Repositories) {
+	h := handlers{repositories: repos}
+	log.Println("[Router]", "Handling reputation")
+	tags := []string{"reputation"}
+	openapi.Declare(router.HandleFunc("/reputation", h.getReputation).Methods("GET"),
+		openapi.Doc{Summary: "The reputation of a user", Tags: tags, Params: []interface{}{stages.UserRequest{}}})
+	openapi.Declare(router.Handle("/reputation/spam", middleware.Authenticate(middleware.Admin(http.HandlerFunc(h.flagSpammer)))).Methods("POST"),
+		openapi.Doc{Summary: "Flag or unflag a user as spam", Tags: tags, Params: []interface{}{stages.SpamRequest{}}, Security: routedoc.Token})
+}
@@ -0,0 +1,83 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"streelity/v1/model"
+	"streelity/v1/router"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestGetReputation(t *testing.T) {
+	repos := router.MemoryRepositories()
+	reputations := repos.Reputation.(*model.ReputationMemory)
+	reputations.Change("1", model.ReasonConfirmed, "atm", 1)
+	reputations.Change("1", model.ReasonRejected, "atm", 2)
+	reputations.Change("2", model.ReasonConfirmed, "atm", 3)
+
+	r := mux.NewRouter()
+	router.Handle(r, repos)
+
+	var res struct {
+		Status     bool
+		Reputation model.Reputation
+		History    []model.ReputationEvent
+	}
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/reputation?user=1", nil))
+	json.Unmarshal(rr.Body.Bytes(), &res)
+	if !res.Status || res.Reputation.Reputation != 0 || len(res.History) != 2 {
+		t.Errorf("wrong reputation of user 1: %v", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/reputation", nil))
+	json.Unmarshal(rr.Body.Bytes(), &res)
+	if res.Status {
+		t.Errorf("reputation without user is accepted")
+	}
+}
+
+func TestFlagSpammer(t *testing.T) {
+	repos := router.MemoryRepositories()
+	r := mux.NewRouter()
+	router.Handle(r, repos)
+
+	user, _ := model.CreateToken(1)
+	admin, _ := model.CreateRoleToken(2, model.RoleAdmin)
+	tests := []struct {
+		name   string
+		token  string
+		form   url.Values
+		status int
+		spam   bool
+	}{
+		{"anonymous", "", url.Values{"user": {"3"}}, 401, false},
+		{"user", user, url.Values{"user": {"3"}}, 403, false},
+		{"admin", admin, url.Values{"user": {"3"}}, 200, true},
+		{"admin clear", admin, url.Values{"user": {"3"}, "spam": {"false"}}, 200, false},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/reputation/spam", strings.NewReader(test.form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if test.token != "" {
+			req.Header.Set("Auth", test.token)
+		}
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != test.status {
+			t.Errorf("%v: wrong status: got %v want %v", test.name, rr.Code, test.status)
+		}
+
+		if spam := repos.Reputation.ReputationOf("3").Spam; spam != test.spam {
+			t.Errorf("%v: wrong spam flag: got %v want %v", test.name, spam, test.spam)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package rfuel
+
+import (
+	"streelity/v1/model/fuel"
+
+	"github.com/gorilla/mux"
+)
+
+//handlers serve the fuel routes, they access the data through repository which is injected by Handle
+type handlers struct {
+	repository fuel.Repository
+}
+
+//Handle register the fuel handlers to the router, every handler access the data through repo
+func Handle(router *mux.Router, repo fuel.Repository) *mux.Router {
+	h := handlers{repository: repo}
+	s := h.HandleService(router)
+	h.HandleReview(s)
+	h.HandleUnconfirmed(router)
+
+	return s
+}
@@ -3,7 +3,11 @@ package rfuel
 import (
 	"log"
 	"net/http"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
 	"streelity/v1/model/fuel"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
 	"streelity/v1/sres"
 	"streelity/v1/stages"
 
@@ -11,7 +15,7 @@ import (
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
-func ReviewById(w http.ResponseWriter, req *http.Request) {
+func (h handlers) ReviewById(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Review fuel.Review
@@ -24,16 +28,16 @@ func ReviewById(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		review_id := p.GetIntFirstOrDefault("ReviewId")
-		if review, e := fuel.ReviewById(review_id); e != nil {
+		if review, e := h.repository.ReviewById(review_id); e != nil {
 			res.Error(e)
 		} else {
 			res.Review = review
 		}
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
-func UpdateReview(w http.ResponseWriter, req *http.Request) {
+func (h handlers) UpdateReview(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Review fuel.Review
@@ -51,11 +55,14 @@ func UpdateReview(w http.ResponseWriter, req *http.Request) {
 		review_id := p.GetIntFirstOrDefault("ReviewId")
 		new_body := p.GetStringFirstOrDefault("NewBody")
 
-		if review, e := fuel.ReviewById(review_id); e != nil {
+		if review, e := h.repository.ReviewById(review_id); e != nil {
 			res.Error(e)
+		} else if !model.CanEdit(req.Context(), review.Reviewer) {
+			middleware.Forbid(w, req)
+			return
 		} else {
 			review.Body = new_body
-			res.Error(review.Save())
+			res.Error(h.repository.SaveReview(review))
 			res.Review = review
 		}
 	}
@@ -63,9 +70,10 @@ func UpdateReview(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func ReviewByServiceId(w http.ResponseWriter, req *http.Request) {
+func (h handlers) ReviewByServiceId(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Reviews []fuel.Review
 	}
 	res.Status = true
@@ -79,17 +87,18 @@ func ReviewByServiceId(w http.ResponseWriter, req *http.Request) {
 	if res.Status {
 		service_id := p.GetIntFirstOrDefault("ServiceId")
 		order := p.GetIntFirstOrDefault("Order")
-		if reviews, e := fuel.ReviewByService(service_id, order, 5); e != nil {
-			res.Error(e)
-		} else {
-			res.Reviews = reviews
+		q, e := stages.PageQuery(req)
+		if e == nil {
+			res.Reviews, res.Page, e = h.repository.PageReviews(service_id, order, q)
 		}
+
+		res.Error(e)
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func CreateReview(w http.ResponseWriter, req *http.Request) {
+func (h handlers) CreateReview(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Review fuel.Review
@@ -103,10 +112,10 @@ func CreateReview(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		service_id := p.GetIntFirstOrDefault("ServiceId")
-		reviewer := p.GetStringFirstOrDefault("Reviewer")
+		reviewer := model.ContributorOf(req.Context(), p.GetStringFirstOrDefault("Reviewer"))
 		score := p.GetFloatFirstOrDefault("Score")
 		body := p.GetStringFirstOrDefault("Body")
-		if review, e := fuel.CreateReview(service_id, reviewer, float32(score), body); e != nil {
+		if review, e := h.repository.CreateReview(service_id, reviewer, float32(score), body); e != nil {
 			res.Error(e)
 		} else {
 			res.Review = review
@@ -116,7 +125,7 @@ func CreateReview(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func DeleteReview(w http.ResponseWriter, req *http.Request) {
+func (h handlers) DeleteReview(w http.ResponseWriter, req *http.Request) {
 	var res sres.Response = sres.Response{Status: true}
 	p := pipeline.NewPipeline()
 	stage := stages.ReviewIdValidate(req.URL.Query())
@@ -125,14 +134,19 @@ func DeleteReview(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		review_id := p.GetIntFirstOrDefault("ReviewId")
-		if e := fuel.DeleteReview(review_id); e != nil {
+		if review, e := h.repository.ReviewById(review_id); e == nil && !model.CanEdit(req.Context(), review.Reviewer) {
+			middleware.Forbid(w, req)
+			return
+		}
+
+		if e := h.repository.DeleteReview(review_id); e != nil {
 			res.Error(e)
 		}
 	}
 	sres.WriteJson(w, res)
 }
 
-func ReviewAverageScore(w http.ResponseWriter, req *http.Request) {
+func (h handlers) ReviewAverageScore(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Value float64
@@ -145,22 +159,22 @@ func ReviewAverageScore(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		service_id := p.GetIntFirstOrDefault("ServiceId")
-		res.Value = fuel.ReviewAverageScore(service_id)
+		res.Value = h.repository.ReviewAverageScore(service_id)
 	}
 
 	sres.WriteJson(w, res)
 }
 
-func HandleReview(router *mux.Router) *mux.Router {
+func (h handlers) HandleReview(router *mux.Router) *mux.Router {
 	log.Println("[Router]", "Handling review fuel")
 	s := router.PathPrefix("/review").Subrouter()
 
-	s.HandleFunc("/", ReviewById).Methods("GET")
-	s.HandleFunc("/", UpdateReview).Methods("POST")
-	s.HandleFunc("/", DeleteReview).Methods("DELETE")
-	s.HandleFunc("/query", ReviewByServiceId).Methods("GET")
-	s.HandleFunc("/create", CreateReview).Methods("POST")
-	s.HandleFunc("/score", ReviewAverageScore).Methods("GET")
+	openapi.Declare(s.HandleFunc("/", h.ReviewById).Methods("GET"), routedoc.ReviewById)
+	openapi.Declare(s.Handle("/", middleware.Authenticate(http.HandlerFunc(h.UpdateReview))).Methods("POST"), routedoc.UpdateReview)
+	openapi.Declare(s.Handle("/", middleware.Authenticate(http.HandlerFunc(h.DeleteReview))).Methods("DELETE"), routedoc.DeleteReview)
+	openapi.Declare(s.HandleFunc("/query", h.ReviewByServiceId).Methods("GET"), routedoc.ReviewByServiceId)
+	openapi.Declare(s.Handle("/create", middleware.Authenticate(http.HandlerFunc(h.CreateReview))).Methods("POST"), routedoc.CreateReview)
+	openapi.Declare(s.HandleFunc("/score", h.ReviewAverageScore).Methods("GET"), routedoc.ReviewAverageScore)
 
 	return s
 }
@@ -0,0 +1,190 @@
+package rfuel_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
+	"streelity/v1/model/fuel"
+	"streelity/v1/router/rfuel"
+	"streelity/v1/router/routertest"
+	"streelity/v1/sres"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandlers(t *testing.T) {
+	repo := fuel.NewMemory()
+	router := mux.NewRouter()
+	rfuel.Handle(router, repo)
+	token, _ := model.CreateToken(1)
+
+	repo.CreateService(fuel.Fuel{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Contributor: "Streetlity"}, Name: "First"})
+	repo.CreateService(fuel.Fuel{Service: model.Service{Lat: 11, Lon: 107, Address: "2 Le Loi", Confident: 1, Contributor: "1"}, Name: "Second"})
+	repo.CreateReview(2, "1", 4, "good")
+
+	tests := []routertest.Case{
+		{Name: "get by id", Method: "GET", Path: "/fuel/?id=1", Status: true},
+		{Name: "get missing id", Method: "GET", Path: "/fuel/?id=100"},
+		{Name: "get without param", Method: "GET", Path: "/fuel/"},
+		{Name: "get by address", Method: "GET", Path: "/fuel/s?address=le+loi", Status: true, Field: "Services", Length: 1},
+		{Name: "all", Method: "GET", Path: "/fuel/all", Status: true, Field: "Services", Length: 2},
+		{Name: "range", Method: "GET", Path: "/fuel/range?location=10&location=106&range=0.5", Status: true, Field: "Services", Length: 1},
+		{Name: "range without location", Method: "GET", Path: "/fuel/range?range=0.5"},
+		{Name: "create", Method: "POST", Path: "/fuel/create", Form: url.Values{"location": {"12", "108"}, "address": {"3 Hai Ba Trung"}, "name": {"Station"}}, Status: true},
+		{Name: "create existed location", Method: "POST", Path: "/fuel/create", Form: url.Values{"location": {"12", "108"}, "address": {"3 Hai Ba Trung"}, "name": {"Station"}}},
+		{Name: "create without address", Method: "POST", Path: "/fuel/create", Form: url.Values{"location": {"13", "108"}}},
+		{Name: "update", Method: "POST", Path: "/fuel/update", Form: url.Values{"id": {"2"}, "note": {"updated"}}, Status: true},
+		{Name: "create review", Method: "POST", Path: "/fuel/review/create", Form: url.Values{"service_id": {"2"}, "reviewer": {"a"}, "score": {"5"}, "body": {"great"}}, Status: true},
+		{Name: "create review without score", Method: "POST", Path: "/fuel/review/create", Form: url.Values{"service_id": {"2"}, "reviewer": {"a"}, "body": {"great"}}},
+		{Name: "query review", Method: "GET", Path: "/fuel/review/query?service_id=2&order=0&limit=10", Status: true, Field: "Reviews", Length: 2},
+		{Name: "update review", Method: "POST", Path: "/fuel/review/", Form: url.Values{"review_id": {"3"}, "new_body": {"changed"}}, Status: true},
+		{Name: "review score", Method: "GET", Path: "/fuel/review/score?service_id=2", Status: true},
+		{Name: "unconfirmed range", Method: "GET", Path: "/fuel_ucf/range?location=11&location=107&range=0.5", Status: true, Field: "Services", Length: 1},
+		{Name: "upvote", Method: "POST", Path: "/fuel_ucf/upvote", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "upvote missing service", Method: "POST", Path: "/fuel_ucf/upvote", Form: url.Values{"id": {"100"}}},
+		{Name: "downvote", Method: "POST", Path: "/fuel_ucf/downvote", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "voters", Method: "GET", Path: "/fuel_ucf/voters?id=2", Status: true, Field: "Votes", Length: 1},
+		{Name: "withdraw", Method: "POST", Path: "/fuel_ucf/withdraw", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "withdraw missing vote", Method: "POST", Path: "/fuel_ucf/withdraw", Form: url.Values{"id": {"2"}}},
+		{Name: "voters after withdraw", Method: "GET", Path: "/fuel_ucf/voters?id=2", Status: true, Field: "Votes"},
+	}
+
+	routertest.Run(t, router, token, tests)
+}
+
+//TestDeleteUnconfirmed delete by the id in the query, the body of a DELETE request is not parsed
+func TestDeleteUnconfirmed(t *testing.T) {
+	repo := fuel.NewMemory()
+	router := mux.NewRouter()
+	rfuel.Handle(router, repo)
+	token, _ := model.CreateToken(1)
+
+	ucf, _ := repo.CreateUcf(fuel.FuelUcf{ServiceUcf: model.ServiceUcf{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Contributor: "1"}})
+	path := "/fuel_ucf/?id=" + strconv.FormatInt(ucf.Id, 10)
+	routertest.Run(t, router, token, []routertest.Case{
+		{Name: "delete", Method: "DELETE", Path: path, Status: true},
+		{Name: "delete deleted", Method: "DELETE", Path: path},
+		{Name: "delete without id", Method: "DELETE", Path: "/fuel_ucf/"},
+	})
+
+	if _, e := repo.UcfById(ucf.Id); e == nil {
+		t.Errorf("unconfirmed service is not deleted")
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	repo := fuel.NewMemory()
+	router := mux.NewRouter()
+	router.Use(middleware.RequestId)
+	rfuel.Handle(router, repo)
+	token, _ := model.CreateToken(1)
+
+	repo.CreateService(fuel.Fuel{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Contributor: "1"}, Name: "First"})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		form   url.Values
+		token  string
+		status int
+		code   string
+		field  string
+	}{
+		{"found", "GET", "/fuel/?id=1", nil, token, http.StatusOK, "", ""},
+		{"missing record", "GET", "/fuel/?id=100", nil, token, http.StatusNotFound, sres.CodeNotFound, ""},
+		{"missing param", "GET", "/fuel/review/query?order=0&limit=10", nil, token, http.StatusUnprocessableEntity, sres.CodeValidation, "service_id"},
+		{"invalid param", "POST", "/fuel/review/create", url.Values{"service_id": {"1"}, "reviewer": {"a"}, "score": {"9"}, "body": {"b"}}, token, http.StatusUnprocessableEntity, sres.CodeValidation, "score"},
+		{"existed location", "POST", "/fuel/create", url.Values{"location": {"10", "106"}, "address": {"1 Nguyen Hue"}, "name": {"First"}}, token, http.StatusConflict, sres.CodeConflict, ""},
+		{"without token", "POST", "/fuel/create", url.Values{"location": {"12", "108"}}, "", http.StatusUnauthorized, sres.CodeUnauthorized, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set(sres.RequestIdHeader, "req-"+test.name[:3])
+			if test.token != "" {
+				req.Header.Set("Auth", test.token)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			var res sres.Response
+			json.Unmarshal(rr.Body.Bytes(), &res)
+			if rr.Code != test.status || res.Code != test.code {
+				t.Fatalf("%v %v returned wrong error: got %v %v want %v %v, message %v", test.method, test.path, rr.Code, res.Code, test.status, test.code, res.Message)
+			}
+
+			if test.code != "" && res.RequestId != "req-"+test.name[:3] {
+				t.Errorf("%v %v returned wrong request id: got %v", test.method, test.path, res.RequestId)
+			}
+
+			if test.field != "" && (len(res.Fields) != 1 || res.Fields[0].Field != test.field) {
+				t.Errorf("%v %v returned wrong fields: got %v want %v", test.method, test.path, res.Fields, test.field)
+			}
+		})
+	}
+}
+
+func TestPagination(t *testing.T) {
+	repo := fuel.NewMemory()
+	router := mux.NewRouter()
+	rfuel.Handle(router, repo)
+
+	repo.CreateService(fuel.Fuel{Service: model.Service{Lat: 10, Lon: 106, Contributor: "1"}, Name: "First"})
+	repo.CreateService(fuel.Fuel{Service: model.Service{Lat: 11, Lon: 107, Contributor: "2"}, Name: "Second"})
+	repo.CreateService(fuel.Fuel{Service: model.Service{Lat: 12, Lon: 108, Contributor: "1"}, Name: "Third"})
+	repo.CreateReview(3, "1", 5, "great")
+	repo.CreateReview(1, "1", 2, "bad")
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"all", "", []string{"First", "Second", "Third"}},
+		{"filter", "filter[contributor]=1", []string{"First", "Third"}},
+		{"rating", "sort=-rating", []string{"Third", "First", "Second"}},
+		{"distance", "sort=distance&location=12&location=108", []string{"Third", "Second", "First"}},
+	}
+
+	for _, test := range tests {
+		var names []string
+		cursor := ""
+		for i := 0; i < 5; i++ {
+			path := "/fuel/all?limit=1&" + test.query
+			if cursor != "" {
+				path += "&cursor=" + url.QueryEscape(cursor)
+			}
+
+			res := routertest.Serve(router, "GET", path, nil, "")
+			services, _ := res["Services"].([]interface{})
+			if res["Status"] != true || len(services) != 1 {
+				t.Fatalf("%v: wrong page %v: %v", test.name, i, res)
+			}
+
+			names = append(names, services[0].(map[string]interface{})["Name"].(string))
+			if cursor, _ = res["Next"].(string); cursor == "" {
+				break
+			}
+		}
+
+		if strings.Join(names, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("%v: wrong services: got %v want %v", test.name, names, test.expected)
+		}
+	}
+
+	for _, path := range []string{"/fuel/all?sort=name", "/fuel/all?limit=0", "/fuel/all?filter[secret]=1", "/fuel_ucf/all?sort=rating"} {
+		if res := routertest.Serve(router, "GET", path, nil, ""); res["Code"] != sres.CodeValidation {
+			t.Errorf("%v is not rejected: %v", path, res)
+		}
+	}
+}
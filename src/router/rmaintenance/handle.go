@@ -0,0 +1,24 @@
+package rmaintenance
+
+import (
+	"streelity/v1/model/maintenance"
+
+	"github.com/gorilla/mux"
+)
+
+//handlers serve the maintenance routes, they access the data through repository which is injected by Handle
+type handlers struct {
+	repository maintenance.Repository
+}
+
+//Handle register the maintenance handlers to the router, every handler access the data through repo
+func Handle(router *mux.Router, repo maintenance.Repository) *mux.Router {
+	h := handlers{repository: repo}
+	s := h.HandleService(router)
+	h.HandleReview(s)
+	h.HandleUnconfirmed(router)
+	h.HandleHistory(s)
+	h.HandleOrder(s)
+
+	return s
+}
@@ -1,10 +1,12 @@
 package rmaintenance
 
 import (
-	"errors"
 	"log"
 	"net/http"
+	"streelity/v1/model"
 	"streelity/v1/model/maintenance"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
 	"streelity/v1/sres"
 	"streelity/v1/stages"
 
@@ -12,7 +14,7 @@ import (
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
-func GetHistory(w http.ResponseWriter, req *http.Request) {
+func (h handlers) GetHistory(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		History maintenance.MaintenanceHistory
@@ -26,91 +28,79 @@ func GetHistory(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		id := p.GetInt("Id")[0]
-		if history, e := maintenance.HistoryById(id); e != nil {
+		if history, e := h.repository.HistoryById(id); e != nil {
 			res.Error(e)
 		} else {
 			res.History = history
 		}
 	}
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func GetHistoriesC(w http.ResponseWriter, req *http.Request) {
+func (h handlers) GetHistoriesC(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Histories []maintenance.MaintenanceHistory
 	}
 	res.Status = true
 
 	p := pipeline.NewPipeline()
-	stage := pipeline.NewStage(func() (str struct {
-		CommonUser string
-	}, e error) {
-		query := req.URL.Query()
-		users, ok := query["cuser"]
-		if !ok {
-			return str, errors.New("cuser param is missing")
-		}
-		str.CommonUser = users[0]
-		return
-	})
+	stage := stages.CommonUserValidate(req)
 	p.First = stage
 	res.Error(p.Run())
 
 	if res.Status {
 		c := p.GetString("CommonUser")[0]
-		if histories, e := maintenance.HistoriesByCUser(c); e != nil {
-			res.Error(e)
-		} else {
-			res.Histories = histories
+		q, e := stages.PageQuery(req)
+		if e == nil {
+			res.Histories, res.Page, e = h.repository.PageHistoriesByCUser(c, q)
 		}
+
+		res.Error(e)
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func GetHistoriesM(w http.ResponseWriter, req *http.Request) {
+func (h handlers) GetHistoriesM(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Histories []maintenance.MaintenanceHistory
 	}
 	res.Status = true
 
 	p := pipeline.NewPipeline()
-	stage := pipeline.NewStage(func() (str struct {
-		MaintenanceUser string
-	}, e error) {
-		query := req.URL.Query()
-
-		users, ok := query["muser"]
-		if !ok {
-			return str, errors.New("muser param is missing")
-		}
-
-		str.MaintenanceUser = users[0]
-		return
-	})
+	stage := stages.MaintenanceUserValidate(req)
 	p.First = stage
 	res.Error(p.Run())
 
 	if res.Status {
 		m := p.GetString("MaintenanceUser")[0]
-		if histories, e := maintenance.HistoriesByMUser(m); e != nil {
-			res.Error(e)
-		} else {
-			res.Histories = histories
+		q, e := stages.PageQuery(req)
+		if e == nil {
+			res.Histories, res.Page, e = h.repository.PageHistoriesByMUser(m, q)
 		}
+
+		res.Error(e)
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func HandleHistory(router *mux.Router) *mux.Router {
+var (
+	getHistory    = openapi.Doc{Summary: "A maintenance history by id", Tags: []string{"histories"}, Params: []interface{}{stages.IdRequest{}, routedoc.FieldsQuery{}}}
+	getHistoriesC = openapi.Doc{Summary: "The maintenance histories of a common user", Tags: []string{"histories"}, Params: routedoc.Listing(stages.CommonUserRequest{}, routedoc.FieldsQuery{}), Extra: []openapi.Param{routedoc.Filter}}
+	getHistoriesM = openapi.Doc{Summary: "The maintenance histories of a maintenance user", Tags: []string{"histories"}, Params: routedoc.Listing(stages.MaintenanceUserRequest{}, routedoc.FieldsQuery{}), Extra: []openapi.Param{routedoc.Filter}}
+)
+
+func (h handlers) HandleHistory(router *mux.Router) *mux.Router {
 	log.Println("[Maintenance]", "Loading history")
 	s := router.PathPrefix("/history").Subrouter()
-	s.HandleFunc("/", GetHistory).Methods("GET")
-	s.HandleFunc("/c", GetHistoriesC).Methods("GET")
-	s.HandleFunc("/m", GetHistoriesM).Methods("GET")
+	openapi.Declare(s.HandleFunc("/", h.GetHistory).Methods("GET"), getHistory)
+	openapi.Declare(s.HandleFunc("/c", h.GetHistoriesC).Methods("GET"), getHistoriesC)
+	openapi.Declare(s.HandleFunc("/m", h.GetHistoriesM).Methods("GET"), getHistoriesM)
 
 	return s
 }
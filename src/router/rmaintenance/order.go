@@ -1,11 +1,12 @@
 package rmaintenance
 
 import (
-	"errors"
 	"log"
 	"net/http"
 	"net/url"
-	"streelity/v1/model/maintenance"
+	"streelity/v1/middleware"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
 	"streelity/v1/sres"
 	"streelity/v1/srpc"
 	"streelity/v1/stages"
@@ -14,8 +15,12 @@ import (
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
-func EmergencyOrder(w http.ResponseWriter, req *http.Request) {
-	var res sres.Response = sres.Response{Status: true}
+func (h handlers) EmergencyOrder(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Order srpc.MaintenanceOrder
+	}
+	res.Status = true
 
 	p := pipeline.NewPipeline()
 	stage := stages.EmergencyOrderValidate(req)
@@ -42,14 +47,19 @@ func EmergencyOrder(w http.ResponseWriter, req *http.Request) {
 			log.Println(order)
 			res.Status = order.Status
 			res.Message = order.Message
+			res.Order = order.Order
 		}
 	}
 
 	sres.WriteJson(w, res)
 }
 
-func CommonOrder(w http.ResponseWriter, req *http.Request) {
-	var res sres.Response = sres.Response{Status: true, Message: "Order successfully"}
+func (h handlers) CommonOrder(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Order srpc.MaintenanceOrder
+	}
+	res.Status, res.Message = true, "Order successfully"
 
 	p := pipeline.NewPipeline()
 	stage := stages.CommonOrderValidate(req)
@@ -63,7 +73,7 @@ func CommonOrder(w http.ResponseWriter, req *http.Request) {
 		note := p.GetStringFirstOrDefault("Note")
 		phone := p.GetString("Phone")[0]
 		order_type := "1"
-		services := maintenance.ServicesByIds(service_ids...)
+		services := h.repository.ServicesByIds(service_ids...)
 		maintenance_users := []string{}
 		for _, s := range services {
 			if s.Maintainer != "" {
@@ -75,7 +85,7 @@ func CommonOrder(w http.ResponseWriter, req *http.Request) {
 		}
 
 		if len(maintenance_users) == 0 {
-			res.Error(errors.New("cannot find any suitable maintenance user"))
+			res.Error(sres.NewError(sres.CodeNotFound, "cannot find any suitable maintenance user"))
 		} else {
 			if order, e := srpc.RequestOrder(url.Values{
 				"maintenance_users": maintenance_users,
@@ -90,6 +100,7 @@ func CommonOrder(w http.ResponseWriter, req *http.Request) {
 				log.Println(order)
 				res.Status = order.Status
 				res.Message = order.Message
+				res.Order = order.Order
 			}
 		}
 	}
@@ -97,9 +108,14 @@ func CommonOrder(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func HandleOrder(router *mux.Router) *mux.Router {
+var (
+	emergencyOrder = openapi.Doc{Summary: "Order the maintenance users in an emergency", Tags: []string{"orders"}, Params: []interface{}{stages.EmergencyOrderRequest{}}, Security: routedoc.Token}
+	commonOrder    = openapi.Doc{Summary: "Order the maintainers of the services", Tags: []string{"orders"}, Params: []interface{}{stages.CommonOrderRequest{}}, Security: routedoc.Token}
+)
+
+func (h handlers) HandleOrder(router *mux.Router) *mux.Router {
 	s := router.PathPrefix("/order").Subrouter()
-	s.HandleFunc("/e", EmergencyOrder).Methods("POST")
-	s.HandleFunc("/c", CommonOrder).Methods("POST")
+	openapi.Declare(s.Handle("/e", middleware.Authenticate(http.HandlerFunc(h.EmergencyOrder))).Methods("POST"), emergencyOrder)
+	openapi.Declare(s.Handle("/c", middleware.Authenticate(http.HandlerFunc(h.CommonOrder))).Methods("POST"), commonOrder)
 	return s
 }
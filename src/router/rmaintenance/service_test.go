@@ -0,0 +1,60 @@
+package rmaintenance_test
+
+import (
+	"net/url"
+	"streelity/v1/model"
+	"streelity/v1/model/maintenance"
+	"streelity/v1/router/rmaintenance"
+	"streelity/v1/router/routertest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandlers(t *testing.T) {
+	repo := maintenance.NewMemory()
+	router := mux.NewRouter()
+	rmaintenance.Handle(router, repo)
+	token, _ := model.CreateToken(1)
+
+	repo.CreateService(maintenance.Maintenance{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Contributor: "Streetlity"}, Name: "First"})
+	repo.CreateService(maintenance.Maintenance{Service: model.Service{Lat: 11, Lon: 107, Address: "2 Le Loi", Confident: 1, Contributor: "1"}, Name: "Second"})
+	repo.CreateReview(2, "1", 4, "good")
+	repo.AddMaintenanceHistory(maintenance.MaintenanceHistory{MaintenanceUser: "mechanic", CommonUser: "customer"})
+	repo.AddMaintenanceHistory(maintenance.MaintenanceHistory{MaintenanceUser: "other", CommonUser: "customer"})
+
+	tests := []routertest.Case{
+		{Name: "get by id", Method: "GET", Path: "/maintenance/?id=1", Status: true},
+		{Name: "get missing id", Method: "GET", Path: "/maintenance/?id=100"},
+		{Name: "get without param", Method: "GET", Path: "/maintenance/"},
+		{Name: "get by address", Method: "GET", Path: "/maintenance/s?address=le+loi", Status: true, Field: "Services", Length: 1},
+		{Name: "all", Method: "GET", Path: "/maintenance/all", Status: true, Field: "Services", Length: 2},
+		{Name: "range", Method: "GET", Path: "/maintenance/range?location=10&location=106&range=0.5", Status: true, Field: "Services", Length: 1},
+		{Name: "range without location", Method: "GET", Path: "/maintenance/range?range=0.5"},
+		{Name: "create", Method: "POST", Path: "/maintenance/create", Form: url.Values{"location": {"12", "108"}, "address": {"3 Hai Ba Trung"}, "name": {"Garage"}}, Status: true},
+		{Name: "create existed location", Method: "POST", Path: "/maintenance/create", Form: url.Values{"location": {"12", "108"}, "address": {"3 Hai Ba Trung"}, "name": {"Garage"}}},
+		{Name: "create without address", Method: "POST", Path: "/maintenance/create", Form: url.Values{"location": {"13", "108"}, "name": {"Garage"}}},
+		{Name: "update", Method: "POST", Path: "/maintenance/update", Form: url.Values{"id": {"2"}, "note": {"updated"}}, Status: true},
+		{Name: "create review", Method: "POST", Path: "/maintenance/review/create", Form: url.Values{"service_id": {"2"}, "reviewer": {"a"}, "score": {"5"}, "body": {"great"}}, Status: true},
+		{Name: "create review without score", Method: "POST", Path: "/maintenance/review/create", Form: url.Values{"service_id": {"2"}, "reviewer": {"a"}, "body": {"great"}}},
+		{Name: "query review", Method: "GET", Path: "/maintenance/review/query?service_id=2&order=0&limit=10", Status: true, Field: "Reviews", Length: 2},
+		{Name: "update review", Method: "POST", Path: "/maintenance/review/", Form: url.Values{"review_id": {"3"}, "new_body": {"changed"}}, Status: true},
+		{Name: "unconfirmed range", Method: "GET", Path: "/maintenance_ucf/range?location=11&location=107&range=0.5", Status: true, Field: "Services", Length: 1},
+		{Name: "upvote", Method: "POST", Path: "/maintenance_ucf/upvote", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "upvote missing service", Method: "POST", Path: "/maintenance_ucf/upvote", Form: url.Values{"id": {"100"}}},
+		{Name: "downvote", Method: "POST", Path: "/maintenance_ucf/downvote", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "voters", Method: "GET", Path: "/maintenance_ucf/voters?id=2", Status: true, Field: "Votes", Length: 1},
+		{Name: "withdraw", Method: "POST", Path: "/maintenance_ucf/withdraw", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "withdraw missing vote", Method: "POST", Path: "/maintenance_ucf/withdraw", Form: url.Values{"id": {"2"}}},
+		{Name: "voters after withdraw", Method: "GET", Path: "/maintenance_ucf/voters?id=2", Status: true, Field: "Votes"},
+		{Name: "add maintainer", Method: "POST", Path: "/maintenance/maintainer", Form: url.Values{"service_id": {"2"}, "maintainer": {"mechanic"}}, Status: true},
+		{Name: "add existed maintainer", Method: "POST", Path: "/maintenance/maintainer", Form: url.Values{"service_id": {"2"}, "maintainer": {"mechanic"}}},
+		{Name: "remove maintainer", Method: "DELETE", Path: "/maintenance/maintainer?service_id=2&maintainer=mechanic", Status: true},
+		{Name: "remove missing maintainer", Method: "DELETE", Path: "/maintenance/maintainer?service_id=2&maintainer=mechanic"},
+		{Name: "history by common user", Method: "GET", Path: "/maintenance/history/c?cuser=customer", Status: true, Field: "Histories", Length: 2},
+		{Name: "history by maintenance user", Method: "GET", Path: "/maintenance/history/m?muser=mechanic", Status: true, Field: "Histories", Length: 1},
+		{Name: "history without user", Method: "GET", Path: "/maintenance/history/m"},
+	}
+
+	routertest.Run(t, router, token, tests)
+}
@@ -0,0 +1,81 @@
+//Package routedoc is the documentation of the routes which every type of the services has, the routes are documented
+//where they are registered by openapi.Declare
+package routedoc
+
+import (
+	"streelity/v1/openapi"
+	"streelity/v1/stages"
+)
+
+//The security schemes of the document
+const (
+	Token  = "token"
+	ApiKey = "apiKey"
+)
+
+//FieldsQuery and ShapeQuery are the params of the shapes of the responses, they are read by sres.Shape instead of a
+//stage
+
+type FieldsQuery struct {
+	Fields []string `param:"fields" from:"query"`
+}
+
+type ShapeQuery struct {
+	Fields  []string `param:"fields" from:"query"`
+	Include []string `param:"include" from:"query"`
+}
+
+//Filter is the filters of the listings like `filter[contributor]=1`, see stages.PageQuery
+var Filter openapi.Param = openapi.Param{Name: "filter", In: "query", Style: "deepObject",
+	Schema: &openapi.Schema{Type: "object", AdditionalProperties: true}}
+
+//Listing return params with the params of the page, they are the params of a listing
+func Listing(params ...interface{}) []interface{} {
+	return append(params, stages.PageRequest{})
+}
+
+var (
+	services    = []string{"services"}
+	unconfirmed = []string{"unconfirmed services"}
+	reviews     = []string{"reviews"}
+)
+
+//The docs of the service routes, they are named by their handlers
+var (
+	CreateService  = openapi.Doc{Summary: "Submit a service", Tags: services, Params: []interface{}{stages.CreateServiceRequest{}, stages.NameRequest{}}, Security: Token}
+	GetService     = openapi.Doc{Summary: "A service by id, location or address", Tags: services, Params: []interface{}{stages.QueryServiceRequest{}, ShapeQuery{}}}
+	GetServices    = openapi.Doc{Summary: "The services by address", Tags: services, Params: Listing(stages.AddressRequest{}, ShapeQuery{}), Extra: []openapi.Param{Filter}}
+	UpdateService  = openapi.Doc{Summary: "Change a service", Tags: services, Params: []interface{}{stages.UpdateServiceRequest{}}, Security: Token}
+	AllServices    = openapi.Doc{Summary: "The services", Tags: services, Params: Listing(stages.StatesRequest{}, ShapeQuery{}), Extra: []openapi.Param{Filter}}
+	ServiceInRange = openapi.Doc{Summary: "The services in the range of a location", Tags: services, Params: Listing(stages.StatesRequest{}, stages.RangeRequest{}, ShapeQuery{}), Extra: []openapi.Param{Filter}}
+	Import         = openapi.Doc{Summary: "Import the services from the csv file in the `f` field of a multipart body", Tags: services, Params: []interface{}{stages.ImportRequest{}}, Security: Token}
+	TransitService = openapi.Doc{Summary: "Change the lifecycle state of a service", Tags: services, Params: []interface{}{stages.TransitRequest{}}, Security: Token}
+	GetTransitions = openapi.Doc{Summary: "The lifecycle transitions of a service", Tags: services, Params: []interface{}{stages.IdRequest{}}}
+	CheckinService = openapi.Doc{Summary: "Confirm that a service is still there", Tags: services, Params: []interface{}{stages.IdRequest{}}, Security: Token}
+	ReverifyQueue  = openapi.Doc{Summary: "The services in the range of a location which need to be verified again", Tags: services, Params: []interface{}{stages.RangeRequest{}}}
+	ReportService  = openapi.Doc{Summary: "Report a problem of a service", Tags: services, Params: []interface{}{stages.ReportRequest{}}, Security: Token}
+	GetReports     = openapi.Doc{Summary: "The problem reports of a service", Tags: services, Params: []interface{}{stages.IdRequest{}}}
+)
+
+//The docs of the unconfirmed service routes
+var (
+	GetUnconfirmed      = openapi.Doc{Summary: "An unconfirmed service by id, location or address", Tags: unconfirmed, Params: []interface{}{stages.QueryServiceRequest{}, FieldsQuery{}}}
+	DeleteUnconfirmed   = openapi.Doc{Summary: "Delete an unconfirmed service", Tags: unconfirmed, Params: []interface{}{stages.IdRequest{}}, Security: Token}
+	GetAllUnconfirmed   = openapi.Doc{Summary: "The unconfirmed services", Tags: unconfirmed, Params: Listing(FieldsQuery{}), Extra: []openapi.Param{Filter}}
+	GetUnconfirmeds     = openapi.Doc{Summary: "The unconfirmed services by address", Tags: unconfirmed, Params: Listing(stages.AddressRequest{}, FieldsQuery{}), Extra: []openapi.Param{Filter}}
+	UnconfirmedInRange  = openapi.Doc{Summary: "The unconfirmed services in the range of a location", Tags: unconfirmed, Params: Listing(stages.RangeRequest{}, FieldsQuery{}), Extra: []openapi.Param{Filter}}
+	UpvoteUnconfirmed   = openapi.Doc{Summary: "Upvote an unconfirmed service", Tags: unconfirmed, Params: []interface{}{stages.UpvoteRequest{}}, Security: Token}
+	DownvoteUnconfirmed = openapi.Doc{Summary: "Downvote an unconfirmed service", Tags: unconfirmed, Params: []interface{}{stages.UpvoteRequest{}}, Security: Token}
+	WithdrawVote        = openapi.Doc{Summary: "Withdraw the vote on an unconfirmed service", Tags: unconfirmed, Params: []interface{}{stages.UpvoteRequest{}}, Security: Token}
+	GetVoters           = openapi.Doc{Summary: "The voters of an unconfirmed service", Tags: unconfirmed, Params: []interface{}{stages.IdRequest{}}}
+)
+
+//The docs of the review routes
+var (
+	ReviewById         = openapi.Doc{Summary: "A review by id", Tags: reviews, Params: []interface{}{stages.ReviewIdRequest{}, FieldsQuery{}}}
+	UpdateReview       = openapi.Doc{Summary: "Change the body of a review", Tags: reviews, Params: []interface{}{stages.UpdateReviewRequest{}}, Security: Token}
+	DeleteReview       = openapi.Doc{Summary: "Delete a review", Tags: reviews, Params: []interface{}{stages.ReviewIdRequest{}}, Security: Token}
+	CreateReview       = openapi.Doc{Summary: "Review a service", Tags: reviews, Params: []interface{}{stages.ReviewRequest{}}, Security: Token}
+	ReviewByServiceId  = openapi.Doc{Summary: "The reviews of a service", Tags: reviews, Params: Listing(stages.QueryReviewRequest{}, FieldsQuery{}), Extra: []openapi.Param{Filter}}
+	ReviewAverageScore = openapi.Doc{Summary: "The average score of the reviews of a service", Tags: reviews, Params: []interface{}{stages.ServiceIdRequest{}}}
+)
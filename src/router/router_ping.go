@@ -3,6 +3,7 @@ package router
 import (
 	"log"
 	"net/http"
+	"streelity/v1/openapi"
 
 	"github.com/gorilla/mux"
 )
@@ -15,5 +16,5 @@ func ping(w http.ResponseWriter, req *http.Request) {
 
 func HandlePing(router *mux.Router) {
 	log.Println("[Router]", "Handling ping")
-	router.HandleFunc("/ping", ping).Methods("GET", "POST")
+	openapi.Declare(router.HandleFunc("/ping", ping).Methods("GET", "POST"), openapi.Doc{Summary: "Check the server", Tags: []string{"health"}})
 }
@@ -0,0 +1,60 @@
+package routertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+//Case is a request of the table tests of the handlers and the response which is expected, the length of Field in the
+//response is checked when Field is not empty
+type Case struct {
+	Name   string
+	Method string
+	Path   string
+	Form   url.Values
+	Status bool
+	Field  string
+	Length int
+}
+
+//Serve send a request to router, form is sent as an url-encoded body and token as the Auth header, the decoded json
+//body of the response is returned
+func Serve(router http.Handler, method, path string, form url.Values, token string) (res map[string]interface{}) {
+	req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	if token != "" {
+		req.Header.Set("Auth", token)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	json.Unmarshal(rr.Body.Bytes(), &res)
+	return
+}
+
+//Run serve the cases in order to router with token, the cases are sharing the data so a case can depend on the
+//previous ones
+func Run(t *testing.T, router http.Handler, token string, cases []Case) {
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			res := Serve(router, c.Method, c.Path, c.Form, token)
+			if res["Status"] != c.Status {
+				t.Fatalf("%v %v returned wrong status: got %v want %v, message %v", c.Method, c.Path, res["Status"], c.Status, res["Message"])
+			}
+
+			if c.Field != "" {
+				items, _ := res[c.Field].([]interface{})
+				if len(items) != c.Length {
+					t.Errorf("%v %v returned wrong %v: got %v want %v", c.Method, c.Path, c.Field, len(items), c.Length)
+				}
+			}
+		})
+	}
+}
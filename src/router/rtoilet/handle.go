@@ -0,0 +1,22 @@
+package rtoilet
+
+import (
+	"streelity/v1/model/toilet"
+
+	"github.com/gorilla/mux"
+)
+
+//handlers serve the toilet routes, they access the data through repository which is injected by Handle
+type handlers struct {
+	repository toilet.Repository
+}
+
+//Handle register the toilet handlers to the router, every handler access the data through repo
+func Handle(router *mux.Router, repo toilet.Repository) *mux.Router {
+	h := handlers{repository: repo}
+	s := h.HandleService(router)
+	h.HandleReview(s)
+	h.HandleUnconfirmed(router)
+
+	return s
+}
@@ -0,0 +1,61 @@
+package rtoilet
+
+import (
+	"streelity/v1/model"
+	"streelity/v1/model/toilet"
+	"streelity/v1/sres"
+)
+
+//latestReviews is the number of the reviews which are embedded by `include=reviews`
+const latestReviews = 3
+
+//includes are the related data which the toilet services embed by the `include` param, every include reads the
+//data of the whole page at once
+func (h handlers) includes() sres.Includes {
+	return sres.Includes{
+		"score": func(ids []int64) (map[int64]interface{}, error) {
+			averages := h.repository.ReviewAverageScores(ids...)
+			scores := make(map[int64]interface{})
+			for _, id := range ids {
+				scores[id] = averages[id]
+			}
+
+			return scores, nil
+		},
+		"reviews": func(ids []int64) (map[int64]interface{}, error) {
+			//the reviews are the newest first
+			reviews, e := h.repository.ReviewsByServices(ids...)
+			latest := make(map[int64][]toilet.Review)
+			for _, r := range reviews {
+				if len(latest[r.ServiceId]) < latestReviews {
+					latest[r.ServiceId] = append(latest[r.ServiceId], r)
+				}
+			}
+
+			included := make(map[int64]interface{})
+			for _, id := range ids {
+				included[id] = latest[id]
+			}
+
+			return included, e
+		},
+		"open": func(ids []int64) (map[int64]interface{}, error) {
+			reports, e := h.repository.ReportsByServices(ids...)
+			if e != nil {
+				return nil, e
+			}
+
+			byService := make(map[int64][]model.Report)
+			for _, r := range reports {
+				byService[r.ServiceId] = append(byService[r.ServiceId], r)
+			}
+
+			open := make(map[int64]interface{})
+			for _, service := range h.repository.ServicesByIds(ids...) {
+				open[service.Id] = model.IsOpen(service.GetState(), byService[service.Id])
+			}
+
+			return open, nil
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package rtoilet_test
+
+import (
+	"net/url"
+	"streelity/v1/model"
+	"streelity/v1/model/toilet"
+	"streelity/v1/router/routertest"
+	"streelity/v1/router/rtoilet"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandlers(t *testing.T) {
+	repo := toilet.NewMemory()
+	router := mux.NewRouter()
+	rtoilet.Handle(router, repo)
+	token, _ := model.CreateToken(1)
+
+	repo.CreateService(toilet.Toilet{Service: model.Service{Lat: 10, Lon: 106, Address: "1 Nguyen Hue", Contributor: "Streetlity"}, Name: "First"})
+	repo.CreateService(toilet.Toilet{Service: model.Service{Lat: 11, Lon: 107, Address: "2 Le Loi", Confident: 1, Contributor: "1"}, Name: "Second"})
+	repo.CreateReview(2, "1", 4, "good")
+
+	tests := []routertest.Case{
+		{Name: "get by id", Method: "GET", Path: "/toilet/?id=1", Status: true},
+		{Name: "get missing id", Method: "GET", Path: "/toilet/?id=100"},
+		{Name: "get without param", Method: "GET", Path: "/toilet/"},
+		{Name: "get by address", Method: "GET", Path: "/toilet/s?address=le+loi", Status: true, Field: "Services", Length: 1},
+		{Name: "all", Method: "GET", Path: "/toilet/all", Status: true, Field: "Services", Length: 2},
+		{Name: "range", Method: "GET", Path: "/toilet/range?location=10&location=106&range=0.5", Status: true, Field: "Services", Length: 1},
+		{Name: "range without location", Method: "GET", Path: "/toilet/range?range=0.5"},
+		{Name: "create", Method: "POST", Path: "/toilet/create", Form: url.Values{"location": {"12", "108"}, "address": {"3 Hai Ba Trung"}, "name": {"Station"}}, Status: true},
+		{Name: "create existed location", Method: "POST", Path: "/toilet/create", Form: url.Values{"location": {"12", "108"}, "address": {"3 Hai Ba Trung"}, "name": {"Station"}}},
+		{Name: "create without address", Method: "POST", Path: "/toilet/create", Form: url.Values{"location": {"13", "108"}}},
+		{Name: "update", Method: "POST", Path: "/toilet/update", Form: url.Values{"id": {"2"}, "note": {"updated"}}, Status: true},
+		{Name: "create review", Method: "POST", Path: "/toilet/review/create", Form: url.Values{"service_id": {"2"}, "reviewer": {"a"}, "score": {"5"}, "body": {"great"}}, Status: true},
+		{Name: "create review without score", Method: "POST", Path: "/toilet/review/create", Form: url.Values{"service_id": {"2"}, "reviewer": {"a"}, "body": {"great"}}},
+		{Name: "query review", Method: "GET", Path: "/toilet/review/query?service_id=2&order=0&limit=10", Status: true, Field: "Reviews", Length: 2},
+		{Name: "update review", Method: "POST", Path: "/toilet/review/", Form: url.Values{"review_id": {"3"}, "new_body": {"changed"}}, Status: true},
+		{Name: "unconfirmed range", Method: "GET", Path: "/toilet_ucf/range?location=11&location=107&range=0.5", Status: true, Field: "Services", Length: 1},
+		{Name: "upvote", Method: "POST", Path: "/toilet_ucf/upvote", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "upvote missing service", Method: "POST", Path: "/toilet_ucf/upvote", Form: url.Values{"id": {"100"}}},
+		{Name: "downvote", Method: "POST", Path: "/toilet_ucf/downvote", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "voters", Method: "GET", Path: "/toilet_ucf/voters?id=2", Status: true, Field: "Votes", Length: 1},
+		{Name: "withdraw", Method: "POST", Path: "/toilet_ucf/withdraw", Form: url.Values{"id": {"2"}}, Status: true},
+		{Name: "withdraw missing vote", Method: "POST", Path: "/toilet_ucf/withdraw", Form: url.Values{"id": {"2"}}},
+		{Name: "voters after withdraw", Method: "GET", Path: "/toilet_ucf/voters?id=2", Status: true, Field: "Votes"},
+	}
+
+	routertest.Run(t, router, token, tests)
+}
@@ -2,7 +2,11 @@ package rtoilet
 
 import (
 	"net/http"
+	"streelity/v1/middleware"
+	"streelity/v1/model"
 	"streelity/v1/model/toilet"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
 	"streelity/v1/sres"
 	"streelity/v1/stages"
 
@@ -11,7 +15,7 @@ import (
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
-func GetUnconfirmed(w http.ResponseWriter, req *http.Request) {
+func (h handlers) GetUnconfirmed(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Service toilet.ToiletUcf
@@ -29,7 +33,7 @@ func GetUnconfirmed(w http.ResponseWriter, req *http.Request) {
 		switch c {
 		case 1:
 			id := p.GetInt("Id")[0]
-			if service, e := toilet.UcfById(id); e != nil {
+			if service, e := h.repository.UcfById(id); e != nil {
 				res.Error(e)
 			} else {
 				res.Service = service
@@ -38,7 +42,7 @@ func GetUnconfirmed(w http.ResponseWriter, req *http.Request) {
 		case 2:
 			lat := p.GetFloat("Lat")[0]
 			lon := p.GetFloat("Lon")[0]
-			if service, e := toilet.UcfByLocation(lat, lon); e != nil {
+			if service, e := h.repository.UcfByLocation(lat, lon); e != nil {
 				res.Error(e)
 			} else {
 				res.Service = service
@@ -46,7 +50,7 @@ func GetUnconfirmed(w http.ResponseWriter, req *http.Request) {
 			break
 		case 3:
 			address := p.GetString("Address")[0]
-			if service, e := toilet.UcfByAddress(address); e != nil {
+			if service, e := h.repository.UcfByAddress(address); e != nil {
 				res.Error(e)
 			} else {
 				res.Service = service
@@ -55,12 +59,13 @@ func GetUnconfirmed(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func GetUnconfirmeds(w http.ResponseWriter, req *http.Request) {
+func (h handlers) GetUnconfirmeds(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Services []toilet.ToiletUcf
 	}
 	res.Status = true
@@ -72,27 +77,35 @@ func GetUnconfirmeds(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		address := p.GetString("Address")[0]
-		if services, e := toilet.UcfsByAddress(address); e != nil {
-			res.Error(e)
-		} else {
-			res.Services = services
+		q, e := stages.PageQuery(req)
+		if e == nil {
+			res.Services, res.Page, e = h.repository.PageUcfsByAddress(address, q)
 		}
+
+		res.Error(e)
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func GetAllUnconfirmed(w http.ResponseWriter, req *http.Request) {
+func (h handlers) GetAllUnconfirmed(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Services []toilet.ToiletUcf
 	}
 	res.Status = true
-	res.Services = toilet.AllToiletUcfs()
-	sres.WriteJson(w, res)
+	q, e := stages.PageQuery(req)
+	if e == nil {
+		res.Services, res.Page, e = h.repository.PageUcfs(q)
+	}
+
+	res.Error(e)
+
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func UpvoteUnconfirmed(w http.ResponseWriter, req *http.Request) {
+func (h handlers) UpvoteUnconfirmed(w http.ResponseWriter, req *http.Request) {
 	var res sres.Response = sres.Response{Status: true}
 
 	req.ParseForm()
@@ -103,18 +116,10 @@ func UpvoteUnconfirmed(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		id := p.GetInt("ServiceId")[0]
-		t := p.GetStringFirstOrDefault("UpvoteType")
-
-		switch t {
-		case "Immediately":
-			if e := toilet.UpvoteServiceImmediately(id); e != nil {
-				res.Error(e)
-			}
-			break
-		default:
-			if e := toilet.UpvoteService(id); e != nil {
-				res.Error(e)
-			}
+		voter := p.GetString("UpvoteUser")[0]
+		//the upvote of a trusted source confirm the service at once, see config.Policy
+		if e := h.repository.UpvoteService(id, voter); e != nil {
+			res.Error(e)
 		}
 
 	}
@@ -122,7 +127,7 @@ func UpvoteUnconfirmed(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func DownvoteService(w http.ResponseWriter, req *http.Request) {
+func (h handlers) DownvoteService(w http.ResponseWriter, req *http.Request) {
 	var res sres.Response = sres.Response{Status: true, Message: "Downvote successfully"}
 
 	req.ParseForm()
@@ -133,22 +138,19 @@ func DownvoteService(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		id := p.GetInt("ServiceId")[0]
-		t := p.GetStringFirstOrDefault("UpvoteType")
-
-		switch t {
-		default:
-			if e := toilet.DownvoteService(id); e != nil {
-				res.Error(e)
-			}
+		voter := p.GetString("UpvoteUser")[0]
+		if e := h.repository.DownvoteService(id, voter); e != nil {
+			res.Error(e)
 		}
 	}
 
 	sres.WriteJson(w, res)
 }
 
-func UnconfirmedInRange(w http.ResponseWriter, req *http.Request) {
+func (h handlers) UnconfirmedInRange(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
+		model.Page
 		Services []toilet.Toilet
 	}
 	res.Status = true
@@ -161,39 +163,95 @@ func UnconfirmedInRange(w http.ResponseWriter, req *http.Request) {
 	if res.Status {
 		location := r2.Point{X: p.GetFloatFirstOrDefault("Lat"), Y: p.GetFloatFirstOrDefault("Lon")}
 		r := p.GetFloatFirstOrDefault("Range")
-		res.Services = toilet.UcfInRange(location, r)
+		q, e := stages.PageQuery(req)
+		if e == nil {
+			res.Services, res.Page, e = h.repository.PageServicesInRange(location, r, q, model.StatePending)
+		}
+
+		res.Error(e)
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func DeleteUnconfirmed(w http.ResponseWriter, req *http.Request) {
+func (h handlers) DeleteUnconfirmed(w http.ResponseWriter, req *http.Request) {
 	var res sres.Response = sres.Response{Status: true}
 
 	req.ParseForm()
 	p := pipeline.NewPipeline()
-	stage := stages.IdValidateStage(req.PostForm)
+	stage := stages.IdValidateStage(req.URL.Query())
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		id := p.GetIntFirstOrDefault("Id")
+		if ucf, e := h.repository.UcfById(id); e == nil && !model.CanEdit(req.Context(), ucf.Contributor) {
+			middleware.Forbid(w, req)
+			return
+		}
+
+		if e := h.repository.DeleteUcf(id, model.ActorOf(req.Context())); e != nil {
+			res.Error(e)
+		}
+	}
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) WithdrawVote(w http.ResponseWriter, req *http.Request) {
+	var res sres.Response = sres.Response{Status: true, Message: "Withdraw vote successfully"}
+
+	req.ParseForm()
+	p := pipeline.NewPipeline()
+	stage := stages.UpvoteValidateStage(req)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		id := p.GetInt("ServiceId")[0]
+		voter := p.GetString("UpvoteUser")[0]
+		if e := h.repository.WithdrawVote(id, voter); e != nil {
+			res.Error(e)
+		}
+	}
+
+	sres.WriteJson(w, res)
+}
+
+func (h handlers) GetVoters(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Votes []model.Vote
+	}
+	res.Status = true
+
+	p := pipeline.NewPipeline()
+	stage := stages.IdValidateStage(req.URL.Query())
 	p.First = stage
 	res.Error(p.Run())
 
 	if res.Status {
 		id := p.GetIntFirstOrDefault("Id")
-		if e := toilet.DeleteUcf(id); e != nil {
+		if votes, e := h.repository.VotesByService(id); e != nil {
 			res.Error(e)
+		} else {
+			res.Votes = votes
 		}
 	}
+
 	sres.WriteJson(w, res)
 }
 
-func HandleUnconfirmed(router *mux.Router) *mux.Router {
+func (h handlers) HandleUnconfirmed(router *mux.Router) *mux.Router {
 	s := router.PathPrefix("/toilet_ucf").Subrouter()
 
-	s.HandleFunc("/", GetUnconfirmed).Methods("GET")
-	s.HandleFunc("/", GetAllUnconfirmed).Methods("GET")
-	s.HandleFunc("/", DeleteUnconfirmed).Methods("DELETE")
-	s.HandleFunc("/s", GetUnconfirmeds).Methods("GET")
-	s.HandleFunc("/range", UnconfirmedInRange).Methods("GET")
-	s.HandleFunc("/upvote", UpvoteUnconfirmed).Methods("POST")
-	s.HandleFunc("/downvote", DownvoteService).Methods("POST")
+	openapi.Declare(s.HandleFunc("/", h.GetUnconfirmed).Methods("GET"), routedoc.GetUnconfirmed)
+	openapi.Declare(s.HandleFunc("/all", h.GetAllUnconfirmed).Methods("GET"), routedoc.GetAllUnconfirmed)
+	openapi.Declare(s.Handle("/", middleware.Authenticate(http.HandlerFunc(h.DeleteUnconfirmed))).Methods("DELETE"), routedoc.DeleteUnconfirmed)
+	openapi.Declare(s.HandleFunc("/s", h.GetUnconfirmeds).Methods("GET"), routedoc.GetUnconfirmeds)
+	openapi.Declare(s.HandleFunc("/range", h.UnconfirmedInRange).Methods("GET"), routedoc.UnconfirmedInRange)
+	openapi.Declare(s.Handle("/upvote", middleware.Authenticate(http.HandlerFunc(h.UpvoteUnconfirmed))).Methods("POST"), routedoc.UpvoteUnconfirmed)
+	openapi.Declare(s.Handle("/downvote", middleware.Authenticate(http.HandlerFunc(h.DownvoteService))).Methods("POST"), routedoc.DownvoteUnconfirmed)
+	openapi.Declare(s.Handle("/withdraw", middleware.Authenticate(http.HandlerFunc(h.WithdrawVote))).Methods("POST"), routedoc.WithdrawVote)
+	openapi.Declare(s.HandleFunc("/voters", h.GetVoters).Methods("GET"), routedoc.GetVoters)
 	return s
 }
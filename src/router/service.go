@@ -3,11 +3,14 @@ package router
 import (
 	"log"
 	"net/http"
+	"streelity/v1/config"
 	"streelity/v1/middleware"
 	"streelity/v1/model/atm"
 	"streelity/v1/model/fuel"
 	"streelity/v1/model/maintenance"
 	"streelity/v1/model/toilet"
+	"streelity/v1/openapi"
+	"streelity/v1/router/routedoc"
 	"streelity/v1/sres"
 	"streelity/v1/stages"
 
@@ -16,7 +19,12 @@ import (
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
-func ServiceInRange(w http.ResponseWriter, req *http.Request) {
+//handlers serve the routes which are not belong to a service type, they access the data through repositories
+type handlers struct {
+	repositories Repositories
+}
+
+func (h handlers) ServiceInRange(w http.ResponseWriter, req *http.Request) {
 	var res struct {
 		sres.Response
 		Fuels        []fuel.Fuel
@@ -37,24 +45,63 @@ func ServiceInRange(w http.ResponseWriter, req *http.Request) {
 		max_range := p.GetFloatFirstOrDefault("Range")
 		var location r2.Point = r2.Point{X: lat, Y: lon}
 
-		res.Fuels = fuel.ServicesInRange(location, max_range)
-		res.Atms = atm.ServicesInRange(location, max_range)
-		res.Maintenances = maintenance.ServicesInRange(location, max_range)
-		res.Toilets = toilet.ServicesInRange(location, max_range)
+		res.Fuels = h.repositories.Fuel.ServicesInRange(location, max_range)
+		res.Atms = h.repositories.Atm.ServicesInRange(location, max_range)
+		res.Maintenances = h.repositories.Maintenance.ServicesInRange(location, max_range)
+		res.Toilets = h.repositories.Toilet.ServicesInRange(location, max_range)
+	}
+
+	sres.WriteShaped(w, req, res, nil)
+}
+
+//ServiceInRangeV2 is ServiceInRange of the api version 2, the services are grouped by their service type
+func (h handlers) ServiceInRangeV2(w http.ResponseWriter, req *http.Request) {
+	var res struct {
+		sres.Response
+		Services map[string]interface{}
+		Total    int
+	}
+	res.Status = true
+
+	p := pipeline.NewPipeline()
+	stage := stages.InRangeServiceValidateStage(req)
+	p.First = stage
+	res.Error(p.Run())
+
+	if res.Status {
+		location := r2.Point{X: p.GetFloatFirstOrDefault("Lat"), Y: p.GetFloatFirstOrDefault("Lon")}
+		max_range := p.GetFloatFirstOrDefault("Range")
+
+		fuels := h.repositories.Fuel.ServicesInRange(location, max_range)
+		atms := h.repositories.Atm.ServicesInRange(location, max_range)
+		maintenances := h.repositories.Maintenance.ServicesInRange(location, max_range)
+		toilets := h.repositories.Toilet.ServicesInRange(location, max_range)
+		res.Services = map[string]interface{}{
+			fuel.ServiceTableName:        fuels,
+			atm.ServiceTableName:         atms,
+			maintenance.ServiceTableName: maintenances,
+			toilet.ServiceTableName:      toilets,
+		}
+		res.Total = len(fuels) + len(atms) + len(maintenances) + len(toilets)
 	}
 
-	sres.WriteJson(w, res)
+	sres.WriteShaped(w, req, res, nil)
 }
 
-func HandleService(router *mux.Router) {
+func HandleService(router *mux.Router, repos Repositories) {
 	log.Println("[Router]", "Handling service")
+	h := handlers{repositories: repos}
 
 	s := router.PathPrefix("/service").Subrouter()
-	s.HandleFunc("/range", ServiceInRange).Methods("GET")
-	HandleFuel(s)
-	HandleAtm(s)
-	HandleToilet(s)
-	HandleMaintenance(s)
+	openapi.Declare(s.Handle("/range", new(middleware.Versioned).Version("1.0.0", h.ServiceInRange).Version("2.0.0", h.ServiceInRangeV2)).Methods("GET"), openapi.Doc{
+		Summary: "The services of every type in the range of a location, they are grouped by type since version 2", Tags: []string{"services"},
+		Params: []interface{}{stages.RangeRequest{}, routedoc.FieldsQuery{}},
+	})
+	HandleFuel(s, repos.Fuel)
+	HandleAtm(s, repos.Atm)
+	HandleToilet(s, repos.Toilet)
+	HandleMaintenance(s, repos.Maintenance)
 
-	middleware.Versioning(s, "1.0.0", "2.1.0")
+	versions := config.Config.Versions
+	middleware.Versioning(s, versions.Min, versions.Max, versions.Deprecated...)
 }
@@ -63,8 +63,7 @@ func addAtm(w http.ResponseWriter, req *http.Request) {
 		_, err := atm.CreateUcf(s)
 
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
+			res.Error(err)
 		} else {
 			res.Message = "Create new atm is succeed"
 		}
@@ -94,7 +93,11 @@ func upvoteAtm(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		var id int64 = p.GetInt("Id")[0]
-		res.Error(atm.UpvoteUcf(id))
+		if user, ok := model.UserFromContext(req.Context()); !ok {
+			res.Error(errors.New("voter is not authenticated"))
+		} else {
+			res.Error(atm.UpvoteUcf(id, user.GetName()))
+		}
 	}
 
 	sres.WriteJson(w, res)
@@ -234,10 +237,7 @@ func getBanks(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func HandleAtm(router *mux.Router) {
+func HandleAtm(router *mux.Router, repo atm.Repository) {
 	log.Println("[Router]", "Handling Atm")
-	s := ratm.HandleService(router)
-	ratm.HandleReview(s)
-	ratm.HandleBank(s)
-	ratm.HandleUnconfirmed(router)
+	ratm.Handle(router, repo)
 }
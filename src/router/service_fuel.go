@@ -72,8 +72,7 @@ func updateFuel(w http.ResponseWriter, req *http.Request) {
 		var f fuel.Fuel
 		id, _ := strconv.ParseInt(form["id"][0], 10, 64)
 		if err := model.Db.Where(&fuel.Fuel{Service: model.Service{Id: id}}).First(&f).Error; err != nil {
-			res.Status = false
-			res.Message = err.Error()
+			res.Error(err)
 		}
 
 	}
@@ -110,8 +109,7 @@ func addFuel(w http.ResponseWriter, req *http.Request) {
 		_, err := fuel.CreateUcf(s)
 
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
+			res.Error(err)
 		} else {
 			res.Message = "Create new fuel is succeed"
 		}
@@ -269,7 +267,11 @@ func upvoteFuel(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		var id int64 = p.GetInt("Id")[0]
-		res.Error(fuel.UpvoteUcf(id))
+		if user, ok := model.UserFromContext(req.Context()); !ok {
+			res.Error(errors.New("voter is not authenticated"))
+		} else {
+			res.Error(fuel.UpvoteUcf(id, user.GetName()))
+		}
 	}
 
 	sres.WriteJson(w, res)
@@ -318,9 +320,7 @@ func getFuel(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func HandleFuel(router *mux.Router) {
+func HandleFuel(router *mux.Router, repo fuel.Repository) {
 	log.Println("[Router]", "Handling fuel")
-	s := rfuel.HandleService(router)
-	rfuel.HandleReview(s)
-	rfuel.HandleUnconfirmed(router)
+	rfuel.Handle(router, repo)
 }
@@ -2,18 +2,15 @@ package router
 
 import (
 	"log"
+	"streelity/v1/model/maintenance"
 	"streelity/v1/router/rmaintenance"
 
 	"github.com/gorilla/mux"
 )
 
-func HandleMaintenance(router *mux.Router) {
+func HandleMaintenance(router *mux.Router, repo maintenance.Repository) {
 	log.Println("[Router]", "Handling fuel")
-	s := rmaintenance.HandleService(router)
-	rmaintenance.HandleReview(s)
-	rmaintenance.HandleUnconfirmed(router)
-	rmaintenance.HandleHistory(s)
-	rmaintenance.HandleOrder(s)
+	rmaintenance.Handle(router, repo)
 	// s.HandleFunc("/order", orderMaintenance).Methods("POST")
 	// s.HandleFunc("/accept", acceptOrderMaintenance).Methods("POST")
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"streelity/v1/middleware"
 	"streelity/v1/model/maintenance"
 	"streelity/v1/sres"
 
@@ -80,5 +81,5 @@ func HandleMaintenanceHistory(router *mux.Router) {
 	log.Println("[Router]", "Handling maintenance history")
 	s := router.PathPrefix("/history").Subrouter()
 	s.HandleFunc("/", getMaintenanceHistories).Methods("GET")
-	s.HandleFunc("/", removeMaintenanceHistory).Methods("DELETE")
+	s.Handle("/", middleware.Authenticate(middleware.Moderator(http.HandlerFunc(removeMaintenanceHistory)))).Methods("DELETE")
 }
@@ -41,8 +41,7 @@ func addToilet(w http.ResponseWriter, req *http.Request) {
 		_, err := toilet.CreateUcf(s)
 
 		if err != nil {
-			res.Status = false
-			res.Message = err.Error()
+			res.Error(err)
 		} else {
 			res.Message = "Create new fuel is succeed"
 		}
@@ -72,7 +71,11 @@ func upvoteToilet(w http.ResponseWriter, req *http.Request) {
 
 	if res.Status {
 		var id int64 = p.GetInt("Id")[0]
-		res.Error(toilet.UpvoteUcf(id))
+		if user, ok := model.UserFromContext(req.Context()); !ok {
+			res.Error(errors.New("voter is not authenticated"))
+		} else {
+			res.Error(toilet.UpvoteUcf(id, user.GetName()))
+		}
 	}
 
 	sres.WriteJson(w, res)
@@ -126,9 +129,7 @@ func getToilet(w http.ResponseWriter, req *http.Request) {
 	sres.WriteJson(w, res)
 }
 
-func HandleToilet(router *mux.Router) {
+func HandleToilet(router *mux.Router, repo toilet.Repository) {
 	log.Println("[Router]", "Handling Toilet")
-	s := rtoilet.HandleService(router)
-	rtoilet.HandleReview(s)
-	rtoilet.HandleUnconfirmed(router)
+	rtoilet.Handle(router, repo)
 }
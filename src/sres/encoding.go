@@ -0,0 +1,158 @@
+package sres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"streelity/v1/sres/pb"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+//The content types of the responses, the binary ones are negotiated by the `Accept` header
+const (
+	ContentJson = "application/json"
+	//ContentMsgpack is the MessagePack of the json of the response
+	ContentMsgpack = "application/msgpack"
+	//ContentProtobuf is the Protocol Buffers of the response, see sres/pb/response.proto
+	ContentProtobuf = "application/x-protobuf"
+)
+
+//ErrNoSchema is the error of the responses which have no Protocol Buffers schema, they are written as json
+var ErrNoSchema = errors.New("response has no protobuf schema")
+
+//decodeJson decode data to the generic values, the integers are kept as json.Number so they are not encoded as floats
+func decodeJson(data []byte) (v interface{}, e error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	e = decoder.Decode(&v)
+	return
+}
+
+//sortedKeys return the keys of an object in order, the binary encodings of the same data must be the same for their
+//ETag
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for k := range object {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func msgpackLength(b *bytes.Buffer, n int, fix byte, fixMax int, codes [3]byte) {
+	switch {
+	case fix != 0 && n <= fixMax:
+		b.WriteByte(fix | byte(n))
+	case codes[0] != 0 && n <= math.MaxUint8:
+		b.Write([]byte{codes[0], byte(n)})
+	case n <= math.MaxUint16:
+		b.WriteByte(codes[1])
+		binary.Write(b, binary.BigEndian, uint16(n))
+	default:
+		b.WriteByte(codes[2])
+		binary.Write(b, binary.BigEndian, uint32(n))
+	}
+}
+
+func msgpackNumber(b *bytes.Buffer, n json.Number) {
+	i, e := strconv.ParseInt(string(n), 10, 64)
+	if e != nil {
+		f, _ := n.Float64()
+		b.WriteByte(0xcb)
+		binary.Write(b, binary.BigEndian, f)
+		return
+	}
+
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		b.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		b.WriteByte(byte(int8(i)))
+	case i > 0 && i <= math.MaxUint8:
+		b.Write([]byte{0xcc, byte(i)})
+	case i > 0 && i <= math.MaxUint16:
+		b.WriteByte(0xcd)
+		binary.Write(b, binary.BigEndian, uint16(i))
+	case i > 0 && i <= math.MaxUint32:
+		b.WriteByte(0xce)
+		binary.Write(b, binary.BigEndian, uint32(i))
+	case i >= math.MinInt8 && i < 0:
+		b.Write([]byte{0xd0, byte(int8(i))})
+	case i >= math.MinInt16 && i < 0:
+		b.WriteByte(0xd1)
+		binary.Write(b, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i < 0:
+		b.WriteByte(0xd2)
+		binary.Write(b, binary.BigEndian, int32(i))
+	default:
+		b.WriteByte(0xd3)
+		binary.Write(b, binary.BigEndian, i)
+	}
+}
+
+func msgpackValue(b *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteByte(0xc0)
+	case bool:
+		if v {
+			b.WriteByte(0xc3)
+		} else {
+			b.WriteByte(0xc2)
+		}
+	case json.Number:
+		msgpackNumber(b, v)
+	case string:
+		msgpackLength(b, len(v), 0xa0, 31, [3]byte{0xd9, 0xda, 0xdb})
+		b.WriteString(v)
+	case []interface{}:
+		msgpackLength(b, len(v), 0x90, 15, [3]byte{0, 0xdc, 0xdd})
+		for _, item := range v {
+			msgpackValue(b, item)
+		}
+	case map[string]interface{}:
+		msgpackLength(b, len(v), 0x80, 15, [3]byte{0, 0xde, 0xdf})
+		for _, k := range sortedKeys(v) {
+			msgpackValue(b, k)
+			msgpackValue(b, v[k])
+		}
+	}
+}
+
+//JsonToMsgpack encode a json document as MessagePack, the integers are the smallest ints, the other numbers are
+//float64 and the keys of the maps are sorted
+func JsonToMsgpack(data []byte) ([]byte, error) {
+	v, e := decodeJson(data)
+	if e != nil {
+		return nil, e
+	}
+
+	var b bytes.Buffer
+	msgpackValue(&b, v)
+	return b.Bytes(), nil
+}
+
+//JsonToProtobuf encode a json response as its Protocol Buffers message, it is a pb.Response or a pb.RangeResponse.
+//ErrNoSchema is returned when a key of the json is not in the messages, so no data of a response is dropped
+func JsonToProtobuf(data []byte) ([]byte, error) {
+	for _, m := range []proto.Message{&pb.Response{}, &pb.RangeResponse{}} {
+		if protojson.Unmarshal(data, m) == nil {
+			return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+		}
+	}
+
+	return nil, ErrNoSchema
+}
+
+//Encoders are the binary encodings of the json responses by their content types
+var Encoders map[string]func(data []byte) ([]byte, error) = map[string]func(data []byte) ([]byte, error){
+	ContentMsgpack:  JsonToMsgpack,
+	ContentProtobuf: JsonToProtobuf,
+}
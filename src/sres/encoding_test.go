@@ -0,0 +1,72 @@
+package sres_test
+
+import (
+	"bytes"
+	"streelity/v1/sres"
+	"streelity/v1/sres/pb"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestJsonToMsgpack(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected []byte
+	}{
+		{"object", `{"b":[true,null],"a":"x"}`, []byte{0x82, 0xa1, 'a', 0xa1, 'x', 0xa1, 'b', 0x92, 0xc3, 0xc0}},
+		{"fixint", `[1,-1,127]`, []byte{0x93, 0x01, 0xff, 0x7f}},
+		{"ints", `[300,-100,70000,-40000]`, []byte{0x94, 0xcd, 0x01, 0x2c, 0xd0, 0x9c, 0xce, 0x00, 0x01, 0x11, 0x70, 0xd2, 0xff, 0xff, 0x63, 0xc0}},
+		{"float", `1.5`, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"int64", `5000000000`, []byte{0xd3, 0, 0, 0, 0x01, 0x2a, 0x05, 0xf2, 0}},
+	}
+
+	for _, test := range tests {
+		data, e := sres.JsonToMsgpack([]byte(test.json))
+		if e != nil || !bytes.Equal(data, test.expected) {
+			t.Errorf("%v: got % x %v want % x", test.name, data, e, test.expected)
+		}
+	}
+
+	long := `"` + string(bytes.Repeat([]byte("a"), 40)) + `"`
+	if data, _ := sres.JsonToMsgpack([]byte(long)); len(data) != 42 || data[0] != 0xd9 || data[1] != 40 {
+		t.Errorf("str8: got % x", data[:2])
+	}
+}
+
+func TestJsonToProtobuf(t *testing.T) {
+	services := `{"Status":true,"Message":"","Next":"abc","Total":2,"Services":[` +
+		`{"Id":1,"Lat":10.5,"Lon":106,"Name":"First","State":"active","CreatedAt":null,"Score":4.5,"Reviews":[{"Id":3,"ServiceId":1,"Score":5,"Body":"good"}]},` +
+		`{"Id":2,"BankId":7,"Bank":{"Id":7,"Name":"ACB"}}]}`
+	data, e := sres.JsonToProtobuf([]byte(services))
+	var res pb.Response
+	if e != nil || proto.Unmarshal(data, &res) != nil {
+		t.Fatalf("services: %v", e)
+	}
+
+	if len(res.Services) != 2 || res.Next != "abc" || res.Services[0].Name != "First" || res.Services[0].Score != 4.5 ||
+		len(res.Services[0].Reviews) != 1 || res.Services[1].Bank.GetName() != "ACB" {
+		t.Errorf("services: got %v", &res)
+	}
+
+	if len(data) >= len(services) {
+		t.Errorf("services: protobuf is not smaller than the json, %v >= %v", len(data), len(services))
+	}
+
+	data, e = sres.JsonToProtobuf([]byte(`{"Status":true,"Message":"","Services":{"fuel":[{"Id":1}],"atm":[]},"Total":1}`))
+	var grouped pb.RangeResponse
+	if e != nil || proto.Unmarshal(data, &grouped) != nil || len(grouped.Services.GetFuel()) != 1 || grouped.Total != 1 {
+		t.Errorf("range: got %v %v", &grouped, e)
+	}
+
+	data, e = sres.JsonToProtobuf([]byte(`{"Status":false,"Message":"missing","Code":"not_found"}`))
+	if e != nil || proto.Unmarshal(data, &res) != nil || res.Code != "not_found" {
+		t.Errorf("error: got %v %v", &res, e)
+	}
+
+	//the payloads without a schema are not encoded instead of losing their data
+	if _, e := sres.JsonToProtobuf([]byte(`{"Status":true,"Votes":[{"Voter":"1"}]}`)); e != sres.ErrNoSchema {
+		t.Errorf("unknown payload: got %v", e)
+	}
+}
@@ -0,0 +1,115 @@
+package sres
+
+import (
+	"errors"
+	"net/http"
+	"streelity/v1/model"
+)
+
+//The codes of the errors, they are the machine-readable part of the failed responses
+const (
+	CodeBadRequest      = "bad_request"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeValidation      = "validation_failed"
+	CodeTooManyRequests = "too_many_requests"
+	CodeInternal        = "internal_error"
+)
+
+var statuses map[string]int = map[string]int{
+	CodeBadRequest:      http.StatusBadRequest,
+	CodeUnauthorized:    http.StatusUnauthorized,
+	CodeForbidden:       http.StatusForbidden,
+	CodeNotFound:        http.StatusNotFound,
+	CodeConflict:        http.StatusConflict,
+	CodeValidation:      http.StatusUnprocessableEntity,
+	CodeTooManyRequests: http.StatusTooManyRequests,
+	CodeInternal:        http.StatusInternalServerError,
+}
+
+//StatusOf return the HTTP status of the code, the unknown codes are 500
+func StatusOf(code string) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+//FieldError is the validation error of a param
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+//FieldErrors is implemented by the errors which carry the validation errors by field, they are mapped to
+//CodeValidation
+type FieldErrors interface {
+	error
+	FieldErrors() []FieldError
+}
+
+//Error is an error which carry its code and the validation errors of the fields
+type Error struct {
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+//NewError create an error of code
+func NewError(code string, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+//Status return the HTTP status of the error
+func (e *Error) Status() int {
+	return StatusOf(e.Code)
+}
+
+//codes are the codes of the kinds of the errors of model, see model.KindOf
+var codes map[error]string = map[error]string{
+	model.ErrNotFound:      CodeNotFound,
+	model.ErrConflict:      CodeConflict,
+	model.ErrInvalid:       CodeBadRequest,
+	model.ErrQuotaExceeded: CodeTooManyRequests,
+}
+
+//ErrorOf map err to an Error:
+//
+//  - an Error which is wrapped in err is returned as it is
+//  - the FieldErrors and the model.FieldError are CodeValidation with their fields
+//  - the errors of model are the codes of their kinds, see model.KindOf
+//  - the others are CodeInternal because the errors of the requests are typed where they are found, an unknown
+//    error is a failure of the server like a lost connection of the database
+func ErrorOf(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed
+	}
+
+	var fields FieldErrors
+	if errors.As(err, &fields) {
+		return &Error{Code: CodeValidation, Message: err.Error(), Fields: fields.FieldErrors()}
+	}
+
+	var field model.FieldError
+	if errors.As(err, &field) {
+		return &Error{Code: CodeValidation, Message: err.Error(), Fields: []FieldError{{Field: field.Field, Message: field.Message}}}
+	}
+
+	if code, ok := codes[model.KindOf(err)]; ok {
+		return NewError(code, err.Error())
+	}
+
+	return NewError(CodeInternal, err.Error())
+}
@@ -0,0 +1,73 @@
+package sres_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"streelity/v1/model"
+	"streelity/v1/sres"
+	"testing"
+)
+
+type fieldsError []sres.FieldError
+
+func (e fieldsError) Error() string                  { return "fields are invalid" }
+func (e fieldsError) FieldErrors() []sres.FieldError { return e }
+
+func TestErrorOf(t *testing.T) {
+	notFound := sres.NewError(sres.CodeNotFound, "service was not found")
+	tests := []struct {
+		name   string
+		err    error
+		code   string
+		status int
+	}{
+		{"typed", notFound, sres.CodeNotFound, http.StatusNotFound},
+		{"wrapped", fmt.Errorf("query: %w", notFound), sres.CodeNotFound, http.StatusNotFound},
+		{"fields", fieldsError{{Field: "id", Message: "id param is missing"}}, sres.CodeValidation, http.StatusUnprocessableEntity},
+		{"model", model.NewError(model.ErrConflict, "service is existed"), sres.CodeConflict, http.StatusConflict},
+		{"model field", fmt.Errorf("page: %w", model.FieldError{Field: "cursor", Message: "cursor is invalid"}), sres.CodeValidation, http.StatusUnprocessableEntity},
+		{"quota", model.ErrQuotaExceeded, sres.CodeTooManyRequests, http.StatusTooManyRequests},
+		{"plain", errors.New("connection is lost"), sres.CodeInternal, http.StatusInternalServerError},
+		{"unknown code", sres.NewError("unknown", "?"), "unknown", http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		e := sres.ErrorOf(test.err)
+		if e.Code != test.code || e.Status() != test.status {
+			t.Errorf("%v: got %v %v want %v %v", test.name, e.Code, e.Status(), test.code, test.status)
+		}
+	}
+
+	if sres.ErrorOf(nil) != nil {
+		t.Errorf("nil error is mapped")
+	}
+}
+
+func TestWriteJson(t *testing.T) {
+	var res struct {
+		sres.Response
+		Services []string
+	}
+	res.Status = true
+	res.Error(fieldsError{{Field: "id", Message: "id param is missing"}})
+
+	rr := httptest.NewRecorder()
+	rr.Header().Set(sres.RequestIdHeader, "abc")
+	sres.WriteJson(rr, res)
+
+	var body sres.Response
+	json.Unmarshal(rr.Body.Bytes(), &body)
+	if rr.Code != http.StatusUnprocessableEntity || body.Code != sres.CodeValidation || body.RequestId != "abc" || len(body.Fields) != 1 {
+		t.Errorf("wrong failed response: got %v %v", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	rr.Header().Set(sres.RequestIdHeader, "abc")
+	sres.WriteJson(rr, sres.Response{Status: true, Message: "ok"})
+	if rr.Code != http.StatusOK || rr.Body.String() != `{"Status":true,"Message":"ok"}` {
+		t.Errorf("wrong succeeded response: got %v %v", rr.Code, rr.Body.String())
+	}
+}
@@ -2,50 +2,114 @@ package sres
 
 import (
 	"encoding/json"
-	"errors"
 	"log"
 	"net/http"
 	"net/url"
+	"reflect"
 )
 
+//RequestIdHeader is the header which carry the id of a request, the failed responses have it in their body
+const RequestIdHeader = "X-Request-Id"
+
 //Response representing the data for a response, include
 //
 //  - `Status` : determine request is success or not
 //  - `Message` : description for an issue or state of response
+//  - `Code` : the code of the error, see ErrorOf
+//  - `Fields` : the validation errors by field
+//  - `RequestId` : the id of the failed request
 //
 type Response struct {
-	Status  bool
-	Message string
+	Status    bool
+	Message   string
+	Code      string       `json:",omitempty"`
+	Fields    []FieldError `json:",omitempty"`
+	RequestId string       `json:",omitempty"`
 }
 
-//Error validate the data of response by err
+//Error validate the data of response by err, the code of the response is mapped from err by ErrorOf
 func (res *Response) Error(err error) bool {
 	if err != nil {
+		typed := ErrorOf(err)
 		res.Status = false
-		res.Message = err.Error()
+		res.Message = typed.Message
+		res.Code = typed.Code
+		res.Fields = typed.Fields
 		return true
 	}
 
 	return false
 }
 
+//HttpStatus return the HTTP status of the response, it is 200 unless the response has an error code
+func (res Response) HttpStatus() int {
+	if res.Status || res.Code == "" {
+		return http.StatusOK
+	}
+
+	return StatusOf(res.Code)
+}
+
+//Fail create the failed response of err
+func Fail(err error) Response {
+	var res Response
+	res.Error(err)
+	return res
+}
+
+//WriteError write the failed response of err
+func WriteError(w http.ResponseWriter, err error) {
+	WriteJson(w, Fail(err))
+}
+
 func (res *Response) Write(w http.ResponseWriter) {
-	jsonData, jsonErr := json.Marshal(res)
+	WriteJson(w, res)
+}
 
-	if jsonErr != nil {
-		log.Println(jsonErr)
+//withRequestId return a copy of data whose `RequestId` is id, data is a Response or a struct which embed it
+func withRequestId(data interface{}, id string) interface{} {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		return data
 	}
 
-	w.Write(jsonData)
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	field := copied.FieldByName("RequestId")
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return data
+	}
+
+	field.SetString(id)
+	return copied.Interface()
 }
 
+//WriteJson write data as json, the status of the response is the HttpStatus of data when it is a Response or it
+//embed one. The failed responses carry the request id which is set to the `X-Request-Id` header by the middlewares
 func WriteJson(w http.ResponseWriter, data interface{}) {
+	status := http.StatusOK
+	if res, ok := data.(interface{ HttpStatus() int }); ok {
+		status = res.HttpStatus()
+	}
+
+	if id := w.Header().Get(RequestIdHeader); id != "" && status != http.StatusOK {
+		data = withRequestId(data, id)
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
 	jsonData, jsonErr := json.Marshal(data)
 
 	if jsonErr != nil {
 		log.Println(jsonErr)
 	}
 
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+	}
+
 	w.Write(jsonData)
 }
 
@@ -54,7 +118,7 @@ func ValidateParams(data url.Values, fields ...string) error {
 		_, ok := data[field]
 
 		if !ok {
-			return errors.New(" param is missing")
+			return &Error{Code: CodeValidation, Message: field + " param is missing", Fields: []FieldError{{Field: field, Message: field + " param is missing"}}}
 		}
 	}
 	return nil
@@ -0,0 +1,162 @@
+package sres
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+//Include compute the related data of the items which are having ids by their id, they are embedded into the items by
+//the `include` param so the clients don't need to request them again. An include is computed once for the items of a
+//response, the items without data get null
+type Include func(ids []int64) (map[int64]interface{}, error)
+
+//Includes are the related data which the items of a response can embed by their names
+type Includes map[string]Include
+
+//listParam return the values of a param which is repeated or separated by commas
+func listParam(values url.Values, param string) (list []string) {
+	for _, value := range values[param] {
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+
+	return
+}
+
+//includeName return the key of an included data in the items, it is capitalized like the other keys
+func includeName(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+//shapeItem keep the fields of item and embed the included data which are requested
+func shapeItem(item map[string]interface{}, fields []string, names []string, included map[string]map[int64]interface{}) map[string]interface{} {
+	shaped := item
+	if len(fields) > 0 {
+		shaped = make(map[string]interface{})
+		for key, value := range item {
+			for _, f := range fields {
+				if strings.EqualFold(key, f) {
+					shaped[key] = value
+					break
+				}
+			}
+		}
+	}
+
+	id, _ := item["Id"].(float64)
+	for _, name := range names {
+		shaped[includeName(name)] = included[name][int64(id)]
+	}
+
+	return shaped
+}
+
+//Shape apply the `fields` and `include` params of values to the items of data, they are the objects with an `Id`
+//and the arrays of them in the top level of data or in its groups. The other values of data are not changed
+func Shape(values url.Values, data interface{}, includes Includes) (interface{}, error) {
+	fields, names := listParam(values, "fields"), listParam(values, "include")
+	if len(fields) == 0 && len(names) == 0 {
+		return data, nil
+	}
+
+	for _, name := range names {
+		if _, ok := includes[name]; !ok {
+			message := "include " + name + " is unknown"
+			return nil, &Error{Code: CodeValidation, Message: message, Fields: []FieldError{{Field: "include", Message: message}}}
+		}
+	}
+
+	raw, e := json.Marshal(data)
+	if e != nil {
+		return nil, e
+	}
+
+	var top map[string]interface{}
+	if e = json.Unmarshal(raw, &top); e != nil {
+		return nil, e
+	}
+
+	isItem := func(v interface{}) (map[string]interface{}, bool) {
+		item, ok := v.(map[string]interface{})
+		if ok {
+			_, ok = item["Id"]
+		}
+
+		return item, ok
+	}
+
+	//the items are collected with the setters of their shape, so the includes are computed once for all of them
+	var items []map[string]interface{}
+	var sets []func(shaped map[string]interface{})
+	collectList := func(value interface{}) {
+		if list, ok := value.([]interface{}); ok {
+			for i, v := range list {
+				if item, ok := isItem(v); ok {
+					i := i
+					items = append(items, item)
+					sets = append(sets, func(shaped map[string]interface{}) { list[i] = shaped })
+				}
+			}
+		}
+	}
+
+	for key, value := range top {
+		if item, ok := isItem(value); ok {
+			key := key
+			items = append(items, item)
+			sets = append(sets, func(shaped map[string]interface{}) { top[key] = shaped })
+		} else if group, ok := value.(map[string]interface{}); ok {
+			//the items which are grouped like the services by type
+			for _, v := range group {
+				collectList(v)
+			}
+		} else {
+			collectList(value)
+		}
+	}
+
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		id, _ := item["Id"].(float64)
+		ids[i] = int64(id)
+	}
+
+	included := make(map[string]map[int64]interface{})
+	for _, name := range names {
+		data, e := includes[name](ids)
+		if e != nil {
+			log.Println("[Response]", "include", name, e.Error())
+		}
+
+		included[name] = data
+	}
+
+	for i, item := range items {
+		sets[i](shapeItem(item, fields, names, included))
+	}
+
+	return top, nil
+}
+
+//WriteShaped write data like WriteJson after its items are shaped by the params of req, see Shape. The failed
+//responses are written as they are
+func WriteShaped(w http.ResponseWriter, req *http.Request, data interface{}, includes Includes) {
+	if res, ok := data.(interface{ HttpStatus() int }); ok && res.HttpStatus() != http.StatusOK {
+		WriteJson(w, data)
+		return
+	}
+
+	shaped, e := Shape(req.URL.Query(), data, includes)
+	if e != nil {
+		WriteError(w, e)
+		return
+	}
+
+	WriteJson(w, shaped)
+}
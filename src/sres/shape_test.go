@@ -0,0 +1,73 @@
+package sres_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"streelity/v1/sres"
+	"testing"
+)
+
+type shapedItem struct {
+	Id         int64
+	Name       string
+	Maintainer string
+}
+
+func TestShape(t *testing.T) {
+	var res struct {
+		sres.Response
+		Service  shapedItem
+		Services []shapedItem
+		Groups   map[string][]shapedItem
+	}
+	res.Status = true
+	res.Service = shapedItem{1, "First", `{"a": "b"}`}
+	res.Services = []shapedItem{{1, "First", ""}, {2, "Second", ""}}
+	res.Groups = map[string][]shapedItem{"fuel": {{3, "Third", ""}}}
+
+	calls := 0
+	includes := sres.Includes{
+		"score": func(ids []int64) (map[int64]interface{}, error) {
+			calls++
+			scores := make(map[int64]interface{})
+			for _, id := range ids {
+				scores[id] = float64(id) / 2
+			}
+
+			return scores, nil
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"nothing", "", `{"Status":true,"Message":"","Service":{"Id":1,"Name":"First","Maintainer":"{\"a\": \"b\"}"},"Services":[{"Id":1,"Name":"First","Maintainer":""},{"Id":2,"Name":"Second","Maintainer":""}],"Groups":{"fuel":[{"Id":3,"Name":"Third","Maintainer":""}]}}`},
+		{"fields", "fields=id,name", `{"Groups":{"fuel":[{"Id":3,"Name":"Third"}]},"Message":"","Service":{"Id":1,"Name":"First"},"Services":[{"Id":1,"Name":"First"},{"Id":2,"Name":"Second"}],"Status":true}`},
+		{"include", "fields=ID&include=score", `{"Groups":{"fuel":[{"Id":3,"Score":1.5}]},"Message":"","Service":{"Id":1,"Score":0.5},"Services":[{"Id":1,"Score":0.5},{"Id":2,"Score":1}],"Status":true}`},
+	}
+
+	for _, test := range tests {
+		calls = 0
+		values, _ := url.ParseQuery(test.query)
+		shaped, e := sres.Shape(values, res, includes)
+		if e != nil {
+			t.Fatalf("%v: Shape returned error: %v", test.name, e)
+		}
+
+		data, _ := json.Marshal(shaped)
+		if string(data) != test.expected {
+			t.Errorf("%v: got %s want %s", test.name, data, test.expected)
+		}
+
+		//the include is computed once for every item of the response
+		if len(values["include"]) > 0 && calls != 1 {
+			t.Errorf("%v: include is computed %v times", test.name, calls)
+		}
+	}
+
+	if _, e := sres.Shape(url.Values{"include": {"bank"}}, res, includes); sres.ErrorOf(e).Code != sres.CodeValidation {
+		t.Errorf("unknown include is not rejected: %v", e)
+	}
+}
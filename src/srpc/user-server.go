@@ -2,9 +2,12 @@
 package srpc
 
 import (
+	"log"
 	"net/http"
 	"net/url"
+	"strconv"
 	"streelity/v1/config"
+	"streelity/v1/model"
 )
 
 func RequestNotify(values url.Values) (resp *http.Response, e error) {
@@ -13,3 +16,20 @@ func RequestNotify(values url.Values) (resp *http.Response, e error) {
 	resp, e = http.PostForm(host, values)
 	return
 }
+
+//Notify deliver the notification to the contributor through the user server, it is using for model.Notifier
+func Notify(n model.Notification) {
+	resp, e := RequestNotify(url.Values{
+		"user":         {n.User},
+		"message":      {n.Message},
+		"service_type": {n.ServiceType},
+		"service_id":   {strconv.FormatInt(n.ServiceId, 10)},
+	})
+
+	if e != nil {
+		log.Println("[Notify]", n.User, ":", e.Error())
+		return
+	}
+
+	resp.Body.Close()
+}
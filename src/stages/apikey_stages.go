@@ -0,0 +1,38 @@
+package stages
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/nvnamsss/goinf/pipeline"
+)
+
+//ApiKeyRequest is the request of issuing an api key. type and endpoint can be repeated, the key is read-only unless
+//read_only is false and the quotas are unlimited when they are missing
+type ApiKeyRequest struct {
+	Name         string   `param:"name" from:"form" required:"true"`
+	Type         []string `param:"type" from:"form"`
+	Endpoint     []string `param:"endpoint" from:"form" regex:"^/[^,]*$"`
+	ReadOnly     bool     `param:"read_only" from:"form" default:"true"`
+	DailyQuota   int64    `param:"daily_quota" from:"form" min:"0"`
+	MonthlyQuota int64    `param:"monthly_quota" from:"form" min:"0"`
+	Types        string
+	Endpoints    string
+}
+
+func (r *ApiKeyRequest) Resolve() error {
+	for _, t := range r.Type {
+		if t == "" || strings.Contains(t, ",") {
+			return invalid("type", "type param must be a service type")
+		}
+	}
+
+	r.Types = strings.Join(r.Type, ",")
+	r.Endpoints = strings.Join(r.Endpoint, ",")
+	return nil
+}
+
+//ApiKeyValidateStage create the validated stage for issuing an api key
+func ApiKeyValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, ApiKeyRequest{})
+}
@@ -1 +1,16 @@
 package stages
+
+import (
+	"net/url"
+
+	"github.com/nvnamsss/goinf/pipeline"
+)
+
+//BankRequest is the bank of an atm which is created
+type BankRequest struct {
+	BankId int64 `param:"bank_id" from:"form" required:"true"`
+}
+
+func BankValidate(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, BankRequest{})
+}
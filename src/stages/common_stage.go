@@ -1,11 +1,9 @@
 package stages
 
 import (
-	"errors"
-	"log"
 	"net/http"
 	"net/url"
-	"strconv"
+	"streelity/v1/model"
 
 	"github.com/nvnamsss/goinf/pipeline"
 )
@@ -13,427 +11,263 @@ import (
 /*Containing commom stage for pipeline, in order to reduce the effort and lines of code to implement
 the pipeline in request handle*/
 
-//ServiceValidateStage create the validated stage for adding a new service
-func CreateServiceValidate(req *http.Request) *pipeline.Stage {
-	req.ParseForm()
-	s := pipeline.NewStage(func() (str struct {
-		Lat         float64
-		Lon         float64
-		Address     string
-		Note        string
-		Contributor string
-		Images      []string
-	}, e error) {
-		form := req.PostForm
-		log.Println("[Pipeline]", form)
-		location, locationOk := form["location"]
-		_, addressOk := form["address"]
-		if !locationOk {
-			return str, errors.New("location param is missing")
-		} else {
-			if len(location) < 2 {
-				return str, errors.New("location param must have 2 values")
-			}
-		}
-
-		if !addressOk {
-			return str, errors.New("address param is missing")
-		} else {
-			str.Address = form["address"][0]
-		}
-
-		lat, latErr := strconv.ParseFloat(form["location"][0], 64)
-		lon, lonErr := strconv.ParseFloat(form["location"][1], 64)
-		if latErr != nil {
-			return str, errors.New("cannot parse location[0] to float")
-		}
-		if lonErr != nil {
-			return str, errors.New("cannot parse location[1] to float")
-		}
+//CreateServiceRequest is the request of creating a service, the contributor is the authenticated user except for the
+//admins who submit on behalf of a trusted source
+type CreateServiceRequest struct {
+	Location    []float64 `param:"location" from:"form" required:"true" count:"2"`
+	Address     string    `param:"address" from:"form" required:"true"`
+	Note        string    `param:"note" from:"form"`
+	Contributor string    `param:"contributor" from:"form"`
+	Images      []string  `param:"images" from:"form"`
+	Lat         float64
+	Lon         float64
+}
 
-		_, ok := form["note"]
-		if ok {
-			str.Note = form["note"][0]
-		}
+func (r *CreateServiceRequest) Resolve() error {
+	r.Lat, r.Lon = r.Location[0], r.Location[1]
+	return nil
+}
 
-		if _, ok = form["contributor"]; ok {
-			str.Contributor = form["contributor"][0]
-		} else {
-			str.Contributor = "Streetlity"
-		}
+//ServiceValidateStage create the validated stage for adding a new service
+func CreateServiceValidate(req *http.Request) *pipeline.Stage {
+	return Declare(req, CreateServiceRequest{})
+}
 
-		str.Images = form["images"]
-		str.Lat = lat
-		str.Lon = lon
-		return
-	})
+//RangeRequest is the request of the services in the range of a location
+type RangeRequest struct {
+	Location []float64 `param:"location" from:"query" required:"true" count:"2"`
+	Range    float64   `param:"range" from:"query" required:"true"`
+	Lat      float64
+	Lon      float64
+}
 
-	return s
+func (r *RangeRequest) Resolve() error {
+	r.Lat, r.Lon = r.Location[0], r.Location[1]
+	return nil
 }
 
 func InRangeServiceValidateStage(req *http.Request) *pipeline.Stage {
-	validateParamsStage := pipeline.NewStage(func() error {
-		query := req.URL.Query()
-
-		location, locationOk := query["location"]
-		if !locationOk {
-			return errors.New("location param is missing")
-		} else {
-			if len(location) < 2 {
-				return errors.New("location param must have 2 values")
-			}
-		}
-
-		_, rangeOk := query["range"]
-		if !rangeOk {
-			return errors.New("range param is missing")
-		}
-
-		return nil
-	})
-
-	parseValueStage := pipeline.NewStage(func() (str struct {
-		Lat   float64
-		Lon   float64
-		Range float64
-	}, e error) {
-		var (
-			latErr   error
-			lonErr   error
-			rangeErr error
-		)
-		query := req.URL.Query()
-
-		str.Lat, latErr = strconv.ParseFloat(query["location"][0], 64)
-		str.Lon, lonErr = strconv.ParseFloat(query["location"][1], 64)
-		str.Range, rangeErr = strconv.ParseFloat(query["range"][0], 64)
-		if latErr != nil {
-			return str, errors.New("cannot parse location[0] to float")
-		}
-		if lonErr != nil {
-			return str, errors.New("cannot parse location[1] to float")
-		}
-		if rangeErr != nil {
-			return str, errors.New("cannot parse range to float")
-		}
-		return str, nil
-	})
+	return Declare(req, RangeRequest{})
+}
 
-	validateParamsStage.NextStage(parseValueStage)
+//QueryServiceRequest is the request of a service by id, by location or by address, Case is 1, 2 or 3 by the params
+//which are passed in that order
+type QueryServiceRequest struct {
+	Id      int64   `param:"id" from:"query"`
+	Lat     float64 `param:"lat" from:"query"`
+	Lon     float64 `param:"lon" from:"query"`
+	Address string  `param:"address" from:"query"`
+	Case    int
+}
 
-	return validateParamsStage
+func (r *QueryServiceRequest) Resolve() error {
+	switch {
+	case r.Id != 0:
+		r.Case = 1
+	case r.Lat != 0 || r.Lon != 0:
+		r.Case = 2
+	case r.Address != "":
+		r.Case = 3
+	default:
+		return invalid("id", "required at least one param id / lat - lon / address")
+	}
+
+	return nil
 }
 
 func QueryServiceValidateStage(req *http.Request) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		Id      int64
-		Lat     float64
-		Lon     float64
-		Address string
-		Case    int
-	}, e error) {
-		query := req.URL.Query()
-
-		id, ok := query["id"]
-		if ok {
-			if id, e := strconv.ParseInt(id[0], 10, 64); e != nil {
-				return str, errors.New("cannot parse id to int")
-			} else {
-				str.Id = id
-				str.Case = 1
-				return str, nil
-			}
-		}
-
-		lat, latOk := query["lat"]
-		lon, lonOk := query["lon"]
-		if latOk && lonOk {
-			lat, latOk := strconv.ParseFloat(lat[0], 64)
-			lon, lonOk := strconv.ParseFloat(lon[0], 64)
-
-			if latOk == nil && lonOk == nil {
-				str.Lat = lat
-				str.Lon = lon
-				str.Case = 2
-				return str, nil
-			}
-		}
-
-		addresses, addressOk := query["address"]
-
-		if !addressOk {
-			return str, errors.New("required at least one param id / lat - lon / address")
-		}
-
-		str.Address = addresses[0]
-		str.Case = 3
-		return
-	})
+	return Declare(req, QueryServiceRequest{})
+}
 
-	return stage
+//AddressRequest is the request of the services by address
+type AddressRequest struct {
+	Address string `param:"address" from:"query" required:"true"`
 }
 
 func QueryServicesValidateStage(req *http.Request) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		Address string
-	}, e error) {
-		query := req.URL.Query()
-		addresses, ok := query["address"]
-		if !ok {
-			return str, errors.New("address param is missing")
-		}
-
-		str.Address = addresses[0]
-		return
-	})
+	return Declare(req, AddressRequest{})
+}
 
-	return stage
+//ReviewRequest is the request of creating a review, the reviewer is the authenticated user except for the admins who
+//review on behalf of another user
+type ReviewRequest struct {
+	ServiceId int64   `param:"service_id" from:"form" required:"true"`
+	Reviewer  string  `param:"reviewer" from:"form"`
+	Score     float32 `param:"score" from:"form" required:"true" min:"0" max:"5"`
+	Body      string  `param:"body" from:"form" required:"true"`
 }
 
 func ReviewValidateStage(req *http.Request) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		ServiceId int64
-		Reviewer  string
-		Score     float32
-		Body      string
-	}, e error) {
-		form := req.PostForm
-
-		if _, ok := form["service_id"]; !ok {
-			return str, errors.New("service_id param is missing")
-		}
-
-		if _, ok := form["reviewer"]; !ok {
-			return str, errors.New("reviewer param is missing")
-		}
-
-		if _, ok := form["score"]; !ok {
-			return str, errors.New("score param is missing")
-		}
-
-		if _, ok := form["body"]; !ok {
-			return str, errors.New("body param is missing")
-		}
-
-		if i, e := strconv.ParseInt(form["service_id"][0], 10, 64); e == nil {
-			str.ServiceId = i
-		} else {
-			return str, errors.New("service_id cannot parse to int")
-		}
-
-		if f, e := strconv.ParseFloat(form["score"][0], 32); e == nil {
-			str.Score = float32(f)
-		} else {
-			return str, errors.New("score cannot parse to float")
-		}
-
-		str.Reviewer = form["reviewer"][0]
-		str.Body = form["body"][0]
-
-		return
-	})
+	return Declare(req, ReviewRequest{})
+}
 
-	return stage
+//QueryReviewRequest is the request of querying the reviews of a service, Order is the offset of the first review. The
+//reviews are paginated by the page params, see PageQuery
+type QueryReviewRequest struct {
+	ServiceId int64 `param:"service_id" from:"query" required:"true"`
+	Order     int64 `param:"order" from:"query" default:"0" min:"0"`
 }
 
 func QueryReviewByOrderValidate(req *http.Request) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		ServiceId int64
-		Order     int64
-		Limit     int64
-	}, e error) {
-		query := req.URL.Query()
-
-		_, ok := query["service_id"]
-		if !ok {
-			return str, errors.New("service_id param is missing")
-		}
-
-		_, ok = query["order"]
-		if !ok {
-			return str, errors.New("order param is missing")
-		}
-
-		_, ok = query["limit"]
-		if !ok {
-			return str, errors.New("limit param is missing")
-		}
+	return Declare(req, QueryReviewRequest{})
+}
 
-		review_id, e := strconv.ParseInt(query["service_id"][0], 10, 64)
-		if e != nil {
-			return str, errors.New("service_id cannot parse to int64")
-		}
+//UpdateServiceRequest is the request of changing a service, the other fields of the service type are read by the
+//repositories from the form
+type UpdateServiceRequest struct {
+	Id     int64    `param:"id" from:"form" required:"true"`
+	Note   string   `param:"note" from:"form"`
+	Name   string   `param:"name" from:"form"`
+	Images []string `param:"images" from:"form"`
+}
 
-		order, e := strconv.ParseInt(query["order"][0], 10, 64)
-		if e != nil {
-			return str, errors.New("order cannot parse to int64")
-		}
+func UpdateServiceValidateStage(req *http.Request) *pipeline.Stage {
+	return Declare(req, UpdateServiceRequest{})
+}
 
-		limit, e := strconv.ParseInt(query["limit"][0], 10, 64)
+//UpdateReviewRequest is the request of changing the body of a review
+type UpdateReviewRequest struct {
+	ReviewId int64  `param:"review_id" from:"form" required:"true"`
+	NewBody  string `param:"new_body" from:"form" required:"true"`
+}
 
-		str.ServiceId = review_id
-		str.Order = order
-		str.Limit = limit
-		return
-	})
-	return stage
+func UpdateReviewValidateStage(req *http.Request) *pipeline.Stage {
+	return Declare(req, UpdateReviewRequest{})
 }
 
-func UpdateServiceValidateStage(req *http.Request) *pipeline.Stage {
-	req.ParseForm()
-	stage := pipeline.NewStage(func() (str struct {
-		Id     int64
-		Note   string
-		Name   string
-		Images []string
-	}, e error) {
-		form := req.PostForm
-		if ids, ok := form["id"]; !ok {
-			return str, errors.New("id param is missing")
-		} else {
-			if id, e := strconv.ParseInt(ids[0], 10, 64); e != nil {
-				return str, errors.New("id param cannot parse to int64")
-			} else {
-				str.Id = id
-			}
-		}
+//ReviewIdRequest is the request of a review by id, it is read from the query
+type ReviewIdRequest struct {
+	ReviewId int64 `param:"review_id" from:"query" required:"true"`
+}
 
-		if images, ok := form["image"]; ok {
-			str.Images = images
-		}
+func ReviewIdValidate(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, ReviewIdRequest{})
+}
 
-		if notes, ok := form["note"]; ok {
-			str.Note = notes[0]
-		}
+//ServiceIdRequest is the request of the reviews of a service
+type ServiceIdRequest struct {
+	ServiceId int64 `param:"service_id" from:"query" required:"true"`
+}
 
-		if names, ok := form["name"]; ok {
-			str.Name = names[0]
-		}
+func ServiceIdValidate(req *http.Request) *pipeline.Stage {
+	return Declare(req, ServiceIdRequest{})
+}
 
-		return
-	})
+//IdRequest is the request of a service or an item by id, the id is read from the query of the GET and DELETE
+//requests and from the form of the others
+type IdRequest struct {
+	Id int64 `param:"id" required:"true"`
+}
 
-	return stage
+func IdValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, IdRequest{})
 }
 
-func UpdateReviewValidateStage(req *http.Request) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		ReviewId int64
-		NewBody  string
-	}, e error) {
-		form := req.PostForm
-		review_ids, ok := form["review_id"]
-		if !ok {
-			return str, errors.New("review_id param is missing")
-		}
+//ImportRequest is the request of importing the services of a file, Type is the format of the file
+type ImportRequest struct {
+	Type string `param:"type" from:"query" required:"true"`
+}
 
-		bodies, ok := form["new_body"]
-		if !ok {
-			return str, errors.New("new_body param is missing")
-		}
+func ImportValidate(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, ImportRequest{})
+}
 
-		review_id, e := strconv.ParseInt(review_ids[0], 10, 64)
-		if e != nil {
-			return str, errors.New("review_id param cannot parse to int64")
-		}
+//NameRequest is the name of a service or a bank which is created
+type NameRequest struct {
+	Name string `param:"name" from:"form" required:"true"`
+}
 
-		str.ReviewId = review_id
-		str.NewBody = bodies[0]
-		return
-	})
+func NameValidate(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, NameRequest{})
+}
 
-	return stage
+//UserRequest is the request which is querying by an user
+type UserRequest struct {
+	User string `param:"user" from:"query" required:"true"`
 }
 
-func ReviewIdValidate(values url.Values) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		ReviewId int64
-	}, e error) {
-		review_ids, ok := values["review_id"]
-		if !ok {
-			return str, errors.New("review_id param is missing")
-		}
+//UserValidateStage create the validated stage for the requests which are querying by an user
+func UserValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, UserRequest{})
+}
 
-		review_id, e := strconv.ParseInt(review_ids[0], 10, 64)
-		if e != nil {
-			return str, errors.New("review_id param cannot parse to int64")
-		}
+//SpamRequest is the request of flagging an user as spam, the user is unflagged when spam is false
+type SpamRequest struct {
+	User string `param:"user" from:"form" required:"true"`
+	Spam bool   `param:"spam" from:"form" default:"true"`
+}
 
-		str.ReviewId = review_id
-		return
-	})
+//SpamValidateStage create the validated stage for flagging an user as spam
+func SpamValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, SpamRequest{})
+}
 
-	return stage
+//StatesRequest is the lifecycle states which the services are filtered by, the state param is optional and can be
+//repeated
+type StatesRequest struct {
+	States []string `param:"state" from:"query"`
 }
 
-func ServiceIdValidate(req *http.Request) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		ServiceId int64
-	}, e error) {
-		form := req.PostForm
-		service_ids, ok := form["service_id"]
-		if !ok {
-			return str, errors.New("service_id param is missing")
+func (r *StatesRequest) Resolve() error {
+	for _, state := range r.States {
+		if !model.IsState(state) {
+			return invalid("state", "state param is not a lifecycle state")
 		}
+	}
 
-		service_id, e := strconv.ParseInt(service_ids[0], 10, 64)
-		if e != nil {
-			return str, errors.New("service_id param cannot parse to int64")
-		}
+	return nil
+}
 
-		str.ServiceId = service_id
-		return
-	})
+//StatesValidateStage create the validated stage for the requests which are filtering by the lifecycle states
+func StatesValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, StatesRequest{})
+}
 
-	return stage
+//TransitRequest is the request of changing the state of a service
+type TransitRequest struct {
+	Id     int64  `param:"id" from:"form" required:"true"`
+	State  string `param:"state" from:"form" required:"true"`
+	Reason string `param:"reason" from:"form"`
 }
 
-func IdValidateStage(values url.Values) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		Id int64
-	}, e error) {
-		_, ok := values["id"]
-		if !ok {
-			return str, errors.New("id param is missing")
-		}
+func (r *TransitRequest) Resolve() error {
+	if !model.IsState(r.State) {
+		return invalid("state", "state param is not a lifecycle state")
+	}
 
-		if id, e := strconv.ParseInt(values["id"][0], 10, 64); e != nil {
-			return str, errors.New("id cannot parse to int")
-		} else {
-			str.Id = id
-		}
+	return nil
+}
 
-		return
-	})
+//TransitValidateStage create the validated stage for the requests which are changing the state of a service
+func TransitValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, TransitRequest{})
+}
 
-	return stage
+//ReportRequest is the request of reporting a problem of a service, the location is the suggested location of the
+//wrong-location reports and duplicate_of is the original service of the duplicate reports
+type ReportRequest struct {
+	Id          int64     `param:"id" from:"form" required:"true"`
+	Kind        string    `param:"kind" from:"form" required:"true"`
+	Note        string    `param:"note" from:"form"`
+	Location    []float64 `param:"location" from:"form" count:"2"`
+	DuplicateOf int64     `param:"duplicate_of" from:"form"`
+	Lat         float64
+	Lon         float64
 }
 
-func ImportValidate(values url.Values) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		Type string
-	}, e error) {
-		types, ok := values["type"]
-		if !ok {
-			return str, errors.New("type param is missing")
-		}
-		str.Type = types[0]
-		return
-	})
+func (r *ReportRequest) Resolve() error {
+	if !model.IsReportKind(r.Kind) {
+		return invalid("kind", "kind param is not a problem report kind")
+	}
 
-	return stage
+	if len(r.Location) == 2 {
+		r.Lat, r.Lon = r.Location[0], r.Location[1]
+	}
+
+	return nil
 }
 
-func NameValidate(values url.Values) *pipeline.Stage {
-	nameStage := pipeline.NewStage(func() (str struct {
-		Name string
-	}, e error) {
-		names, ok := values["name"]
-		if !ok {
-			return str, errors.New("name param is missing")
-		}
-		str.Name = names[0]
-		return
-	})
-	return nameStage
+//ReportValidateStage create the validated stage for reporting a problem of a service
+func ReportValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, ReportRequest{})
 }
@@ -0,0 +1,329 @@
+package stages
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"streelity/v1/sres"
+	"strings"
+
+	"github.com/nvnamsss/goinf/pipeline"
+)
+
+//The sources of the declared params, see Declare
+const (
+	//SourceAny read the param from the query and the form
+	SourceAny   = ""
+	SourceQuery = "query"
+	SourceForm  = "form"
+	//SourceJson read the param from the json body, it is converted to the form by middleware.JsonBody
+	SourceJson = "json"
+)
+
+//FieldError is the validation error of a param
+type FieldError = sres.FieldError
+
+//ValidationError is the validation errors of the params of a request, they are collected by field. It is responded
+//as sres.CodeValidation with the fields
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e ValidationError) FieldErrors() []FieldError {
+	return e.Fields
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+//declaredField is a field of a declared struct and the rules of its param
+type declaredField struct {
+	index    int
+	param    string
+	from     string
+	required bool
+	def      string
+	min      *float64
+	max      *float64
+	regex    *regexp.Regexp
+	count    int
+}
+
+//declareFields read the rules of the fields of t from their tags, it panics on the invalid tags because they are
+//written by the developers:
+//
+//  - `param`: the name of the param, the fields without it are not read
+//  - `from`: query, form or json, both the query and the form are read when it is empty
+//  - `required`: true when the param must be passed
+//  - `default`: the value which is used when the param is missing
+//  - `min`, `max`: the range of the numbers
+//  - `regex`: the pattern which the strings must match
+//  - `count`: the number of the values of a repeated param, like the 2 values of `location`
+//
+//The type of the param is the type of the field: string, bool, int, int64, float32, float64 or a slice of them.
+func declareFields(t reflect.Type) (fields []declaredField) {
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag
+		param, ok := tag.Lookup("param")
+		if !ok {
+			continue
+		}
+
+		f := declaredField{index: i, param: param, from: tag.Get("from"), required: tag.Get("required") == "true", def: tag.Get("default")}
+		switch f.from {
+		case SourceAny, SourceQuery, SourceForm, SourceJson:
+		default:
+			panic(fmt.Sprintf("%v.%v: unknown source %v", t.Name(), t.Field(i).Name, f.from))
+		}
+
+		for name, bound := range map[string]**float64{"min": &f.min, "max": &f.max} {
+			if s, ok := tag.Lookup(name); ok {
+				v, e := strconv.ParseFloat(s, 64)
+				if e != nil {
+					panic(fmt.Sprintf("%v.%v: %v is not a number", t.Name(), t.Field(i).Name, name))
+				}
+
+				*bound = &v
+			}
+		}
+
+		if s, ok := tag.Lookup("regex"); ok {
+			f.regex = regexp.MustCompile(s)
+		}
+
+		if s, ok := tag.Lookup("count"); ok {
+			count, e := strconv.Atoi(s)
+			if e != nil || t.Field(i).Type.Kind() != reflect.Slice {
+				panic(fmt.Sprintf("%v.%v: count must be a number of a slice", t.Name(), t.Field(i).Name))
+			}
+
+			f.count = count
+		}
+
+		fields = append(fields, f)
+	}
+
+	return
+}
+
+//valuesOf return the values of the source of req
+func valuesOf(req *http.Request, from string) url.Values {
+	req.ParseForm()
+	switch from {
+	case SourceQuery:
+		return req.URL.Query()
+	case SourceForm, SourceJson:
+		return req.PostForm
+	}
+
+	return req.Form
+}
+
+//parseValue parse s to the kind of v and check it by the rules of f
+func (f declaredField) parseValue(v reflect.Value, s string) string {
+	var number float64
+	switch v.Kind() {
+	case reflect.String:
+		if f.regex != nil && !f.regex.MatchString(s) {
+			return f.param + " does not match " + f.regex.String()
+		}
+
+		v.SetString(s)
+		return ""
+	case reflect.Bool:
+		b, e := strconv.ParseBool(s)
+		if e != nil {
+			return f.param + " cannot parse to bool"
+		}
+
+		v.SetBool(b)
+		return ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, e := strconv.ParseInt(s, 10, v.Type().Bits())
+		if e != nil {
+			return f.param + " cannot parse to " + v.Type().String()
+		}
+
+		v.SetInt(i)
+		number = float64(i)
+	case reflect.Float32, reflect.Float64:
+		fl, e := strconv.ParseFloat(s, v.Type().Bits())
+		if e != nil {
+			return f.param + " cannot parse to float"
+		}
+
+		v.SetFloat(fl)
+		number = fl
+	default:
+		return f.param + " has an unsupported type " + v.Type().String()
+	}
+
+	if f.min != nil && number < *f.min {
+		return f.param + " must be at least " + strconv.FormatFloat(*f.min, 'f', -1, 64)
+	}
+
+	if f.max != nil && number > *f.max {
+		return f.param + " must be at most " + strconv.FormatFloat(*f.max, 'f', -1, 64)
+	}
+
+	return ""
+}
+
+//bind set the fields of out by the params of values, the errors are collected by field
+func bind(values func(from string) url.Values, fields []declaredField, out reflect.Value) error {
+	var errs ValidationError
+	for _, f := range fields {
+		params, ok := values(f.from)[f.param]
+		if !ok || len(params) == 0 {
+			switch {
+			case f.def != "":
+				params = []string{f.def}
+			case f.required:
+				errs.Fields = append(errs.Fields, FieldError{Field: f.param, Message: f.param + " param is missing"})
+				continue
+			default:
+				continue
+			}
+		}
+
+		field := out.Field(f.index)
+		if f.count > 0 && len(params) != f.count {
+			errs.Fields = append(errs.Fields, FieldError{Field: f.param, Message: f.param + " param must have " + strconv.Itoa(f.count) + " values"})
+			continue
+		}
+
+		if field.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(field.Type(), len(params), len(params))
+			for i, s := range params {
+				if message := f.parseValue(slice.Index(i), s); message != "" {
+					errs.Fields = append(errs.Fields, FieldError{Field: f.param, Message: message})
+					break
+				}
+			}
+
+			field.Set(slice)
+			continue
+		}
+
+		if message := f.parseValue(field, params[0]); message != "" {
+			errs.Fields = append(errs.Fields, FieldError{Field: f.param, Message: message})
+		}
+	}
+
+	if len(errs.Fields) > 0 {
+		return errs
+	}
+
+	return resolve(out)
+}
+
+//Resolver is implemented by the declared structs which check their params together or set the fields without a
+//param from the others, Resolve is called after the params are bound
+type Resolver interface {
+	Resolve() error
+}
+
+//resolve call Resolve of the struct of out when it is a Resolver
+func resolve(out reflect.Value) error {
+	if r, ok := out.Addr().Interface().(Resolver); ok {
+		return r.Resolve()
+	}
+
+	return nil
+}
+
+//invalid return the ValidationError of a param, it is used by the resolvers
+func invalid(param string, message string) error {
+	return ValidationError{Fields: []FieldError{{Field: param, Message: message}}}
+}
+
+var errorType reflect.Type = reflect.TypeOf((*error)(nil)).Elem()
+
+//declare create the stage which output the struct of t, its fields are set by values
+func declare(t reflect.Type, values func(from string) url.Values) *pipeline.Stage {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	fields := declareFields(t)
+	task := reflect.MakeFunc(reflect.FuncOf(nil, []reflect.Type{t, errorType}, false), func([]reflect.Value) []reflect.Value {
+		out := reflect.New(t).Elem()
+		e := reflect.Zero(errorType)
+		if err := bind(values, fields, out); err != nil {
+			e = reflect.ValueOf(&err).Elem()
+		}
+
+		return []reflect.Value{out, e}
+	})
+
+	return pipeline.NewStage(task.Interface())
+}
+
+//Declare create the validated stage of the request which is declared by the struct of v, see declareFields for the
+//tags. The outputs of the stage are the fields of the struct and the errors are returned as a ValidationError. The
+//form of req is parsed at once, so the next stages can be created from req.PostForm
+func Declare(req *http.Request, v interface{}) *pipeline.Stage {
+	req.ParseForm()
+	return declare(reflect.TypeOf(v), func(from string) url.Values {
+		return valuesOf(req, from)
+	})
+}
+
+//Bind set the fields of the struct which v point to by the params of req, it is Declare for the handlers which read
+//the params without a pipeline
+func Bind(req *http.Request, v interface{}) error {
+	out := reflect.ValueOf(v).Elem()
+	return bind(func(from string) url.Values {
+		return valuesOf(req, from)
+	}, declareFields(out.Type()), out)
+}
+
+//DeclareValues is Declare for the values which are already read, the sources of the fields are ignored
+func DeclareValues(values url.Values, v interface{}) *pipeline.Stage {
+	return declare(reflect.TypeOf(v), func(string) url.Values {
+		return values
+	})
+}
+
+//Param is a declared param and its rules, it is used to document the requests
+type Param struct {
+	Name     string
+	From     string
+	Required bool
+	Default  string
+	Min      *float64
+	Max      *float64
+	Pattern  string
+	//Count is the number of the values of a repeated param, it is 0 when the number is not fixed
+	Count int
+	//Type is the type of the field, the params of a slice are repeated
+	Type reflect.Type
+}
+
+//Params return the params which are declared by the struct of v, see Declare
+func Params(v interface{}) (params []Param) {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for _, f := range declareFields(t) {
+		p := Param{Name: f.param, From: f.from, Required: f.required, Default: f.def, Min: f.min, Max: f.max, Count: f.count, Type: t.Field(f.index).Type}
+		if f.regex != nil {
+			p.Pattern = f.regex.String()
+		}
+
+		params = append(params, p)
+	}
+
+	return
+}
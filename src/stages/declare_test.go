@@ -0,0 +1,125 @@
+package stages_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"streelity/v1/stages"
+	"strings"
+	"testing"
+
+	"github.com/nvnamsss/goinf/pipeline"
+)
+
+type searchRequest struct {
+	Type     string    `param:"type" from:"query" required:"true" regex:"^[a-z]+$"`
+	Limit    int64     `param:"limit" from:"query" default:"10" min:"1" max:"50"`
+	Location []float64 `param:"location" from:"form" required:"true"`
+	Open     bool      `param:"open" from:"form"`
+	Ignored  string
+}
+
+func TestDeclare(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		form     url.Values
+		errors   []string
+		limit    int64
+		location []float64
+	}{
+		{"valid", "type=atm&limit=5", url.Values{"location": {"10", "106"}, "open": {"true"}}, nil, 5, []float64{10, 106}},
+		{"default", "type=atm", url.Values{"location": {"10", "106"}}, nil, 10, []float64{10, 106}},
+		{"form is not query", "type=atm&location=10", url.Values{}, []string{"location"}, 0, nil},
+		{"every error", "type=ATM&limit=100", url.Values{"location": {"north"}, "open": {"maybe"}}, []string{"type", "limit", "location", "open"}, 0, nil},
+		{"missing", "", url.Values{"location": {"10"}}, []string{"type"}, 0, nil},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/search?"+test.query, strings.NewReader(test.form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		p := pipeline.NewPipeline()
+		p.First = stages.Declare(req, searchRequest{})
+		e := p.Run()
+		if len(test.errors) == 0 {
+			if e != nil {
+				t.Errorf("%v: got error %v", test.name, e)
+				continue
+			}
+
+			if limit := p.GetIntFirstOrDefault("Limit"); limit != test.limit {
+				t.Errorf("%v: got limit %v want %v", test.name, limit, test.limit)
+			}
+
+			if location := p.GetFloat("Location"); len(location) != len(test.location) || location[0] != test.location[0] {
+				t.Errorf("%v: got location %v want %v", test.name, location, test.location)
+			}
+
+			continue
+		}
+
+		v, ok := e.(stages.ValidationError)
+		if !ok {
+			t.Errorf("%v: got %v want a ValidationError", test.name, e)
+			continue
+		}
+
+		fields := []string{}
+		for _, f := range v.Fields {
+			fields = append(fields, f.Field)
+		}
+
+		if strings.Join(fields, ",") != strings.Join(test.errors, ",") {
+			t.Errorf("%v: got errors of %v want %v", test.name, fields, test.errors)
+		}
+	}
+}
+
+func TestDeclareValues(t *testing.T) {
+	p := pipeline.NewPipeline()
+	p.First = stages.IdValidateStage(url.Values{"id": {"abc"}})
+	if e := p.Run(); e == nil || e.Error() != "id cannot parse to int64" {
+		t.Errorf("wrong error: got %v", e)
+	}
+
+	p.First = stages.IdValidateStage(url.Values{"id": {"7"}})
+	if e := p.Run(); e != nil || p.GetIntFirstOrDefault("Id") != 7 {
+		t.Errorf("wrong id: got %v %v", p.GetIntFirstOrDefault("Id"), e)
+	}
+}
+
+func TestDeclareResolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		error string
+		lat   float64
+		lon   float64
+	}{
+		{"valid", "location=10&location=106&range=5", "", 10, 106},
+		{"one coordinate", "location=10&range=5", "location param must have 2 values", 0, 0},
+		{"missing range", "location=10&location=106", "range param is missing", 0, 0},
+	}
+
+	for _, test := range tests {
+		p := pipeline.NewPipeline()
+		p.First = stages.InRangeServiceValidateStage(httptest.NewRequest("GET", "/range?"+test.query, nil))
+		e := p.Run()
+		if test.error != "" {
+			if e == nil || e.Error() != test.error {
+				t.Errorf("%v: got error %v want %v", test.name, e, test.error)
+			}
+
+			continue
+		}
+
+		if e != nil {
+			t.Errorf("%v: got error %v", test.name, e)
+			continue
+		}
+
+		if lat, lon := p.GetFloatFirstOrDefault("Lat"), p.GetFloatFirstOrDefault("Lon"); lat != test.lat || lon != test.lon {
+			t.Errorf("%v: got %v %v want %v %v", test.name, lat, lon, test.lat, test.lon)
+		}
+	}
+}
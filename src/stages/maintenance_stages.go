@@ -2,70 +2,50 @@ package stages
 
 import (
 	"errors"
-	"log"
 	"net/http"
-	"net/url"
 	"strconv"
 
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
-func AddMaintainerValidate(req *http.Request) *pipeline.Stage {
-	req.ParseForm()
-	stage := pipeline.NewStage(func() (str struct {
-		ServiceId  int64
-		Maintainer string
-	}, e error) {
-		form := req.PostForm
-		log.Println(form)
-		service_ids, ok := form["service_id"]
-		if !ok {
-			return str, errors.New("service_id param is missing")
-		}
-		maintainers, ok := form["maintainer"]
-		if !ok {
-			return str, errors.New("maintainer param is missing")
-		}
+//AddMaintainerRequest is the request of adding a maintainer to a maintenance service
+type AddMaintainerRequest struct {
+	ServiceId  int64  `param:"service_id" from:"form" required:"true"`
+	Maintainer string `param:"maintainer" from:"form" required:"true"`
+}
 
-		if service_id, e := strconv.ParseInt(service_ids[0], 10, 64); e != nil {
-			return str, errors.New("service_id cannot parse to int64")
-		} else {
-			str.ServiceId = service_id
-		}
-		str.Maintainer = maintainers[0]
-		return
-	})
+func AddMaintainerValidate(req *http.Request) *pipeline.Stage {
+	return Declare(req, AddMaintainerRequest{})
+}
 
-	return stage
+//RemoveMaintainerRequest is the request of removing a maintainer from a maintenance service
+type RemoveMaintainerRequest struct {
+	ServiceId  int64  `param:"service_id" from:"query" required:"true"`
+	Maintainer string `param:"maintainer" from:"query" required:"true"`
 }
 
 func RemoveMaintainerValidate(req *http.Request) *pipeline.Stage {
-	stage := pipeline.NewStage(func() (str struct {
-		ServiceId  int64
-		Maintainer string
-	}, e error) {
-		query, _ := url.ParseQuery(req.URL.RawQuery)
-		log.Println(query)
-		service_ids, ok := query["service_id"]
-		if !ok {
-			return str, errors.New("service_id param is missing")
-		}
-		maintainers, ok := query["maintainer"]
-		if !ok {
-			return str, errors.New("maintainer param is missing")
-		}
+	return Declare(req, RemoveMaintainerRequest{})
+}
 
-		if service_id, e := strconv.ParseInt(service_ids[0], 10, 64); e != nil {
-			return str, errors.New("service_id cannot parse to int64")
-		} else {
-			str.ServiceId = service_id
-		}
-		str.Maintainer = maintainers[0]
-		return
-	})
+//CommonUserRequest is the request of the maintenance histories of a common user
+type CommonUserRequest struct {
+	CommonUser string `param:"cuser" from:"query" required:"true"`
+}
 
-	return stage
+func CommonUserValidate(req *http.Request) *pipeline.Stage {
+	return Declare(req, CommonUserRequest{})
+}
+
+//MaintenanceUserRequest is the request of the maintenance histories of a maintenance user
+type MaintenanceUserRequest struct {
+	MaintenanceUser string `param:"muser" from:"query" required:"true"`
+}
+
+func MaintenanceUserValidate(req *http.Request) *pipeline.Stage {
+	return Declare(req, MaintenanceUserRequest{})
 }
+
 func QueryMaintenanceValidate(req *http.Request) *pipeline.Stage {
 	stage := pipeline.NewStage(func() (str struct {
 		Case    int
@@ -0,0 +1,149 @@
+package stages
+
+import (
+	"net/url"
+	"strconv"
+	"streelity/v1/model"
+
+	"github.com/nvnamsss/goinf/pipeline"
+)
+
+//ModerationFilterRequest is the request of listing the moderation queue, every param is optional. kind, type and
+//status can be repeated, min_age and max_age are in days and the region is the range of location
+type ModerationFilterRequest struct {
+	Kinds       []string  `param:"kind" from:"query"`
+	Types       []string  `param:"type" from:"query"`
+	Statuses    []string  `param:"status" from:"query"`
+	Location    []float64 `param:"location" from:"query" count:"2"`
+	Range       float64   `param:"range" from:"query"`
+	MinAge      int64     `param:"min_age" from:"query"`
+	MaxAge      int64     `param:"max_age" from:"query"`
+	MinPriority int64     `param:"min_priority" from:"query"`
+	Lat         float64
+	Lon         float64
+}
+
+func (r *ModerationFilterRequest) Resolve() error {
+	for _, kind := range r.Kinds {
+		if !model.IsModerationKind(kind) {
+			return invalid("kind", "kind param is not a moderation kind")
+		}
+	}
+
+	if len(r.Location) == 2 {
+		if r.Range == 0 {
+			return invalid("range", "range param is missing")
+		}
+
+		r.Lat, r.Lon = r.Location[0], r.Location[1]
+	}
+
+	return nil
+}
+
+//ModerationFilterValidateStage create the validated stage for listing the moderation queue
+func ModerationFilterValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, ModerationFilterRequest{})
+}
+
+//ModerationActionRequest is the request of an action on a moderation item, the note is optional
+type ModerationActionRequest struct {
+	Id   int64  `param:"id" from:"form" required:"true"`
+	Note string `param:"note" from:"form"`
+}
+
+//ModerationActionValidateStage create the validated stage for the actions on a moderation item
+func ModerationActionValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, ModerationActionRequest{})
+}
+
+//AuditRequest is the request of the moderation actions of an item or an actor, both are optional
+type AuditRequest struct {
+	Id    int64  `param:"id" from:"query"`
+	Actor string `param:"actor" from:"query"`
+}
+
+//AuditValidateStage create the validated stage for the moderation actions of an item or an actor
+func AuditValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, AuditRequest{})
+}
+
+//FlagRequest is the request of flagging a review or an image of a service, the flagged review is identified by
+//review_id and the flagged image by image. Subject is the flagged review or image
+type FlagRequest struct {
+	Id       int64  `param:"id" from:"form" required:"true"`
+	Type     string `param:"type" from:"form" required:"true"`
+	Kind     string `param:"kind" from:"form" required:"true" regex:"^(review|image)$"`
+	ReviewId int64  `param:"review_id" from:"form"`
+	Image    string `param:"image" from:"form"`
+	Note     string `param:"note" from:"form"`
+	Subject  string
+}
+
+func (r *FlagRequest) Resolve() error {
+	switch r.Kind {
+	case model.ModerationReview:
+		if r.ReviewId == 0 {
+			return invalid("review_id", "review_id param is missing")
+		}
+
+		r.Subject = strconv.FormatInt(r.ReviewId, 10)
+	case model.ModerationImage:
+		if r.Image == "" {
+			return invalid("image", "image param is missing")
+		}
+
+		r.Subject = r.Image
+	}
+
+	return nil
+}
+
+//FlagValidateStage create the validated stage for flagging a review or an image of a service
+func FlagValidateStage(values url.Values) *pipeline.Stage {
+	return DeclareValues(values, FlagRequest{})
+}
+
+//ProposeRequest is the request of proposing an edit of a service, the edit is read from the params of EditRequest
+//and reason is optional
+type ProposeRequest struct {
+	Id     int64  `param:"id" from:"form" required:"true"`
+	Type   string `param:"type" from:"form" required:"true"`
+	Reason string `param:"reason" from:"form"`
+}
+
+//EditRequest is the service fields which can be proposed as an edit, they are accepted by the update of the services
+//and at least one of them is proposed
+type EditRequest struct {
+	Lat     float64  `param:"lat" from:"form"`
+	Lon     float64  `param:"lon" from:"form"`
+	Note    string   `param:"note" from:"form"`
+	Address string   `param:"address" from:"form"`
+	Images  []string `param:"images" from:"form"`
+	Name    string   `param:"name" from:"form"`
+}
+
+//ProposeValidateStage create the validated stage for proposing an edit of a service
+func ProposeValidateStage(values url.Values) *pipeline.Stage {
+	stage := DeclareValues(values, ProposeRequest{})
+	editStage := pipeline.NewStage(func() (str struct {
+		Edit string
+	}, e error) {
+		edit := url.Values{}
+		for _, p := range Params(EditRequest{}) {
+			if value, ok := values[p.Name]; ok {
+				edit[p.Name] = value
+			}
+		}
+
+		if len(edit) == 0 {
+			return str, invalid("edit", "edit is missing")
+		}
+
+		str.Edit = edit.Encode()
+		return
+	})
+
+	stage.NextStage(editStage)
+	return stage
+}
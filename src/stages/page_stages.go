@@ -0,0 +1,39 @@
+package stages
+
+import (
+	"net/http"
+	"streelity/v1/model"
+	"strings"
+
+	"github.com/golang/geo/r2"
+)
+
+//PageRequest is the params of a page of a listing, the filters are read by PageQuery
+type PageRequest struct {
+	Cursor   string    `param:"cursor" from:"query"`
+	Limit    int       `param:"limit" from:"query" min:"1"`
+	Sort     string    `param:"sort" from:"query" regex:"^-?[a-z]+$"`
+	Location []float64 `param:"location" from:"query"`
+}
+
+//PageQuery read the query of a page of a listing from the `cursor`, `limit`, `sort` and `location` params and the
+//filters like `filter[contributor]=1`
+func PageQuery(req *http.Request) (q model.PageQuery, e error) {
+	var r PageRequest
+	if e = Bind(req, &r); e != nil {
+		return
+	}
+
+	q = model.PageQuery{Cursor: r.Cursor, Limit: r.Limit, Sort: r.Sort, Filters: map[string]string{}}
+	if len(r.Location) >= 2 {
+		q.Location = &r2.Point{X: r.Location[0], Y: r.Location[1]}
+	}
+
+	for param, values := range req.URL.Query() {
+		if strings.HasPrefix(param, "filter[") && strings.HasSuffix(param, "]") && len(values) > 0 {
+			q.Filters[strings.TrimSuffix(strings.TrimPrefix(param, "filter["), "]")] = values[0]
+		}
+	}
+
+	return
+}
@@ -1,38 +1,33 @@
 package stages
 
 import (
-	"errors"
 	"net/http"
-	"strconv"
+	"streelity/v1/model"
+	"streelity/v1/sres"
 
 	"github.com/nvnamsss/goinf/pipeline"
 )
 
+//UpvoteRequest is the request of voting on a service
+type UpvoteRequest struct {
+	ServiceId int64 `param:"id" from:"form" required:"true"`
+}
+
+//UpvoteValidateStage create the validated stage for voting on a service, the voter is the user
+//which is authenticated by the request
 func UpvoteValidateStage(req *http.Request) *pipeline.Stage {
-	req.ParseForm()
 	stage := pipeline.NewStage(func() (str struct {
 		UpvoteUser string
-		ServiceId  int64
-		UpvoteType string
 	}, e error) {
-		form := req.PostForm
-
-		if ids, ok := form["id"]; !ok {
-			return str, errors.New("id param is missing")
-		} else {
-			if id, e := strconv.ParseInt(ids[0], 10, 64); e != nil {
-				return str, errors.New("id param cannot parse to int64")
-			} else {
-				str.ServiceId = id
-			}
-		}
-
-		if types, ok := form["upvote_type"]; ok {
-			str.UpvoteType = types[0]
+		user, ok := model.UserFromContext(req.Context())
+		if !ok {
+			return str, sres.NewError(sres.CodeUnauthorized, "voter is not authenticated")
 		}
 
+		str.UpvoteUser = user.GetName()
 		return
 	})
 
+	stage.NextStage(Declare(req, UpvoteRequest{}))
 	return stage
 }